@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"laguna-escondida/backend/internal/domain/aggregate/bill"
+	"laguna-escondida/backend/internal/domain/dto"
+	"laguna-escondida/backend/internal/platform/fakeprovider"
+)
+
+// The fake provider takes the place of the facturacion.v30 API in development, point
+// ELECTRONIC_INVOICE_URL at it with the same user and password
+func main() {
+	addr := flag.String("addr", ":9090", "address to listen on")
+	user := flag.String("user", "", "basic auth user required, any when empty")
+	password := flag.String("password", "", "basic auth password required, any when empty")
+	latency := flag.Duration("latency", 0, "delay added to every response")
+	processingTime := flag.Duration("processing-time", 0, "time a document stays pending at the DIAN")
+	errorRate := flag.Float64("error-rate", 0, "fraction of requests answered with a 503")
+	rejectionRate := flag.Float64("rejection-rate", 0, "fraction of documents the DIAN rejects")
+	resolutionSize := flag.Int("resolution-size", 5000, "numbers in the resolution of each prefix")
+	supplierNIT := flag.String("supplier-nit", "", "ELECTRONIC_INVOICE_SUPPLIER_NIT of the backend, the CUFE is computed with it")
+	technicalKey := flag.String("technical-key", "", "technical key of the resolution registered in the backend")
+	environment := flag.String("environment", "test", "ELECTRONIC_INVOICE_ENVIRONMENT of the backend")
+	cufeMismatch := flag.Bool("cufe-mismatch", false, "answer every invoice with a CUFE that does not match")
+	flag.Parse()
+
+	issuer := bill.Issuer{Environment: dto.DIANEnvironment(*environment)}
+	if *supplierNIT != "" {
+		var err error
+		if issuer, err = bill.NewIssuer(*supplierNIT, issuer.Environment); err != nil {
+			log.Fatalf("Invalid supplier NIT: %v", err)
+		}
+	}
+
+	server := &http.Server{
+		Addr: *addr,
+		Handler: fakeprovider.New(fakeprovider.Options{
+			User:           *user,
+			Password:       *password,
+			Latency:        *latency,
+			ProcessingTime: *processingTime,
+			ErrorRate:      *errorRate,
+			RejectionRate:  *rejectionRate,
+			ResolutionSize: *resolutionSize,
+			Issuer:         issuer,
+			TechnicalKey:   *technicalKey,
+			CUFEMismatch:   *cufeMismatch,
+		}),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		log.Printf("Fake provider listening on %s", *addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Fake provider failed: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down fake provider")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Error shutting down fake provider: %v", err)
+	}
+}
@@ -1,16 +1,25 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
+	"laguna-escondida/backend/internal/domain/aggregate/bill"
+	"laguna-escondida/backend/internal/domain/dto"
 	"laguna-escondida/backend/internal/domain/service"
 	"laguna-escondida/backend/internal/platform/config"
+	"laguna-escondida/backend/internal/platform/einvoice"
 	"laguna-escondida/backend/internal/platform/handler"
-	"laguna-escondida/backend/internal/platform/httpclient"
 	"laguna-escondida/backend/internal/platform/postgres/repository"
+	"laguna-escondida/backend/internal/platform/worker"
 
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
@@ -34,21 +43,53 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	electronicInvoiceClient, err := einvoice.NewElectronicInvoiceClient(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create electronic invoice client: %v", err)
+	}
+
+	// Without the NIT the CUFE of the invoices is not computed, the bills keep the one the
+	// provider answers as before and print no QR code when issued
+	issuer := bill.Issuer{Environment: dto.DIANEnvironment(cfg.ElectronicInvoiceEnvironment)}
+	if cfg.ElectronicInvoiceSupplierNIT != "" {
+		issuer, err = bill.NewIssuer(cfg.ElectronicInvoiceSupplierNIT, issuer.Environment)
+		if err != nil {
+			log.Fatalf("Invalid ELECTRONIC_INVOICE_SUPPLIER_NIT: %v", err)
+		}
+	} else {
+		log.Println("ELECTRONIC_INVOICE_SUPPLIER_NIT is not set, the CUFE of the invoices is not checked")
+	}
+
 	// Initialize repositories
 	productRepo := repository.NewProductRepository(db.DB)
 	openBillRepo := repository.NewOpenBillRepository(db.DB)
-	electronicInvoiceClient := httpclient.NewElectronicInvoiceClient(cfg)
-	billRepo := repository.NewBillRepository(db.DB, electronicInvoiceClient)
-	invoiceService := service.NewInvoiceService(electronicInvoiceClient, productRepo, billRepo)
+	billRepo := repository.NewBillRepository(db.DB)
+	invoiceOutboxRepo := repository.NewInvoiceOutboxRepository(db.DB)
+	resolutionRepo := repository.NewNumberingResolutionRepository(db.DB)
+	creditNoteRepo := repository.NewCreditNoteRepository(db.DB)
+	debitNoteRepo := repository.NewDebitNoteRepository(db.DB)
+	contingencyRepo := repository.NewContingencyRepository(db.DB)
+	idempotencyRepo := repository.NewIdempotencyRepository(db.DB)
+	billOwnerRepo := repository.NewBillOwnerRepository(db.DB)
+	invoiceService := service.NewInvoiceService(electronicInvoiceClient, productRepo, billRepo, creditNoteRepo, debitNoteRepo, contingencyRepo, issuer)
 
 	// Initialize services
 	orderService := service.NewOrderService(openBillRepo, productRepo, invoiceService)
 	productService := service.NewProductService(productRepo)
+	contingencyService := service.NewContingencyService(contingencyRepo)
+	invoiceOutboxService := service.NewInvoiceOutboxService(electronicInvoiceClient, invoiceOutboxRepo, resolutionRepo, contingencyService, issuer)
+	resolutionService := service.NewNumberingResolutionService(resolutionRepo)
+	idempotencyService := service.NewIdempotencyService(idempotencyRepo, cfg.IdempotencyKeyTTL, cfg.IdempotencyProcessingLease)
+	billOwnerService := service.NewBillOwnerService(billOwnerRepo)
 
 	// Initialize handlers
 	orderHandler := handler.NewOrderHandler(orderService)
 	productHandler := handler.NewProductHandler(productService)
 	invoiceHandler := handler.NewInvoiceHandler(invoiceService)
+	invoiceOutboxHandler := handler.NewInvoiceOutboxHandler(invoiceOutboxService)
+	resolutionHandler := handler.NewNumberingResolutionHandler(resolutionService)
+	contingencyHandler := handler.NewContingencyHandler(contingencyService)
+	billOwnerHandler := handler.NewBillOwnerHandler(billOwnerService)
 
 	// Setup routes
 	router := mux.NewRouter()
@@ -56,20 +97,48 @@ func main() {
 	// Apply CORS middleware to routes
 	healthMiddleware := handler.CORSMiddleware([]string{"GET", "OPTIONS"})
 	orderMiddleware := handler.CORSMiddleware([]string{"POST", "OPTIONS"})
+	orderGetMiddleware := handler.CORSMiddleware([]string{"GET", "OPTIONS"})
 	updateOrderMiddleware := handler.CORSMiddleware([]string{"PUT", "OPTIONS"})
 	payOrderMiddleware := handler.CORSMiddleware([]string{"POST", "OPTIONS"})
+	cancelOrderMiddleware := handler.CORSMiddleware([]string{"POST", "OPTIONS"})
+	splitOrderMiddleware := handler.CORSMiddleware([]string{"POST", "OPTIONS"})
+	transferOrderMiddleware := handler.CORSMiddleware([]string{"POST", "OPTIONS"})
 	productGetMiddleware := handler.CORSMiddleware([]string{"GET", "OPTIONS"})
 	productPostMiddleware := handler.CORSMiddleware([]string{"POST", "OPTIONS"})
 	productPutMiddleware := handler.CORSMiddleware([]string{"PUT", "OPTIONS"})
 	productDeleteMiddleware := handler.CORSMiddleware([]string{"DELETE", "OPTIONS"})
 	invoicePostMiddleware := handler.CORSMiddleware([]string{"POST", "OPTIONS"})
+	creditNotePostMiddleware := handler.CORSMiddleware([]string{"POST", "OPTIONS"})
+	debitNotePostMiddleware := handler.CORSMiddleware([]string{"POST", "OPTIONS"})
+	billStatusPostMiddleware := handler.CORSMiddleware([]string{"POST", "OPTIONS"})
+	billGetMiddleware := handler.CORSMiddleware([]string{"GET", "OPTIONS"})
+	invoiceOutboxGetMiddleware := handler.CORSMiddleware([]string{"GET", "OPTIONS"})
+	invoiceOutboxPostMiddleware := handler.CORSMiddleware([]string{"POST", "OPTIONS"})
+	resolutionGetMiddleware := handler.CORSMiddleware([]string{"GET", "OPTIONS"})
+	resolutionPostMiddleware := handler.CORSMiddleware([]string{"POST", "OPTIONS"})
+	resolutionPutMiddleware := handler.CORSMiddleware([]string{"PUT", "OPTIONS"})
+	resolutionDeleteMiddleware := handler.CORSMiddleware([]string{"DELETE", "OPTIONS"})
+	contingencyGetMiddleware := handler.CORSMiddleware([]string{"GET", "OPTIONS"})
+	contingencyPostMiddleware := handler.CORSMiddleware([]string{"POST", "OPTIONS"})
+	billOwnerGetMiddleware := handler.CORSMiddleware([]string{"GET", "OPTIONS"})
+	billOwnerPostMiddleware := handler.CORSMiddleware([]string{"POST", "OPTIONS"})
+	billOwnerPutMiddleware := handler.CORSMiddleware([]string{"PUT", "OPTIONS"})
+	billOwnerDeleteMiddleware := handler.CORSMiddleware([]string{"DELETE", "OPTIONS"})
+	invoiceIdempotencyMiddleware := handler.IdempotencyMiddleware(idempotencyService, "invoices")
+	payOrderIdempotencyMiddleware := handler.IdempotencyMiddleware(idempotencyService, "orders.pay")
 
 	router.HandleFunc("/api/health", healthMiddleware(http.HandlerFunc(handler.HealthCheckHandler)).ServeHTTP).Methods("GET", "OPTIONS")
 
 	// Order routes
 	router.HandleFunc("/api/orders", orderMiddleware(http.HandlerFunc(orderHandler.CreateOrderHandler)).ServeHTTP).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/orders", orderGetMiddleware(http.HandlerFunc(orderHandler.ListOrdersHandler)).ServeHTTP).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/orders/{id}", orderGetMiddleware(http.HandlerFunc(orderHandler.GetOrderHandler)).ServeHTTP).Methods("GET", "OPTIONS")
 	router.HandleFunc("/api/orders/{id}", updateOrderMiddleware(http.HandlerFunc(orderHandler.UpdateOrderHandler)).ServeHTTP).Methods("PUT", "OPTIONS")
-	router.HandleFunc("/api/orders/{id}/pay", payOrderMiddleware(http.HandlerFunc(orderHandler.PayOrderHandler)).ServeHTTP).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/orders/{id}/pay", payOrderMiddleware(payOrderIdempotencyMiddleware(http.HandlerFunc(orderHandler.PayOrderHandler))).ServeHTTP).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/orders/{id}/cancel", cancelOrderMiddleware(http.HandlerFunc(orderHandler.CancelOrderHandler)).ServeHTTP).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/orders/{id}/split", splitOrderMiddleware(http.HandlerFunc(orderHandler.SplitOrderHandler)).ServeHTTP).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/orders/{id}/transfer", transferOrderMiddleware(http.HandlerFunc(orderHandler.TransferOrderItemsHandler)).ServeHTTP).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/orders/{id}/merge", transferOrderMiddleware(http.HandlerFunc(orderHandler.MergeOrdersHandler)).ServeHTTP).Methods("POST", "OPTIONS")
 
 	// Product routes
 	router.HandleFunc("/api/products", productPostMiddleware(http.HandlerFunc(productHandler.CreateProductHandler)).ServeHTTP).Methods("POST", "OPTIONS")
@@ -79,15 +148,95 @@ func main() {
 	router.HandleFunc("/api/products/{id}", productDeleteMiddleware(http.HandlerFunc(productHandler.DeleteProductHandler)).ServeHTTP).Methods("DELETE", "OPTIONS")
 
 	// Invoice routes
-	router.HandleFunc("/api/invoices", invoicePostMiddleware(http.HandlerFunc(invoiceHandler.CreateElectronicInvoiceHandler)).ServeHTTP).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/invoices", invoicePostMiddleware(invoiceIdempotencyMiddleware(http.HandlerFunc(invoiceHandler.CreateElectronicInvoiceHandler))).ServeHTTP).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/bills", billGetMiddleware(http.HandlerFunc(invoiceHandler.ListBillsHandler)).ServeHTTP).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/bills/{id}", billGetMiddleware(http.HandlerFunc(invoiceHandler.GetBillHandler)).ServeHTTP).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/bills/{id}/credit-notes", creditNotePostMiddleware(http.HandlerFunc(invoiceHandler.CreateCreditNoteHandler)).ServeHTTP).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/bills/{id}/debit-notes", debitNotePostMiddleware(http.HandlerFunc(invoiceHandler.CreateDebitNoteHandler)).ServeHTTP).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/bills/{id}/refresh-status", billStatusPostMiddleware(http.HandlerFunc(invoiceHandler.RefreshBillStatusHandler)).ServeHTTP).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/invoice-outbox/dead-letters", invoiceOutboxGetMiddleware(http.HandlerFunc(invoiceOutboxHandler.ListDeadLettersHandler)).ServeHTTP).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/invoice-outbox/{id}/retry", invoiceOutboxPostMiddleware(http.HandlerFunc(invoiceOutboxHandler.RetryDeadLetterHandler)).ServeHTTP).Methods("POST", "OPTIONS")
+
+	// Numbering resolution routes
+	router.HandleFunc("/api/numbering-resolutions", resolutionPostMiddleware(http.HandlerFunc(resolutionHandler.CreateResolutionHandler)).ServeHTTP).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/numbering-resolutions", resolutionGetMiddleware(http.HandlerFunc(resolutionHandler.ListResolutionsHandler)).ServeHTTP).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/numbering-resolutions/active", resolutionGetMiddleware(http.HandlerFunc(resolutionHandler.GetActiveResolutionHandler)).ServeHTTP).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/numbering-resolutions/{id}", resolutionGetMiddleware(http.HandlerFunc(resolutionHandler.GetResolutionHandler)).ServeHTTP).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/numbering-resolutions/{id}", resolutionPutMiddleware(http.HandlerFunc(resolutionHandler.UpdateResolutionHandler)).ServeHTTP).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/api/numbering-resolutions/{id}", resolutionDeleteMiddleware(http.HandlerFunc(resolutionHandler.DeleteResolutionHandler)).ServeHTTP).Methods("DELETE", "OPTIONS")
+
+	// Contingency routes
+	router.HandleFunc("/api/contingency", contingencyGetMiddleware(http.HandlerFunc(contingencyHandler.GetStatusHandler)).ServeHTTP).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/contingency/activate", contingencyPostMiddleware(http.HandlerFunc(contingencyHandler.ActivateHandler)).ServeHTTP).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/contingency/deactivate", contingencyPostMiddleware(http.HandlerFunc(contingencyHandler.DeactivateHandler)).ServeHTTP).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/contingency/periods", contingencyGetMiddleware(http.HandlerFunc(contingencyHandler.ListPeriodsHandler)).ServeHTTP).Methods("GET", "OPTIONS")
+
+	// Bill owner routes, the id is the document number
+	router.HandleFunc("/api/bill-owners", billOwnerPostMiddleware(http.HandlerFunc(billOwnerHandler.CreateBillOwnerHandler)).ServeHTTP).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/bill-owners", billOwnerGetMiddleware(http.HandlerFunc(billOwnerHandler.ListBillOwnersHandler)).ServeHTTP).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/bill-owners/{id}", billOwnerGetMiddleware(http.HandlerFunc(billOwnerHandler.GetBillOwnerHandler)).ServeHTTP).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/bill-owners/{id}", billOwnerPutMiddleware(http.HandlerFunc(billOwnerHandler.UpdateBillOwnerHandler)).ServeHTTP).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/api/bill-owners/{id}", billOwnerDeleteMiddleware(http.HandlerFunc(billOwnerHandler.DeleteBillOwnerHandler)).ServeHTTP).Methods("DELETE", "OPTIONS")
 
 	port := os.Getenv("PORT")
 	if port == "" {
 		panic("PORT is not set")
 	}
 
-	log.Printf("Server starting on port %s", port)
-	log.Fatal(http.ListenAndServe(":"+port, router))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Background workers
+	var workers sync.WaitGroup
+	reconciliationWorker := worker.NewInvoiceReconciliationWorker(
+		invoiceService,
+		cfg.InvoiceReconciliationInterval,
+		cfg.InvoiceReconciliationMaxBackoff,
+		cfg.InvoiceReconciliationBatchSize,
+	)
+	outboxDispatcher := worker.NewInvoiceOutboxDispatcher(
+		invoiceOutboxService,
+		cfg.InvoiceOutboxInterval,
+		cfg.InvoiceOutboxMaxBackoff,
+		cfg.InvoiceOutboxBatchSize,
+	)
+	idempotencyKeyCleaner := worker.NewIdempotencyKeyCleaner(idempotencyService, cfg.IdempotencyCleanupInterval)
+	workers.Add(3)
+	go func() {
+		defer workers.Done()
+		reconciliationWorker.Run(ctx)
+	}()
+	go func() {
+		defer workers.Done()
+		outboxDispatcher.Run(ctx)
+	}()
+	go func() {
+		defer workers.Done()
+		idempotencyKeyCleaner.Run(ctx)
+	}()
+
+	server := &http.Server{
+		Addr:    ":" + port,
+		Handler: router,
+	}
+
+	go func() {
+		log.Printf("Server starting on port %s", port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Server failed: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down server")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Error shutting down server: %v", err)
+	}
+
+	workers.Wait()
 }
 
 func getDSN() string {
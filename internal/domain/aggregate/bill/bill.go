@@ -2,8 +2,8 @@ package bill
 
 import (
 	billError "laguna-escondida/backend/internal/domain/aggregate/bill/error"
+	"laguna-escondida/backend/internal/domain/aggregate/billowner"
 	"laguna-escondida/backend/internal/domain/dto"
-	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,20 +11,27 @@ import (
 )
 
 type Aggregate struct {
-	id             string
-	totalAmount    float64
-	discountAmount float64
-	taxAmount      float64
-	payAmount      float64
-	vat            float64
-	ico            float64
-	tip            float64
-	documentURL    *string
-	customer       *dto.Customer
-	paymentCode    dto.ElectronicInvoicePaymentCode
-	products       []*BillProduct
-	createdAt      time.Time
-	updatedAt      time.Time
+	id                  string
+	openBillID          string
+	prefix              string
+	consecutive         int
+	technicalKey        string
+	contingencyPeriodID *string
+	totalAmount         dto.Money
+	discountAmount      dto.Money
+	taxAmount           dto.Money
+	payAmount           dto.Money
+	vat                 dto.Money
+	ico                 dto.Money
+	tip                 dto.Money
+	payments            []dto.Payment
+	change              dto.Money
+	documentURL         *string
+	customer            *dto.Customer
+	paymentCode         dto.ElectronicInvoicePaymentCode
+	products            []*BillProduct
+	createdAt           time.Time
+	updatedAt           time.Time
 }
 
 func NewBillFromCreateElectronicInvoiceRequest(invoice *dto.ElectronicInvoice, products []*BillProduct) (*Aggregate, error) {
@@ -32,55 +39,54 @@ func NewBillFromCreateElectronicInvoiceRequest(invoice *dto.ElectronicInvoice, p
 		return nil, billError.NewProductsCannotBeEmptyError()
 	}
 
-	totalAmount := 0.0
-	discountAmount := 0.0
-	taxAmount := 0.0
-	payAmount := 0.0
-	totalVat := 0.0
-	totalIco := 0.0
-	totalTip := 0.0
+	// The customer is stored and sent to the DIAN with the normalized document number
+	var customer *dto.Customer
+	if invoice.Customer != nil {
+		normalized, err := billowner.NewCustomer(invoice.Customer)
+		if err != nil {
+			return nil, err
+		}
+		customer = normalized
+	}
 
-	for _, product := range products {
-		totalAmount += product.unitPrice * float64(product.quantity)
+	amounts, err := sumBillProducts(products)
+	if err != nil {
+		return nil, err
+	}
 
-		for _, allowance := range product.allowance {
-			allowanceAmount, err := strconv.ParseFloat(allowance.Amount, 64)
-			if err != nil {
-				return nil, billError.NewInvalidAllowanceAmountError(allowance.Amount)
-			}
-			discountAmount += allowanceAmount
-		}
+	// The tip is a voluntary charge on top of the bill, it is paid but never taxed
+	if invoice.Tip.IsNegative() {
+		return nil, billError.NewInvalidTipAmountError(invoice.Tip.String())
+	}
 
-		for _, tax := range product.taxes {
-			parsedTaxAmount, err := strconv.ParseFloat(tax.TaxAmount, 64)
-			if err != nil {
-				return nil, billError.NewInvalidTaxAmountError(tax.TaxAmount)
-			}
+	payAmount := amounts.total.Add(amounts.tax).Sub(amounts.discount).Add(invoice.Tip)
 
-			switch tax.TaxCode {
-			case dto.TaxCodeVAT:
-				totalVat += parsedTaxAmount
-			case dto.TaxCodeICO:
-				totalIco += parsedTaxAmount
-			}
-			taxAmount += parsedTaxAmount
-		}
+	payments, change, err := newPayments(invoice.PaymentCode, invoice.Payments, payAmount)
+	if err != nil {
+		return nil, err
 	}
 
-	payAmount = totalAmount + taxAmount - discountAmount
+	// The bill keeps a main payment code, the first method used when it was not given
+	paymentCode := invoice.PaymentCode
+	if paymentCode == "" {
+		paymentCode = payments[0].Method
+	}
 
 	return &Aggregate{
 		id:             uuid.New().String(),
-		totalAmount:    totalAmount,
-		discountAmount: discountAmount,
-		taxAmount:      taxAmount,
+		openBillID:     invoice.OpenBillID,
+		totalAmount:    amounts.total,
+		discountAmount: amounts.discount,
+		taxAmount:      amounts.tax,
 		payAmount:      payAmount,
-		vat:            totalVat,
-		ico:            totalIco,
-		tip:            totalTip,
+		vat:            amounts.vat,
+		ico:            amounts.ico,
+		tip:            invoice.Tip,
+		payments:       payments,
+		change:         change,
 		documentURL:    nil,
-		customer:       invoice.Customer,
-		paymentCode:    invoice.PaymentCode,
+		customer:       customer,
+		paymentCode:    paymentCode,
 		products:       products,
 		createdAt:      time.Now(),
 		updatedAt:      time.Now(),
@@ -89,34 +95,50 @@ func NewBillFromCreateElectronicInvoiceRequest(invoice *dto.ElectronicInvoice, p
 
 func (a *Aggregate) ToDTO() *dto.Bill {
 	return &dto.Bill{
-		ID:             a.id,
-		TotalAmount:    a.totalAmount,
-		DiscountAmount: a.discountAmount,
-		TaxAmount:      a.taxAmount,
-		PayAmount:      a.payAmount,
-		CreatedAt:      a.createdAt,
-		UpdatedAt:      a.updatedAt,
-		VAT:            a.vat,
-		ICO:            a.ico,
-		Tip:            a.tip,
-		DocumentURL:    a.documentURL,
-		Customer:       a.customer,
-		Products: lo.Map(a.products, func(product *BillProduct, _ int) dto.BillProduct {
-			return dto.BillProduct{
-				ProductID:   product.id,
-				Quantity:    product.quantity,
-				UnitPrice:   product.unitPrice,
-				Description: product.description,
-				Brand:       product.brand,
-				Model:       product.model,
-				Code:        product.code,
-				Allowance:   product.allowance,
-				Taxes:       product.taxes,
-			}
-		}),
+		ID:                  a.id,
+		Prefix:              a.prefix,
+		Consecutive:         a.consecutive,
+		ContingencyPeriodID: a.contingencyPeriodID,
+		TotalAmount:         a.totalAmount,
+		DiscountAmount:      a.discountAmount,
+		TaxAmount:           a.taxAmount,
+		PayAmount:           a.payAmount,
+		CreatedAt:           a.createdAt,
+		UpdatedAt:           a.updatedAt,
+		VAT:                 a.vat,
+		ICO:                 a.ico,
+		Tip:                 a.tip,
+		Payments:            a.payments,
+		Change:              a.change,
+		DocumentURL:         a.documentURL,
+		Customer:            a.customer,
+		Products:            billProductsToDTO(a.products),
 	}
 }
 
+// AssignNumber records the prefix and consecutive the bill was numbered with when it was saved,
+// along with the technical key of the resolution they were taken from
+func (a *Aggregate) AssignNumber(prefix string, consecutive int, technicalKey string) {
+	a.prefix = prefix
+	a.consecutive = consecutive
+	a.technicalKey = technicalKey
+}
+
+// MarkContingency issues the bill in the open contingency period, it is numbered with the
+// contingency resolution and transmitted once the provider recovers
+func (a *Aggregate) MarkContingency(periodID string) {
+	a.contingencyPeriodID = &periodID
+}
+
+// OpenBillID is the order the bill pays, empty for bills issued directly
+func (a *Aggregate) OpenBillID() string {
+	return a.openBillID
+}
+
+func (a *Aggregate) ContingencyPeriodID() *string {
+	return a.contingencyPeriodID
+}
+
 func (a *Aggregate) Products() []*BillProduct {
 	return a.products
 }
@@ -124,3 +146,53 @@ func (a *Aggregate) Products() []*BillProduct {
 func (a *Aggregate) PaymentCode() dto.ElectronicInvoicePaymentCode {
 	return a.paymentCode
 }
+
+func (a *Aggregate) Tip() dto.Money {
+	return a.tip
+}
+
+func (a *Aggregate) Payments() []dto.Payment {
+	return a.payments
+}
+
+type billAmounts struct {
+	total    dto.Money
+	discount dto.Money
+	tax      dto.Money
+	vat      dto.Money
+	ico      dto.Money
+}
+
+// sumBillProducts adds up the amounts of the products of a bill or a note
+func sumBillProducts(products []*BillProduct) (billAmounts, error) {
+	var amounts billAmounts
+	for _, product := range products {
+		amounts.total = amounts.total.Add(product.unitPrice.Mul(product.quantity))
+
+		for _, allowance := range product.allowance {
+			if allowance.Amount.IsNegative() {
+				return billAmounts{}, billError.NewInvalidAllowanceAmountError(allowance.Amount.String())
+			}
+			amounts.discount = amounts.discount.Add(allowance.Amount)
+		}
+
+		// Line taxes are already rounded per line, so the bill totals are exact sums
+		for _, tax := range product.taxes {
+			switch tax.TaxCode {
+			case dto.TaxCodeVAT:
+				amounts.vat = amounts.vat.Add(tax.TaxAmount)
+			case dto.TaxCodeICO:
+				amounts.ico = amounts.ico.Add(tax.TaxAmount)
+			}
+			amounts.tax = amounts.tax.Add(tax.TaxAmount)
+		}
+	}
+
+	return amounts, nil
+}
+
+func billProductsToDTO(products []*BillProduct) []dto.BillProduct {
+	return lo.Map(products, func(product *BillProduct, _ int) dto.BillProduct {
+		return product.ToDTO()
+	})
+}
@@ -2,6 +2,7 @@ package bill
 
 import (
 	"laguna-escondida/backend/internal/domain/dto"
+	"laguna-escondida/backend/internal/domain/tax"
 	"strconv"
 	"time"
 )
@@ -9,35 +10,42 @@ import (
 type BillProduct struct {
 	id          string
 	quantity    int
-	unitPrice   float64
+	unitPrice   dto.Money
 	description *string
 	brand       *string
 	model       *string
 	code        string
 	allowance   []dto.InvoiceAllowance
+	vatRate     float64
+	icoRate     float64
 	taxes       []dto.InvoiceTax
 	createdAt   time.Time
 	updatedAt   time.Time
 }
 
-func NewBillProduct(productID string, quantity int, unitPrice float64, description *string, brand *string, model *string, code string, allowance []dto.InvoiceAllowance, vat float64, ico float64) *BillProduct {
-	baseAmount := unitPrice * float64(quantity)
+func NewBillProduct(productID string, quantity int, unitPrice dto.Money, description *string, brand *string, model *string, code string, allowance []dto.InvoiceAllowance, vat float64, ico float64) *BillProduct {
+	breakdown := tax.ApplyToBase(unitPrice.Mul(quantity), vat, ico)
+
+	return NewBillProductWithTaxes(productID, quantity, unitPrice, description, brand, model, code, allowance, vat, ico, breakdown.VAT, breakdown.ICO)
+}
+
+// NewBillProductWithTaxes builds a bill product whose tax amounts were already computed,
+// such as lines extracted from a price with taxes included that must keep their exact total
+func NewBillProductWithTaxes(productID string, quantity int, unitPrice dto.Money, description *string, brand *string, model *string, code string, allowance []dto.InvoiceAllowance, vat float64, ico float64, vatAmount dto.Money, icoAmount dto.Money) *BillProduct {
 	taxes := []dto.InvoiceTax{}
 
 	if vat > 0 {
-		vatAmount := baseAmount * vat
 		taxes = append(taxes, dto.InvoiceTax{
 			TaxCode:   dto.TaxCodeVAT,
-			TaxAmount: strconv.FormatFloat(vatAmount, 'f', 2, 64),
+			TaxAmount: vatAmount,
 			Percent:   strconv.FormatFloat(vat*100, 'f', 2, 64),
 		})
 	}
 
 	if ico > 0 {
-		icoAmount := baseAmount * ico
 		taxes = append(taxes, dto.InvoiceTax{
 			TaxCode:   dto.TaxCodeICO,
-			TaxAmount: strconv.FormatFloat(icoAmount, 'f', 2, 64),
+			TaxAmount: icoAmount,
 			Percent:   strconv.FormatFloat(ico*100, 'f', 2, 64),
 		})
 	}
@@ -51,12 +59,39 @@ func NewBillProduct(productID string, quantity int, unitPrice float64, descripti
 		model:       model,
 		code:        code,
 		allowance:   allowance,
+		vatRate:     vat,
+		icoRate:     ico,
 		taxes:       taxes,
 		createdAt:   time.Now(),
 		updatedAt:   time.Now(),
 	}
 }
 
+// Portion returns quantity units of the line at the price and rates it was billed with,
+// without its allowances. The whole line keeps its exact taxes, a part of it has them
+// computed again on its amount
+func (bp *BillProduct) Portion(quantity int) *BillProduct {
+	if quantity == bp.quantity {
+		return NewBillProductWithTaxes(bp.id, quantity, bp.unitPrice, bp.description, bp.brand, bp.model, bp.code, nil, bp.vatRate, bp.icoRate, bp.TaxAmount(dto.TaxCodeVAT), bp.TaxAmount(dto.TaxCodeICO))
+	}
+
+	return NewBillProduct(bp.id, quantity, bp.unitPrice, bp.description, bp.brand, bp.model, bp.code, nil, bp.vatRate, bp.icoRate)
+}
+
+func (bp *BillProduct) ToDTO() dto.BillProduct {
+	return dto.BillProduct{
+		ProductID:   bp.id,
+		Quantity:    bp.quantity,
+		UnitPrice:   bp.unitPrice,
+		Description: bp.description,
+		Brand:       bp.brand,
+		Model:       bp.model,
+		Code:        bp.code,
+		Allowance:   bp.allowance,
+		Taxes:       bp.taxes,
+	}
+}
+
 func (bp *BillProduct) ID() string {
 	return bp.id
 }
@@ -65,7 +100,7 @@ func (bp *BillProduct) Quantity() int {
 	return bp.quantity
 }
 
-func (bp *BillProduct) UnitPrice() float64 {
+func (bp *BillProduct) UnitPrice() dto.Money {
 	return bp.unitPrice
 }
 
@@ -92,3 +127,23 @@ func (bp *BillProduct) Model() *string {
 func (bp *BillProduct) Code() string {
 	return bp.code
 }
+
+// VATRate and ICORate are the decimal rates the taxes of the line were computed with
+func (bp *BillProduct) VATRate() float64 {
+	return bp.vatRate
+}
+
+func (bp *BillProduct) ICORate() float64 {
+	return bp.icoRate
+}
+
+// TaxAmount returns the amount charged on the line for the tax, zero when it does not apply
+func (bp *BillProduct) TaxAmount(code dto.TaxCode) dto.Money {
+	for _, tax := range bp.taxes {
+		if tax.TaxCode == code {
+			return tax.TaxAmount
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,119 @@
+package bill
+
+import (
+	billError "laguna-escondida/backend/internal/domain/aggregate/bill/error"
+	"laguna-escondida/backend/internal/domain/dto"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreditNote reverses all or part of an issued bill, its products are the quantities
+// being credited and the tip is only credited back when the whole bill is reversed
+type CreditNote struct {
+	id             string
+	billID         string
+	reason         dto.CreditNoteReason
+	description    string
+	totalAmount    dto.Money
+	discountAmount dto.Money
+	taxAmount      dto.Money
+	payAmount      dto.Money
+	vat            dto.Money
+	ico            dto.Money
+	tip            dto.Money
+	products       []*BillProduct
+	createdAt      time.Time
+	updatedAt      time.Time
+}
+
+// NewCreditNote builds a credit note for the bill, creditable is what is left to credit
+// of the bill once previous credit notes are discounted
+func NewCreditNote(billID string, reason dto.CreditNoteReason, description string, tip dto.Money, creditable dto.Money, products []*BillProduct) (*CreditNote, error) {
+	if !isValidCreditNoteReason(reason) {
+		return nil, billError.NewInvalidCreditNoteReasonError(string(reason))
+	}
+
+	if len(products) == 0 {
+		return nil, billError.NewProductsCannotBeEmptyError()
+	}
+
+	if tip.IsNegative() {
+		return nil, billError.NewInvalidTipAmountError(tip.String())
+	}
+
+	amounts, err := sumBillProducts(products)
+	if err != nil {
+		return nil, err
+	}
+
+	payAmount := amounts.total.Add(amounts.tax).Sub(amounts.discount).Add(tip)
+	if payAmount > creditable {
+		return nil, billError.NewCreditNoteExceedsBillError(creditable.String())
+	}
+
+	return &CreditNote{
+		id:             uuid.New().String(),
+		billID:         billID,
+		reason:         reason,
+		description:    description,
+		totalAmount:    amounts.total,
+		discountAmount: amounts.discount,
+		taxAmount:      amounts.tax,
+		payAmount:      payAmount,
+		vat:            amounts.vat,
+		ico:            amounts.ico,
+		tip:            tip,
+		products:       products,
+		createdAt:      time.Now(),
+		updatedAt:      time.Now(),
+	}, nil
+}
+
+func isValidCreditNoteReason(reason dto.CreditNoteReason) bool {
+	switch reason {
+	case dto.CreditNoteReasonReturnedGoods,
+		dto.CreditNoteReasonCancellation,
+		dto.CreditNoteReasonDiscount,
+		dto.CreditNoteReasonPriceAdjustment,
+		dto.CreditNoteReasonOther:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *CreditNote) ToDTO() *dto.CreditNote {
+	return &dto.CreditNote{
+		ID:             c.id,
+		BillID:         c.billID,
+		Reason:         c.reason,
+		Description:    c.description,
+		TotalAmount:    c.totalAmount,
+		DiscountAmount: c.discountAmount,
+		TaxAmount:      c.taxAmount,
+		PayAmount:      c.payAmount,
+		VAT:            c.vat,
+		ICO:            c.ico,
+		Tip:            c.tip,
+		Products:       billProductsToDTO(c.products),
+		CreatedAt:      c.createdAt,
+		UpdatedAt:      c.updatedAt,
+	}
+}
+
+func (c *CreditNote) ID() string {
+	return c.id
+}
+
+func (c *CreditNote) BillID() string {
+	return c.billID
+}
+
+func (c *CreditNote) Products() []*BillProduct {
+	return c.products
+}
+
+func (c *CreditNote) PayAmount() dto.Money {
+	return c.payAmount
+}
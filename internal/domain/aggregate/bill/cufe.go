@@ -0,0 +1,148 @@
+package bill
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"laguna-escondida/backend/internal/domain/aggregate/billowner"
+	"laguna-escondida/backend/internal/domain/dto"
+)
+
+// colombiaTime is the offset of the issue time in the CUFE, Colombia has no daylight saving
+var colombiaTime = time.FixedZone("COT", -5*60*60)
+
+// Issuer is the restaurant as the DIAN knows it, the CUFE of its invoices is computed with
+// its NIT and the environment the documents are validated in
+type Issuer struct {
+	// NIT is given without its check digit
+	NIT         string
+	Environment dto.DIANEnvironment
+}
+
+// NewIssuer validates the NIT of the restaurant, the check digit is dropped if it was typed
+func NewIssuer(nit string, environment dto.DIANEnvironment) (Issuer, error) {
+	nit, _, _ = strings.Cut(billowner.NormalizeDocumentNumber(nit), "-")
+	if err := billowner.ValidateDocument(dto.DocumentTypeNIT, nit); err != nil {
+		return Issuer{}, err
+	}
+
+	return Issuer{NIT: nit, Environment: environment}, nil
+}
+
+// environmentCode is the TipoAmbiente of the annex, 1 for production and 2 for tests
+func (i Issuer) environmentCode() string {
+	if i.Environment == dto.DIANEnvironmentProduction {
+		return "1"
+	}
+
+	return "2"
+}
+
+// searchURL is where the DIAN shows the document of a CUFE, the QR code links to it
+func (i Issuer) searchURL(cufe string) string {
+	if i.Environment == dto.DIANEnvironmentProduction {
+		return "https://catalogo-vpfe.dian.gov.co/document/searchqr?documentkey=" + cufe
+	}
+
+	return "https://catalogo-vpfe-hab.dian.gov.co/document/searchqr?documentkey=" + cufe
+}
+
+// documentTaxes adds up the VAT and the INC of the lines, ICA is never charged on a sale
+type documentTaxes struct {
+	vat dto.Money
+	ico dto.Money
+}
+
+func sumDocumentTaxes(lines []dto.BillProduct) documentTaxes {
+	var taxes documentTaxes
+	for _, line := range lines {
+		for _, tax := range line.Taxes {
+			switch tax.TaxCode {
+			case dto.TaxCodeVAT:
+				taxes.vat = taxes.vat.Add(tax.TaxAmount)
+			case dto.TaxCodeICO:
+				taxes.ico = taxes.ico.Add(tax.TaxAmount)
+			}
+		}
+	}
+
+	return taxes
+}
+
+// InvoiceCUFE computes the CUFE of an invoice as the DIAN technical annex defines it, the
+// SHA-384 of its number, issue date and time, amounts before taxes, VAT (01), INC (04) and
+// ICA (03), total, the NIT of the issuer, the document of the customer, the technical key of
+// the numbering resolution and the environment. The provider must answer the same one
+func InvoiceCUFE(document *dto.ElectronicDocument, issuer Issuer, technicalKey string) string {
+	issuedAt := document.IssuedAt.In(colombiaTime)
+	taxes := sumDocumentTaxes(document.Lines)
+	totals := document.Totals
+
+	sum := sha512.Sum384([]byte(strings.Join([]string{
+		documentNumber(document),
+		issuedAt.Format(time.DateOnly),
+		issuedAt.Format("15:04:05-07:00"),
+		totals.LineAmount.Sub(totals.DiscountAmount).String(),
+		"01", taxes.vat.String(),
+		"04", taxes.ico.String(),
+		"03", dto.Money(0).String(),
+		totals.PayAmount.String(),
+		issuer.NIT,
+		document.Customer.DocumentNumber,
+		technicalKey,
+		issuer.environmentCode(),
+	}, "")))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// ExpectedCUFE computes the CUFE of the bill once it was numbered, it is empty when the NIT
+// of the issuer is unknown
+func (a *Aggregate) ExpectedCUFE(issuer Issuer) string {
+	if issuer.NIT == "" || a.technicalKey == "" {
+		return ""
+	}
+
+	return InvoiceCUFE(a.InvoiceDocument(), issuer, a.technicalKey)
+}
+
+// InvoiceQRPayload is the text of the QR code printed on the invoice, it only needs the
+// CUFE so it can be printed before the DIAN validates the invoice, as in contingency
+func InvoiceQRPayload(document *dto.ElectronicDocument, issuer Issuer, cufe string) string {
+	issuedAt := document.IssuedAt.In(colombiaTime)
+	taxes := sumDocumentTaxes(document.Lines)
+	totals := document.Totals
+
+	var payload strings.Builder
+	fmt.Fprintf(&payload, "NumFac: %s\n", documentNumber(document))
+	fmt.Fprintf(&payload, "FecFac: %s\n", issuedAt.Format(time.DateOnly))
+	fmt.Fprintf(&payload, "HorFac: %s\n", issuedAt.Format("15:04:05-07:00"))
+	fmt.Fprintf(&payload, "NitFac: %s\n", issuer.NIT)
+	fmt.Fprintf(&payload, "DocAdq: %s\n", document.Customer.DocumentNumber)
+	fmt.Fprintf(&payload, "ValFac: %s\n", totals.LineAmount.Sub(totals.DiscountAmount).String())
+	fmt.Fprintf(&payload, "ValIva: %s\n", taxes.vat.String())
+	fmt.Fprintf(&payload, "ValOtroIm: %s\n", taxes.ico.String())
+	fmt.Fprintf(&payload, "ValTolFac: %s\n", totals.PayAmount.String())
+	fmt.Fprintf(&payload, "CUFE: %s\n", cufe)
+	fmt.Fprintf(&payload, "QRCode: %s", issuer.searchURL(cufe))
+
+	return payload.String()
+}
+
+func documentNumber(document *dto.ElectronicDocument) string {
+	return fmt.Sprintf("%s%d", document.Prefix, document.Consecutive)
+}
+
+// InvoiceDocument is the document the outbox sends for the bill once it was numbered
+func (a *Aggregate) InvoiceDocument() *dto.ElectronicDocument {
+	return NewInvoiceDocument(&dto.CreateElectronicInvoiceRequest{
+		Prefix:      a.prefix,
+		Consecutive: a.consecutive,
+		PaymentCode: a.paymentCode,
+		Bill:        a.ToDTO(),
+		Contingency: a.contingencyPeriodID != nil,
+	})
+}
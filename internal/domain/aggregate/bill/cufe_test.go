@@ -0,0 +1,81 @@
+package bill
+
+import (
+	"testing"
+	"time"
+
+	"laguna-escondida/backend/internal/domain/dto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The expected CUFEs are the SHA-384 of the string the DIAN technical annex concatenates,
+// computed apart from this package, so the provider fake that shares InvoiceCUFE is not
+// checking the formula against itself
+func TestInvoiceCUFE(t *testing.T) {
+	testCases := []struct {
+		name         string
+		document     *dto.ElectronicDocument
+		issuer       Issuer
+		technicalKey string
+		expected     string
+	}{
+		{
+			// Example of the technical annex:
+			// 3232000001292019-01-1610:53:10-05:001500000.0001285000.00040.00030.001785000.00
+			// 700085371800199436693ff6f2a553c3646a063436fd4dd9ded03114711
+			name: "DIAN technical annex example",
+			document: &dto.ElectronicDocument{
+				Type:        dto.ElectronicDocumentTypeInvoice,
+				Prefix:      "323200000",
+				Consecutive: 129,
+				IssuedAt:    time.Date(2019, 1, 16, 15, 53, 10, 0, time.UTC),
+				Customer:    dto.Customer{DocumentNumber: "800199436", DocumentType: dto.DocumentTypeNIT},
+				Lines: []dto.BillProduct{{
+					Quantity:  1,
+					UnitPrice: dto.NewMoneyFromFloat(1500000),
+					Taxes:     []dto.InvoiceTax{{TaxCode: dto.TaxCodeVAT, TaxAmount: dto.NewMoneyFromFloat(285000), Percent: "19.00"}},
+				}},
+				Totals: dto.ElectronicDocumentTotals{
+					LineAmount: dto.NewMoneyFromFloat(1500000),
+					TaxAmount:  dto.NewMoneyFromFloat(285000),
+					PayAmount:  dto.NewMoneyFromFloat(1785000),
+				},
+			},
+			issuer:       Issuer{NIT: "700085371", Environment: dto.DIANEnvironmentProduction},
+			technicalKey: "693ff6f2a553c3646a063436fd4dd9ded0311471",
+			expected:     "8bb918b19ba22a694f1da11c643b5e9de39adf60311cf179179e9b33381030bcd4c3c3f156c506ed5908f9276f5bd9b4",
+		},
+		{
+			// LAG422026-03-0115:15:00-05:00100000.00010.00048000.00030.00108000.00
+			// 900123456222222222222fc8eac422eba16e22ffd8c6f94b3f40a6e38162c2
+			name: "INC in the test environment",
+			document: &dto.ElectronicDocument{
+				Type:        dto.ElectronicDocumentTypeInvoice,
+				Prefix:      "LAG",
+				Consecutive: 42,
+				IssuedAt:    time.Date(2026, 3, 1, 20, 15, 0, 0, time.UTC),
+				Customer:    dto.Customer{DocumentNumber: "222222222222", DocumentType: dto.DocumentTypeNationalIdentificationNumber},
+				Lines: []dto.BillProduct{{
+					Quantity:  2,
+					UnitPrice: dto.NewMoneyFromFloat(50000),
+					Taxes:     []dto.InvoiceTax{{TaxCode: dto.TaxCodeICO, TaxAmount: dto.NewMoneyFromFloat(8000), Percent: "8.00"}},
+				}},
+				Totals: dto.ElectronicDocumentTotals{
+					LineAmount: dto.NewMoneyFromFloat(100000),
+					TaxAmount:  dto.NewMoneyFromFloat(8000),
+					PayAmount:  dto.NewMoneyFromFloat(108000),
+				},
+			},
+			issuer:       Issuer{NIT: "900123456", Environment: dto.DIANEnvironmentTest},
+			technicalKey: "fc8eac422eba16e22ffd8c6f94b3f40a6e38162c",
+			expected:     "ec8f7ee3d5ef5d5fcc3f4b84b7f7e5ad0dcbb17683c73bf2a944c0265615f21ed62811c39519bb73d4f04f8e1b79a271",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, InvoiceCUFE(tc.document, tc.issuer, tc.technicalKey))
+		})
+	}
+}
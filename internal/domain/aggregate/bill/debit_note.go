@@ -0,0 +1,103 @@
+package bill
+
+import (
+	billError "laguna-escondida/backend/internal/domain/aggregate/bill/error"
+	"laguna-escondida/backend/internal/domain/dto"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DebitNote adds charges to an issued bill, such as a forgotten item or a corkage fee
+type DebitNote struct {
+	id             string
+	billID         string
+	reason         dto.DebitNoteReason
+	description    string
+	totalAmount    dto.Money
+	discountAmount dto.Money
+	taxAmount      dto.Money
+	payAmount      dto.Money
+	vat            dto.Money
+	ico            dto.Money
+	products       []*BillProduct
+	createdAt      time.Time
+	updatedAt      time.Time
+}
+
+func NewDebitNote(billID string, reason dto.DebitNoteReason, description string, products []*BillProduct) (*DebitNote, error) {
+	if !isValidDebitNoteReason(reason) {
+		return nil, billError.NewInvalidDebitNoteReasonError(string(reason))
+	}
+
+	if len(products) == 0 {
+		return nil, billError.NewProductsCannotBeEmptyError()
+	}
+
+	amounts, err := sumBillProducts(products)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DebitNote{
+		id:             uuid.New().String(),
+		billID:         billID,
+		reason:         reason,
+		description:    description,
+		totalAmount:    amounts.total,
+		discountAmount: amounts.discount,
+		taxAmount:      amounts.tax,
+		payAmount:      amounts.total.Add(amounts.tax).Sub(amounts.discount),
+		vat:            amounts.vat,
+		ico:            amounts.ico,
+		products:       products,
+		createdAt:      time.Now(),
+		updatedAt:      time.Now(),
+	}, nil
+}
+
+func isValidDebitNoteReason(reason dto.DebitNoteReason) bool {
+	switch reason {
+	case dto.DebitNoteReasonInterest,
+		dto.DebitNoteReasonExpenses,
+		dto.DebitNoteReasonPriceChange,
+		dto.DebitNoteReasonOther:
+		return true
+	default:
+		return false
+	}
+}
+
+func (d *DebitNote) ToDTO() *dto.DebitNote {
+	return &dto.DebitNote{
+		ID:             d.id,
+		BillID:         d.billID,
+		Reason:         d.reason,
+		Description:    d.description,
+		TotalAmount:    d.totalAmount,
+		DiscountAmount: d.discountAmount,
+		TaxAmount:      d.taxAmount,
+		PayAmount:      d.payAmount,
+		VAT:            d.vat,
+		ICO:            d.ico,
+		Products:       billProductsToDTO(d.products),
+		CreatedAt:      d.createdAt,
+		UpdatedAt:      d.updatedAt,
+	}
+}
+
+func (d *DebitNote) ID() string {
+	return d.id
+}
+
+func (d *DebitNote) BillID() string {
+	return d.billID
+}
+
+func (d *DebitNote) Products() []*BillProduct {
+	return d.products
+}
+
+func (d *DebitNote) PayAmount() dto.Money {
+	return d.payAmount
+}
@@ -0,0 +1,142 @@
+package bill
+
+import (
+	"time"
+
+	"laguna-escondida/backend/internal/domain/dto"
+
+	"github.com/samber/lo"
+)
+
+// finalConsumer is the customer the DIAN expects on bills not issued to anyone
+var finalConsumer = dto.Customer{
+	DocumentNumber: "222222222222",
+	DocumentType:   dto.DocumentTypeNIT,
+	Name:           "consumidor final",
+	Email:          "noenviar@noenviar.com",
+	TaxRegime:      dto.TaxRegimeNotVATResponsible,
+}
+
+// NewInvoiceDocument builds the document sent to the provider for an invoice. Invoices are
+// sent from the outbox after the bill was saved, they keep the date the sale happened, which
+// for contingency invoices can be hours before
+func NewInvoiceDocument(req *dto.CreateElectronicInvoiceRequest) *dto.ElectronicDocument {
+	documentType := dto.ElectronicDocumentTypeInvoice
+	if req.Contingency {
+		documentType = dto.ElectronicDocumentTypeContingencyInvoice
+	}
+
+	return &dto.ElectronicDocument{
+		Type:         documentType,
+		Prefix:       req.Prefix,
+		Consecutive:  req.Consecutive,
+		IssuedAt:     issuedAt(req.Bill.CreatedAt),
+		Customer:     documentCustomer(req.Bill.Customer),
+		PaymentCode:  req.PaymentCode,
+		PaymentMeans: documentPaymentMeans(req.Bill.Payments),
+		Lines:        req.Bill.Products,
+		Totals: dto.ElectronicDocumentTotals{
+			LineAmount:     req.Bill.TotalAmount,
+			DiscountAmount: req.Bill.DiscountAmount,
+			TaxAmount:      req.Bill.TaxAmount,
+			Tip:            req.Bill.Tip,
+			PayAmount:      req.Bill.PayAmount,
+		},
+	}
+}
+
+// NewCreditNoteDocument builds the document of a credit note, it references the original
+// invoice by its number, issue date and CUFE
+func NewCreditNoteDocument(req *dto.CreateElectronicCreditNoteRequest) *dto.ElectronicDocument {
+	creditNote := req.CreditNote
+
+	return &dto.ElectronicDocument{
+		Type:        dto.ElectronicDocumentTypeCreditNote,
+		Prefix:      req.Prefix,
+		Consecutive: req.Consecutive,
+		IssuedAt:    issuedAt(creditNote.CreatedAt),
+		Customer:    documentCustomer(req.Bill.Customer),
+		PaymentCode: dto.ElectronicInvoicePaymentCodeCash,
+		Lines:       creditNote.Products,
+		Totals: dto.ElectronicDocumentTotals{
+			LineAmount:     creditNote.TotalAmount,
+			DiscountAmount: creditNote.DiscountAmount,
+			TaxAmount:      creditNote.TaxAmount,
+			Tip:            creditNote.Tip,
+			PayAmount:      creditNote.PayAmount,
+		},
+		Reference: documentReference(req.Bill),
+		Correction: &dto.ElectronicDocumentCorrection{
+			Reason:      string(creditNote.Reason),
+			Description: creditNote.Description,
+		},
+	}
+}
+
+// NewDebitNoteDocument builds the document of a debit note, it references the original
+// invoice by its number, issue date and CUFE
+func NewDebitNoteDocument(req *dto.CreateElectronicDebitNoteRequest) *dto.ElectronicDocument {
+	debitNote := req.DebitNote
+
+	return &dto.ElectronicDocument{
+		Type:        dto.ElectronicDocumentTypeDebitNote,
+		Prefix:      req.Prefix,
+		Consecutive: req.Consecutive,
+		IssuedAt:    issuedAt(debitNote.CreatedAt),
+		Customer:    documentCustomer(req.Bill.Customer),
+		PaymentCode: dto.ElectronicInvoicePaymentCodeCash,
+		Lines:       debitNote.Products,
+		Totals: dto.ElectronicDocumentTotals{
+			LineAmount:     debitNote.TotalAmount,
+			DiscountAmount: debitNote.DiscountAmount,
+			TaxAmount:      debitNote.TaxAmount,
+			PayAmount:      debitNote.PayAmount,
+		},
+		Reference: documentReference(req.Bill),
+		Correction: &dto.ElectronicDocumentCorrection{
+			Reason:      string(debitNote.Reason),
+			Description: debitNote.Description,
+		},
+	}
+}
+
+func issuedAt(createdAt time.Time) time.Time {
+	if createdAt.IsZero() {
+		return time.Now()
+	}
+
+	return createdAt
+}
+
+func documentCustomer(customer *dto.Customer) dto.Customer {
+	if customer == nil {
+		return finalConsumer
+	}
+
+	return *customer
+}
+
+func documentReference(original *dto.Bill) *dto.ElectronicDocumentReference {
+	return &dto.ElectronicDocumentReference{
+		Prefix:      original.Prefix,
+		Consecutive: original.Consecutive,
+		CUFE:        lo.FromPtr(original.CUFE),
+		IssuedAt:    original.CreatedAt,
+	}
+}
+
+// documentPaymentMeans reports the amount applied by each payment, the change given back in
+// cash is not part of what the bill was paid with. A single payment is already the payment code
+func documentPaymentMeans(payments []dto.Payment) []dto.ElectronicDocumentPayment {
+	if len(payments) < 2 {
+		return nil
+	}
+
+	return lo.Map(payments, func(payment dto.Payment, _ int) dto.ElectronicDocumentPayment {
+		return dto.ElectronicDocumentPayment{
+			Method:    payment.Method,
+			Amount:    payment.Amount.Sub(payment.Change),
+			Reference: payment.Reference,
+		}
+	})
+}
@@ -10,7 +10,12 @@ type ProductErrorCode string
 const (
 	CodeProductsCannotBeEmpty  ProductErrorCode = "PRODUCTS_CANNOT_BE_EMPTY"
 	CodeInvalidAllowanceAmount ProductErrorCode = "INVALID_ALLOWANCE_AMOUNT"
-	CodeInvalidTaxAmount       ProductErrorCode = "INVALID_TAX_AMOUNT"
+	CodeInvalidTipAmount       ProductErrorCode = "INVALID_TIP_AMOUNT"
+	CodeMissingPaymentMethod   ProductErrorCode = "MISSING_PAYMENT_METHOD"
+	CodeInvalidPaymentAmount   ProductErrorCode = "INVALID_PAYMENT_AMOUNT"
+	CodePaymentsDoNotMatch     ProductErrorCode = "PAYMENTS_DO_NOT_MATCH"
+	CodeInvalidCreditNote      ProductErrorCode = "INVALID_CREDIT_NOTE"
+	CodeInvalidDebitNote       ProductErrorCode = "INVALID_DEBIT_NOTE"
 )
 
 // NewProductsCannotBeEmptyError creates an error for products cannot be empty
@@ -23,7 +28,37 @@ func NewInvalidAllowanceAmountError(amount string) *baseError.BaseError {
 	return baseError.NewBaseError(baseError.ErrorCode(CodeInvalidAllowanceAmount), "invalid allowance amount: "+amount)
 }
 
-// NewInvalidTaxAmountError creates an error for invalid tax amount
-func NewInvalidTaxAmountError(amount string) *baseError.BaseError {
-	return baseError.NewBaseError(baseError.ErrorCode(CodeInvalidTaxAmount), "invalid tax amount: "+amount)
+// NewInvalidTipAmountError creates an error for a negative tip
+func NewInvalidTipAmountError(amount string) *baseError.BaseError {
+	return baseError.NewBaseError(baseError.ErrorCode(CodeInvalidTipAmount), "invalid tip amount: "+amount)
+}
+
+// NewMissingPaymentMethodError creates an error for a payment without method
+func NewMissingPaymentMethodError() *baseError.BaseError {
+	return baseError.NewBaseError(baseError.ErrorCode(CodeMissingPaymentMethod), "payment method is required")
+}
+
+// NewInvalidPaymentAmountError creates an error for a payment amount that is not positive
+func NewInvalidPaymentAmountError(amount string) *baseError.BaseError {
+	return baseError.NewBaseError(baseError.ErrorCode(CodeInvalidPaymentAmount), "invalid payment amount: "+amount)
+}
+
+// NewPaymentsDoNotMatchError creates an error for payments that do not add up to the amount to pay
+func NewPaymentsDoNotMatchError(message string, paid string) *baseError.BaseError {
+	return baseError.NewBaseErrorWithField(baseError.ErrorCode(CodePaymentsDoNotMatch), message, paid)
+}
+
+// NewInvalidCreditNoteReasonError creates an error for an unknown credit note reason
+func NewInvalidCreditNoteReasonError(reason string) *baseError.BaseError {
+	return baseError.NewBaseErrorWithField(baseError.ErrorCode(CodeInvalidCreditNote), "invalid credit note reason", reason)
+}
+
+// NewCreditNoteExceedsBillError creates an error for a credit note worth more than what is left to credit
+func NewCreditNoteExceedsBillError(creditable string) *baseError.BaseError {
+	return baseError.NewBaseError(baseError.ErrorCode(CodeInvalidCreditNote), "credit note exceeds the amount left to credit: "+creditable)
+}
+
+// NewInvalidDebitNoteReasonError creates an error for an unknown debit note reason
+func NewInvalidDebitNoteReasonError(reason string) *baseError.BaseError {
+	return baseError.NewBaseErrorWithField(baseError.ErrorCode(CodeInvalidDebitNote), "invalid debit note reason", reason)
 }
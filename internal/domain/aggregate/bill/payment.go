@@ -0,0 +1,60 @@
+package bill
+
+import (
+	billError "laguna-escondida/backend/internal/domain/aggregate/bill/error"
+	"laguna-escondida/backend/internal/domain/dto"
+)
+
+// newPayments validates how the bill is paid, without payments the bill is paid in full
+// with the invoice payment code
+// Only cash can go over the amount to pay, the difference is the change given back and it
+// is taken from the last cash payments
+func newPayments(paymentCode dto.ElectronicInvoicePaymentCode, payments []dto.Payment, payAmount dto.Money) ([]dto.Payment, dto.Money, error) {
+	if len(payments) == 0 {
+		return []dto.Payment{{Method: paymentCode, Amount: payAmount}}, 0, nil
+	}
+
+	var cash, nonCash dto.Money
+	for _, payment := range payments {
+		if payment.Method == "" {
+			return nil, 0, billError.NewMissingPaymentMethodError()
+		}
+		if !payment.Amount.IsPositive() {
+			return nil, 0, billError.NewInvalidPaymentAmountError(payment.Amount.String())
+		}
+
+		if payment.Method == dto.ElectronicInvoicePaymentCodeCash {
+			cash = cash.Add(payment.Amount)
+		} else {
+			nonCash = nonCash.Add(payment.Amount)
+		}
+	}
+
+	if nonCash > payAmount {
+		return nil, 0, billError.NewPaymentsDoNotMatchError("non cash payments exceed the amount to pay "+payAmount.String(), nonCash.String())
+	}
+
+	paid := cash.Add(nonCash)
+	if paid < payAmount {
+		return nil, 0, billError.NewPaymentsDoNotMatchError("payments do not cover the amount to pay "+payAmount.String(), paid.String())
+	}
+
+	change := paid.Sub(payAmount)
+	remaining := change
+	result := make([]dto.Payment, len(payments))
+	for i := len(payments) - 1; i >= 0; i-- {
+		result[i] = payments[i]
+		result[i].Change = 0
+		if payments[i].Method != dto.ElectronicInvoicePaymentCodeCash || remaining.IsZero() {
+			continue
+		}
+
+		result[i].Change = remaining
+		if remaining > payments[i].Amount {
+			result[i].Change = payments[i].Amount
+		}
+		remaining = remaining.Sub(result[i].Change)
+	}
+
+	return result, change, nil
+}
@@ -0,0 +1,73 @@
+package bill
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+const provisionalDocumentWidth = 40
+
+// ProvisionalDocument renders the plain text document printed for the customer when the bill
+// is issued in contingency, it stands in for the electronic invoice until the DIAN validates it
+// The CUFE computed for the invoice is printed when known, the DIAN will report the same one
+func (a *Aggregate) ProvisionalDocument(cufe string) string {
+	separator := strings.Repeat("-", provisionalDocumentWidth)
+
+	var document strings.Builder
+	document.WriteString("FACTURA DE VENTA DE CONTINGENCIA\n")
+	fmt.Fprintf(&document, "No. %s%d\n", a.prefix, a.consecutive)
+	fmt.Fprintf(&document, "Fecha: %s\n", a.createdAt.Format("2006-01-02 15:04"))
+	if a.customer != nil {
+		fmt.Fprintf(&document, "Cliente: %s (%s)\n", a.customer.Name, a.customer.DocumentNumber)
+	} else {
+		document.WriteString("Cliente: Consumidor final\n")
+	}
+	document.WriteString(separator + "\n")
+
+	for _, product := range a.products {
+		description := lo.FromPtr(product.description)
+		if description == "" {
+			description = product.code
+		}
+		writeProvisionalLine(&document, fmt.Sprintf("%d x %s", product.quantity, description), product.unitPrice.Mul(product.quantity).String())
+	}
+
+	document.WriteString(separator + "\n")
+	writeProvisionalLine(&document, "Subtotal", a.totalAmount.String())
+	if !a.discountAmount.IsZero() {
+		writeProvisionalLine(&document, "Descuentos", a.discountAmount.String())
+	}
+	writeProvisionalLine(&document, "Impuestos", a.taxAmount.String())
+	if !a.tip.IsZero() {
+		writeProvisionalLine(&document, "Propina voluntaria", a.tip.String())
+	}
+	writeProvisionalLine(&document, "Total a pagar", a.payAmount.String())
+	document.WriteString(separator + "\n")
+	if cufe != "" {
+		document.WriteString("CUFE:\n")
+		for len(cufe) > provisionalDocumentWidth {
+			document.WriteString(cufe[:provisionalDocumentWidth] + "\n")
+			cufe = cufe[provisionalDocumentWidth:]
+		}
+		document.WriteString(cufe + "\n")
+		document.WriteString(separator + "\n")
+	}
+	document.WriteString("Documento provisional emitido en\n")
+	document.WriteString("contingencia. La factura electronica\n")
+	document.WriteString("se enviara a la DIAN cuando el servicio\n")
+	document.WriteString("se restablezca.\n")
+
+	return document.String()
+}
+
+// writeProvisionalLine aligns the amount to the right, long labels are cut to keep the width
+func writeProvisionalLine(document *strings.Builder, label string, amount string) {
+	labelWidth := provisionalDocumentWidth - len(amount) - 1
+	if runes := []rune(label); len(runes) > labelWidth {
+		label = string(runes[:labelWidth])
+	}
+
+	fmt.Fprintf(document, "%-*s %s\n", labelWidth, label, amount)
+}
@@ -0,0 +1,353 @@
+package billowner
+
+import (
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	billOwnerError "laguna-escondida/backend/internal/domain/aggregate/billowner/error"
+	"laguna-escondida/backend/internal/domain/dto"
+
+	"github.com/samber/lo"
+)
+
+const (
+	maxNameLength          = 255
+	maxCelphoneLength      = 50
+	municipalityCodeLength = 5
+)
+
+// nitWeights are the DIAN prime factors applied to the NIT digits from right to left
+var nitWeights = []int{3, 7, 13, 17, 19, 23, 29, 37, 41, 43, 47, 53, 59, 67, 71}
+
+type Aggregate struct {
+	id           string
+	documentType dto.DocumentType
+	celphone     *string
+	email        string
+	name         string
+	fiscalData   fiscalData
+	createdAt    time.Time
+	updatedAt    time.Time
+}
+
+// fiscalData is what corporate customers need on the invoice to deduct it
+type fiscalData struct {
+	address                *string
+	city                   *string
+	municipalityCode       *string
+	department             *string
+	taxRegime              dto.TaxRegime
+	fiscalResponsibilities []dto.FiscalResponsibility
+}
+
+// NewAggregateFromCreateRequest validates a new bill owner, the document number is stored
+// without separators and a NIT without its check digit, which is always derived from it
+func NewAggregateFromCreateRequest(req *dto.CreateBillOwnerRequest) (*Aggregate, error) {
+	documentNumber, checkDigit := splitCheckDigit(req.ID, req.IdentificationType)
+	if req.CheckDigit != "" {
+		if checkDigit != "" && checkDigit != strings.TrimSpace(req.CheckDigit) {
+			return nil, billOwnerError.NewInvalidCheckDigitError(documentNumber, req.CheckDigit)
+		}
+		checkDigit = strings.TrimSpace(req.CheckDigit)
+	}
+
+	now := time.Now()
+	aggregate := &Aggregate{
+		id:           documentNumber,
+		documentType: req.IdentificationType,
+		celphone:     trimmedOrNil(req.Celphone),
+		email:        strings.TrimSpace(req.Email),
+		name:         strings.TrimSpace(req.Name),
+		fiscalData: fiscalData{
+			address:                trimmedOrNil(req.Address),
+			city:                   trimmedOrNil(req.City),
+			municipalityCode:       trimmedOrNil(req.MunicipalityCode),
+			department:             trimmedOrNil(req.Department),
+			taxRegime:              req.TaxRegime,
+			fiscalResponsibilities: uniqueResponsibilities(req.FiscalResponsibilities),
+		},
+		createdAt: now,
+		updatedAt: now,
+	}
+
+	if err := aggregate.validate(); err != nil {
+		return nil, err
+	}
+
+	if checkDigit != "" {
+		if aggregate.documentType != dto.DocumentTypeNIT {
+			return nil, billOwnerError.NewInvalidRequestError("check_digit only applies to a NIT", checkDigit)
+		}
+		if checkDigit != NITCheckDigit(aggregate.id) {
+			return nil, billOwnerError.NewInvalidCheckDigitError(aggregate.id, checkDigit)
+		}
+	}
+
+	return aggregate, nil
+}
+
+// NewCustomer validates the customer a bill is issued to as a bill owner, since the bill
+// saves it as one. It comes back with the document number normalized, a NIT typed with a
+// check digit that does not match it is rejected
+func NewCustomer(customer *dto.Customer) (*dto.Customer, error) {
+	aggregate, err := NewAggregateFromCreateRequest(&dto.CreateBillOwnerRequest{
+		ID:                     customer.DocumentNumber,
+		IdentificationType:     customer.DocumentType,
+		Name:                   customer.Name,
+		Email:                  customer.Email,
+		Celphone:               lo.EmptyableToPtr(customer.Telephone),
+		Address:                lo.EmptyableToPtr(customer.Address),
+		City:                   lo.EmptyableToPtr(customer.City),
+		MunicipalityCode:       lo.EmptyableToPtr(customer.MunicipalityCode),
+		Department:             lo.EmptyableToPtr(customer.Department),
+		TaxRegime:              customer.TaxRegime,
+		FiscalResponsibilities: customer.FiscalResponsibilities,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return aggregate.toCustomer(), nil
+}
+
+func NewAggregateFromDTO(owner *dto.BillOwner) *Aggregate {
+	return &Aggregate{
+		id:           owner.ID,
+		documentType: owner.IdentificationType,
+		celphone:     owner.Celphone,
+		email:        owner.Email,
+		name:         owner.Name,
+		fiscalData: fiscalData{
+			address:                owner.Address,
+			city:                   owner.City,
+			municipalityCode:       owner.MunicipalityCode,
+			department:             owner.Department,
+			taxRegime:              owner.TaxRegime,
+			fiscalResponsibilities: owner.FiscalResponsibilities,
+		},
+		createdAt: owner.CreatedAt,
+		updatedAt: owner.UpdatedAt,
+	}
+}
+
+// Update returns the bill owner with the new data, the document number cannot change
+// since bills reference it, but its type can be corrected
+func (a *Aggregate) Update(req *dto.UpdateBillOwnerRequest) (*Aggregate, error) {
+	updated := &Aggregate{
+		id:           a.id,
+		documentType: req.IdentificationType,
+		celphone:     trimmedOrNil(req.Celphone),
+		email:        strings.TrimSpace(req.Email),
+		name:         strings.TrimSpace(req.Name),
+		fiscalData: fiscalData{
+			address:                trimmedOrNil(req.Address),
+			city:                   trimmedOrNil(req.City),
+			municipalityCode:       trimmedOrNil(req.MunicipalityCode),
+			department:             trimmedOrNil(req.Department),
+			taxRegime:              req.TaxRegime,
+			fiscalResponsibilities: uniqueResponsibilities(req.FiscalResponsibilities),
+		},
+		createdAt: a.createdAt,
+		updatedAt: time.Now(),
+	}
+
+	if err := updated.validate(); err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func (a *Aggregate) validate() error {
+	if err := ValidateDocument(a.documentType, a.id); err != nil {
+		return err
+	}
+	if a.name == "" || len(a.name) > maxNameLength {
+		return billOwnerError.NewInvalidRequestError("name must have between 1 and 255 characters", a.name)
+	}
+	if _, err := mail.ParseAddress(a.email); err != nil || strings.ContainsAny(a.email, " <>") {
+		return billOwnerError.NewInvalidRequestError("email must be a valid address", a.email)
+	}
+	if a.celphone != nil && len(*a.celphone) > maxCelphoneLength {
+		return billOwnerError.NewInvalidRequestError("celphone must have at most 50 characters", *a.celphone)
+	}
+
+	return ValidateFiscalData(lo.FromPtr(a.fiscalData.municipalityCode), a.fiscalData.taxRegime, a.fiscalData.fiscalResponsibilities)
+}
+
+// ValidateFiscalData checks the fiscal data of a customer, every field is optional
+func ValidateFiscalData(municipalityCode string, taxRegime dto.TaxRegime, responsibilities []dto.FiscalResponsibility) error {
+	if municipalityCode != "" && (len(municipalityCode) != municipalityCodeLength || !isDigits(municipalityCode)) {
+		return billOwnerError.NewInvalidFiscalDataError("municipality_code must be the 5 digit DANE code", municipalityCode)
+	}
+
+	switch taxRegime {
+	case "", dto.TaxRegimeVATResponsible, dto.TaxRegimeNotVATResponsible:
+	default:
+		return billOwnerError.NewInvalidFiscalDataError("tax_regime must be vat_responsible or not_vat_responsible", taxRegime)
+	}
+
+	for _, responsibility := range responsibilities {
+		switch responsibility {
+		case dto.FiscalResponsibilityLargeTaxpayer, dto.FiscalResponsibilitySelfWithholder, dto.FiscalResponsibilityVATWithholder,
+			dto.FiscalResponsibilitySimpleTaxRegime, dto.FiscalResponsibilityNotApplicable:
+		default:
+			return billOwnerError.NewInvalidFiscalDataError("fiscal_responsibilities must be O-13, O-15, O-23, O-47 or R-99-PN", responsibility)
+		}
+	}
+
+	// R-99-PN states the customer has none of the other responsibilities
+	if len(responsibilities) > 1 && lo.Contains(responsibilities, dto.FiscalResponsibilityNotApplicable) {
+		return billOwnerError.NewInvalidFiscalDataError("R-99-PN cannot be combined with other responsibilities", responsibilities)
+	}
+
+	return nil
+}
+
+// ValidateDocument checks the document number fits its type. Colombian documents are only
+// digits, documents issued abroad may also have letters
+func ValidateDocument(documentType dto.DocumentType, number string) error {
+	switch documentType {
+	case dto.DocumentTypeNationalIdentificationNumber:
+		if !isDigits(number) || len(number) < 3 || len(number) > 10 {
+			return billOwnerError.NewInvalidDocumentError("a CC must have between 3 and 10 digits", number)
+		}
+	case dto.DocumentTypeNIT:
+		if !isDigits(number) || len(number) < 6 || len(number) > len(nitWeights) {
+			return billOwnerError.NewInvalidDocumentError("a NIT must have between 6 and 15 digits without its check digit", number)
+		}
+	case dto.DocumentTypeForeignerID, dto.DocumentTypePassport, dto.DocumentTypeForeignID, dto.DocumentTypeSpecialPermitID:
+		if !isAlphanumeric(number) || len(number) < 3 || len(number) > 20 {
+			return billOwnerError.NewInvalidDocumentError("a foreign document must have between 3 and 20 letters or digits", number)
+		}
+	default:
+		return billOwnerError.NewInvalidDocumentError("identification_type must be one of CC, NIT, CE, PA, DIE or PEP", documentType)
+	}
+
+	return nil
+}
+
+// NITCheckDigit computes the DIAN check digit (DV) of a NIT given only with digits
+func NITCheckDigit(nit string) string {
+	sum := 0
+	for i := 0; i < len(nit) && i < len(nitWeights); i++ {
+		sum += int(nit[len(nit)-1-i]-'0') * nitWeights[i]
+	}
+
+	remainder := sum % 11
+	if remainder > 1 {
+		return strconv.Itoa(11 - remainder)
+	}
+
+	return strconv.Itoa(remainder)
+}
+
+// NormalizeDocumentNumber removes the separators people usually type, such as dots in
+// 1.020.304.050, so the same document always maps to the same bill owner
+func NormalizeDocumentNumber(number string) string {
+	number = strings.ToUpper(strings.TrimSpace(number))
+	return strings.NewReplacer(".", "", " ", "", ",", "").Replace(number)
+}
+
+// splitCheckDigit separates the check digit a NIT may be typed with, as in 900123456-8
+func splitCheckDigit(number string, documentType dto.DocumentType) (string, string) {
+	number = NormalizeDocumentNumber(number)
+	if documentType != dto.DocumentTypeNIT {
+		return number, ""
+	}
+
+	if base, checkDigit, found := strings.Cut(number, "-"); found {
+		return base, checkDigit
+	}
+
+	return number, ""
+}
+
+func uniqueResponsibilities(responsibilities []dto.FiscalResponsibility) []dto.FiscalResponsibility {
+	if len(responsibilities) == 0 {
+		return nil
+	}
+
+	return lo.Uniq(lo.Map(responsibilities, func(responsibility dto.FiscalResponsibility, _ int) dto.FiscalResponsibility {
+		return dto.FiscalResponsibility(strings.ToUpper(strings.TrimSpace(string(responsibility))))
+	}))
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return value != ""
+}
+
+func isAlphanumeric(value string) bool {
+	for _, r := range value {
+		if (r < '0' || r > '9') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+
+	return value != ""
+}
+
+func trimmedOrNil(value *string) *string {
+	if value == nil {
+		return nil
+	}
+
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+
+	return &trimmed
+}
+
+func (a *Aggregate) ID() string {
+	return a.id
+}
+
+func (a *Aggregate) ToDTO() *dto.BillOwner {
+	checkDigit := ""
+	if a.documentType == dto.DocumentTypeNIT {
+		checkDigit = NITCheckDigit(a.id)
+	}
+
+	return &dto.BillOwner{
+		ID:                     a.id,
+		CheckDigit:             checkDigit,
+		Celphone:               a.celphone,
+		Email:                  a.email,
+		Name:                   a.name,
+		IdentificationType:     a.documentType,
+		Address:                a.fiscalData.address,
+		City:                   a.fiscalData.city,
+		MunicipalityCode:       a.fiscalData.municipalityCode,
+		Department:             a.fiscalData.department,
+		TaxRegime:              a.fiscalData.taxRegime,
+		FiscalResponsibilities: a.fiscalData.fiscalResponsibilities,
+		CreatedAt:              a.createdAt,
+		UpdatedAt:              a.updatedAt,
+	}
+}
+
+func (a *Aggregate) toCustomer() *dto.Customer {
+	return &dto.Customer{
+		DocumentNumber:         a.id,
+		DocumentType:           a.documentType,
+		Name:                   a.name,
+		Email:                  a.email,
+		Address:                lo.FromPtr(a.fiscalData.address),
+		City:                   lo.FromPtr(a.fiscalData.city),
+		MunicipalityCode:       lo.FromPtr(a.fiscalData.municipalityCode),
+		Department:             lo.FromPtr(a.fiscalData.department),
+		Telephone:              lo.FromPtr(a.celphone),
+		TaxRegime:              a.fiscalData.taxRegime,
+		FiscalResponsibilities: a.fiscalData.fiscalResponsibilities,
+	}
+}
@@ -0,0 +1,35 @@
+package error
+
+import (
+	baseError "laguna-escondida/backend/internal/platform/shared/domain/error"
+)
+
+// BillOwnerErrorCode defines error codes for bill owner aggregate
+type BillOwnerErrorCode string
+
+const (
+	CodeInvalidRequest    BillOwnerErrorCode = "BILL_OWNER_INVALID_REQUEST"
+	CodeInvalidDocument   BillOwnerErrorCode = "BILL_OWNER_INVALID_DOCUMENT"
+	CodeInvalidCheckDigit BillOwnerErrorCode = "BILL_OWNER_INVALID_CHECK_DIGIT"
+	CodeInvalidFiscalData BillOwnerErrorCode = "BILL_OWNER_INVALID_FISCAL_DATA"
+)
+
+// NewInvalidRequestError creates an error for a missing or malformed field
+func NewInvalidRequestError(message string, value interface{}) *baseError.BaseError {
+	return baseError.NewBaseErrorWithField(baseError.ErrorCode(CodeInvalidRequest), message, value)
+}
+
+// NewInvalidDocumentError creates an error for a document number that does not fit its type
+func NewInvalidDocumentError(message string, value interface{}) *baseError.BaseError {
+	return baseError.NewBaseErrorWithField(baseError.ErrorCode(CodeInvalidDocument), message, value)
+}
+
+// NewInvalidCheckDigitError creates an error for a NIT whose check digit (DV) does not match
+func NewInvalidCheckDigitError(nit string, checkDigit string) *baseError.BaseError {
+	return baseError.NewBaseErrorWithField(baseError.ErrorCode(CodeInvalidCheckDigit), "check digit does not match NIT "+nit, checkDigit)
+}
+
+// NewInvalidFiscalDataError creates an error for an address, tax regime or responsibility the DIAN would not accept
+func NewInvalidFiscalDataError(message string, value interface{}) *baseError.BaseError {
+	return baseError.NewBaseErrorWithField(baseError.ErrorCode(CodeInvalidFiscalData), message, value)
+}
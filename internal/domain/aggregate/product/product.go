@@ -3,7 +3,7 @@ package product
 import (
 	productError "laguna-escondida/backend/internal/domain/aggregate/product/error"
 	"laguna-escondida/backend/internal/domain/dto"
-	"math"
+	"laguna-escondida/backend/internal/domain/tax"
 	"strconv"
 	"time"
 
@@ -15,26 +15,26 @@ type Aggregate struct {
 	name                string
 	category            string
 	version             int
-	unitPrice           float64
+	unitPrice           dto.Money
 	vat                 float64
 	ico                 float64
 	description         string
 	brand               string
 	model               string
 	sku                 string
-	totalPriceWithTaxes float64
+	totalPriceWithTaxes dto.Money
 	createdAt           time.Time
 	updatedAt           time.Time
 }
 
 // calculateTaxesAndUnitPrice parses and validates tax values, then calculates unit price
 // Returns: totalPriceWithTaxes, vat (as decimal), ico (as decimal), unitPrice, error
-func calculateTaxesAndUnitPrice(totalPriceWithTaxesStr, vatStr, icoStr, taxesFormat string) (float64, float64, float64, float64, error) {
-	totalPriceWithTaxes, err := strconv.ParseFloat(totalPriceWithTaxesStr, 64)
+func calculateTaxesAndUnitPrice(totalPriceWithTaxesStr, vatStr, icoStr, taxesFormat string) (dto.Money, float64, float64, dto.Money, error) {
+	totalPriceWithTaxes, err := dto.ParseMoney(totalPriceWithTaxesStr)
 	if err != nil {
 		return 0, 0, 0, 0, productError.NewInvalidPriceErrorWithField("total_price_with_taxes must be a number", totalPriceWithTaxesStr)
 	}
-	if totalPriceWithTaxes <= 0 {
+	if !totalPriceWithTaxes.IsPositive() {
 		return 0, 0, 0, 0, productError.NewInvalidPriceErrorWithField("total_price_with_taxes must be greater than 0", totalPriceWithTaxesStr)
 	}
 
@@ -65,11 +65,7 @@ func calculateTaxesAndUnitPrice(totalPriceWithTaxesStr, vatStr, icoStr, taxesFor
 
 	vatPercentage := vat / 100
 	icoPercentage := ico / 100
-	taxSumPercentage := (vat + ico) / 100
-	unitPrice := totalPriceWithTaxes / (1 + taxSumPercentage)
-
-	// Round unitPrice to 2 decimal places
-	unitPrice = math.Round(unitPrice*100) / 100
+	unitPrice := tax.ExtractFromTotal(totalPriceWithTaxes, vatPercentage, icoPercentage).Base
 
 	return totalPriceWithTaxes, vatPercentage, icoPercentage, unitPrice, nil
 }
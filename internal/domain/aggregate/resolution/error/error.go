@@ -0,0 +1,41 @@
+package error
+
+import (
+	baseError "laguna-escondida/backend/internal/platform/shared/domain/error"
+)
+
+// ResolutionErrorCode defines error codes for numbering resolution aggregate
+type ResolutionErrorCode string
+
+const (
+	CodeInvalidRequest ResolutionErrorCode = "RESOLUTION_INVALID_REQUEST"
+	CodeInvalidRange   ResolutionErrorCode = "RESOLUTION_INVALID_RANGE"
+	CodeInvalidDates   ResolutionErrorCode = "RESOLUTION_INVALID_DATES"
+	CodeExhausted      ResolutionErrorCode = "RESOLUTION_EXHAUSTED"
+	CodeOutOfDate      ResolutionErrorCode = "RESOLUTION_OUT_OF_DATE"
+)
+
+// NewInvalidRequestError creates an error for a missing or malformed field
+func NewInvalidRequestError(message string, value interface{}) *baseError.BaseError {
+	return baseError.NewBaseErrorWithField(baseError.ErrorCode(CodeInvalidRequest), message, value)
+}
+
+// NewInvalidRangeError creates an error for a range of consecutives that cannot be numbered
+func NewInvalidRangeError(message string, value interface{}) *baseError.BaseError {
+	return baseError.NewBaseErrorWithField(baseError.ErrorCode(CodeInvalidRange), message, value)
+}
+
+// NewInvalidDatesError creates an error for a validity period that is malformed or inverted
+func NewInvalidDatesError(message string, value interface{}) *baseError.BaseError {
+	return baseError.NewBaseErrorWithField(baseError.ErrorCode(CodeInvalidDates), message, value)
+}
+
+// NewExhaustedError creates an error for a resolution without numbers left
+func NewExhaustedError(prefix string, to int) *baseError.BaseError {
+	return baseError.NewBaseErrorWithField(baseError.ErrorCode(CodeExhausted), "resolution "+prefix+" has no numbers left", to)
+}
+
+// NewOutOfDateError creates an error for a resolution used outside its validity period
+func NewOutOfDateError(prefix string, date string) *baseError.BaseError {
+	return baseError.NewBaseErrorWithField(baseError.ErrorCode(CodeOutOfDate), "resolution "+prefix+" is not valid on this date", date)
+}
@@ -0,0 +1,266 @@
+package resolution
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	resolutionError "laguna-escondida/backend/internal/domain/aggregate/resolution/error"
+	"laguna-escondida/backend/internal/domain/dto"
+
+	"github.com/google/uuid"
+)
+
+const (
+	dateLayout = "2006-01-02"
+
+	// LowRemainingNumbers and LowRemainingDays are the thresholds under which a resolution
+	// warns that a new one has to be requested to the DIAN
+	LowRemainingNumbers = 100
+	LowRemainingDays    = 30
+)
+
+type Aggregate struct {
+	id                string
+	resolutionNumber  string
+	kind              dto.NumberingResolutionKind
+	prefix            string
+	from              int
+	to                int
+	lastConsecutive   int
+	validFrom         time.Time
+	validTo           time.Time
+	technicalKey      string
+	providerRemaining *int
+	createdAt         time.Time
+	updatedAt         time.Time
+}
+
+// NewAggregateFromCreateRequest validates a new resolution, its first invoice gets the start of the range
+func NewAggregateFromCreateRequest(req *dto.CreateNumberingResolutionRequest) (*Aggregate, error) {
+	validFrom, validTo, err := parseValidity(req.ValidFrom, req.ValidTo)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	aggregate := &Aggregate{
+		id:               uuid.New().String(),
+		resolutionNumber: strings.TrimSpace(req.ResolutionNumber),
+		kind:             kindOrDefault(req.Kind),
+		prefix:           strings.ToUpper(strings.TrimSpace(req.Prefix)),
+		from:             req.From,
+		to:               req.To,
+		lastConsecutive:  req.From - 1,
+		validFrom:        validFrom,
+		validTo:          validTo,
+		technicalKey:     strings.TrimSpace(req.TechnicalKey),
+		createdAt:        now,
+		updatedAt:        now,
+	}
+
+	if err := aggregate.validate(); err != nil {
+		return nil, err
+	}
+
+	return aggregate, nil
+}
+
+func NewAggregateFromDTO(resolution *dto.NumberingResolution) *Aggregate {
+	return &Aggregate{
+		id:                resolution.ID,
+		resolutionNumber:  resolution.ResolutionNumber,
+		kind:              kindOrDefault(resolution.Kind),
+		prefix:            resolution.Prefix,
+		from:              resolution.From,
+		to:                resolution.To,
+		lastConsecutive:   resolution.LastConsecutive,
+		validFrom:         resolution.ValidFrom,
+		validTo:           resolution.ValidTo,
+		technicalKey:      resolution.TechnicalKey,
+		providerRemaining: resolution.ProviderRemaining,
+		createdAt:         resolution.CreatedAt,
+		updatedAt:         resolution.UpdatedAt,
+	}
+}
+
+// Update returns the resolution with the new data. Once an invoice was numbered the prefix
+// and the start of the range are fixed and the range cannot end before the last number used
+func (a *Aggregate) Update(req *dto.UpdateNumberingResolutionRequest) (*Aggregate, error) {
+	validFrom, validTo, err := parseValidity(req.ValidFrom, req.ValidTo)
+	if err != nil {
+		return nil, err
+	}
+
+	kind := a.kind
+	if req.Kind != "" {
+		kind = req.Kind
+	}
+
+	updated := &Aggregate{
+		id:                a.id,
+		resolutionNumber:  strings.TrimSpace(req.ResolutionNumber),
+		kind:              kind,
+		prefix:            strings.ToUpper(strings.TrimSpace(req.Prefix)),
+		from:              req.From,
+		to:                req.To,
+		lastConsecutive:   a.lastConsecutive,
+		validFrom:         validFrom,
+		validTo:           validTo,
+		technicalKey:      strings.TrimSpace(req.TechnicalKey),
+		providerRemaining: a.providerRemaining,
+		createdAt:         a.createdAt,
+		updatedAt:         time.Now(),
+	}
+
+	if a.IsUsed() {
+		if updated.kind != a.kind {
+			return nil, resolutionError.NewInvalidRequestError("kind cannot change after numbering invoices", req.Kind)
+		}
+		if updated.prefix != a.prefix {
+			return nil, resolutionError.NewInvalidRequestError("prefix cannot change after numbering invoices", req.Prefix)
+		}
+		if updated.from != a.from {
+			return nil, resolutionError.NewInvalidRangeError("from cannot change after numbering invoices", req.From)
+		}
+		if updated.to < a.lastConsecutive {
+			return nil, resolutionError.NewInvalidRangeError(fmt.Sprintf("to cannot be lower than the last number used %d", a.lastConsecutive), req.To)
+		}
+	} else {
+		updated.lastConsecutive = updated.from - 1
+	}
+
+	if err := updated.validate(); err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func (a *Aggregate) validate() error {
+	if a.resolutionNumber == "" {
+		return resolutionError.NewInvalidRequestError("resolution_number is required", a.resolutionNumber)
+	}
+	if a.kind != dto.NumberingResolutionKindElectronic && a.kind != dto.NumberingResolutionKindContingency {
+		return resolutionError.NewInvalidRequestError("kind must be electronic or contingency", a.kind)
+	}
+	if a.prefix == "" || len(a.prefix) > 4 {
+		return resolutionError.NewInvalidRequestError("prefix must have between 1 and 4 characters", a.prefix)
+	}
+	if a.technicalKey == "" {
+		return resolutionError.NewInvalidRequestError("technical_key is required", a.technicalKey)
+	}
+	if a.from <= 0 {
+		return resolutionError.NewInvalidRangeError("from must be greater than 0", a.from)
+	}
+	if a.to < a.from {
+		return resolutionError.NewInvalidRangeError("to must be greater than or equal to from", a.to)
+	}
+
+	return nil
+}
+
+func kindOrDefault(kind dto.NumberingResolutionKind) dto.NumberingResolutionKind {
+	if kind == "" {
+		return dto.NumberingResolutionKindElectronic
+	}
+
+	return kind
+}
+
+func parseValidity(validFromStr, validToStr string) (time.Time, time.Time, error) {
+	validFrom, err := time.ParseInLocation(dateLayout, validFromStr, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, resolutionError.NewInvalidDatesError("valid_from must be a date formatted as YYYY-MM-DD", validFromStr)
+	}
+	validTo, err := time.ParseInLocation(dateLayout, validToStr, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, resolutionError.NewInvalidDatesError("valid_to must be a date formatted as YYYY-MM-DD", validToStr)
+	}
+	if validTo.Before(validFrom) {
+		return time.Time{}, time.Time{}, resolutionError.NewInvalidDatesError("valid_to must be on or after valid_from", validToStr)
+	}
+
+	return validFrom, validTo, nil
+}
+
+// IsUsed tells whether the resolution already numbered an invoice
+func (a *Aggregate) IsUsed() bool {
+	return a.lastConsecutive >= a.from
+}
+
+// Remaining returns how many numbers are left, the provider count is trusted when it is lower
+func (a *Aggregate) Remaining() int {
+	remaining := a.to - a.lastConsecutive
+	if a.providerRemaining != nil {
+		remaining = min(remaining, *a.providerRemaining)
+	}
+
+	return max(remaining, 0)
+}
+
+// remainingDays counts the days left including the last day of validity
+func (a *Aggregate) remainingDays(now time.Time) int {
+	lastDay := time.Date(a.validTo.Year(), a.validTo.Month(), a.validTo.Day(), 0, 0, 0, 0, time.UTC)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return int(lastDay.Sub(today).Hours()/24) + 1
+}
+
+// IsValidOn tells whether the date falls within the validity period, both ends included
+func (a *Aggregate) IsValidOn(now time.Time) bool {
+	date := now.Format(dateLayout)
+	return date >= a.validFrom.Format(dateLayout) && date <= a.validTo.Format(dateLayout)
+}
+
+// CheckUsable refuses to number invoices with a resolution out of date or out of numbers
+func (a *Aggregate) CheckUsable(now time.Time) error {
+	if !a.IsValidOn(now) {
+		return resolutionError.NewOutOfDateError(a.prefix, now.Format(dateLayout))
+	}
+	if a.Remaining() == 0 {
+		return resolutionError.NewExhaustedError(a.prefix, a.to)
+	}
+
+	return nil
+}
+
+// Warnings lists what needs attention before the resolution stops numbering invoices
+func (a *Aggregate) Warnings(now time.Time) []string {
+	var warnings []string
+	if remaining := a.Remaining(); remaining <= LowRemainingNumbers {
+		warnings = append(warnings, fmt.Sprintf("only %d numbers left in resolution %s", remaining, a.resolutionNumber))
+	}
+	if days := a.remainingDays(now); a.IsValidOn(now) && days <= LowRemainingDays {
+		warnings = append(warnings, fmt.Sprintf("resolution %s expires in %d days", a.resolutionNumber, days))
+	}
+
+	return warnings
+}
+
+// Overlaps tells whether both resolutions could give the same number to two invoices
+func (a *Aggregate) Overlaps(other *dto.NumberingResolution) bool {
+	return a.id != other.ID && a.prefix == other.Prefix && a.from <= other.To && other.From <= a.to
+}
+
+func (a *Aggregate) ID() string {
+	return a.id
+}
+
+func (a *Aggregate) ToDTO() *dto.NumberingResolution {
+	return &dto.NumberingResolution{
+		ID:                a.id,
+		ResolutionNumber:  a.resolutionNumber,
+		Kind:              a.kind,
+		Prefix:            a.prefix,
+		From:              a.from,
+		To:                a.to,
+		LastConsecutive:   a.lastConsecutive,
+		ValidFrom:         a.validFrom,
+		ValidTo:           a.validTo,
+		TechnicalKey:      a.technicalKey,
+		ProviderRemaining: a.providerRemaining,
+		Remaining:         a.Remaining(),
+		CreatedAt:         a.createdAt,
+		UpdatedAt:         a.updatedAt,
+	}
+}
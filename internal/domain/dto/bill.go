@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+// ListBillsRequest filters the issued bills, every filter is optional
+type ListBillsRequest struct {
+	CreatedFrom *time.Time `json:"created_from"`
+	CreatedTo   *time.Time `json:"created_to"`
+	// PaymentMethods matches bills paid at least partly with any of the methods
+	PaymentMethods []ElectronicInvoicePaymentCode `json:"payment_methods"`
+	// CustomerID is the document number of the customer
+	CustomerID string `json:"customer_id"`
+	// Statuses are DIAN statuses, pending includes the bills not sent to the provider yet
+	Statuses []ElectronicInvoiceDocumentStatus `json:"statuses"`
+	Page     int                               `json:"page"`
+	PageSize int                               `json:"page_size"`
+}
+
+type BillListResponse struct {
+	Bills    []*Bill `json:"bills"`
+	Total    int     `json:"total"`
+	Page     int     `json:"page"`
+	PageSize int     `json:"page_size"`
+}
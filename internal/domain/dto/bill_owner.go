@@ -2,27 +2,67 @@ package dto
 
 import "time"
 
+// BillOwner is a customer bills can be issued to, identified by its document number
 type BillOwner struct {
-	ID                 string    `json:"id"`
-	Celphone           *string   `json:"celphone"`
-	Email              string    `json:"email"`
-	Name               string    `json:"name"`
-	IdentificationType *string   `json:"identification_type"`
-	CreatedAt          time.Time `json:"created_at"`
-	UpdatedAt          time.Time `json:"updated_at"`
+	ID string `json:"id"`
+	// CheckDigit is the DV of a NIT, derived from the number and empty for other documents
+	CheckDigit string `json:"check_digit,omitempty"`
+	// Celphone is the telephone sent on the invoices issued to the bill owner
+	Celphone               *string                `json:"celphone"`
+	Email                  string                 `json:"email"`
+	Name                   string                 `json:"name"`
+	IdentificationType     DocumentType           `json:"identification_type"`
+	Address                *string                `json:"address"`
+	City                   *string                `json:"city"`
+	MunicipalityCode       *string                `json:"municipality_code"`
+	Department             *string                `json:"department"`
+	TaxRegime              TaxRegime              `json:"tax_regime"`
+	FiscalResponsibilities []FiscalResponsibility `json:"fiscal_responsibilities"`
+	CreatedAt              time.Time              `json:"created_at"`
+	UpdatedAt              time.Time              `json:"updated_at"`
 }
 
 type CreateBillOwnerRequest struct {
-	ID                 string  `json:"id" validate:"required,min=1"`
-	Celphone           *string `json:"celphone"`
-	Email              string  `json:"email" validate:"required,email"`
-	Name               string  `json:"name" validate:"required,min=1,max=255"`
-	IdentificationType *string `json:"identification_type"`
+	// ID is the document number, a NIT may include its check digit as in 900123456-8
+	ID string `json:"id" validate:"required,min=1"`
+	// CheckDigit is optional, when given it must match the NIT
+	CheckDigit             string                 `json:"check_digit"`
+	Celphone               *string                `json:"celphone"`
+	Email                  string                 `json:"email" validate:"required,email"`
+	Name                   string                 `json:"name" validate:"required,min=1,max=255"`
+	IdentificationType     DocumentType           `json:"identification_type" validate:"required"`
+	Address                *string                `json:"address"`
+	City                   *string                `json:"city"`
+	MunicipalityCode       *string                `json:"municipality_code"`
+	Department             *string                `json:"department"`
+	TaxRegime              TaxRegime              `json:"tax_regime"`
+	FiscalResponsibilities []FiscalResponsibility `json:"fiscal_responsibilities"`
 }
 
 type UpdateBillOwnerRequest struct {
-	Celphone           *string `json:"celphone"`
-	Email              string  `json:"email" validate:"required,email"`
-	Name               string  `json:"name" validate:"required,min=1,max=255"`
-	IdentificationType *string `json:"identification_type"`
+	Celphone               *string                `json:"celphone"`
+	Email                  string                 `json:"email" validate:"required,email"`
+	Name                   string                 `json:"name" validate:"required,min=1,max=255"`
+	IdentificationType     DocumentType           `json:"identification_type" validate:"required"`
+	Address                *string                `json:"address"`
+	City                   *string                `json:"city"`
+	MunicipalityCode       *string                `json:"municipality_code"`
+	Department             *string                `json:"department"`
+	TaxRegime              TaxRegime              `json:"tax_regime"`
+	FiscalResponsibilities []FiscalResponsibility `json:"fiscal_responsibilities"`
+}
+
+// ListBillOwnersRequest pages through the bill owners, DocumentNumber matches the start of
+// the document so the cashier can look a customer up while typing it
+type ListBillOwnersRequest struct {
+	DocumentNumber string
+	Page           int
+	PageSize       int
+}
+
+type BillOwnerListResponse struct {
+	BillOwners []*BillOwner `json:"bill_owners"`
+	Total      int          `json:"total"`
+	Page       int          `json:"page"`
+	PageSize   int          `json:"page_size"`
 }
@@ -0,0 +1,32 @@
+package dto
+
+import "time"
+
+// ContingencyPeriod is a time span in which bills were issued locally because the provider
+// could not be reached, they are transmitted once it recovers
+type ContingencyPeriod struct {
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+	// Automatic periods are started by the outbox dispatcher and end on its first successful send
+	Automatic bool       `json:"automatic"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	// BillCount is how many bills were issued in the period and PendingCount how many of them
+	// the provider has not accepted yet
+	BillCount    int `json:"bill_count"`
+	PendingCount int `json:"pending_count"`
+}
+
+type ActivateContingencyRequest struct {
+	Reason string `json:"reason" validate:"required,min=1"`
+}
+
+type ContingencyStatus struct {
+	Active bool               `json:"active"`
+	Period *ContingencyPeriod `json:"period,omitempty"`
+}
+
+type ContingencyPeriodListResponse struct {
+	Periods []*ContingencyPeriod `json:"periods"`
+	Total   int                  `json:"total"`
+}
@@ -0,0 +1,50 @@
+package dto
+
+import "time"
+
+// CreditNoteReason is the DIAN correction concept of a credit note
+type CreditNoteReason string
+
+const (
+	CreditNoteReasonReturnedGoods   CreditNoteReason = "returned_goods"
+	CreditNoteReasonCancellation    CreditNoteReason = "cancellation"
+	CreditNoteReasonDiscount        CreditNoteReason = "discount"
+	CreditNoteReasonPriceAdjustment CreditNoteReason = "price_adjustment"
+	CreditNoteReasonOther           CreditNoteReason = "other"
+)
+
+// CreateCreditNoteRequest corrects an issued bill, without items everything not credited
+// yet is credited and the bill is fully reversed
+type CreateCreditNoteRequest struct {
+	Reason      CreditNoteReason   `json:"reason"`
+	Description string             `json:"description"`
+	Items       []OrderProductItem `json:"items,omitempty"`
+}
+
+type CreditNote struct {
+	ID             string           `json:"id"`
+	BillID         string           `json:"bill_id"`
+	Prefix         string           `json:"prefix"`
+	Consecutive    int              `json:"consecutive"`
+	Reason         CreditNoteReason `json:"reason"`
+	Description    string           `json:"description"`
+	TotalAmount    Money            `json:"total_amount"`
+	DiscountAmount Money            `json:"discount_amount"`
+	TaxAmount      Money            `json:"tax_amount"`
+	PayAmount      Money            `json:"pay_amount"`
+	VAT            Money            `json:"vat"`
+	ICO            Money            `json:"ico"`
+	Tip            Money            `json:"tip"`
+	CUFE           *string          `json:"cufe,omitempty"`
+	Products       []BillProduct    `json:"products,omitempty"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+}
+
+type CreateElectronicCreditNoteRequest struct {
+	Prefix      string
+	Consecutive int
+	// Bill is the original invoice the credit note references through its CUFE
+	Bill       *Bill
+	CreditNote *CreditNote
+}
@@ -0,0 +1,60 @@
+package dto
+
+import "time"
+
+// DebitNoteReason is the DIAN correction concept of a debit note
+type DebitNoteReason string
+
+const (
+	DebitNoteReasonInterest    DebitNoteReason = "interest"
+	DebitNoteReasonExpenses    DebitNoteReason = "expenses"
+	DebitNoteReasonPriceChange DebitNoteReason = "price_change"
+	DebitNoteReasonOther       DebitNoteReason = "other"
+)
+
+// CreateDebitNoteRequest adds charges to an issued bill
+type CreateDebitNoteRequest struct {
+	Reason      DebitNoteReason `json:"reason"`
+	Description string          `json:"description"`
+	Items       []DebitNoteItem `json:"items"`
+}
+
+// DebitNoteItem is either a product of the catalog, charged at its current price, or a
+// charge outside the catalog such as a corkage fee, described by its own price and taxes
+type DebitNoteItem struct {
+	ProductID   string `json:"product_id,omitempty"`
+	Quantity    int    `json:"quantity"`
+	Description string `json:"description,omitempty"`
+	Code        string `json:"code,omitempty"`
+	UnitPrice   Money  `json:"unit_price,omitempty"`
+	// VAT and ICO are decimal rates (0.19 for 19%) of a charge outside the catalog
+	VAT float64 `json:"vat,omitempty"`
+	ICO float64 `json:"ico,omitempty"`
+}
+
+type DebitNote struct {
+	ID             string          `json:"id"`
+	BillID         string          `json:"bill_id"`
+	Prefix         string          `json:"prefix"`
+	Consecutive    int             `json:"consecutive"`
+	Reason         DebitNoteReason `json:"reason"`
+	Description    string          `json:"description"`
+	TotalAmount    Money           `json:"total_amount"`
+	DiscountAmount Money           `json:"discount_amount"`
+	TaxAmount      Money           `json:"tax_amount"`
+	PayAmount      Money           `json:"pay_amount"`
+	VAT            Money           `json:"vat"`
+	ICO            Money           `json:"ico"`
+	CUFE           *string         `json:"cufe,omitempty"`
+	Products       []BillProduct   `json:"products,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+type CreateElectronicDebitNoteRequest struct {
+	Prefix      string
+	Consecutive int
+	// Bill is the original invoice the debit note references through its CUFE
+	Bill      *Bill
+	DebitNote *DebitNote
+}
@@ -0,0 +1,75 @@
+package dto
+
+import "time"
+
+// ElectronicDocumentType is the kind of document sent to the DIAN
+type ElectronicDocumentType string
+
+const (
+	ElectronicDocumentTypeInvoice ElectronicDocumentType = "invoice"
+	// ElectronicDocumentTypeContingencyInvoice is an invoice issued while the provider was down
+	ElectronicDocumentTypeContingencyInvoice ElectronicDocumentType = "contingency_invoice"
+	ElectronicDocumentTypeCreditNote         ElectronicDocumentType = "credit_note"
+	ElectronicDocumentTypeDebitNote          ElectronicDocumentType = "debit_note"
+)
+
+// ElectronicDocument is an invoice or a note as the DIAN sees it, each provider adapter
+// translates it to its own format
+type ElectronicDocument struct {
+	Type        ElectronicDocumentType
+	Prefix      string
+	Consecutive int
+	IssuedAt    time.Time
+	// Customer is the final consumer when the bill was not issued to anyone
+	Customer    Customer
+	PaymentCode ElectronicInvoicePaymentCode
+	// PaymentMeans lists every method used when the bill is paid with more than one
+	PaymentMeans []ElectronicDocumentPayment
+	Lines        []BillProduct
+	Totals       ElectronicDocumentTotals
+	// Reference and Correction are only set on notes
+	Reference  *ElectronicDocumentReference
+	Correction *ElectronicDocumentCorrection
+}
+
+// ElectronicDocumentPayment is the amount applied by one payment, without the change
+type ElectronicDocumentPayment struct {
+	Method    ElectronicInvoicePaymentCode
+	Amount    Money
+	Reference string
+}
+
+// ElectronicDocumentTotals are the document amounts, LineAmount is the sum of the lines
+// before allowances and taxes and Tip is a charge outside the taxable base
+type ElectronicDocumentTotals struct {
+	LineAmount     Money
+	DiscountAmount Money
+	TaxAmount      Money
+	Tip            Money
+	PayAmount      Money
+}
+
+// ElectronicDocumentReference is the invoice a note corrects
+type ElectronicDocumentReference struct {
+	Prefix      string
+	Consecutive int
+	CUFE        string
+	IssuedAt    time.Time
+}
+
+// ElectronicDocumentCorrection is why a note was issued, Reason holds the CreditNoteReason
+// or DebitNoteReason depending on the document type
+type ElectronicDocumentCorrection struct {
+	Reason      string
+	Description string
+}
+
+// DIANEnvironment is where the documents are validated, the CUFE of the same invoice is not
+// the same in both
+type DIANEnvironment string
+
+const (
+	DIANEnvironmentProduction DIANEnvironment = "production"
+	// DIANEnvironmentTest is the habilitación environment used while certifying the software
+	DIANEnvironmentTest DIANEnvironment = "test"
+)
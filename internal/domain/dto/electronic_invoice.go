@@ -16,6 +16,31 @@ type DocumentType string
 const (
 	DocumentTypeNationalIdentificationNumber DocumentType = "CC"
 	DocumentTypeNIT                          DocumentType = "NIT"
+	// Documents of foreigners: cédula de extranjería, passport, foreign identification
+	// document and the special permanence permit
+	DocumentTypeForeignerID     DocumentType = "CE"
+	DocumentTypePassport        DocumentType = "PA"
+	DocumentTypeForeignID       DocumentType = "DIE"
+	DocumentTypeSpecialPermitID DocumentType = "PEP"
+)
+
+// TaxRegime tells whether the customer is responsible for collecting VAT
+type TaxRegime string
+
+const (
+	TaxRegimeVATResponsible    TaxRegime = "vat_responsible"
+	TaxRegimeNotVATResponsible TaxRegime = "not_vat_responsible"
+)
+
+// FiscalResponsibility is one of the RUT responsibilities the DIAN expects on the invoice
+type FiscalResponsibility string
+
+const (
+	FiscalResponsibilityLargeTaxpayer   FiscalResponsibility = "O-13"
+	FiscalResponsibilitySelfWithholder  FiscalResponsibility = "O-15"
+	FiscalResponsibilityVATWithholder   FiscalResponsibility = "O-23"
+	FiscalResponsibilitySimpleTaxRegime FiscalResponsibility = "O-47"
+	FiscalResponsibilityNotApplicable   FiscalResponsibility = "R-99-PN"
 )
 
 type TaxCode string
@@ -30,6 +55,16 @@ type Customer struct {
 	DocumentType   DocumentType `json:"document_type"`
 	Name           string       `json:"name"`
 	Email          string       `json:"email"`
+	// Fiscal data corporate customers need on the invoice, anything left empty is
+	// reported to the DIAN as not given
+	Address string `json:"address,omitempty"`
+	City    string `json:"city,omitempty"`
+	// MunicipalityCode is the DANE code of the municipality, 11001 for Bogotá
+	MunicipalityCode       string                 `json:"municipality_code,omitempty"`
+	Department             string                 `json:"department,omitempty"`
+	Telephone              string                 `json:"telephone,omitempty"`
+	TaxRegime              TaxRegime              `json:"tax_regime,omitempty"`
+	FiscalResponsibilities []FiscalResponsibility `json:"fiscal_responsibilities,omitempty"`
 }
 
 type InvoiceAmounts struct {
@@ -43,13 +78,13 @@ type InvoiceAllowance struct {
 	Charge      string `json:"charge"`
 	ReasonCode  string `json:"reasonCode"`
 	Description string `json:"description"`
-	BaseAmount  string `json:"baseAmount"`
-	Amount      string `json:"amount"`
+	BaseAmount  Money  `json:"baseAmount"`
+	Amount      Money  `json:"amount"`
 }
 
 type InvoiceTax struct {
 	TaxCode   TaxCode `json:"taxCode"`
-	TaxAmount string  `json:"taxAmount"`
+	TaxAmount Money   `json:"taxAmount"`
 	Percent   string  `json:"percent"`
 }
 
@@ -59,10 +94,26 @@ type InvoiceItem struct {
 	Allowance []InvoiceAllowance `json:"allowance,omitempty"`
 }
 
+// Payment is one of the means used to pay a bill, Amount is what was received and
+// Change what was given back, only cash payments can have change
+type Payment struct {
+	Method    ElectronicInvoicePaymentCode `json:"method"`
+	Amount    Money                        `json:"amount"`
+	Reference string                       `json:"reference,omitempty"`
+	Change    Money                        `json:"change"`
+}
+
 type ElectronicInvoice struct {
 	PaymentCode ElectronicInvoicePaymentCode `json:"payment_code"`
-	Customer    *Customer                    `json:"customer"`
-	Items       []InvoiceItem                `json:"items"`
+	// Payments is optional, without it the bill is paid in full with PaymentCode
+	Payments []Payment `json:"payments,omitempty"`
+	// Tip is the voluntary tip the customer agreed to pay, it is not part of the taxable base
+	Tip      Money         `json:"tip"`
+	Customer *Customer     `json:"customer"`
+	Items    []InvoiceItem `json:"items"`
+	// OpenBillID is the order paid with the bill, it is marked paid in the same transaction
+	// the bill is saved in
+	OpenBillID string `json:"-"`
 }
 
 type CreateElectronicInvoiceRequest struct {
@@ -71,9 +122,36 @@ type CreateElectronicInvoiceRequest struct {
 	PaymentCode ElectronicInvoicePaymentCode
 	Bill        *Bill
 	Products    []*Product
+	// Contingency sends the invoice as a contingency invoice issued while the provider was down
+	Contingency bool
 }
 
 type CreateElectronicInvoiceResponse struct {
 	Tascode string
 	CUFE    string
+	// PrefixRemaining is how many numbers the provider says are left in the resolution, when it says so
+	PrefixRemaining *int
+}
+
+// ElectronicInvoiceDocumentStatus is where a document stands at the DIAN
+type ElectronicInvoiceDocumentStatus string
+
+const (
+	ElectronicInvoiceDocumentStatusPending  ElectronicInvoiceDocumentStatus = "pending"
+	ElectronicInvoiceDocumentStatusAccepted ElectronicInvoiceDocumentStatus = "accepted"
+	ElectronicInvoiceDocumentStatusRejected ElectronicInvoiceDocumentStatus = "rejected"
+)
+
+// ElectronicInvoiceStatus is the state of a document at the provider, PDF and XML are
+// the official documents encoded in base64 as the provider returns them
+type ElectronicInvoiceStatus struct {
+	Tascode     string
+	CUFE        string
+	Status      ElectronicInvoiceDocumentStatus
+	Process     int
+	Retries     int
+	Messages    []string
+	DocumentURL string
+	PDF         string
+	XML         string
 }
@@ -0,0 +1,25 @@
+package dto
+
+import "time"
+
+// IdempotencyStatus tells whether the first request sent with a key already finished
+type IdempotencyStatus string
+
+const (
+	IdempotencyStatusProcessing IdempotencyStatus = "processing"
+	IdempotencyStatusCompleted  IdempotencyStatus = "completed"
+)
+
+// IdempotencyRecord is the response stored for a key, replayed to every retry of the same
+// request until it expires
+type IdempotencyRecord struct {
+	Scope          string
+	Key            string
+	RequestHash    string
+	Status         IdempotencyStatus
+	ResponseStatus int
+	ContentType    string
+	ResponseBody   []byte
+	CreatedAt      time.Time
+	ExpiresAt      time.Time
+}
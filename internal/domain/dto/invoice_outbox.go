@@ -0,0 +1,43 @@
+package dto
+
+import "time"
+
+// InvoiceOutboxStatus is the delivery state of an invoice waiting to be sent to the provider
+type InvoiceOutboxStatus string
+
+const (
+	InvoiceOutboxStatusPending InvoiceOutboxStatus = "pending"
+	InvoiceOutboxStatusSent    InvoiceOutboxStatus = "sent"
+	// InvoiceOutboxStatusDead marks invoices that ran out of attempts and need someone to look at them
+	InvoiceOutboxStatusDead InvoiceOutboxStatus = "dead"
+)
+
+// InvoiceOutboxEntry is an invoice or a note saved with its bill and pending delivery. The
+// request is frozen when the document is created so every attempt sends the same prefix and
+// consecutive
+type InvoiceOutboxEntry struct {
+	ID string `json:"id"`
+	// DocumentType is invoice, credit_note or debit_note, contingency invoices are invoices
+	// whose request is marked as contingency
+	DocumentType ElectronicDocumentType `json:"document_type"`
+	// BillID is the invoice, or the invoice the note corrects, NoteID is only set on notes
+	BillID            string                             `json:"bill_id"`
+	NoteID            *string                            `json:"note_id,omitempty"`
+	Prefix            string                             `json:"prefix"`
+	Consecutive       int                                `json:"consecutive"`
+	Request           *CreateElectronicInvoiceRequest    `json:"-"`
+	CreditNoteRequest *CreateElectronicCreditNoteRequest `json:"-"`
+	DebitNoteRequest  *CreateElectronicDebitNoteRequest  `json:"-"`
+	Status            InvoiceOutboxStatus                `json:"status"`
+	Attempts          int                                `json:"attempts"`
+	LastError         *string                            `json:"last_error,omitempty"`
+	NextAttemptAt     time.Time                          `json:"next_attempt_at"`
+	SentAt            *time.Time                         `json:"sent_at,omitempty"`
+	CreatedAt         time.Time                          `json:"created_at"`
+	UpdatedAt         time.Time                          `json:"updated_at"`
+}
+
+type InvoiceOutboxListResponse struct {
+	Entries []*InvoiceOutboxEntry `json:"entries"`
+	Total   int                   `json:"total"`
+}
@@ -0,0 +1,225 @@
+package dto
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// Money is an exact amount of Colombian pesos stored as an integer number of centavos.
+// It lives in dto so every DTO can carry amounts without depending on other packages.
+//
+// COP rounding rules:
+//   - Amounts are kept with two decimals, the precision the DIAN expects on invoices
+//   - Any operation that produces fractions of a centavo rounds half away from zero
+//   - Rates are applied with basis point precision (0.19 -> 1900 bp)
+//   - Taxes are rounded per line and then added up, the same way the provider computes them
+type Money int64
+
+const (
+	centsPerPeso      = 100
+	basisPointsPerOne = 10000
+)
+
+// NewMoneyFromCents builds an amount from an integer number of centavos
+func NewMoneyFromCents(cents int64) Money {
+	return Money(cents)
+}
+
+// NewMoneyFromFloat converts a float amount rounding it to the closest centavo
+func NewMoneyFromFloat(amount float64) Money {
+	return Money(math.Round(amount * centsPerPeso))
+}
+
+// ParseMoney parses a decimal amount such as "1234.5" without going through float64
+func ParseMoney(value string) (Money, error) {
+	rat, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return 0, fmt.Errorf("invalid money amount: %q", value)
+	}
+
+	rat.Mul(rat, big.NewRat(centsPerPeso, 1))
+	cents := roundRat(rat)
+	if !cents.IsInt64() {
+		return 0, fmt.Errorf("money amount out of range: %q", value)
+	}
+
+	return Money(cents.Int64()), nil
+}
+
+func (m Money) Cents() int64 {
+	return int64(m)
+}
+
+func (m Money) Float64() float64 {
+	return float64(m) / centsPerPeso
+}
+
+// String formats the amount with exactly two decimals, as the invoice payloads expect
+func (m Money) String() string {
+	cents := int64(m)
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, cents/centsPerPeso, cents%centsPerPeso)
+}
+
+func (m Money) Add(other Money) Money {
+	return m + other
+}
+
+func (m Money) Sub(other Money) Money {
+	return m - other
+}
+
+func (m Money) Mul(quantity int) Money {
+	return m * Money(quantity)
+}
+
+// MulRate applies a decimal rate (0.19 for 19%) to the amount
+func (m Money) MulRate(rate float64) Money {
+	return Money(mulDivRound(int64(m), rateToBasisPoints(rate), basisPointsPerOne))
+}
+
+// DivRate removes a decimal rate already included in the amount, that is m / (1 + rate)
+func (m Money) DivRate(rate float64) Money {
+	return Money(mulDivRound(int64(m), basisPointsPerOne, basisPointsPerOne+rateToBasisPoints(rate)))
+}
+
+// Share returns the portion of the amount that part represents of whole
+func (m Money) Share(part, whole Money) Money {
+	return Money(mulDivRound(int64(m), int64(part), int64(whole)))
+}
+
+// SplitEvenly divides the amount in n parts that add up exactly to the amount,
+// the remaining centavos go to the first parts
+func (m Money) SplitEvenly(n int) []Money {
+	parts := make([]Money, n)
+	quotient := int64(m) / int64(n)
+	remainder := int64(m) % int64(n)
+	for i := range parts {
+		parts[i] = Money(quotient)
+		if int64(i) < remainder {
+			parts[i]++
+		}
+	}
+
+	return parts
+}
+
+// RoundToPeso rounds the amount to whole pesos, used for amounts handled in cash
+func (m Money) RoundToPeso() Money {
+	return Money(mulDivRound(int64(m), 1, centsPerPeso) * centsPerPeso)
+}
+
+func (m Money) IsZero() bool {
+	return m == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m < 0
+}
+
+func (m Money) IsPositive() bool {
+	return m > 0
+}
+
+// MarshalJSON encodes the amount as a JSON number with two decimals
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts both JSON numbers and decimal strings
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	value := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return fmt.Errorf("invalid money amount: %s", value)
+		}
+		value = unquoted
+	}
+
+	parsed, err := ParseMoney(value)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+	return nil
+}
+
+// Value stores the amount as a decimal string so NUMERIC columns keep it exact
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Scan reads the amount from NUMERIC columns
+func (m *Money) Scan(src interface{}) error {
+	switch value := src.(type) {
+	case nil:
+		*m = 0
+		return nil
+	case string:
+		parsed, err := ParseMoney(value)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	case []byte:
+		parsed, err := ParseMoney(string(value))
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	case int64:
+		*m = Money(value * centsPerPeso)
+		return nil
+	case float64:
+		*m = NewMoneyFromFloat(value)
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into Money", src)
+	}
+}
+
+func rateToBasisPoints(rate float64) int64 {
+	return int64(math.Round(rate * basisPointsPerOne))
+}
+
+// mulDivRound computes value * numerator / denominator rounding half away from zero
+func mulDivRound(value, numerator, denominator int64) int64 {
+	rat := new(big.Rat).SetFrac(
+		new(big.Int).Mul(big.NewInt(value), big.NewInt(numerator)),
+		big.NewInt(denominator),
+	)
+
+	return roundRat(rat).Int64()
+}
+
+func roundRat(rat *big.Rat) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(rat.Num(), rat.Denom(), new(big.Int))
+	doubled := new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2))
+	if doubled.Cmp(rat.Denom()) >= 0 {
+		if rat.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+
+	return quotient
+}
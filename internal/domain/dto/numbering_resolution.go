@@ -0,0 +1,66 @@
+package dto
+
+import "time"
+
+// NumberingResolutionKind tells which invoices a resolution numbers, contingency invoices
+// issued while the provider is unreachable have their own authorized range
+type NumberingResolutionKind string
+
+const (
+	NumberingResolutionKindElectronic  NumberingResolutionKind = "electronic"
+	NumberingResolutionKindContingency NumberingResolutionKind = "contingency"
+)
+
+// NumberingResolution is a DIAN authorization to number invoices with a prefix, within a
+// range of consecutives and between two dates
+type NumberingResolution struct {
+	ID               string                  `json:"id"`
+	ResolutionNumber string                  `json:"resolution_number"`
+	Kind             NumberingResolutionKind `json:"kind"`
+	Prefix           string                  `json:"prefix"`
+	From             int                     `json:"from"`
+	To               int                     `json:"to"`
+	LastConsecutive  int                     `json:"last_consecutive"`
+	ValidFrom        time.Time               `json:"valid_from"`
+	ValidTo          time.Time               `json:"valid_to"`
+	TechnicalKey     string                  `json:"technical_key"`
+	// ProviderRemaining is how many numbers the provider reported left on its last invoice
+	ProviderRemaining *int      `json:"provider_remaining,omitempty"`
+	Remaining         int       `json:"remaining"`
+	Active            bool      `json:"active"`
+	Warnings          []string  `json:"warnings,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// CreateNumberingResolutionRequest registers a resolution, dates are formatted as 2006-01-02
+type CreateNumberingResolutionRequest struct {
+	ResolutionNumber string `json:"resolution_number" validate:"required,min=1,max=50"`
+	// Kind defaults to electronic
+	Kind         NumberingResolutionKind `json:"kind" validate:"omitempty,oneof=electronic contingency"`
+	Prefix       string                  `json:"prefix" validate:"required,min=1,max=4"`
+	From         int                     `json:"from" validate:"required,gt=0"`
+	To           int                     `json:"to" validate:"required,gtefield=From"`
+	ValidFrom    string                  `json:"valid_from" validate:"required"`
+	ValidTo      string                  `json:"valid_to" validate:"required"`
+	TechnicalKey string                  `json:"technical_key" validate:"required"`
+}
+
+// UpdateNumberingResolutionRequest replaces the resolution data, the prefix and the start of
+// the range cannot change once the resolution has numbered an invoice
+type UpdateNumberingResolutionRequest struct {
+	ResolutionNumber string `json:"resolution_number" validate:"required,min=1,max=50"`
+	// Kind keeps the current one when empty
+	Kind         NumberingResolutionKind `json:"kind" validate:"omitempty,oneof=electronic contingency"`
+	Prefix       string                  `json:"prefix" validate:"required,min=1,max=4"`
+	From         int                     `json:"from" validate:"required,gt=0"`
+	To           int                     `json:"to" validate:"required,gtefield=From"`
+	ValidFrom    string                  `json:"valid_from" validate:"required"`
+	ValidTo      string                  `json:"valid_to" validate:"required"`
+	TechnicalKey string                  `json:"technical_key" validate:"required"`
+}
+
+type NumberingResolutionListResponse struct {
+	Resolutions []*NumberingResolution `json:"resolutions"`
+	Total       int                    `json:"total"`
+}
@@ -2,17 +2,48 @@ package dto
 
 import "time"
 
+type OpenBillStatus string
+
+const (
+	OpenBillStatusOpen      OpenBillStatus = "open"
+	OpenBillStatusPaying    OpenBillStatus = "paying"
+	OpenBillStatusPaid      OpenBillStatus = "paid"
+	OpenBillStatusCancelled OpenBillStatus = "cancelled"
+	// OpenBillStatusSplit is set on an order once it has been split into parts,
+	// each part is an order of its own that is paid separately
+	OpenBillStatusSplit OpenBillStatus = "split"
+	// OpenBillStatusMerged is set on an order whose products were all moved into another order
+	OpenBillStatusMerged OpenBillStatus = "merged"
+)
+
 type OpenBill struct {
-	ID                 string    `json:"id"`
-	TemporalIdentifier string    `json:"temporal_identifier"`
-	TotalPrice         float64   `json:"total_price"`
-	VAT                float64   `json:"vat"`
-	ICO                float64   `json:"ico"`
-	Tip                float64   `json:"tip"`
-	DocumentURL        *string   `json:"document_url,omitempty"`
-	Products           []Product `json:"products,omitempty"`
-	CreatedAt          time.Time `json:"created_at"`
-	UpdatedAt          time.Time `json:"updated_at"`
+	ID                 string         `json:"id"`
+	TemporalIdentifier string         `json:"temporal_identifier"`
+	Status             OpenBillStatus `json:"status"`
+	BillID             *string        `json:"bill_id,omitempty"`
+	ParentID           *string        `json:"parent_id,omitempty"`
+	// SplitAmount is set on parts created by an even or amount split, the part
+	// pays this share of its lines instead of the lines in full
+	SplitAmount *Money      `json:"split_amount,omitempty"`
+	TotalPrice  Money       `json:"total_price"`
+	VAT         Money       `json:"vat"`
+	ICO         Money       `json:"ico"`
+	Tip         Money       `json:"tip"`
+	DocumentURL *string     `json:"document_url,omitempty"`
+	Products    []OrderLine `json:"products,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// OrderLine is a product of an open bill with its quantity and line amounts
+// Subtotal is the amount before taxes and Total the amount with taxes included
+type OrderLine struct {
+	Product  Product `json:"product"`
+	Quantity int     `json:"quantity"`
+	Subtotal Money   `json:"subtotal"`
+	VAT      Money   `json:"vat"`
+	ICO      Money   `json:"ico"`
+	Total    Money   `json:"total"`
 }
 
 type CreateOrderRequest struct {
@@ -22,16 +53,131 @@ type CreateOrderRequest struct {
 type OrderProductItem struct {
 	ProductID string `json:"product_id" validate:"required,uuid"`
 	Quantity  int    `json:"quantity" validate:"required,min=1"`
+	// Price is what the line was charged at when it was saved, items read back from an order
+	// keep it so a later catalog change does not reprice the order. Requests never carry it
+	Price *OrderItemPrice `json:"-"`
+}
+
+// OrderItemPrice is the product price with taxes and the tax rates of an order line
+type OrderItemPrice struct {
+	TotalPriceWithTaxes Money
+	VAT                 float64
+	ICO                 float64
 }
 
 type UpdateOrderRequest struct {
 	Products []OrderProductItem `json:"products" validate:"dive"`
 }
 
+type PayOrderRequest struct {
+	PaymentCode ElectronicInvoicePaymentCode `json:"payment_code"`
+	// Payments splits the payment between several methods, required when there is no payment_code
+	Payments []Payment `json:"payments,omitempty"`
+	// Tip replaces the suggested tip of the order, zero when the customer declines it
+	Tip      *Money    `json:"tip,omitempty"`
+	Customer *Customer `json:"customer,omitempty"`
+}
+
+type SplitMode string
+
+const (
+	SplitModeItems   SplitMode = "items"
+	SplitModeEven    SplitMode = "even"
+	SplitModeAmounts SplitMode = "amounts"
+)
+
+// SplitOrderRequest describes how to split an order depending on the mode:
+// items uses Parts, even uses People and amounts uses Amounts
+type SplitOrderRequest struct {
+	Mode    SplitMode        `json:"mode" validate:"required,oneof=items even amounts"`
+	Parts   []SplitOrderPart `json:"parts,omitempty"`
+	People  int              `json:"people,omitempty"`
+	Amounts []Money          `json:"amounts,omitempty"`
+}
+
+type SplitOrderPart struct {
+	Products []OrderProductItem `json:"products" validate:"dive"`
+}
+
+type SplitOrderResponse struct {
+	Order *OpenBill   `json:"order"`
+	Parts []*OpenBill `json:"parts"`
+}
+
+type TransferOrderItemsRequest struct {
+	TargetOrderID string             `json:"target_order_id" validate:"required,uuid"`
+	Products      []OrderProductItem `json:"products" validate:"required,dive"`
+	MovedBy       string             `json:"moved_by" validate:"required"`
+}
+
+type MergeOrdersRequest struct {
+	SourceOrderID string `json:"source_order_id" validate:"required,uuid"`
+	MovedBy       string `json:"moved_by" validate:"required"`
+}
+
+// OrderTransfer is the audit entry of a product quantity moved between orders
+type OrderTransfer struct {
+	ID            string    `json:"id"`
+	SourceOrderID string    `json:"source_order_id"`
+	TargetOrderID string    `json:"target_order_id"`
+	ProductID     string    `json:"product_id"`
+	Quantity      int       `json:"quantity"`
+	MovedBy       string    `json:"moved_by"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// OrderItemsTransfer holds both orders of a transfer with the products each one is left with
+// and the transfers to record
+type OrderItemsTransfer struct {
+	Source      *OpenBill
+	SourceItems []OrderProductItem
+	Target      *OpenBill
+	TargetItems []OrderProductItem
+	Transfers   []OrderTransfer
+}
+
+type OrderTransferResponse struct {
+	Source    *OpenBill       `json:"source"`
+	Target    *OpenBill       `json:"target"`
+	Transfers []OrderTransfer `json:"transfers"`
+}
+
+type OpenBillSortField string
+
+const (
+	OpenBillSortFieldCreatedAt  OpenBillSortField = "created_at"
+	OpenBillSortFieldUpdatedAt  OpenBillSortField = "updated_at"
+	OpenBillSortFieldTotalPrice OpenBillSortField = "total_price"
+)
+
+type SortOrder string
+
+const (
+	SortOrderAsc  SortOrder = "asc"
+	SortOrderDesc SortOrder = "desc"
+)
+
+type ListOrdersRequest struct {
+	Statuses    []OpenBillStatus  `json:"statuses"`
+	CreatedFrom *time.Time        `json:"created_from"`
+	CreatedTo   *time.Time        `json:"created_to"`
+	SortBy      OpenBillSortField `json:"sort_by"`
+	SortOrder   SortOrder         `json:"sort_order"`
+	Page        int               `json:"page"`
+	PageSize    int               `json:"page_size"`
+}
+
+type OpenBillListResponse struct {
+	Orders   []*OpenBill `json:"orders"`
+	Total    int         `json:"total"`
+	Page     int         `json:"page"`
+	PageSize int         `json:"page_size"`
+}
+
 type BillProduct struct {
 	ProductID   string
 	Quantity    int
-	UnitPrice   float64
+	UnitPrice   Money
 	Description *string
 	Brand       *string
 	Model       *string
@@ -41,17 +187,32 @@ type BillProduct struct {
 }
 
 type Bill struct {
-	ID             string        `json:"id"`
-	TotalAmount    float64       `json:"total_amount"`
-	DiscountAmount float64       `json:"discount_amount"`
-	TaxAmount      float64       `json:"tax_amount"`
-	PayAmount      float64       `json:"pay_amount"`
-	VAT            float64       `json:"vat"`
-	ICO            float64       `json:"ico"`
-	Tip            float64       `json:"tip"`
-	DocumentURL    *string       `json:"document_url,omitempty"`
-	Customer       *Customer     `json:"customer,omitempty"`
-	Products       []BillProduct `json:"products,omitempty"`
-	CreatedAt      time.Time     `json:"created_at"`
-	UpdatedAt      time.Time     `json:"updated_at"`
+	ID          string  `json:"id"`
+	Prefix      string  `json:"prefix,omitempty"`
+	Consecutive int     `json:"consecutive,omitempty"`
+	CUFE        *string `json:"cufe,omitempty"`
+	// ExpectedCUFE is the CUFE computed by the backend when the invoice was sent, CUFEMismatch
+	// flags a provider that answered a different one
+	ExpectedCUFE        *string                         `json:"expected_cufe,omitempty"`
+	CUFEMismatch        bool                            `json:"cufe_mismatch"`
+	Tascode             *string                         `json:"tascode,omitempty"`
+	TotalAmount         Money                           `json:"total_amount"`
+	DiscountAmount      Money                           `json:"discount_amount"`
+	TaxAmount           Money                           `json:"tax_amount"`
+	PayAmount           Money                           `json:"pay_amount"`
+	VAT                 Money                           `json:"vat"`
+	ICO                 Money                           `json:"ico"`
+	Tip                 Money                           `json:"tip"`
+	Payments            []Payment                       `json:"payments,omitempty"`
+	Change              Money                           `json:"change"`
+	DIANStatus          ElectronicInvoiceDocumentStatus `json:"dian_status,omitempty"`
+	DocumentURL         *string                         `json:"document_url,omitempty"`
+	ContingencyPeriodID *string                         `json:"contingency_period_id,omitempty"`
+	ProvisionalDocument *string                         `json:"provisional_document,omitempty"`
+	// QRPayload is the text of the QR code to print, only returned when the bill is issued
+	QRPayload *string       `json:"qr_payload,omitempty"`
+	Customer  *Customer     `json:"customer,omitempty"`
+	Products  []BillProduct `json:"products,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
 }
@@ -7,14 +7,14 @@ type Product struct {
 	Name                string    `json:"name"`
 	Category            string    `json:"category"`
 	Version             int       `json:"version"`
-	UnitPrice           float64   `json:"unit_price"`
+	UnitPrice           Money     `json:"unit_price"`
 	VAT                 float64   `json:"vat"`
 	ICO                 float64   `json:"ico"`
 	Description         *string   `json:"description"`
 	Brand               *string   `json:"brand"`
 	Model               *string   `json:"model"`
 	SKU                 string    `json:"sku"`
-	TotalPriceWithTaxes float64   `json:"total_price_with_taxes"`
+	TotalPriceWithTaxes Money     `json:"total_price_with_taxes"`
 	CreatedAt           time.Time `json:"created_at"`
 	UpdatedAt           time.Time `json:"updated_at"`
 }
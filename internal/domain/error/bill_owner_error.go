@@ -0,0 +1,11 @@
+package error
+
+import "errors"
+
+var (
+	ErrBillOwnerNotFound      = errors.New("bill owner not found")
+	ErrInvalidBillOwner       = errors.New("invalid bill owner")
+	ErrBillOwnerAlreadyExists = errors.New("a bill owner with this document number already exists")
+	ErrInvalidBillOwnerFilter = errors.New("invalid bill owner filter")
+	ErrBillOwnerListFailed    = errors.New("failed to list bill owners")
+)
@@ -0,0 +1,11 @@
+package error
+
+import "errors"
+
+var (
+	// ErrProviderUnavailable marks failures reaching the provider, as opposed to documents it rejected
+	ErrProviderUnavailable      = errors.New("electronic invoice provider unavailable")
+	ErrContingencyAlreadyActive = errors.New("contingency mode is already active")
+	ErrContingencyNotActive     = errors.New("contingency mode is not active")
+	ErrInvalidContingency       = errors.New("invalid contingency request")
+)
@@ -0,0 +1,11 @@
+package error
+
+import "errors"
+
+var (
+	ErrInvalidIdempotencyKey = errors.New("invalid idempotency key")
+	// ErrIdempotencyKeyInProgress is returned while the first request with the key is still running
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is in progress")
+	// ErrIdempotencyKeyReused is returned when the key was already used for a different request
+	ErrIdempotencyKeyReused = errors.New("idempotency key was used for a different request")
+)
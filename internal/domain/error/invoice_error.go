@@ -0,0 +1,27 @@
+package error
+
+import "errors"
+
+var (
+	ErrInvalidInvoice        = errors.New("invalid invoice")
+	ErrBillNotFound          = errors.New("bill not found")
+	ErrInvalidBillFilter     = errors.New("invalid bill filter")
+	ErrBillListFailed        = errors.New("failed to list bills")
+	ErrBillNotIssued         = errors.New("bill has not been issued to the DIAN")
+	ErrInvalidCreditNote     = errors.New("invalid credit note")
+	ErrCreditNoteFailed      = errors.New("failed to create credit note")
+	ErrInvalidDebitNote      = errors.New("invalid debit note")
+	ErrDebitNoteFailed       = errors.New("failed to create debit note")
+	ErrBillStatusFailed      = errors.New("failed to refresh bill status")
+	ErrOutboxEntryNotFound   = errors.New("invoice outbox entry not found")
+	ErrInvoiceDispatchFailed = errors.New("failed to dispatch invoices")
+	// ErrDocumentAlreadyReceived is the answer of the provider to a number it already has, as
+	// when a document is sent again after a crash that kept it from being marked sent
+	ErrDocumentAlreadyReceived = errors.New("the provider already received a document with this number")
+	// ErrStatusUnsupported is returned by the adapters of providers that cannot be asked for
+	// the DIAN status of a document, the reconciler leaves their bills alone
+	ErrStatusUnsupported = errors.New("the provider does not report the DIAN status of documents")
+	// ErrIssuerNotConfigured is returned when issuing in contingency without the NIT of the
+	// restaurant, the provisional document cannot be printed without its CUFE
+	ErrIssuerNotConfigured = errors.New("the NIT of the issuer is not configured")
+)
@@ -3,10 +3,21 @@ package error
 import "errors"
 
 var (
-	ErrProductNotFound     = errors.New("product not found")
-	ErrInvalidProductIDs   = errors.New("invalid product ids")
-	ErrOrderCreationFailed = errors.New("failed to create order")
-	ErrOrderNotFound       = errors.New("order not found")
-	ErrOrderUpdateFailed   = errors.New("failed to update order")
-	ErrOrderPaymentFailed  = errors.New("failed to pay order")
+	ErrProductNotFound      = errors.New("product not found")
+	ErrInvalidProductIDs    = errors.New("invalid product ids")
+	ErrOrderCreationFailed  = errors.New("failed to create order")
+	ErrOrderNotFound        = errors.New("order not found")
+	ErrOrderUpdateFailed    = errors.New("failed to update order")
+	ErrOrderPaymentFailed   = errors.New("failed to pay order")
+	ErrOrderEmpty           = errors.New("order has no products")
+	ErrInvalidOrderStatus   = errors.New("order status does not allow this operation")
+	ErrOrderCancelFailed    = errors.New("failed to cancel order")
+	ErrInvalidOrderFilter   = errors.New("invalid order filter")
+	ErrOrderListFailed      = errors.New("failed to list orders")
+	ErrInvalidOrderSplit    = errors.New("invalid order split")
+	ErrOrderSplitFailed     = errors.New("failed to split order")
+	ErrOrderIsSplitShare    = errors.New("order is a share of a split order and its products cannot change")
+	ErrInvalidOrderTransfer = errors.New("invalid order transfer")
+	ErrOrderTransferFailed  = errors.New("failed to transfer order products")
+	ErrOrderChanged         = errors.New("order changed while it was being processed")
 )
@@ -0,0 +1,15 @@
+package error
+
+import "errors"
+
+var (
+	ErrResolutionNotFound = errors.New("numbering resolution not found")
+	ErrInvalidResolution  = errors.New("invalid numbering resolution")
+	ErrNoActiveResolution = errors.New("no numbering resolution is valid today with numbers left")
+	// ErrResolutionInUse is returned when deleting a resolution that numbered invoices, their
+	// CUFE is computed with its technical key
+	ErrResolutionInUse = errors.New("numbering resolution already numbered invoices")
+	// ErrResolutionChanged is returned when the resolution numbered invoices while it was being
+	// updated, the update was validated against numbers that are no longer the last ones
+	ErrResolutionChanged = errors.New("numbering resolution changed while it was being updated")
+)
@@ -0,0 +1,20 @@
+package ports
+
+import (
+	"context"
+
+	"laguna-escondida/backend/internal/domain/aggregate/billowner"
+	"laguna-escondida/backend/internal/domain/dto"
+)
+
+type BillOwnerRepository interface {
+	// Create fails with ErrBillOwnerAlreadyExists when the document number is taken, a
+	// deleted bill owner is brought back with the new data instead
+	Create(ctx context.Context, owner *billowner.Aggregate) error
+	Update(ctx context.Context, owner *billowner.Aggregate) error
+	Delete(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (*dto.BillOwner, error)
+	// FindAll returns a page of bill owners ordered by document number
+	FindAll(ctx context.Context, filter *dto.ListBillOwnersRequest) ([]*dto.BillOwner, error)
+	Count(ctx context.Context, filter *dto.ListBillOwnersRequest) (int, error)
+}
@@ -8,5 +8,15 @@ import (
 
 type BillRepository interface {
 	Create(ctx context.Context, bill *bill.Aggregate, products []*dto.Product) error
+	// FindByID returns the bill with its products, payments and customer
 	FindByID(ctx context.Context, id string) (*dto.Bill, error)
+	// FindAll returns a page of bills, the most recent first, with their products, payments and customer
+	FindAll(ctx context.Context, filter *dto.ListBillsRequest) ([]*dto.Bill, error)
+	Count(ctx context.Context, filter *dto.ListBillsRequest) (int, error)
+	// FindProductsByID returns the lines of the bill as they were invoiced, in their original order
+	FindProductsByID(ctx context.Context, id string) ([]*bill.BillProduct, error)
+	// UpdateDocumentStatus stores the DIAN status of the bill along with its official documents
+	UpdateDocumentStatus(ctx context.Context, id string, status *dto.ElectronicInvoiceStatus) error
+	// FindPendingStatus returns up to limit bills sent to the provider whose DIAN status is not final yet
+	FindPendingStatus(ctx context.Context, limit int) ([]*dto.Bill, error)
 }
@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"laguna-escondida/backend/internal/domain/dto"
+)
+
+type ContingencyRepository interface {
+	// FindActive returns the open contingency period, or nil when the system is not in contingency
+	FindActive(ctx context.Context) (*dto.ContingencyPeriod, error)
+	// Start opens a contingency period, only one can be open at a time
+	Start(ctx context.Context, reason string, automatic bool, now time.Time) (*dto.ContingencyPeriod, error)
+	End(ctx context.Context, id string, now time.Time) error
+	// FindAll returns every period, the most recent first, with the count of bills issued in it
+	FindAll(ctx context.Context) ([]*dto.ContingencyPeriod, error)
+}
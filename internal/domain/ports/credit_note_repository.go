@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"context"
+
+	"laguna-escondida/backend/internal/domain/aggregate/bill"
+	"laguna-escondida/backend/internal/domain/dto"
+)
+
+type CreditNoteRepository interface {
+	// Create numbers the credit note with its own sequence, links it to the original bill
+	// and queues it in the outbox, which emits it referencing the bill CUFE. It locks the bill
+	// and fails with ErrInvalidCreditNote when the bill has less left to credit than the note
+	Create(ctx context.Context, creditNote *bill.CreditNote, original *dto.Bill) error
+	FindByID(ctx context.Context, id string) (*dto.CreditNote, error)
+	// FindByBillID returns the credit notes of a bill with their products
+	FindByBillID(ctx context.Context, billID string) ([]*dto.CreditNote, error)
+}
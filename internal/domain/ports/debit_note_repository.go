@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"context"
+
+	"laguna-escondida/backend/internal/domain/aggregate/bill"
+	"laguna-escondida/backend/internal/domain/dto"
+)
+
+type DebitNoteRepository interface {
+	// Create numbers the debit note with its own sequence, links it to the original bill
+	// and queues it in the outbox, which emits it referencing the bill CUFE
+	Create(ctx context.Context, debitNote *bill.DebitNote, original *dto.Bill) error
+	FindByID(ctx context.Context, id string) (*dto.DebitNote, error)
+}
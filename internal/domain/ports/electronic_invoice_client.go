@@ -5,7 +5,12 @@ import (
 	"laguna-escondida/backend/internal/domain/dto"
 )
 
+// ElectronicInvoiceClient is implemented by an adapter of each authorized provider
 type ElectronicInvoiceClient interface {
-	Create(ctx context.Context, req *dto.CreateElectronicInvoiceRequest) (*dto.CreateElectronicInvoiceResponse, error)
-	Get(ctx context.Context, billID string) (*dto.ElectronicInvoice, error)
+	// Send emits an invoice or a note and returns how the provider identifies it. A number the
+	// provider already received is answered with the document registered the first time
+	Send(ctx context.Context, document *dto.ElectronicDocument) (*dto.CreateElectronicInvoiceResponse, error)
+	// Get returns the status of a document at the DIAN by the tascode the provider gave it,
+	// or ErrStatusUnsupported when the provider cannot be asked for it
+	Get(ctx context.Context, tascode string) (*dto.ElectronicInvoiceStatus, error)
 }
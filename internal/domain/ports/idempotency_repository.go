@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"laguna-escondida/backend/internal/domain/dto"
+)
+
+type IdempotencyRepository interface {
+	// Reserve claims the key for a request until expiresAt. It returns nil when the key was
+	// free or expired, otherwise the record already stored for it. The claim relies on a
+	// unique constraint so concurrent duplicates reserve the key only once
+	Reserve(ctx context.Context, scope string, key string, requestHash string, now time.Time, expiresAt time.Time) (*dto.IdempotencyRecord, error)
+	// Complete stores the response of the request that reserved the key, replayed until expiresAt
+	Complete(ctx context.Context, scope string, key string, responseStatus int, contentType string, responseBody []byte, expiresAt time.Time) error
+	DeleteExpired(ctx context.Context, now time.Time) (int, error)
+}
@@ -0,0 +1,29 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"laguna-escondida/backend/internal/domain/dto"
+)
+
+// InvoiceOutboxRepository holds the invoices saved with their bills until the provider accepts them
+type InvoiceOutboxRepository interface {
+	// ClaimDue takes up to limit pending entries whose next attempt is due and hides them from
+	// other dispatchers for a while, an entry whose dispatcher dies is claimed again later.
+	// Entries whose payload does not decode are dead lettered instead of returned
+	ClaimDue(ctx context.Context, limit int) ([]*dto.InvoiceOutboxEntry, error)
+	// MarkSent stores the CUFE and tascode on the bill and closes the entry in one transaction,
+	// the bill is flagged when the provider answered a CUFE other than the expected one. Nothing
+	// is checked when the expected CUFE is empty
+	MarkSent(ctx context.Context, entry *dto.InvoiceOutboxEntry, response *dto.CreateElectronicInvoiceResponse, expectedCUFE string) error
+	// MarkFailed records a failed attempt and schedules the next one
+	MarkFailed(ctx context.Context, id string, attempts int, lastError string, nextAttemptAt time.Time) error
+	// MarkDead moves the entry to the dead letter after its last failed attempt
+	MarkDead(ctx context.Context, id string, attempts int, lastError string) error
+	FindDeadLetters(ctx context.Context) ([]*dto.InvoiceOutboxEntry, error)
+	// Requeue sends a dead letter back to the queue with its attempts reset
+	Requeue(ctx context.Context, id string) error
+	// ReleaseRetries makes every pending entry due now, used when the provider comes back
+	ReleaseRetries(ctx context.Context) error
+}
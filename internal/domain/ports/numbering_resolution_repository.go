@@ -0,0 +1,25 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"laguna-escondida/backend/internal/domain/aggregate/resolution"
+	"laguna-escondida/backend/internal/domain/dto"
+)
+
+type NumberingResolutionRepository interface {
+	Create(ctx context.Context, resolution *resolution.Aggregate) error
+	// Update saves the resolution as long as its last consecutive is still readLastConsecutive,
+	// the one the update was validated against, failing with ErrResolutionChanged otherwise
+	Update(ctx context.Context, resolution *resolution.Aggregate, readLastConsecutive int) error
+	Delete(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (*dto.NumberingResolution, error)
+	FindAll(ctx context.Context) ([]*dto.NumberingResolution, error)
+	// FindActive returns the resolution electronic invoices are numbered with on that date, the
+	// oldest valid one with numbers left, the same one BillRepository.Create takes numbers from
+	FindActive(ctx context.Context, now time.Time) (*dto.NumberingResolution, error)
+	// FindByNumber returns the resolution an invoice number was taken from, its technical key
+	// is part of the CUFE
+	FindByNumber(ctx context.Context, prefix string, consecutive int) (*dto.NumberingResolution, error)
+}
@@ -2,13 +2,33 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"laguna-escondida/backend/internal/domain/dto"
 )
 
+// OrderTransferFunc works out a transfer from the products both open bills hold once locked
+type OrderTransferFunc func(sourceItems, targetItems []dto.OrderProductItem) (*dto.OrderItemsTransfer, error)
+
 type OpenBillRepository interface {
 	Create(ctx context.Context, openBill *dto.OpenBill, products []dto.OrderProductItem) error
 	FindByID(ctx context.Context, id string) (*dto.OpenBill, error)
+	FindAll(ctx context.Context, filter *dto.ListOrdersRequest) ([]*dto.OpenBill, error)
+	Count(ctx context.Context, filter *dto.ListOrdersRequest) (int, error)
+	FindItemsByID(ctx context.Context, id string) ([]dto.OrderProductItem, error)
 	Update(ctx context.Context, openBillID string, openBill *dto.OpenBill, products []dto.OrderProductItem) error
-	PayOrder(ctx context.Context, openBillID string) (*dto.Bill, error)
+	// UpdateStatus moves the open bill from one status to another, failing when the
+	// current status is no longer `from` so concurrent transitions cannot both succeed
+	UpdateStatus(ctx context.Context, openBillID string, from dto.OpenBillStatus, to dto.OpenBillStatus, billID *string) error
+	// StartPayment moves an open bill to paying, or takes over a payment started before
+	// staleBefore that never finished, failing with ErrInvalidOrderStatus otherwise
+	StartPayment(ctx context.Context, openBillID string, staleBefore time.Time) error
+	// Split marks the open bill as split and creates its parts with their products atomically,
+	// items[i] holds the products of parts[i]. It fails with ErrOrderChanged when the open bill
+	// no longer holds splitItems, the products the parts were taken from
+	Split(ctx context.Context, openBillID string, splitItems []dto.OrderProductItem, parts []*dto.OpenBill, items [][]dto.OrderProductItem) error
+	// Transfer locks both open bills, failing when either is no longer open, works out the transfer
+	// with apply from the products they hold then and saves it atomically. An error from apply is
+	// returned as is and nothing is saved
+	Transfer(ctx context.Context, sourceID string, targetID string, apply OrderTransferFunc) (*dto.OrderItemsTransfer, error)
 }
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"laguna-escondida/backend/internal/domain/aggregate/billowner"
+	"laguna-escondida/backend/internal/domain/dto"
+	domainError "laguna-escondida/backend/internal/domain/error"
+	"laguna-escondida/backend/internal/domain/ports"
+)
+
+const (
+	defaultBillOwnersPageSize = 20
+	maxBillOwnersPageSize     = 100
+)
+
+type BillOwnerService struct {
+	billOwnerRepo ports.BillOwnerRepository
+}
+
+func NewBillOwnerService(billOwnerRepo ports.BillOwnerRepository) *BillOwnerService {
+	return &BillOwnerService{
+		billOwnerRepo: billOwnerRepo,
+	}
+}
+
+// CreateBillOwner registers a customer, a NIT given with its check digit must match it
+func (s *BillOwnerService) CreateBillOwner(ctx context.Context, req *dto.CreateBillOwnerRequest) (*dto.BillOwner, error) {
+	owner, err := billowner.NewAggregateFromCreateRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrInvalidBillOwner, err)
+	}
+
+	if err := s.billOwnerRepo.Create(ctx, owner); err != nil {
+		return nil, err
+	}
+
+	return owner.ToDTO(), nil
+}
+
+func (s *BillOwnerService) UpdateBillOwner(ctx context.Context, id string, req *dto.UpdateBillOwnerRequest) (*dto.BillOwner, error) {
+	existing, err := s.billOwnerRepo.FindByID(ctx, lookupDocumentNumber(id))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrBillOwnerNotFound, err)
+	}
+
+	updated, err := billowner.NewAggregateFromDTO(existing).Update(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrInvalidBillOwner, err)
+	}
+
+	if err := s.billOwnerRepo.Update(ctx, updated); err != nil {
+		return nil, err
+	}
+
+	return updated.ToDTO(), nil
+}
+
+// DeleteBillOwner soft deletes a customer, the bills issued to it keep referencing it
+func (s *BillOwnerService) DeleteBillOwner(ctx context.Context, id string) error {
+	documentNumber := lookupDocumentNumber(id)
+	if _, err := s.billOwnerRepo.FindByID(ctx, documentNumber); err != nil {
+		return fmt.Errorf("%w: %w", domainError.ErrBillOwnerNotFound, err)
+	}
+
+	return s.billOwnerRepo.Delete(ctx, documentNumber)
+}
+
+// GetBillOwner looks a customer up by document number, typed with or without separators
+func (s *BillOwnerService) GetBillOwner(ctx context.Context, id string) (*dto.BillOwner, error) {
+	existing, err := s.billOwnerRepo.FindByID(ctx, lookupDocumentNumber(id))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrBillOwnerNotFound, err)
+	}
+
+	return billowner.NewAggregateFromDTO(existing).ToDTO(), nil
+}
+
+// ListBillOwners returns a page of customers whose document number starts with the one given
+func (s *BillOwnerService) ListBillOwners(ctx context.Context, req *dto.ListBillOwnersRequest) (*dto.BillOwnerListResponse, error) {
+	filter, err := normalizeListBillOwnersRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	owners, err := s.billOwnerRepo.FindAll(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrBillOwnerListFailed, err)
+	}
+
+	total, err := s.billOwnerRepo.Count(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrBillOwnerListFailed, err)
+	}
+
+	result := make([]*dto.BillOwner, len(owners))
+	for i, owner := range owners {
+		result[i] = billowner.NewAggregateFromDTO(owner).ToDTO()
+	}
+
+	return &dto.BillOwnerListResponse{
+		BillOwners: result,
+		Total:      total,
+		Page:       filter.Page,
+		PageSize:   filter.PageSize,
+	}, nil
+}
+
+func normalizeListBillOwnersRequest(req *dto.ListBillOwnersRequest) (*dto.ListBillOwnersRequest, error) {
+	filter := dto.ListBillOwnersRequest{}
+	if req != nil {
+		filter = *req
+	}
+
+	filter.DocumentNumber = lookupDocumentNumber(filter.DocumentNumber)
+
+	if filter.Page < 0 || filter.PageSize < 0 {
+		return nil, fmt.Errorf("%w: page and page_size must be positive", domainError.ErrInvalidBillOwnerFilter)
+	}
+	if filter.Page == 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize == 0 {
+		filter.PageSize = defaultBillOwnersPageSize
+	}
+	if filter.PageSize > maxBillOwnersPageSize {
+		filter.PageSize = maxBillOwnersPageSize
+	}
+
+	return &filter, nil
+}
+
+// lookupDocumentNumber drops the separators and the check digit a NIT may be typed with,
+// bill owners are stored by the bare number
+func lookupDocumentNumber(number string) string {
+	documentNumber, _, _ := strings.Cut(billowner.NormalizeDocumentNumber(number), "-")
+	return documentNumber
+}
@@ -0,0 +1,374 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"laguna-escondida/backend/internal/domain/aggregate/billowner"
+	"laguna-escondida/backend/internal/domain/dto"
+	domainError "laguna-escondida/backend/internal/domain/error"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockBillOwnerRepository is a mock implementation of ports.BillOwnerRepository
+type MockBillOwnerRepository struct {
+	mock.Mock
+}
+
+func (m *MockBillOwnerRepository) Create(ctx context.Context, owner *billowner.Aggregate) error {
+	args := m.Called(ctx, owner)
+	return args.Error(0)
+}
+
+func (m *MockBillOwnerRepository) Update(ctx context.Context, owner *billowner.Aggregate) error {
+	args := m.Called(ctx, owner)
+	return args.Error(0)
+}
+
+func (m *MockBillOwnerRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockBillOwnerRepository) FindByID(ctx context.Context, id string) (*dto.BillOwner, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.BillOwner), args.Error(1)
+}
+
+func (m *MockBillOwnerRepository) FindAll(ctx context.Context, filter *dto.ListBillOwnersRequest) ([]*dto.BillOwner, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*dto.BillOwner), args.Error(1)
+}
+
+func (m *MockBillOwnerRepository) Count(ctx context.Context, filter *dto.ListBillOwnersRequest) (int, error) {
+	args := m.Called(ctx, filter)
+	return args.Int(0), args.Error(1)
+}
+
+func createTestBillOwner(id string, documentType dto.DocumentType) *dto.BillOwner {
+	return &dto.BillOwner{
+		ID:                 id,
+		Email:              "cliente@example.com",
+		Name:               "Cliente " + id,
+		IdentificationType: documentType,
+	}
+}
+
+// CreateBillOwner Tests
+
+// Success Cases
+func TestCreateBillOwner_NITWithCheckDigit(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockBillOwnerRepository)
+	service := NewBillOwnerService(mockRepo)
+
+	req := &dto.CreateBillOwnerRequest{
+		ID:                 "900.123.456-8",
+		Email:              "facturas@empresa.co",
+		Name:               "Empresa SAS",
+		IdentificationType: dto.DocumentTypeNIT,
+	}
+
+	mockRepo.On("Create", ctx, mock.MatchedBy(func(owner *billowner.Aggregate) bool {
+		return owner.ID() == "900123456"
+	})).Return(nil)
+
+	result, err := service.CreateBillOwner(ctx, req)
+
+	// The NIT is stored without separators nor check digit, which is derived from it
+	require.NoError(t, err)
+	assert.Equal(t, "900123456", result.ID)
+	assert.Equal(t, "8", result.CheckDigit)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateBillOwner_ForeignDocument(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockBillOwnerRepository)
+	service := NewBillOwnerService(mockRepo)
+
+	req := &dto.CreateBillOwnerRequest{
+		ID:                 " ab123456 ",
+		Email:              "tourist@example.com",
+		Name:               "Jane Doe",
+		IdentificationType: dto.DocumentTypePassport,
+	}
+
+	mockRepo.On("Create", ctx, mock.AnythingOfType("*billowner.Aggregate")).Return(nil)
+
+	result, err := service.CreateBillOwner(ctx, req)
+
+	require.NoError(t, err)
+	assert.Equal(t, "AB123456", result.ID)
+	assert.Equal(t, dto.DocumentTypePassport, result.IdentificationType)
+	assert.Empty(t, result.CheckDigit)
+}
+
+func TestCreateBillOwner_WithFiscalData(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockBillOwnerRepository)
+	service := NewBillOwnerService(mockRepo)
+
+	address := " Calle 26 # 69-76 "
+	municipalityCode := "11001"
+	req := &dto.CreateBillOwnerRequest{
+		ID:                 "800197268",
+		Email:              "facturas@empresa.co",
+		Name:               "Empresa SAS",
+		IdentificationType: dto.DocumentTypeNIT,
+		Address:            &address,
+		MunicipalityCode:   &municipalityCode,
+		TaxRegime:          dto.TaxRegimeVATResponsible,
+		FiscalResponsibilities: []dto.FiscalResponsibility{
+			dto.FiscalResponsibilityLargeTaxpayer,
+			dto.FiscalResponsibilitySelfWithholder,
+			dto.FiscalResponsibilityLargeTaxpayer,
+		},
+	}
+
+	mockRepo.On("Create", ctx, mock.AnythingOfType("*billowner.Aggregate")).Return(nil)
+
+	result, err := service.CreateBillOwner(ctx, req)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Calle 26 # 69-76", *result.Address)
+	assert.Equal(t, "11001", *result.MunicipalityCode)
+	assert.Nil(t, result.City)
+	assert.Equal(t, dto.TaxRegimeVATResponsible, result.TaxRegime)
+	assert.Equal(t, []dto.FiscalResponsibility{dto.FiscalResponsibilityLargeTaxpayer, dto.FiscalResponsibilitySelfWithholder}, result.FiscalResponsibilities)
+}
+
+// Error Cases
+func TestCreateBillOwner_InvalidRequest(t *testing.T) {
+	valid := func() *dto.CreateBillOwnerRequest {
+		return &dto.CreateBillOwnerRequest{
+			ID:                 "800197268",
+			Email:              "info@example.com",
+			Name:               "Cliente",
+			IdentificationType: dto.DocumentTypeNIT,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(req *dto.CreateBillOwnerRequest)
+	}{
+		{
+			name:   "wrong check digit in the number",
+			modify: func(req *dto.CreateBillOwnerRequest) { req.ID = "800197268-5" },
+		},
+		{
+			name:   "wrong check digit field",
+			modify: func(req *dto.CreateBillOwnerRequest) { req.CheckDigit = "3" },
+		},
+		{
+			name: "check digit on a CC",
+			modify: func(req *dto.CreateBillOwnerRequest) {
+				req.IdentificationType = dto.DocumentTypeNationalIdentificationNumber
+				req.CheckDigit = "4"
+			},
+		},
+		{
+			name: "CC with letters",
+			modify: func(req *dto.CreateBillOwnerRequest) {
+				req.ID = "10203A"
+				req.IdentificationType = dto.DocumentTypeNationalIdentificationNumber
+			},
+		},
+		{
+			name:   "unknown document type",
+			modify: func(req *dto.CreateBillOwnerRequest) { req.IdentificationType = "XX" },
+		},
+		{
+			name:   "missing name",
+			modify: func(req *dto.CreateBillOwnerRequest) { req.Name = " " },
+		},
+		{
+			name:   "invalid email",
+			modify: func(req *dto.CreateBillOwnerRequest) { req.Email = "not-an-email" },
+		},
+		{
+			name: "municipality code is not a DANE code",
+			modify: func(req *dto.CreateBillOwnerRequest) {
+				municipalityCode := "110"
+				req.MunicipalityCode = &municipalityCode
+			},
+		},
+		{
+			name:   "unknown tax regime",
+			modify: func(req *dto.CreateBillOwnerRequest) { req.TaxRegime = "simplified" },
+		},
+		{
+			name: "unknown fiscal responsibility",
+			modify: func(req *dto.CreateBillOwnerRequest) {
+				req.FiscalResponsibilities = []dto.FiscalResponsibility{"O-99"}
+			},
+		},
+		{
+			name: "no responsibility combined with others",
+			modify: func(req *dto.CreateBillOwnerRequest) {
+				req.FiscalResponsibilities = []dto.FiscalResponsibility{dto.FiscalResponsibilityNotApplicable, dto.FiscalResponsibilityLargeTaxpayer}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockRepo := new(MockBillOwnerRepository)
+			service := NewBillOwnerService(mockRepo)
+
+			req := valid()
+			tt.modify(req)
+
+			result, err := service.CreateBillOwner(ctx, req)
+
+			require.Error(t, err)
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, domainError.ErrInvalidBillOwner)
+			mockRepo.AssertNotCalled(t, "Create")
+		})
+	}
+}
+
+func TestCreateBillOwner_AlreadyExists(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockBillOwnerRepository)
+	service := NewBillOwnerService(mockRepo)
+
+	req := &dto.CreateBillOwnerRequest{
+		ID:                 "1020304050",
+		Email:              "cliente@example.com",
+		Name:               "Cliente",
+		IdentificationType: dto.DocumentTypeNationalIdentificationNumber,
+	}
+
+	mockRepo.On("Create", ctx, mock.AnythingOfType("*billowner.Aggregate")).Return(domainError.ErrBillOwnerAlreadyExists)
+
+	result, err := service.CreateBillOwner(ctx, req)
+
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, domainError.ErrBillOwnerAlreadyExists)
+}
+
+// UpdateBillOwner Tests
+
+func TestUpdateBillOwner_Success(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockBillOwnerRepository)
+	service := NewBillOwnerService(mockRepo)
+
+	existing := createTestBillOwner("1020304050", dto.DocumentTypeNationalIdentificationNumber)
+	req := &dto.UpdateBillOwnerRequest{
+		Email:              "nuevo@example.com",
+		Name:               "Cliente Actualizado",
+		IdentificationType: dto.DocumentTypeNIT,
+	}
+
+	mockRepo.On("FindByID", ctx, "1020304050").Return(existing, nil)
+	mockRepo.On("Update", ctx, mock.AnythingOfType("*billowner.Aggregate")).Return(nil)
+
+	result, err := service.UpdateBillOwner(ctx, "1020304050", req)
+
+	// A person registered with the cédula can switch to the NIT with the same number
+	require.NoError(t, err)
+	assert.Equal(t, "1020304050", result.ID)
+	assert.Equal(t, dto.DocumentTypeNIT, result.IdentificationType)
+	assert.Equal(t, billowner.NITCheckDigit("1020304050"), result.CheckDigit)
+	assert.Equal(t, "nuevo@example.com", result.Email)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateBillOwner_NotFound(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockBillOwnerRepository)
+	service := NewBillOwnerService(mockRepo)
+
+	mockRepo.On("FindByID", ctx, "1020304050").Return(nil, errors.New("record not found"))
+
+	result, err := service.UpdateBillOwner(ctx, "1020304050", &dto.UpdateBillOwnerRequest{})
+
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, domainError.ErrBillOwnerNotFound)
+	mockRepo.AssertNotCalled(t, "Update")
+}
+
+// DeleteBillOwner Tests
+
+func TestDeleteBillOwner_NotFound(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockBillOwnerRepository)
+	service := NewBillOwnerService(mockRepo)
+
+	mockRepo.On("FindByID", ctx, "1020304050").Return(nil, errors.New("record not found"))
+
+	err := service.DeleteBillOwner(ctx, "1020304050")
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, domainError.ErrBillOwnerNotFound)
+	mockRepo.AssertNotCalled(t, "Delete")
+}
+
+// GetBillOwner Tests
+
+func TestGetBillOwner_TypedWithSeparators(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockBillOwnerRepository)
+	service := NewBillOwnerService(mockRepo)
+
+	mockRepo.On("FindByID", ctx, "860034313").Return(createTestBillOwner("860034313", dto.DocumentTypeNIT), nil)
+
+	result, err := service.GetBillOwner(ctx, "860.034.313-7")
+
+	require.NoError(t, err)
+	assert.Equal(t, "860034313", result.ID)
+	assert.Equal(t, "7", result.CheckDigit)
+}
+
+// ListBillOwners Tests
+
+func TestListBillOwners_SearchByDocumentPrefix(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockBillOwnerRepository)
+	service := NewBillOwnerService(mockRepo)
+
+	expectedFilter := &dto.ListBillOwnersRequest{DocumentNumber: "102030", Page: 1, PageSize: 20}
+	owners := []*dto.BillOwner{createTestBillOwner("1020304050", dto.DocumentTypeNationalIdentificationNumber)}
+
+	mockRepo.On("FindAll", ctx, expectedFilter).Return(owners, nil)
+	mockRepo.On("Count", ctx, expectedFilter).Return(1, nil)
+
+	result, err := service.ListBillOwners(ctx, &dto.ListBillOwnersRequest{DocumentNumber: "1.020.30"})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Total)
+	assert.Equal(t, 1, result.Page)
+	assert.Equal(t, 20, result.PageSize)
+	require.Len(t, result.BillOwners, 1)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListBillOwners_InvalidPage(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockBillOwnerRepository)
+	service := NewBillOwnerService(mockRepo)
+
+	result, err := service.ListBillOwners(ctx, &dto.ListBillOwnersRequest{Page: -1})
+
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, domainError.ErrInvalidBillOwnerFilter)
+	mockRepo.AssertNotCalled(t, "FindAll")
+}
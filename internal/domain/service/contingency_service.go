@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"laguna-escondida/backend/internal/domain/dto"
+	domainError "laguna-escondida/backend/internal/domain/error"
+	"laguna-escondida/backend/internal/domain/ports"
+)
+
+// contingencyFailureThreshold is how many sends in a row must fail to reach the provider
+// before bills start being issued in contingency
+const contingencyFailureThreshold = 3
+
+// ContingencyService decides when bills are issued in contingency. The outbox dispatcher
+// reports whether the provider can be reached, an automatic period opens after several
+// failures in a row and closes on the first successful send. Periods can also be opened and
+// closed by hand, those are only closed by hand
+type ContingencyService struct {
+	contingencyRepo ports.ContingencyRepository
+
+	mu                  sync.Mutex
+	consecutiveFailures int
+}
+
+func NewContingencyService(contingencyRepo ports.ContingencyRepository) *ContingencyService {
+	return &ContingencyService{
+		contingencyRepo: contingencyRepo,
+	}
+}
+
+func (s *ContingencyService) Status(ctx context.Context) (*dto.ContingencyStatus, error) {
+	period, err := s.contingencyRepo.FindActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ContingencyStatus{
+		Active: period != nil,
+		Period: period,
+	}, nil
+}
+
+// Activate opens a contingency period by hand, for instance when the provider announced a maintenance
+func (s *ContingencyService) Activate(ctx context.Context, req *dto.ActivateContingencyRequest) (*dto.ContingencyPeriod, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: reason is required", domainError.ErrInvalidContingency)
+	}
+
+	active, err := s.contingencyRepo.FindActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if active != nil {
+		return nil, domainError.ErrContingencyAlreadyActive
+	}
+
+	return s.contingencyRepo.Start(ctx, reason, false, time.Now())
+}
+
+// Deactivate closes the open period, the bills issued in it keep being transmitted by the outbox
+func (s *ContingencyService) Deactivate(ctx context.Context) error {
+	active, err := s.contingencyRepo.FindActive(ctx)
+	if err != nil {
+		return err
+	}
+	if active == nil {
+		return domainError.ErrContingencyNotActive
+	}
+
+	s.mu.Lock()
+	s.consecutiveFailures = 0
+	s.mu.Unlock()
+
+	return s.contingencyRepo.End(ctx, active.ID, time.Now())
+}
+
+func (s *ContingencyService) ListPeriods(ctx context.Context) ([]*dto.ContingencyPeriod, error) {
+	return s.contingencyRepo.FindAll(ctx)
+}
+
+// ReportProviderUnavailable counts a send that could not reach the provider and opens an
+// automatic period once the threshold is reached. It returns whether the system is in contingency
+func (s *ContingencyService) ReportProviderUnavailable(ctx context.Context, cause error) (bool, error) {
+	s.mu.Lock()
+	s.consecutiveFailures++
+	failures := s.consecutiveFailures
+	s.mu.Unlock()
+
+	active, err := s.contingencyRepo.FindActive(ctx)
+	if err != nil {
+		return false, err
+	}
+	if active != nil {
+		return true, nil
+	}
+	if failures < contingencyFailureThreshold {
+		return false, nil
+	}
+
+	reason := fmt.Sprintf("provider unreachable after %d attempts: %v", failures, cause)
+	if _, err := s.contingencyRepo.Start(ctx, reason, true, time.Now()); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ReportProviderAvailable resets the failure count and closes an automatic period. It returns
+// whether a period was closed, so the invoices waiting for the provider can be sent right away
+func (s *ContingencyService) ReportProviderAvailable(ctx context.Context) (bool, error) {
+	s.mu.Lock()
+	s.consecutiveFailures = 0
+	s.mu.Unlock()
+
+	active, err := s.contingencyRepo.FindActive(ctx)
+	if err != nil {
+		return false, err
+	}
+	if active == nil || !active.Automatic {
+		return false, nil
+	}
+
+	if err := s.contingencyRepo.End(ctx, active.ID, time.Now()); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
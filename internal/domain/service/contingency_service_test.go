@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"laguna-escondida/backend/internal/domain/dto"
+	domainError "laguna-escondida/backend/internal/domain/error"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockContingencyRepository is a mock implementation of ports.ContingencyRepository
+type MockContingencyRepository struct {
+	mock.Mock
+}
+
+func (m *MockContingencyRepository) FindActive(ctx context.Context) (*dto.ContingencyPeriod, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ContingencyPeriod), args.Error(1)
+}
+
+func (m *MockContingencyRepository) Start(ctx context.Context, reason string, automatic bool, now time.Time) (*dto.ContingencyPeriod, error) {
+	args := m.Called(ctx, reason, automatic, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ContingencyPeriod), args.Error(1)
+}
+
+func (m *MockContingencyRepository) End(ctx context.Context, id string, now time.Time) error {
+	args := m.Called(ctx, id, now)
+	return args.Error(0)
+}
+
+func (m *MockContingencyRepository) FindAll(ctx context.Context) ([]*dto.ContingencyPeriod, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*dto.ContingencyPeriod), args.Error(1)
+}
+
+// newTestContingencyRepo returns a repository with no open period, for tests that do not
+// care about contingency
+func newTestContingencyRepo() *MockContingencyRepository {
+	contingencyRepo := new(MockContingencyRepository)
+	contingencyRepo.On("FindActive", mock.Anything).Return(nil, nil).Maybe()
+	return contingencyRepo
+}
+
+func createTestContingencyPeriod(id string, automatic bool) *dto.ContingencyPeriod {
+	return &dto.ContingencyPeriod{
+		ID:        id,
+		Reason:    "provider unreachable",
+		Automatic: automatic,
+		StartedAt: time.Now().Add(-10 * time.Minute),
+	}
+}
+
+var errProviderDown = fmt.Errorf("%w: connection refused", domainError.ErrProviderUnavailable)
+
+// Activate Tests
+
+func TestActivateContingency_Success(t *testing.T) {
+	ctx := context.Background()
+	contingencyRepo := new(MockContingencyRepository)
+	service := NewContingencyService(contingencyRepo)
+
+	period := createTestContingencyPeriod("period-1", false)
+	contingencyRepo.On("FindActive", ctx).Return(nil, nil)
+	contingencyRepo.On("Start", ctx, "provider maintenance", false, mock.AnythingOfType("time.Time")).Return(period, nil)
+
+	result, err := service.Activate(ctx, &dto.ActivateContingencyRequest{Reason: "  provider maintenance "})
+
+	require.NoError(t, err)
+	assert.Equal(t, period, result)
+	contingencyRepo.AssertExpectations(t)
+}
+
+func TestActivateContingency_AlreadyActive(t *testing.T) {
+	ctx := context.Background()
+	contingencyRepo := new(MockContingencyRepository)
+	service := NewContingencyService(contingencyRepo)
+
+	contingencyRepo.On("FindActive", ctx).Return(createTestContingencyPeriod("period-1", true), nil)
+
+	result, err := service.Activate(ctx, &dto.ActivateContingencyRequest{Reason: "provider maintenance"})
+
+	assert.ErrorIs(t, err, domainError.ErrContingencyAlreadyActive)
+	assert.Nil(t, result)
+	contingencyRepo.AssertNotCalled(t, "Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestActivateContingency_MissingReason(t *testing.T) {
+	service := NewContingencyService(new(MockContingencyRepository))
+
+	result, err := service.Activate(context.Background(), &dto.ActivateContingencyRequest{Reason: "   "})
+
+	assert.ErrorIs(t, err, domainError.ErrInvalidContingency)
+	assert.Nil(t, result)
+}
+
+// Deactivate Tests
+
+func TestDeactivateContingency_NotActive(t *testing.T) {
+	ctx := context.Background()
+	contingencyRepo := new(MockContingencyRepository)
+	service := NewContingencyService(contingencyRepo)
+
+	contingencyRepo.On("FindActive", ctx).Return(nil, nil)
+
+	err := service.Deactivate(ctx)
+
+	assert.ErrorIs(t, err, domainError.ErrContingencyNotActive)
+	contingencyRepo.AssertNotCalled(t, "End", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// ReportProviderUnavailable Tests
+
+func TestReportProviderUnavailable_StartsAutomaticPeriodAfterThreshold(t *testing.T) {
+	ctx := context.Background()
+	contingencyRepo := new(MockContingencyRepository)
+	service := NewContingencyService(contingencyRepo)
+
+	contingencyRepo.On("FindActive", ctx).Return(nil, nil)
+	contingencyRepo.On("Start", ctx, mock.AnythingOfType("string"), true, mock.AnythingOfType("time.Time")).
+		Return(createTestContingencyPeriod("period-1", true), nil).Once()
+
+	for i := 1; i < contingencyFailureThreshold; i++ {
+		active, err := service.ReportProviderUnavailable(ctx, errProviderDown)
+		require.NoError(t, err)
+		assert.False(t, active)
+	}
+
+	active, err := service.ReportProviderUnavailable(ctx, errProviderDown)
+
+	require.NoError(t, err)
+	assert.True(t, active)
+	contingencyRepo.AssertExpectations(t)
+}
+
+func TestReportProviderUnavailable_SuccessResetsTheCount(t *testing.T) {
+	ctx := context.Background()
+	contingencyRepo := new(MockContingencyRepository)
+	service := NewContingencyService(contingencyRepo)
+
+	contingencyRepo.On("FindActive", ctx).Return(nil, nil)
+
+	for i := 1; i < contingencyFailureThreshold; i++ {
+		_, err := service.ReportProviderUnavailable(ctx, errProviderDown)
+		require.NoError(t, err)
+	}
+	_, err := service.ReportProviderAvailable(ctx)
+	require.NoError(t, err)
+
+	active, err := service.ReportProviderUnavailable(ctx, errProviderDown)
+
+	require.NoError(t, err)
+	assert.False(t, active)
+	contingencyRepo.AssertNotCalled(t, "Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// ReportProviderAvailable Tests
+
+func TestReportProviderAvailable_EndsAutomaticPeriod(t *testing.T) {
+	ctx := context.Background()
+	contingencyRepo := new(MockContingencyRepository)
+	service := NewContingencyService(contingencyRepo)
+
+	contingencyRepo.On("FindActive", ctx).Return(createTestContingencyPeriod("period-1", true), nil)
+	contingencyRepo.On("End", ctx, "period-1", mock.AnythingOfType("time.Time")).Return(nil)
+
+	ended, err := service.ReportProviderAvailable(ctx)
+
+	require.NoError(t, err)
+	assert.True(t, ended)
+	contingencyRepo.AssertExpectations(t)
+}
+
+func TestReportProviderAvailable_KeepsManualPeriod(t *testing.T) {
+	ctx := context.Background()
+	contingencyRepo := new(MockContingencyRepository)
+	service := NewContingencyService(contingencyRepo)
+
+	contingencyRepo.On("FindActive", ctx).Return(createTestContingencyPeriod("period-1", false), nil)
+
+	ended, err := service.ReportProviderAvailable(ctx)
+
+	require.NoError(t, err)
+	assert.False(t, ended)
+	contingencyRepo.AssertNotCalled(t, "End", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReportProviderAvailable_RepositoryError(t *testing.T) {
+	ctx := context.Background()
+	contingencyRepo := new(MockContingencyRepository)
+	service := NewContingencyService(contingencyRepo)
+
+	contingencyRepo.On("FindActive", ctx).Return(nil, errors.New("connection refused"))
+
+	ended, err := service.ReportProviderAvailable(ctx)
+
+	assert.Error(t, err)
+	assert.False(t, ended)
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"laguna-escondida/backend/internal/domain/dto"
+	domainError "laguna-escondida/backend/internal/domain/error"
+	"laguna-escondida/backend/internal/domain/ports"
+)
+
+// maxIdempotencyKeyLength fits the UUIDs and ULIDs clients usually send with room to spare
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyService makes retries of a request return the response of the first one instead
+// of running it again, so a double tap or a network retry never issues two bills
+// A key being processed is held for the processing lease only, so a request that died
+// without answering does not lock its key for the whole TTL
+type IdempotencyService struct {
+	idempotencyRepo ports.IdempotencyRepository
+	ttl             time.Duration
+	processingLease time.Duration
+}
+
+func NewIdempotencyService(idempotencyRepo ports.IdempotencyRepository, ttl time.Duration, processingLease time.Duration) *IdempotencyService {
+	return &IdempotencyService{
+		idempotencyRepo: idempotencyRepo,
+		ttl:             ttl,
+		processingLease: processingLease,
+	}
+}
+
+// Begin reserves the key for the request. It returns nil when the request must run, or the
+// completed record whose response must be replayed
+func (s *IdempotencyService) Begin(ctx context.Context, scope string, key string, requestHash string) (*dto.IdempotencyRecord, error) {
+	if key == "" || len(key) > maxIdempotencyKeyLength {
+		return nil, fmt.Errorf("%w: it must have between 1 and %d characters", domainError.ErrInvalidIdempotencyKey, maxIdempotencyKeyLength)
+	}
+
+	now := time.Now()
+	existing, err := s.idempotencyRepo.Reserve(ctx, scope, key, requestHash, now, now.Add(s.processingLease))
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case existing == nil:
+		return nil, nil
+	case existing.RequestHash != requestHash:
+		return nil, domainError.ErrIdempotencyKeyReused
+	case existing.Status != dto.IdempotencyStatusCompleted:
+		return nil, domainError.ErrIdempotencyKeyInProgress
+	default:
+		return existing, nil
+	}
+}
+
+// Complete stores the response to replay for the key during the TTL
+func (s *IdempotencyService) Complete(ctx context.Context, scope string, key string, responseStatus int, contentType string, responseBody []byte) error {
+	return s.idempotencyRepo.Complete(ctx, scope, key, responseStatus, contentType, responseBody, time.Now().Add(s.ttl))
+}
+
+// PurgeExpired deletes the keys past their window and returns how many were deleted
+func (s *IdempotencyService) PurgeExpired(ctx context.Context) (int, error) {
+	return s.idempotencyRepo.DeleteExpired(ctx, time.Now())
+}
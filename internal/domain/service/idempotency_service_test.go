@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"laguna-escondida/backend/internal/domain/dto"
+	domainError "laguna-escondida/backend/internal/domain/error"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockIdempotencyRepository is a mock implementation of ports.IdempotencyRepository
+type MockIdempotencyRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyRepository) Reserve(ctx context.Context, scope string, key string, requestHash string, now time.Time, expiresAt time.Time) (*dto.IdempotencyRecord, error) {
+	args := m.Called(ctx, scope, key, requestHash, now, expiresAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.IdempotencyRecord), args.Error(1)
+}
+
+func (m *MockIdempotencyRepository) Complete(ctx context.Context, scope string, key string, responseStatus int, contentType string, responseBody []byte, expiresAt time.Time) error {
+	args := m.Called(ctx, scope, key, responseStatus, contentType, responseBody, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	args := m.Called(ctx, now)
+	return args.Int(0), args.Error(1)
+}
+
+func createTestIdempotencyRecord(requestHash string, status dto.IdempotencyStatus) *dto.IdempotencyRecord {
+	return &dto.IdempotencyRecord{
+		Scope:          "invoices",
+		Key:            "key-1",
+		RequestHash:    requestHash,
+		Status:         status,
+		ResponseStatus: 201,
+		ContentType:    "application/json",
+		ResponseBody:   []byte(`{"id":"bill-1"}`),
+	}
+}
+
+// Begin Tests
+
+func TestBeginIdempotentRequest_NewKey(t *testing.T) {
+	ctx := context.Background()
+	idempotencyRepo := new(MockIdempotencyRepository)
+	service := NewIdempotencyService(idempotencyRepo, 24*time.Hour, time.Minute)
+
+	// The key is only held for the processing lease until the response is stored
+	idempotencyRepo.On("Reserve", ctx, "invoices", "key-1", "hash-1", mock.AnythingOfType("time.Time"), mock.MatchedBy(func(expiresAt time.Time) bool {
+		return expiresAt.After(time.Now().Add(50*time.Second)) && expiresAt.Before(time.Now().Add(2*time.Minute))
+	})).Return(nil, nil)
+
+	record, err := service.Begin(ctx, "invoices", "key-1", "hash-1")
+
+	require.NoError(t, err)
+	assert.Nil(t, record)
+	idempotencyRepo.AssertExpectations(t)
+}
+
+func TestBeginIdempotentRequest_ReplaysCompletedResponse(t *testing.T) {
+	ctx := context.Background()
+	idempotencyRepo := new(MockIdempotencyRepository)
+	service := NewIdempotencyService(idempotencyRepo, 24*time.Hour, time.Minute)
+
+	stored := createTestIdempotencyRecord("hash-1", dto.IdempotencyStatusCompleted)
+	idempotencyRepo.On("Reserve", ctx, "invoices", "key-1", "hash-1", mock.Anything, mock.Anything).Return(stored, nil)
+
+	record, err := service.Begin(ctx, "invoices", "key-1", "hash-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, stored, record)
+}
+
+func TestBeginIdempotentRequest_InProgress(t *testing.T) {
+	ctx := context.Background()
+	idempotencyRepo := new(MockIdempotencyRepository)
+	service := NewIdempotencyService(idempotencyRepo, 24*time.Hour, time.Minute)
+
+	idempotencyRepo.On("Reserve", ctx, "invoices", "key-1", "hash-1", mock.Anything, mock.Anything).
+		Return(createTestIdempotencyRecord("hash-1", dto.IdempotencyStatusProcessing), nil)
+
+	record, err := service.Begin(ctx, "invoices", "key-1", "hash-1")
+
+	assert.ErrorIs(t, err, domainError.ErrIdempotencyKeyInProgress)
+	assert.Nil(t, record)
+}
+
+func TestBeginIdempotentRequest_KeyReusedForAnotherRequest(t *testing.T) {
+	ctx := context.Background()
+	idempotencyRepo := new(MockIdempotencyRepository)
+	service := NewIdempotencyService(idempotencyRepo, 24*time.Hour, time.Minute)
+
+	idempotencyRepo.On("Reserve", ctx, "invoices", "key-1", "hash-2", mock.Anything, mock.Anything).
+		Return(createTestIdempotencyRecord("hash-1", dto.IdempotencyStatusCompleted), nil)
+
+	record, err := service.Begin(ctx, "invoices", "key-1", "hash-2")
+
+	assert.ErrorIs(t, err, domainError.ErrIdempotencyKeyReused)
+	assert.Nil(t, record)
+}
+
+func TestBeginIdempotentRequest_InvalidKey(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+	}{
+		{name: "empty", key: ""},
+		{name: "too long", key: strings.Repeat("k", maxIdempotencyKeyLength+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idempotencyRepo := new(MockIdempotencyRepository)
+			service := NewIdempotencyService(idempotencyRepo, 24*time.Hour, time.Minute)
+
+			record, err := service.Begin(context.Background(), "invoices", tt.key, "hash-1")
+
+			assert.ErrorIs(t, err, domainError.ErrInvalidIdempotencyKey)
+			assert.Nil(t, record)
+			idempotencyRepo.AssertNotCalled(t, "Reserve", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestBeginIdempotentRequest_RepositoryError(t *testing.T) {
+	ctx := context.Background()
+	idempotencyRepo := new(MockIdempotencyRepository)
+	service := NewIdempotencyService(idempotencyRepo, 24*time.Hour, time.Minute)
+
+	idempotencyRepo.On("Reserve", ctx, "invoices", "key-1", "hash-1", mock.Anything, mock.Anything).Return(nil, errors.New("connection refused"))
+
+	record, err := service.Begin(ctx, "invoices", "key-1", "hash-1")
+
+	assert.Error(t, err)
+	assert.Nil(t, record)
+}
+
+// Complete Tests
+
+func TestCompleteIdempotentRequest_KeepsResponseForTTL(t *testing.T) {
+	ctx := context.Background()
+	idempotencyRepo := new(MockIdempotencyRepository)
+	service := NewIdempotencyService(idempotencyRepo, 24*time.Hour, time.Minute)
+
+	body := []byte(`{"error":"failed"}`)
+	idempotencyRepo.On("Complete", ctx, "invoices", "key-1", 500, "text/plain", body, mock.MatchedBy(func(expiresAt time.Time) bool {
+		return expiresAt.After(time.Now().Add(23 * time.Hour))
+	})).Return(nil)
+
+	err := service.Complete(ctx, "invoices", "key-1", 500, "text/plain", body)
+
+	require.NoError(t, err)
+	idempotencyRepo.AssertExpectations(t)
+}
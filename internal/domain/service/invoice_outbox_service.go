@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"laguna-escondida/backend/internal/domain/aggregate/bill"
+	"laguna-escondida/backend/internal/domain/dto"
+	domainError "laguna-escondida/backend/internal/domain/error"
+	"laguna-escondida/backend/internal/domain/ports"
+)
+
+const (
+	// maxInvoiceDispatchAttempts is how many times an invoice is sent before it goes to the dead letter
+	maxInvoiceDispatchAttempts = 8
+	invoiceDispatchBaseDelay   = 30 * time.Second
+	invoiceDispatchMaxDelay    = time.Hour
+	// contingencyDispatchMaxDelay keeps retrying often while in contingency, the first
+	// invoice that goes through ends an automatic period
+	contingencyDispatchMaxDelay = 5 * time.Minute
+)
+
+// InvoiceOutboxService sends to the provider the invoices saved in the outbox. Bills are
+// always committed first, so a slow or failing provider never holds database locks nor
+// loses a consecutive already taken
+type InvoiceOutboxService struct {
+	electronicInvoiceClient ports.ElectronicInvoiceClient
+	outboxRepo              ports.InvoiceOutboxRepository
+	resolutionRepo          ports.NumberingResolutionRepository
+	contingencyService      *ContingencyService
+	issuer                  bill.Issuer
+}
+
+func NewInvoiceOutboxService(
+	electronicInvoiceClient ports.ElectronicInvoiceClient,
+	outboxRepo ports.InvoiceOutboxRepository,
+	resolutionRepo ports.NumberingResolutionRepository,
+	contingencyService *ContingencyService,
+	issuer bill.Issuer,
+) *InvoiceOutboxService {
+	return &InvoiceOutboxService{
+		electronicInvoiceClient: electronicInvoiceClient,
+		outboxRepo:              outboxRepo,
+		resolutionRepo:          resolutionRepo,
+		contingencyService:      contingencyService,
+		issuer:                  issuer,
+	}
+}
+
+// DispatchPending sends up to limit due invoices and notes and returns how many the provider accepted.
+// A failed invoice is retried later with exponential backoff and does not stop the others,
+// only a failure of the outbox itself is returned. When the provider cannot be reached the
+// rest of the batch is left for the next run, once its lease expires
+func (s *InvoiceOutboxService) DispatchPending(ctx context.Context, limit int) (int, error) {
+	entries, err := s.outboxRepo.ClaimDue(ctx, limit)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", domainError.ErrInvoiceDispatchFailed, err)
+	}
+
+	sent := 0
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return sent, err
+		}
+
+		// An invoice whose CUFE cannot be computed, such as one whose resolution is missing,
+		// is retried and dead lettered like one the provider rejected, so it does not hold
+		// back the entries queued after it
+		document, expectedCUFE, err := s.outboxDocument(ctx, entry)
+		if err != nil {
+			if err := s.recordFailure(ctx, entry, err, false); err != nil {
+				return sent, fmt.Errorf("%w: %s%d: %w", domainError.ErrInvoiceDispatchFailed, entry.Prefix, entry.Consecutive, err)
+			}
+			continue
+		}
+
+		response, err := s.electronicInvoiceClient.Send(ctx, document)
+		if err != nil {
+			unavailable := errors.Is(err, domainError.ErrProviderUnavailable)
+			inContingency := false
+			if unavailable {
+				var reportErr error
+				inContingency, reportErr = s.contingencyService.ReportProviderUnavailable(ctx, err)
+				if reportErr != nil {
+					return sent, fmt.Errorf("%w: %w", domainError.ErrInvoiceDispatchFailed, reportErr)
+				}
+			}
+
+			if err := s.recordFailure(ctx, entry, err, inContingency); err != nil {
+				return sent, fmt.Errorf("%w: %s%d: %w", domainError.ErrInvoiceDispatchFailed, entry.Prefix, entry.Consecutive, err)
+			}
+			if unavailable {
+				return sent, nil
+			}
+			continue
+		}
+
+		// A CUFE other than the expected one means the provider reported different data to the
+		// DIAN, the bill is flagged to be reviewed. Pending documents have no CUFE yet
+		if err := s.outboxRepo.MarkSent(ctx, entry, response, expectedCUFE); err != nil {
+			return sent, fmt.Errorf("%w: %s%d: %w", domainError.ErrInvoiceDispatchFailed, entry.Prefix, entry.Consecutive, err)
+		}
+		sent++
+
+		if err := s.reportAvailable(ctx); err != nil {
+			return sent, fmt.Errorf("%w: %w", domainError.ErrInvoiceDispatchFailed, err)
+		}
+	}
+
+	return sent, nil
+}
+
+// outboxDocument builds the document of the entry and the CUFE the provider must answer for
+// it. Notes carry a CUDE computed with the software PIN, which is not checked
+func (s *InvoiceOutboxService) outboxDocument(ctx context.Context, entry *dto.InvoiceOutboxEntry) (*dto.ElectronicDocument, string, error) {
+	switch entry.DocumentType {
+	case dto.ElectronicDocumentTypeCreditNote:
+		return bill.NewCreditNoteDocument(entry.CreditNoteRequest), "", nil
+	case dto.ElectronicDocumentTypeDebitNote:
+		return bill.NewDebitNoteDocument(entry.DebitNoteRequest), "", nil
+	}
+
+	document := bill.NewInvoiceDocument(entry.Request)
+	expectedCUFE, err := expectedInvoiceCUFE(ctx, s.resolutionRepo, s.issuer, document)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return document, expectedCUFE, nil
+}
+
+// expectedInvoiceCUFE computes the CUFE of the invoice with the technical key of the
+// resolution its number was taken from. It is empty when the NIT of the issuer is unknown
+func expectedInvoiceCUFE(ctx context.Context, resolutionRepo ports.NumberingResolutionRepository, issuer bill.Issuer, document *dto.ElectronicDocument) (string, error) {
+	if issuer.NIT == "" {
+		return "", nil
+	}
+
+	resolution, err := resolutionRepo.FindByNumber(ctx, document.Prefix, document.Consecutive)
+	if err != nil {
+		return "", err
+	}
+
+	return bill.InvoiceCUFE(document, issuer, resolution.TechnicalKey), nil
+}
+
+// reportAvailable tells the contingency service the provider answered, when that ends an
+// automatic period the invoices issued in it are sent right away instead of waiting their backoff
+func (s *InvoiceOutboxService) reportAvailable(ctx context.Context) error {
+	ended, err := s.contingencyService.ReportProviderAvailable(ctx)
+	if err != nil || !ended {
+		return err
+	}
+
+	return s.outboxRepo.ReleaseRetries(ctx)
+}
+
+// recordFailure schedules the next attempt. Invoices are never dead lettered while in
+// contingency, the provider being down is not a reason to give up on them
+func (s *InvoiceOutboxService) recordFailure(ctx context.Context, entry *dto.InvoiceOutboxEntry, dispatchErr error, inContingency bool) error {
+	attempts := entry.Attempts + 1
+	if attempts >= maxInvoiceDispatchAttempts && !inContingency {
+		return s.outboxRepo.MarkDead(ctx, entry.ID, attempts, dispatchErr.Error())
+	}
+
+	delay := invoiceDispatchDelay(attempts)
+	if inContingency {
+		delay = min(delay, contingencyDispatchMaxDelay)
+	}
+
+	return s.outboxRepo.MarkFailed(ctx, entry.ID, attempts, dispatchErr.Error(), time.Now().Add(delay))
+}
+
+// invoiceDispatchDelay doubles the wait after every failed attempt
+func invoiceDispatchDelay(attempts int) time.Duration {
+	delay := invoiceDispatchBaseDelay
+	for i := 1; i < attempts && delay < invoiceDispatchMaxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, invoiceDispatchMaxDelay)
+}
+
+func (s *InvoiceOutboxService) ListDeadLetters(ctx context.Context) ([]*dto.InvoiceOutboxEntry, error) {
+	return s.outboxRepo.FindDeadLetters(ctx)
+}
+
+// RetryDeadLetter queues again an invoice that ran out of attempts, usually after fixing
+// whatever made the provider reject it
+func (s *InvoiceOutboxService) RetryDeadLetter(ctx context.Context, id string) error {
+	if err := s.outboxRepo.Requeue(ctx, id); err != nil {
+		return fmt.Errorf("%w: %w", domainError.ErrOutboxEntryNotFound, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,493 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"laguna-escondida/backend/internal/domain/aggregate/bill"
+	"laguna-escondida/backend/internal/domain/dto"
+	domainError "laguna-escondida/backend/internal/domain/error"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockInvoiceOutboxRepository is a mock implementation of ports.InvoiceOutboxRepository
+type MockInvoiceOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockInvoiceOutboxRepository) ClaimDue(ctx context.Context, limit int) ([]*dto.InvoiceOutboxEntry, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*dto.InvoiceOutboxEntry), args.Error(1)
+}
+
+func (m *MockInvoiceOutboxRepository) MarkSent(ctx context.Context, entry *dto.InvoiceOutboxEntry, response *dto.CreateElectronicInvoiceResponse, expectedCUFE string) error {
+	args := m.Called(ctx, entry, response, expectedCUFE)
+	return args.Error(0)
+}
+
+func (m *MockInvoiceOutboxRepository) MarkFailed(ctx context.Context, id string, attempts int, lastError string, nextAttemptAt time.Time) error {
+	args := m.Called(ctx, id, attempts, lastError, nextAttemptAt)
+	return args.Error(0)
+}
+
+func (m *MockInvoiceOutboxRepository) MarkDead(ctx context.Context, id string, attempts int, lastError string) error {
+	args := m.Called(ctx, id, attempts, lastError)
+	return args.Error(0)
+}
+
+func (m *MockInvoiceOutboxRepository) FindDeadLetters(ctx context.Context) ([]*dto.InvoiceOutboxEntry, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*dto.InvoiceOutboxEntry), args.Error(1)
+}
+
+func (m *MockInvoiceOutboxRepository) Requeue(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockInvoiceOutboxRepository) ReleaseRetries(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+// createTestOutboxEntry returns a pending entry for the invoice SETP<consecutive>
+func createTestOutboxEntry(id string, consecutive int, attempts int) *dto.InvoiceOutboxEntry {
+	return &dto.InvoiceOutboxEntry{
+		ID:           id,
+		DocumentType: dto.ElectronicDocumentTypeInvoice,
+		BillID:       "bill-" + id,
+		Prefix:       "SETP",
+		Consecutive:  consecutive,
+		Request: &dto.CreateElectronicInvoiceRequest{
+			Prefix:      "SETP",
+			Consecutive: consecutive,
+			Bill:        &dto.Bill{ID: "bill-" + id, CreatedAt: time.Date(2026, 10, 17, 12, 30, 0, 0, time.UTC)},
+		},
+		Status:   dto.InvoiceOutboxStatusPending,
+		Attempts: attempts,
+	}
+}
+
+// documentOf matches the document sent to the provider for the invoice of the entry
+func documentOf(entry *dto.InvoiceOutboxEntry) any {
+	return mock.MatchedBy(func(document *dto.ElectronicDocument) bool {
+		return document.Prefix == entry.Prefix && document.Consecutive == entry.Consecutive
+	})
+}
+
+// expectedCUFEOf is the CUFE the backend computes for the invoice of the entry
+func expectedCUFEOf(entry *dto.InvoiceOutboxEntry) string {
+	return bill.InvoiceCUFE(bill.NewInvoiceDocument(entry.Request), testIssuer, "technical-key-resolution-1")
+}
+
+// DispatchPending Tests
+
+func TestDispatchPending_Success(t *testing.T) {
+	ctx := context.Background()
+	client := new(MockElectronicInvoiceClient)
+	outboxRepo := new(MockInvoiceOutboxRepository)
+	service := NewInvoiceOutboxService(client, outboxRepo, newTestResolutionRepo(), NewContingencyService(newTestContingencyRepo()), testIssuer)
+
+	first := createTestOutboxEntry("entry-1", 1, 0)
+	second := createTestOutboxEntry("entry-2", 2, 3)
+	firstResponse := &dto.CreateElectronicInvoiceResponse{Tascode: "tascode-1", CUFE: "cufe-1"}
+	secondResponse := &dto.CreateElectronicInvoiceResponse{Tascode: "tascode-2", CUFE: "cufe-2"}
+
+	outboxRepo.On("ClaimDue", ctx, 20).Return([]*dto.InvoiceOutboxEntry{first, second}, nil)
+	client.On("Send", ctx, documentOf(first)).Return(firstResponse, nil)
+	client.On("Send", ctx, documentOf(second)).Return(secondResponse, nil)
+	outboxRepo.On("MarkSent", ctx, first, firstResponse, expectedCUFEOf(first)).Return(nil)
+	outboxRepo.On("MarkSent", ctx, second, secondResponse, expectedCUFEOf(second)).Return(nil)
+
+	sent, err := service.DispatchPending(ctx, 20)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, sent)
+	client.AssertExpectations(t)
+	outboxRepo.AssertExpectations(t)
+}
+
+func TestDispatchPending_WithoutSupplierNITTheCUFEIsNotChecked(t *testing.T) {
+	ctx := context.Background()
+	client := new(MockElectronicInvoiceClient)
+	outboxRepo := new(MockInvoiceOutboxRepository)
+	resolutionRepo := new(MockNumberingResolutionRepository)
+	service := NewInvoiceOutboxService(client, outboxRepo, resolutionRepo, NewContingencyService(newTestContingencyRepo()), bill.Issuer{Environment: dto.DIANEnvironmentTest})
+
+	entry := createTestOutboxEntry("entry-1", 1, 0)
+	response := &dto.CreateElectronicInvoiceResponse{Tascode: "tascode-1", CUFE: "cufe-1"}
+
+	outboxRepo.On("ClaimDue", ctx, 20).Return([]*dto.InvoiceOutboxEntry{entry}, nil)
+	client.On("Send", ctx, documentOf(entry)).Return(response, nil)
+	outboxRepo.On("MarkSent", ctx, entry, response, "").Return(nil)
+
+	sent, err := service.DispatchPending(ctx, 20)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	client.AssertExpectations(t)
+	outboxRepo.AssertExpectations(t)
+	resolutionRepo.AssertNotCalled(t, "FindByNumber", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDispatchPending_CreditNoteIsSentWithoutCUFECheck(t *testing.T) {
+	ctx := context.Background()
+	client := new(MockElectronicInvoiceClient)
+	outboxRepo := new(MockInvoiceOutboxRepository)
+	resolutionRepo := new(MockNumberingResolutionRepository)
+	service := NewInvoiceOutboxService(client, outboxRepo, resolutionRepo, NewContingencyService(newTestContingencyRepo()), testIssuer)
+
+	noteID := "note-1"
+	billCUFE := "cufe-1"
+	entry := &dto.InvoiceOutboxEntry{
+		ID:           "entry-1",
+		DocumentType: dto.ElectronicDocumentTypeCreditNote,
+		BillID:       "bill-1",
+		NoteID:       &noteID,
+		Prefix:       "NC",
+		Consecutive:  4,
+		CreditNoteRequest: &dto.CreateElectronicCreditNoteRequest{
+			Prefix:      "NC",
+			Consecutive: 4,
+			Bill:        &dto.Bill{ID: "bill-1", Prefix: "SETP", Consecutive: 1, CUFE: &billCUFE},
+			CreditNote:  &dto.CreditNote{ID: noteID, Reason: dto.CreditNoteReasonCancellation},
+		},
+		Status: dto.InvoiceOutboxStatusPending,
+	}
+	response := &dto.CreateElectronicInvoiceResponse{Tascode: "tascode-nc", CUFE: "cude-nc"}
+
+	outboxRepo.On("ClaimDue", ctx, 20).Return([]*dto.InvoiceOutboxEntry{entry}, nil)
+	client.On("Send", ctx, mock.MatchedBy(func(document *dto.ElectronicDocument) bool {
+		return document.Type == dto.ElectronicDocumentTypeCreditNote && document.Reference.CUFE == "cufe-1"
+	})).Return(response, nil)
+	outboxRepo.On("MarkSent", ctx, entry, response, "").Return(nil)
+
+	sent, err := service.DispatchPending(ctx, 20)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	client.AssertExpectations(t)
+	outboxRepo.AssertExpectations(t)
+	resolutionRepo.AssertNotCalled(t, "FindByNumber", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDispatchPending_DebitNoteIsSentWithoutCUFECheck(t *testing.T) {
+	ctx := context.Background()
+	client := new(MockElectronicInvoiceClient)
+	outboxRepo := new(MockInvoiceOutboxRepository)
+	resolutionRepo := new(MockNumberingResolutionRepository)
+	service := NewInvoiceOutboxService(client, outboxRepo, resolutionRepo, NewContingencyService(newTestContingencyRepo()), testIssuer)
+
+	noteID := "note-1"
+	billCUFE := "cufe-1"
+	entry := &dto.InvoiceOutboxEntry{
+		ID:           "entry-1",
+		DocumentType: dto.ElectronicDocumentTypeDebitNote,
+		BillID:       "bill-1",
+		NoteID:       &noteID,
+		Prefix:       "ND",
+		Consecutive:  2,
+		DebitNoteRequest: &dto.CreateElectronicDebitNoteRequest{
+			Prefix:      "ND",
+			Consecutive: 2,
+			Bill:        &dto.Bill{ID: "bill-1", Prefix: "SETP", Consecutive: 1, CUFE: &billCUFE},
+			DebitNote:   &dto.DebitNote{ID: noteID},
+		},
+		Status: dto.InvoiceOutboxStatusPending,
+	}
+	response := &dto.CreateElectronicInvoiceResponse{Tascode: "tascode-nd", CUFE: "cude-nd"}
+
+	outboxRepo.On("ClaimDue", ctx, 20).Return([]*dto.InvoiceOutboxEntry{entry}, nil)
+	client.On("Send", ctx, mock.MatchedBy(func(document *dto.ElectronicDocument) bool {
+		return document.Type == dto.ElectronicDocumentTypeDebitNote && document.Reference.CUFE == "cufe-1"
+	})).Return(response, nil)
+	outboxRepo.On("MarkSent", ctx, entry, response, "").Return(nil)
+
+	sent, err := service.DispatchPending(ctx, 20)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	client.AssertExpectations(t)
+	outboxRepo.AssertExpectations(t)
+	resolutionRepo.AssertNotCalled(t, "FindByNumber", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDispatchPending_MissingResolutionDoesNotBlockTheQueue(t *testing.T) {
+	ctx := context.Background()
+	client := new(MockElectronicInvoiceClient)
+	outboxRepo := new(MockInvoiceOutboxRepository)
+	resolutionRepo := new(MockNumberingResolutionRepository)
+	service := NewInvoiceOutboxService(client, outboxRepo, resolutionRepo, NewContingencyService(newTestContingencyRepo()), testIssuer)
+
+	orphan := createTestOutboxEntry("entry-1", 1, 0)
+	orphan.Prefix = "OLD"
+	orphan.Request.Prefix = "OLD"
+	last := createTestOutboxEntry("entry-2", 2, maxInvoiceDispatchAttempts-1)
+	last.Prefix = "OLD"
+	last.Request.Prefix = "OLD"
+	next := createTestOutboxEntry("entry-3", 3, 0)
+	response := &dto.CreateElectronicInvoiceResponse{Tascode: "tascode-3", CUFE: "cufe-3"}
+
+	outboxRepo.On("ClaimDue", ctx, 20).Return([]*dto.InvoiceOutboxEntry{orphan, last, next}, nil)
+	resolutionRepo.On("FindByNumber", ctx, "OLD", mock.Anything).Return(nil, errors.New("record not found"))
+	resolutionRepo.On("FindByNumber", ctx, "SETP", 3).Return(createTestResolution("resolution-1", 1, 5000, 0, time.Hour), nil)
+	outboxRepo.On("MarkFailed", ctx, "entry-1", 1, "record not found", mock.AnythingOfType("time.Time")).Return(nil)
+	outboxRepo.On("MarkDead", ctx, "entry-2", maxInvoiceDispatchAttempts, "record not found").Return(nil)
+	client.On("Send", ctx, documentOf(next)).Return(response, nil)
+	outboxRepo.On("MarkSent", ctx, next, response, expectedCUFEOf(next)).Return(nil)
+
+	sent, err := service.DispatchPending(ctx, 20)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	client.AssertNotCalled(t, "Send", ctx, documentOf(orphan))
+	outboxRepo.AssertExpectations(t)
+}
+
+func TestDispatchPending_FailedInvoiceIsRetriedWithBackoff(t *testing.T) {
+	ctx := context.Background()
+	client := new(MockElectronicInvoiceClient)
+	outboxRepo := new(MockInvoiceOutboxRepository)
+	service := NewInvoiceOutboxService(client, outboxRepo, newTestResolutionRepo(), NewContingencyService(newTestContingencyRepo()), testIssuer)
+
+	failing := createTestOutboxEntry("entry-1", 1, 2)
+	next := createTestOutboxEntry("entry-2", 2, 0)
+	response := &dto.CreateElectronicInvoiceResponse{Tascode: "tascode-2", CUFE: "cufe-2"}
+
+	start := time.Now()
+	outboxRepo.On("ClaimDue", ctx, 20).Return([]*dto.InvoiceOutboxEntry{failing, next}, nil)
+	client.On("Send", ctx, documentOf(failing)).Return(nil, errors.New("invoice API returned status 503"))
+	client.On("Send", ctx, documentOf(next)).Return(response, nil)
+	// Third attempt, the next one waits 30s * 2^2
+	outboxRepo.On("MarkFailed", ctx, "entry-1", 3, "invoice API returned status 503", mock.MatchedBy(func(nextAttemptAt time.Time) bool {
+		return !nextAttemptAt.Before(start.Add(2*time.Minute)) && nextAttemptAt.Before(time.Now().Add(2*time.Minute+time.Second))
+	})).Return(nil)
+	outboxRepo.On("MarkSent", ctx, next, response, expectedCUFEOf(next)).Return(nil)
+
+	sent, err := service.DispatchPending(ctx, 20)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	outboxRepo.AssertExpectations(t)
+	outboxRepo.AssertNotCalled(t, "MarkDead", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDispatchPending_DeadLettersAfterLastAttempt(t *testing.T) {
+	ctx := context.Background()
+	client := new(MockElectronicInvoiceClient)
+	outboxRepo := new(MockInvoiceOutboxRepository)
+	service := NewInvoiceOutboxService(client, outboxRepo, newTestResolutionRepo(), NewContingencyService(newTestContingencyRepo()), testIssuer)
+
+	entry := createTestOutboxEntry("entry-1", 1, maxInvoiceDispatchAttempts-1)
+
+	outboxRepo.On("ClaimDue", ctx, 20).Return([]*dto.InvoiceOutboxEntry{entry}, nil)
+	client.On("Send", ctx, documentOf(entry)).Return(nil, errors.New("invoice API error: invalid NIT"))
+	outboxRepo.On("MarkDead", ctx, "entry-1", maxInvoiceDispatchAttempts, "invoice API error: invalid NIT").Return(nil)
+
+	sent, err := service.DispatchPending(ctx, 20)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	outboxRepo.AssertExpectations(t)
+	outboxRepo.AssertNotCalled(t, "MarkFailed", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// The example of the DIAN technical annex, the provider computes the same CUFE
+func TestExpectedInvoiceCUFE_MatchesDIANExample(t *testing.T) {
+	ctx := context.Background()
+	resolutionRepo := new(MockNumberingResolutionRepository)
+	issuer := bill.Issuer{NIT: "700085371", Environment: dto.DIANEnvironmentProduction}
+
+	resolution := createTestResolution("resolution-1", 1, 5000, 0, time.Hour)
+	resolution.TechnicalKey = "693ff6f2a553c3646a063436fd4dd9ded0311471"
+	resolutionRepo.On("FindByNumber", ctx, "323200000", 129).Return(resolution, nil)
+
+	document := &dto.ElectronicDocument{
+		Type:        dto.ElectronicDocumentTypeInvoice,
+		Prefix:      "323200000",
+		Consecutive: 129,
+		IssuedAt:    time.Date(2019, 1, 16, 15, 53, 10, 0, time.UTC),
+		Customer:    dto.Customer{DocumentNumber: "800199436", DocumentType: dto.DocumentTypeNIT},
+		Lines: []dto.BillProduct{{
+			Quantity:  1,
+			UnitPrice: dto.NewMoneyFromFloat(1500000),
+			Taxes:     []dto.InvoiceTax{{TaxCode: dto.TaxCodeVAT, TaxAmount: dto.NewMoneyFromFloat(285000), Percent: "19.00"}},
+		}},
+		Totals: dto.ElectronicDocumentTotals{
+			LineAmount: dto.NewMoneyFromFloat(1500000),
+			TaxAmount:  dto.NewMoneyFromFloat(285000),
+			PayAmount:  dto.NewMoneyFromFloat(1785000),
+		},
+	}
+
+	cufe, err := expectedInvoiceCUFE(ctx, resolutionRepo, issuer, document)
+
+	require.NoError(t, err)
+	assert.Equal(t, "8bb918b19ba22a694f1da11c643b5e9de39adf60311cf179179e9b33381030bcd4c3c3f156c506ed5908f9276f5bd9b4", cufe)
+}
+
+func TestDispatchPending_ClaimError(t *testing.T) {
+	ctx := context.Background()
+	client := new(MockElectronicInvoiceClient)
+	outboxRepo := new(MockInvoiceOutboxRepository)
+	service := NewInvoiceOutboxService(client, outboxRepo, newTestResolutionRepo(), NewContingencyService(newTestContingencyRepo()), testIssuer)
+
+	outboxRepo.On("ClaimDue", ctx, 20).Return(nil, errors.New("connection refused"))
+
+	sent, err := service.DispatchPending(ctx, 20)
+
+	assert.ErrorIs(t, err, domainError.ErrInvoiceDispatchFailed)
+	assert.Equal(t, 0, sent)
+	client.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+}
+
+func TestDispatchPending_NoDeadLetterWhileInContingency(t *testing.T) {
+	ctx := context.Background()
+	client := new(MockElectronicInvoiceClient)
+	outboxRepo := new(MockInvoiceOutboxRepository)
+	contingencyRepo := new(MockContingencyRepository)
+	service := NewInvoiceOutboxService(client, outboxRepo, newTestResolutionRepo(), NewContingencyService(contingencyRepo), testIssuer)
+
+	entry := createTestOutboxEntry("entry-1", 1, maxInvoiceDispatchAttempts+4)
+	untouched := createTestOutboxEntry("entry-2", 2, 0)
+
+	start := time.Now()
+	outboxRepo.On("ClaimDue", ctx, 20).Return([]*dto.InvoiceOutboxEntry{entry, untouched}, nil)
+	client.On("Send", ctx, documentOf(entry)).Return(nil, errProviderDown)
+	contingencyRepo.On("FindActive", ctx).Return(createTestContingencyPeriod("period-1", true), nil)
+	// Retried within the contingency delay instead of the hour of the regular backoff
+	outboxRepo.On("MarkFailed", ctx, "entry-1", maxInvoiceDispatchAttempts+5, errProviderDown.Error(), mock.MatchedBy(func(nextAttemptAt time.Time) bool {
+		return !nextAttemptAt.Before(start.Add(contingencyDispatchMaxDelay)) && nextAttemptAt.Before(time.Now().Add(contingencyDispatchMaxDelay+time.Second))
+	})).Return(nil)
+
+	sent, err := service.DispatchPending(ctx, 20)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	outboxRepo.AssertExpectations(t)
+	outboxRepo.AssertNotCalled(t, "MarkDead", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	// The provider is down, the rest of the batch waits for the next run
+	client.AssertNotCalled(t, "Send", ctx, documentOf(untouched))
+}
+
+func TestDispatchPending_ReleasesRetriesWhenContingencyEnds(t *testing.T) {
+	ctx := context.Background()
+	client := new(MockElectronicInvoiceClient)
+	outboxRepo := new(MockInvoiceOutboxRepository)
+	contingencyRepo := new(MockContingencyRepository)
+	service := NewInvoiceOutboxService(client, outboxRepo, newTestResolutionRepo(), NewContingencyService(contingencyRepo), testIssuer)
+
+	entry := createTestOutboxEntry("entry-1", 1, 3)
+	response := &dto.CreateElectronicInvoiceResponse{Tascode: "tascode-1", CUFE: "cufe-1"}
+
+	outboxRepo.On("ClaimDue", ctx, 20).Return([]*dto.InvoiceOutboxEntry{entry}, nil)
+	client.On("Send", ctx, documentOf(entry)).Return(response, nil)
+	outboxRepo.On("MarkSent", ctx, entry, response, expectedCUFEOf(entry)).Return(nil)
+	contingencyRepo.On("FindActive", ctx).Return(createTestContingencyPeriod("period-1", true), nil)
+	contingencyRepo.On("End", ctx, "period-1", mock.AnythingOfType("time.Time")).Return(nil)
+	outboxRepo.On("ReleaseRetries", ctx).Return(nil)
+
+	sent, err := service.DispatchPending(ctx, 20)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	outboxRepo.AssertExpectations(t)
+	contingencyRepo.AssertExpectations(t)
+}
+
+func TestDispatchPending_SendsInvoiceDocument(t *testing.T) {
+	ctx := context.Background()
+	client := new(MockElectronicInvoiceClient)
+	outboxRepo := new(MockInvoiceOutboxRepository)
+	service := NewInvoiceOutboxService(client, outboxRepo, newTestResolutionRepo(), NewContingencyService(newTestContingencyRepo()), testIssuer)
+
+	issuedAt := time.Now().Add(-2 * time.Hour)
+	entry := createTestOutboxEntry("entry-1", 7, 0)
+	entry.Request.Contingency = true
+	entry.Request.PaymentCode = dto.ElectronicInvoicePaymentCodeCash
+	entry.Request.Bill = &dto.Bill{
+		ID:          "bill-entry-1",
+		TotalAmount: dto.NewMoneyFromFloat(10000),
+		TaxAmount:   dto.NewMoneyFromFloat(1900),
+		Tip:         dto.NewMoneyFromFloat(1000),
+		PayAmount:   dto.NewMoneyFromFloat(12900),
+		Payments: []dto.Payment{
+			{Method: dto.ElectronicInvoicePaymentCodeCash, Amount: dto.NewMoneyFromFloat(10000), Change: dto.NewMoneyFromFloat(2100)},
+			{Method: dto.ElectronicInvoicePaymentCodeCreditCard, Amount: dto.NewMoneyFromFloat(5000), Reference: "auth-1"},
+		},
+		CreatedAt: issuedAt,
+	}
+	response := &dto.CreateElectronicInvoiceResponse{Tascode: "tascode-1", CUFE: "cufe-1"}
+
+	var sentDocument *dto.ElectronicDocument
+	outboxRepo.On("ClaimDue", ctx, 20).Return([]*dto.InvoiceOutboxEntry{entry}, nil)
+	client.On("Send", ctx, mock.AnythingOfType("*dto.ElectronicDocument")).
+		Run(func(args mock.Arguments) { sentDocument = args.Get(1).(*dto.ElectronicDocument) }).
+		Return(response, nil)
+	outboxRepo.On("MarkSent", ctx, entry, response, expectedCUFEOf(entry)).Return(nil)
+
+	sent, err := service.DispatchPending(ctx, 20)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	require.NotNil(t, sentDocument)
+	assert.Equal(t, dto.ElectronicDocumentTypeContingencyInvoice, sentDocument.Type)
+	assert.Equal(t, "SETP", sentDocument.Prefix)
+	assert.Equal(t, 7, sentDocument.Consecutive)
+	// The invoice keeps the date of the sale, not the date it was sent
+	assert.True(t, issuedAt.Equal(sentDocument.IssuedAt))
+	// Bills without customer are issued to the final consumer
+	assert.Equal(t, "222222222222", sentDocument.Customer.DocumentNumber)
+	assert.Equal(t, dto.NewMoneyFromFloat(1000), sentDocument.Totals.Tip)
+	assert.Equal(t, dto.NewMoneyFromFloat(12900), sentDocument.Totals.PayAmount)
+	// The change given back is not part of what was paid in cash
+	require.Len(t, sentDocument.PaymentMeans, 2)
+	assert.Equal(t, dto.NewMoneyFromFloat(7900), sentDocument.PaymentMeans[0].Amount)
+	assert.Equal(t, "auth-1", sentDocument.PaymentMeans[1].Reference)
+	assert.Nil(t, sentDocument.Reference)
+}
+
+func TestInvoiceDispatchDelay(t *testing.T) {
+	assert.Equal(t, 30*time.Second, invoiceDispatchDelay(1))
+	assert.Equal(t, time.Minute, invoiceDispatchDelay(2))
+	assert.Equal(t, 4*time.Minute, invoiceDispatchDelay(4))
+	assert.Equal(t, time.Hour, invoiceDispatchDelay(20))
+}
+
+// RetryDeadLetter Tests
+
+func TestRetryDeadLetter_Success(t *testing.T) {
+	ctx := context.Background()
+	outboxRepo := new(MockInvoiceOutboxRepository)
+	service := NewInvoiceOutboxService(nil, outboxRepo, nil, nil, testIssuer)
+
+	outboxRepo.On("Requeue", ctx, "entry-1").Return(nil)
+
+	err := service.RetryDeadLetter(ctx, "entry-1")
+
+	require.NoError(t, err)
+	outboxRepo.AssertExpectations(t)
+}
+
+func TestRetryDeadLetter_NotFound(t *testing.T) {
+	ctx := context.Background()
+	outboxRepo := new(MockInvoiceOutboxRepository)
+	service := NewInvoiceOutboxService(nil, outboxRepo, nil, nil, testIssuer)
+
+	outboxRepo.On("Requeue", ctx, "entry-1").Return(errors.New("record not found"))
+
+	err := service.RetryDeadLetter(ctx, "entry-1")
+
+	assert.ErrorIs(t, err, domainError.ErrOutboxEntryNotFound)
+}
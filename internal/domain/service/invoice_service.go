@@ -2,44 +2,74 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"laguna-escondida/backend/internal/domain/aggregate/bill"
 	"laguna-escondida/backend/internal/domain/dto"
+	domainError "laguna-escondida/backend/internal/domain/error"
 	"laguna-escondida/backend/internal/domain/ports"
 
 	"github.com/samber/lo"
 )
 
+const (
+	defaultBillsPageSize = 20
+	maxBillsPageSize     = 100
+)
+
 type InvoiceService struct {
 	electronicInvoiceClient ports.ElectronicInvoiceClient
 	productRepo             ports.ProductRepository
 	billRepo                ports.BillRepository
+	creditNoteRepo          ports.CreditNoteRepository
+	debitNoteRepo           ports.DebitNoteRepository
+	contingencyRepo         ports.ContingencyRepository
+	issuer                  bill.Issuer
 }
 
 func NewInvoiceService(
 	electronicInvoiceClient ports.ElectronicInvoiceClient,
 	productRepo ports.ProductRepository,
 	billRepo ports.BillRepository,
+	creditNoteRepo ports.CreditNoteRepository,
+	debitNoteRepo ports.DebitNoteRepository,
+	contingencyRepo ports.ContingencyRepository,
+	issuer bill.Issuer,
 ) *InvoiceService {
 	return &InvoiceService{
 		electronicInvoiceClient: electronicInvoiceClient,
 		productRepo:             productRepo,
 		billRepo:                billRepo,
+		creditNoteRepo:          creditNoteRepo,
+		debitNoteRepo:           debitNoteRepo,
+		contingencyRepo:         contingencyRepo,
+		issuer:                  issuer,
 	}
 }
 
-func (s *InvoiceService) CreateElectronicInvoice(ctx context.Context, invoice *dto.ElectronicInvoice) error {
-	products, err := s.productRepo.FindByIDs(ctx, lo.Map(invoice.Items, func(item dto.InvoiceItem, _ int) string {
+// CreateElectronicInvoice builds a bill from the invoice items and emits it
+// through the electronic invoice provider
+func (s *InvoiceService) CreateElectronicInvoice(ctx context.Context, invoice *dto.ElectronicInvoice) (*dto.Bill, error) {
+	products, err := s.productRepo.FindByIDs(ctx, lo.Uniq(lo.Map(invoice.Items, func(item dto.InvoiceItem, _ int) string {
 		return item.ProductID
-	}))
+	})))
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	bill, err := bill.NewBillFromCreateElectronicInvoiceRequest(invoice, lo.Map(invoice.Items, func(item dto.InvoiceItem, idx int) *bill.BillProduct {
-		product := products[idx]
+	productsByID := lo.KeyBy(products, func(product *dto.Product) string {
+		return product.ID
+	})
+
+	billProducts := make([]*bill.BillProduct, 0, len(invoice.Items))
+	for _, item := range invoice.Items {
+		product, ok := productsByID[item.ProductID]
+		if !ok {
+			return nil, domainError.ErrProductNotFound
+		}
 
-		return bill.NewBillProduct(
+		billProducts = append(billProducts, bill.NewBillProduct(
 			item.ProductID,
 			item.Quantity,
 			product.UnitPrice,
@@ -50,12 +80,475 @@ func (s *InvoiceService) CreateElectronicInvoice(ctx context.Context, invoice *d
 			item.Allowance,
 			product.VAT,
 			product.ICO,
-		)
-	}))
+		))
+	}
+
+	bill, err := bill.NewBillFromCreateElectronicInvoiceRequest(invoice, billProducts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrInvalidInvoice, err)
+	}
+
+	return s.issueBill(ctx, bill, products)
+}
+
+// CreateElectronicInvoiceFromLines emits an invoice for the lines of an order at the amounts
+// the order shows, each line is invoiced with its quantity at the unit price before taxes of
+// the order line and keeps the taxes extracted from its price with taxes
+func (s *InvoiceService) CreateElectronicInvoiceFromLines(ctx context.Context, invoice *dto.ElectronicInvoice, lines []dto.OrderLine) (*dto.Bill, error) {
+	billProducts := lo.Map(lines, func(line dto.OrderLine, _ int) *bill.BillProduct {
+		return orderLineBillProduct(line, line.Quantity, line.Product.UnitPrice)
+	})
+
+	return s.issueOrderLines(ctx, invoice, lines, billProducts)
+}
+
+// CreateElectronicInvoiceFromShares emits an invoice for order lines whose amounts were
+// already computed, such as the share of an order paid by one person after a split
+// Each line is invoiced once with its subtotal as unit price and keeps its own taxes
+func (s *InvoiceService) CreateElectronicInvoiceFromShares(ctx context.Context, invoice *dto.ElectronicInvoice, lines []dto.OrderLine) (*dto.Bill, error) {
+	billProducts := lo.Map(lines, func(line dto.OrderLine, _ int) *bill.BillProduct {
+		return orderLineBillProduct(line, 1, line.Subtotal)
+	})
+
+	return s.issueOrderLines(ctx, invoice, lines, billProducts)
+}
+
+func orderLineBillProduct(line dto.OrderLine, quantity int, unitPrice dto.Money) *bill.BillProduct {
+	product := line.Product
+	return bill.NewBillProductWithTaxes(
+		product.ID,
+		quantity,
+		unitPrice,
+		product.Description,
+		product.Brand,
+		product.Model,
+		product.SKU,
+		nil,
+		product.VAT,
+		product.ICO,
+		line.VAT,
+		line.ICO,
+	)
+}
 
+func (s *InvoiceService) issueOrderLines(ctx context.Context, invoice *dto.ElectronicInvoice, lines []dto.OrderLine, billProducts []*bill.BillProduct) (*dto.Bill, error) {
+	products := lo.Map(lines, func(line dto.OrderLine, _ int) *dto.Product {
+		product := line.Product
+		return &product
+	})
+
+	bill, err := bill.NewBillFromCreateElectronicInvoiceRequest(invoice, billProducts)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("%w: %w", domainError.ErrInvalidInvoice, err)
+	}
+
+	return s.issueBill(ctx, bill, products)
+}
+
+// issueBill saves the bill and queues its invoice. While the provider is unreachable the bill
+// is issued in the open contingency period and comes back with the provisional document to print
+// The CUFE is computed locally with the technical key of the resolution the bill was numbered
+// from, so the QR code can be printed without waiting for the provider
+func (s *InvoiceService) issueBill(ctx context.Context, issued *bill.Aggregate, products []*dto.Product) (*dto.Bill, error) {
+	period, err := s.contingencyRepo.FindActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if period != nil {
+		// The provisional document is worthless without its CUFE, the bill is not even saved
+		if s.issuer.NIT == "" {
+			return nil, domainError.ErrIssuerNotConfigured
+		}
+		issued.MarkContingency(period.ID)
+	}
+
+	if err := s.billRepo.Create(ctx, issued, products); err != nil {
+		return nil, err
+	}
+
+	billDTO := issued.ToDTO()
+
+	cufe := issued.ExpectedCUFE(s.issuer)
+	if cufe != "" {
+		qrPayload := bill.InvoiceQRPayload(issued.InvoiceDocument(), s.issuer, cufe)
+		billDTO.ExpectedCUFE = &cufe
+		billDTO.QRPayload = &qrPayload
+	}
+
+	if period != nil {
+		provisionalDocument := issued.ProvisionalDocument(cufe)
+		billDTO.ProvisionalDocument = &provisionalDocument
+	}
+
+	return billDTO, nil
+}
+
+// CreateCreditNote reverses an issued bill with a credit note referencing its CUFE
+// Without items the credit note covers everything not credited yet, including the tip,
+// otherwise only the given quantities are credited
+func (s *InvoiceService) CreateCreditNote(ctx context.Context, billID string, req *dto.CreateCreditNoteRequest) (*dto.CreditNote, error) {
+	original, err := s.billRepo.FindByID(ctx, billID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrBillNotFound, err)
+	}
+
+	if original.CUFE == nil || *original.CUFE == "" {
+		return nil, domainError.ErrBillNotIssued
+	}
+
+	billed, err := s.billRepo.FindProductsByID(ctx, billID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrCreditNoteFailed, err)
+	}
+
+	previous, err := s.creditNoteRepo.FindByBillID(ctx, billID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrCreditNoteFailed, err)
+	}
+
+	// Lines are credited at the price and taxes they were billed with, not the current catalog ones
+	remaining, creditable, tip := creditableProducts(original, billed, previous)
+
+	billProducts := remaining
+	if len(req.Items) > 0 {
+		if req.Reason == dto.CreditNoteReasonCancellation {
+			return nil, fmt.Errorf("%w: a cancellation credits the whole bill", domainError.ErrInvalidCreditNote)
+		}
+
+		billProducts, err = selectCreditNoteProducts(remaining, req.Items)
+		if err != nil {
+			return nil, err
+		}
+		tip = 0
+	}
+
+	if len(billProducts) == 0 {
+		return nil, fmt.Errorf("%w: the bill is already fully credited", domainError.ErrInvalidCreditNote)
+	}
+
+	creditNote, err := bill.NewCreditNote(billID, req.Reason, req.Description, tip, creditable, billProducts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrInvalidCreditNote, err)
+	}
+
+	if err := s.creditNoteRepo.Create(ctx, creditNote, original); err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrCreditNoteFailed, err)
+	}
+
+	created, err := s.creditNoteRepo.FindByID(ctx, creditNote.ID())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrCreditNoteFailed, err)
+	}
+
+	return created, nil
+}
+
+// creditableProducts returns the billed lines or the part of them not credited yet, in
+// billing order, along with the amount and tip left to credit
+func creditableProducts(original *dto.Bill, billed []*bill.BillProduct, previous []*dto.CreditNote) ([]*bill.BillProduct, dto.Money, dto.Money) {
+	credited := map[string]int{}
+	creditable := original.PayAmount
+	tip := original.Tip
+	for _, creditNote := range previous {
+		for _, product := range creditNote.Products {
+			credited[product.ProductID] += product.Quantity
+		}
+		creditable = creditable.Sub(creditNote.PayAmount)
+		tip = tip.Sub(creditNote.Tip)
+	}
+
+	remaining := make([]*bill.BillProduct, 0, len(billed))
+	for _, product := range billed {
+		used := min(credited[product.ID()], product.Quantity())
+		credited[product.ID()] -= used
+		if product.Quantity() > used {
+			remaining = append(remaining, product.Portion(product.Quantity()-used))
+		}
+	}
+
+	return remaining, creditable, tip
+}
+
+// selectCreditNoteProducts validates the requested quantities against what is left to credit
+// and takes them from the remaining lines of each product in billing order
+func selectCreditNoteProducts(remaining []*bill.BillProduct, requested []dto.OrderProductItem) ([]*bill.BillProduct, error) {
+	available := map[string]int{}
+	for _, product := range remaining {
+		available[product.ID()] += product.Quantity()
+	}
+
+	items := make([]dto.OrderProductItem, 0, len(requested))
+	indexes := make(map[string]int, len(requested))
+	for _, item := range requested {
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: quantities must be greater than 0", domainError.ErrInvalidCreditNote)
+		}
+
+		if i, ok := indexes[item.ProductID]; ok {
+			items[i].Quantity += item.Quantity
+			continue
+		}
+		indexes[item.ProductID] = len(items)
+		items = append(items, item)
+	}
+
+	products := make([]*bill.BillProduct, 0, len(items))
+	for _, item := range items {
+		if item.Quantity > available[item.ProductID] {
+			return nil, fmt.Errorf("%w: product %s has only %d units left to credit", domainError.ErrInvalidCreditNote, item.ProductID, available[item.ProductID])
+		}
+
+		left := item.Quantity
+		for _, product := range remaining {
+			if left == 0 {
+				break
+			}
+			if product.ID() != item.ProductID {
+				continue
+			}
+
+			quantity := min(left, product.Quantity())
+			products = append(products, product.Portion(quantity))
+			left -= quantity
+		}
+	}
+
+	return products, nil
+}
+
+// CreateDebitNote adds charges to an issued bill with a debit note referencing its CUFE
+// Items of the catalog are charged at their current price, any other charge brings its
+// own description, price and taxes
+func (s *InvoiceService) CreateDebitNote(ctx context.Context, billID string, req *dto.CreateDebitNoteRequest) (*dto.DebitNote, error) {
+	original, err := s.billRepo.FindByID(ctx, billID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrBillNotFound, err)
+	}
+
+	if original.CUFE == nil || *original.CUFE == "" {
+		return nil, domainError.ErrBillNotIssued
+	}
+
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("%w: items are required", domainError.ErrInvalidDebitNote)
+	}
+
+	productIDs := make([]string, 0, len(req.Items))
+	for _, item := range req.Items {
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: quantities must be greater than 0", domainError.ErrInvalidDebitNote)
+		}
+
+		if item.ProductID != "" {
+			productIDs = append(productIDs, item.ProductID)
+			continue
+		}
+
+		if item.Description == "" || !item.UnitPrice.IsPositive() {
+			return nil, fmt.Errorf("%w: charges outside the catalog need a description and a unit price", domainError.ErrInvalidDebitNote)
+		}
+	}
+
+	products, err := s.productRepo.FindByIDs(ctx, lo.Uniq(productIDs))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrDebitNoteFailed, err)
+	}
+
+	productsByID := lo.KeyBy(products, func(product *dto.Product) string {
+		return product.ID
+	})
+
+	billProducts := make([]*bill.BillProduct, 0, len(req.Items))
+	for _, item := range req.Items {
+		if item.ProductID == "" {
+			description := item.Description
+			billProducts = append(billProducts, bill.NewBillProduct(
+				"",
+				item.Quantity,
+				item.UnitPrice,
+				&description,
+				nil,
+				nil,
+				item.Code,
+				nil,
+				item.VAT,
+				item.ICO,
+			))
+			continue
+		}
+
+		product, ok := productsByID[item.ProductID]
+		if !ok {
+			return nil, domainError.ErrProductNotFound
+		}
+
+		billProducts = append(billProducts, bill.NewBillProduct(
+			item.ProductID,
+			item.Quantity,
+			product.UnitPrice,
+			product.Description,
+			product.Brand,
+			product.Model,
+			product.SKU,
+			nil,
+			product.VAT,
+			product.ICO,
+		))
+	}
+
+	debitNote, err := bill.NewDebitNote(billID, req.Reason, req.Description, billProducts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrInvalidDebitNote, err)
+	}
+
+	if err := s.debitNoteRepo.Create(ctx, debitNote, original); err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrDebitNoteFailed, err)
+	}
+
+	created, err := s.debitNoteRepo.FindByID(ctx, debitNote.ID())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrDebitNoteFailed, err)
+	}
+
+	return created, nil
+}
+
+// GetBill returns an issued bill with its products, payments and customer
+func (s *InvoiceService) GetBill(ctx context.Context, billID string) (*dto.Bill, error) {
+	bill, err := s.billRepo.FindByID(ctx, billID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrBillNotFound, err)
+	}
+
+	return bill, nil
+}
+
+// ListBills returns a page of issued bills, the most recent first
+func (s *InvoiceService) ListBills(ctx context.Context, req *dto.ListBillsRequest) (*dto.BillListResponse, error) {
+	filter, err := normalizeListBillsRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	bills, err := s.billRepo.FindAll(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrBillListFailed, err)
+	}
+
+	total, err := s.billRepo.Count(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrBillListFailed, err)
+	}
+
+	return &dto.BillListResponse{
+		Bills:    bills,
+		Total:    total,
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+	}, nil
+}
+
+func normalizeListBillsRequest(req *dto.ListBillsRequest) (*dto.ListBillsRequest, error) {
+	filter := dto.ListBillsRequest{}
+	if req != nil {
+		filter = *req
+	}
+
+	for _, method := range filter.PaymentMethods {
+		switch method {
+		case dto.ElectronicInvoicePaymentCodeCreditCard, dto.ElectronicInvoicePaymentCodeDebitCard, dto.ElectronicInvoicePaymentCodeCash,
+			dto.ElectronicInvoicePaymentCodeTransferDebitBank, dto.ElectronicInvoicePaymentCodeTransferCreditBank, dto.ElectronicInvoicePaymentCodeTransferDebitInterbank:
+		default:
+			return nil, fmt.Errorf("%w: unknown payment method %q", domainError.ErrInvalidBillFilter, method)
+		}
+	}
+
+	for _, status := range filter.Statuses {
+		switch status {
+		case dto.ElectronicInvoiceDocumentStatusPending, dto.ElectronicInvoiceDocumentStatusAccepted, dto.ElectronicInvoiceDocumentStatusRejected:
+		default:
+			return nil, fmt.Errorf("%w: unknown status %q", domainError.ErrInvalidBillFilter, status)
+		}
+	}
+
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && filter.CreatedFrom.After(*filter.CreatedTo) {
+		return nil, fmt.Errorf("%w: created_from must be before created_to", domainError.ErrInvalidBillFilter)
+	}
+
+	if filter.Page < 0 || filter.PageSize < 0 {
+		return nil, fmt.Errorf("%w: page and page_size must be positive", domainError.ErrInvalidBillFilter)
+	}
+	if filter.Page == 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize == 0 {
+		filter.PageSize = defaultBillsPageSize
+	}
+	if filter.PageSize > maxBillsPageSize {
+		filter.PageSize = maxBillsPageSize
+	}
+
+	return &filter, nil
+}
+
+// RefreshBillStatus asks the provider for the DIAN status of an issued bill and stores
+// it with the document URL, the PDF and the XML so the official document can be reprinted
+func (s *InvoiceService) RefreshBillStatus(ctx context.Context, billID string) (*dto.Bill, error) {
+	existingBill, err := s.billRepo.FindByID(ctx, billID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrBillNotFound, err)
+	}
+
+	if existingBill.Tascode == nil || *existingBill.Tascode == "" {
+		return nil, domainError.ErrBillNotIssued
+	}
+
+	status, err := s.electronicInvoiceClient.Get(ctx, *existingBill.Tascode)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrBillStatusFailed, err)
+	}
+
+	if err := s.billRepo.UpdateDocumentStatus(ctx, billID, status); err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrBillStatusFailed, err)
+	}
+
+	refreshed, err := s.billRepo.FindByID(ctx, billID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrBillStatusFailed, err)
+	}
+
+	return refreshed, nil
+}
+
+// ReconcilePendingBills syncs the DIAN status of up to limit bills still pending at the provider
+// and returns how many were updated. It stops at the first provider error so the caller can back
+// off, a provider that does not report the status is not an error and leaves the bills pending
+func (s *InvoiceService) ReconcilePendingBills(ctx context.Context, limit int) (int, error) {
+	pendingBills, err := s.billRepo.FindPendingStatus(ctx, limit)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", domainError.ErrBillStatusFailed, err)
+	}
+
+	reconciled := 0
+	for _, pendingBill := range pendingBills {
+		if err := ctx.Err(); err != nil {
+			return reconciled, err
+		}
+
+		status, err := s.electronicInvoiceClient.Get(ctx, lo.FromPtr(pendingBill.Tascode))
+		if errors.Is(err, domainError.ErrStatusUnsupported) {
+			return reconciled, nil
+		}
+		if err != nil {
+			return reconciled, fmt.Errorf("%w: bill %s: %w", domainError.ErrBillStatusFailed, pendingBill.ID, err)
+		}
+
+		if err := s.billRepo.UpdateDocumentStatus(ctx, pendingBill.ID, status); err != nil {
+			return reconciled, fmt.Errorf("%w: bill %s: %w", domainError.ErrBillStatusFailed, pendingBill.ID, err)
+		}
+
+		reconciled++
 	}
 
-	return s.billRepo.Create(ctx, bill, products)
+	return reconciled, nil
 }
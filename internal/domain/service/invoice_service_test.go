@@ -0,0 +1,1291 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"laguna-escondida/backend/internal/domain/aggregate/bill"
+	"laguna-escondida/backend/internal/domain/dto"
+	domainError "laguna-escondida/backend/internal/domain/error"
+	"laguna-escondida/backend/internal/domain/ports"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockBillRepository is a mock implementation of ports.BillRepository
+type MockBillRepository struct {
+	mock.Mock
+}
+
+func (m *MockBillRepository) Create(ctx context.Context, bill *bill.Aggregate, products []*dto.Product) error {
+	args := m.Called(ctx, bill, products)
+	return args.Error(0)
+}
+
+func (m *MockBillRepository) FindByID(ctx context.Context, id string) (*dto.Bill, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.Bill), args.Error(1)
+}
+
+func (m *MockBillRepository) FindAll(ctx context.Context, filter *dto.ListBillsRequest) ([]*dto.Bill, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*dto.Bill), args.Error(1)
+}
+
+func (m *MockBillRepository) Count(ctx context.Context, filter *dto.ListBillsRequest) (int, error) {
+	args := m.Called(ctx, filter)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockBillRepository) FindProductsByID(ctx context.Context, id string) ([]*bill.BillProduct, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*bill.BillProduct), args.Error(1)
+}
+
+func (m *MockBillRepository) UpdateDocumentStatus(ctx context.Context, id string, status *dto.ElectronicInvoiceStatus) error {
+	args := m.Called(ctx, id, status)
+	return args.Error(0)
+}
+
+func (m *MockBillRepository) FindPendingStatus(ctx context.Context, limit int) ([]*dto.Bill, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*dto.Bill), args.Error(1)
+}
+
+// MockElectronicInvoiceClient is a mock implementation of ports.ElectronicInvoiceClient
+type MockElectronicInvoiceClient struct {
+	mock.Mock
+}
+
+func (m *MockElectronicInvoiceClient) Send(ctx context.Context, document *dto.ElectronicDocument) (*dto.CreateElectronicInvoiceResponse, error) {
+	args := m.Called(ctx, document)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.CreateElectronicInvoiceResponse), args.Error(1)
+}
+
+func (m *MockElectronicInvoiceClient) Get(ctx context.Context, tascode string) (*dto.ElectronicInvoiceStatus, error) {
+	args := m.Called(ctx, tascode)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ElectronicInvoiceStatus), args.Error(1)
+}
+
+// MockCreditNoteRepository is a mock implementation of ports.CreditNoteRepository
+type MockCreditNoteRepository struct {
+	mock.Mock
+}
+
+func (m *MockCreditNoteRepository) Create(ctx context.Context, creditNote *bill.CreditNote, original *dto.Bill) error {
+	args := m.Called(ctx, creditNote, original)
+	return args.Error(0)
+}
+
+func (m *MockCreditNoteRepository) FindByID(ctx context.Context, id string) (*dto.CreditNote, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.CreditNote), args.Error(1)
+}
+
+func (m *MockCreditNoteRepository) FindByBillID(ctx context.Context, billID string) ([]*dto.CreditNote, error) {
+	args := m.Called(ctx, billID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*dto.CreditNote), args.Error(1)
+}
+
+// MockDebitNoteRepository is a mock implementation of ports.DebitNoteRepository
+type MockDebitNoteRepository struct {
+	mock.Mock
+}
+
+func (m *MockDebitNoteRepository) Create(ctx context.Context, debitNote *bill.DebitNote, original *dto.Bill) error {
+	args := m.Called(ctx, debitNote, original)
+	return args.Error(0)
+}
+
+func (m *MockDebitNoteRepository) FindByID(ctx context.Context, id string) (*dto.DebitNote, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.DebitNote), args.Error(1)
+}
+
+// Test helpers
+func createTestInvoiceService(productRepo ports.ProductRepository, billRepo ports.BillRepository) *InvoiceService {
+	return NewInvoiceService(nil, productRepo, billRepo, nil, nil, newTestContingencyRepo(), testIssuer)
+}
+
+func createTestDebitNoteService(productRepo ports.ProductRepository, billRepo ports.BillRepository, debitNoteRepo ports.DebitNoteRepository) *InvoiceService {
+	return NewInvoiceService(nil, productRepo, billRepo, nil, debitNoteRepo, newTestContingencyRepo(), testIssuer)
+}
+
+func createTestStatusService(client ports.ElectronicInvoiceClient, billRepo ports.BillRepository) *InvoiceService {
+	return NewInvoiceService(client, nil, billRepo, nil, nil, newTestContingencyRepo(), testIssuer)
+}
+
+func createTestCreditNoteService(productRepo ports.ProductRepository, billRepo ports.BillRepository, creditNoteRepo ports.CreditNoteRepository) *InvoiceService {
+	return NewInvoiceService(nil, productRepo, billRepo, creditNoteRepo, nil, newTestContingencyRepo(), testIssuer)
+}
+
+// createTestIssuedBill returns a bill issued to the DIAN for two units of a 100.00 product
+// with 19% VAT and a 10.00 tip
+func createTestIssuedBill(id string) *dto.Bill {
+	cufe := "cufe-" + id
+	return &dto.Bill{
+		ID:          id,
+		Prefix:      "SETP",
+		Consecutive: 990000001,
+		CUFE:        &cufe,
+		TotalAmount: dto.NewMoneyFromFloat(200.0),
+		TaxAmount:   dto.NewMoneyFromFloat(38.0),
+		VAT:         dto.NewMoneyFromFloat(38.0),
+		Tip:         dto.NewMoneyFromFloat(10.0),
+		PayAmount:   dto.NewMoneyFromFloat(248.0),
+	}
+}
+
+func createTestInvoiceProduct(id string, unitPrice, vat, ico float64) *dto.Product {
+	product := createTestProduct(id, "Product "+id, "Category", 1, unitPrice*(1+vat+ico), vat)
+	product.UnitPrice = dto.NewMoneyFromFloat(unitPrice)
+	product.ICO = ico
+	product.SKU = "SKU-" + id
+	return product
+}
+
+// createTestBilledProducts is the line of createTestIssuedBill as it was billed
+func createTestBilledProducts() []*bill.BillProduct {
+	product := createTestInvoiceProduct("product-1", 100.0, 0.19, 0.0)
+	return []*bill.BillProduct{
+		bill.NewBillProduct(product.ID, 2, product.UnitPrice, product.Description, product.Brand, product.Model, product.SKU, nil, product.VAT, product.ICO),
+	}
+}
+
+// CreateElectronicInvoice Tests
+
+// Success Cases
+func TestCreateElectronicInvoice_Success(t *testing.T) {
+	ctx := context.Background()
+	mockProductRepo := new(MockProductRepository)
+	mockBillRepo := new(MockBillRepository)
+	service := createTestInvoiceService(mockProductRepo, mockBillRepo)
+
+	product1 := createTestInvoiceProduct("product-1", 100.0, 0.19, 0.0)
+	product2 := createTestInvoiceProduct("product-2", 50.0, 0.0, 0.08)
+
+	invoice := &dto.ElectronicInvoice{
+		PaymentCode: dto.ElectronicInvoicePaymentCodeDebitCard,
+		Items: []dto.InvoiceItem{
+			{ProductID: "product-1", Quantity: 2},
+			{ProductID: "product-2", Quantity: 1},
+		},
+	}
+
+	// Products are returned in a different order than requested
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1", "product-2"}).Return([]*dto.Product{product2, product1}, nil)
+	mockBillRepo.On("Create", ctx, mock.AnythingOfType("*bill.Aggregate"), []*dto.Product{product2, product1}).Return(nil)
+
+	result, err := service.CreateElectronicInvoice(ctx, invoice)
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.ID)
+	assert.Equal(t, dto.NewMoneyFromFloat(250.0), result.TotalAmount)
+	assert.Equal(t, dto.NewMoneyFromFloat(38.0), result.VAT)
+	assert.Equal(t, dto.NewMoneyFromFloat(4.0), result.ICO)
+	assert.Equal(t, dto.NewMoneyFromFloat(292.0), result.PayAmount)
+	require.Len(t, result.Products, 2)
+	assert.Equal(t, "product-1", result.Products[0].ProductID)
+	assert.Equal(t, dto.NewMoneyFromFloat(100.0), result.Products[0].UnitPrice)
+	assert.Equal(t, "product-2", result.Products[1].ProductID)
+	assert.Equal(t, dto.NewMoneyFromFloat(50.0), result.Products[1].UnitPrice)
+
+	mockProductRepo.AssertExpectations(t)
+	mockBillRepo.AssertExpectations(t)
+}
+
+func TestCreateElectronicInvoice_InContingency(t *testing.T) {
+	ctx := context.Background()
+	mockProductRepo := new(MockProductRepository)
+	mockBillRepo := new(MockBillRepository)
+	contingencyRepo := new(MockContingencyRepository)
+	service := NewInvoiceService(nil, mockProductRepo, mockBillRepo, nil, nil, contingencyRepo, testIssuer)
+
+	product := createTestInvoiceProduct("product-1", 100.0, 0.19, 0.0)
+	invoice := &dto.ElectronicInvoice{
+		PaymentCode: dto.ElectronicInvoicePaymentCodeCash,
+		Items:       []dto.InvoiceItem{{ProductID: "product-1", Quantity: 1}},
+	}
+
+	contingencyRepo.On("FindActive", ctx).Return(createTestContingencyPeriod("period-1", true), nil)
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1"}).Return([]*dto.Product{product}, nil)
+	mockBillRepo.On("Create", ctx, mock.MatchedBy(func(bill *bill.Aggregate) bool {
+		return bill.ContingencyPeriodID() != nil && *bill.ContingencyPeriodID() == "period-1"
+	}), []*dto.Product{product}).Run(func(args mock.Arguments) {
+		args.Get(1).(*bill.Aggregate).AssignNumber("CONT", 12, "fc8eac422eba16e22ffd8c6f94b3f40a6e38162c")
+	}).Return(nil)
+
+	result, err := service.CreateElectronicInvoice(ctx, invoice)
+
+	require.NoError(t, err)
+	require.NotNil(t, result.ContingencyPeriodID)
+	assert.Equal(t, "period-1", *result.ContingencyPeriodID)
+	require.NotNil(t, result.ProvisionalDocument)
+	assert.Contains(t, *result.ProvisionalDocument, "CONTINGENCIA")
+	// The QR code is printed with the CUFE computed locally, the DIAN has not seen the invoice yet
+	require.NotNil(t, result.ExpectedCUFE)
+	assert.Len(t, *result.ExpectedCUFE, 96)
+	assert.Contains(t, *result.ProvisionalDocument, (*result.ExpectedCUFE)[:40])
+	require.NotNil(t, result.QRPayload)
+	assert.Contains(t, *result.QRPayload, "NumFac: CONT12\n")
+	assert.Contains(t, *result.QRPayload, "NitFac: 900123456\n")
+	assert.Contains(t, *result.QRPayload, "DocAdq: 222222222222\n")
+	assert.Contains(t, *result.QRPayload, "ValIva: 19.00\n")
+	assert.Contains(t, *result.QRPayload, "documentkey="+*result.ExpectedCUFE)
+	mockBillRepo.AssertExpectations(t)
+}
+
+func TestCreateElectronicInvoice_WithoutNITHasNoQR(t *testing.T) {
+	ctx := context.Background()
+	mockProductRepo := new(MockProductRepository)
+	mockBillRepo := new(MockBillRepository)
+	service := NewInvoiceService(nil, mockProductRepo, mockBillRepo, nil, nil, newTestContingencyRepo(), bill.Issuer{})
+
+	product := createTestInvoiceProduct("product-1", 100.0, 0.19, 0.0)
+	invoice := &dto.ElectronicInvoice{
+		PaymentCode: dto.ElectronicInvoicePaymentCodeCash,
+		Items:       []dto.InvoiceItem{{ProductID: "product-1", Quantity: 1}},
+	}
+
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1"}).Return([]*dto.Product{product}, nil)
+	mockBillRepo.On("Create", ctx, mock.AnythingOfType("*bill.Aggregate"), []*dto.Product{product}).Run(func(args mock.Arguments) {
+		args.Get(1).(*bill.Aggregate).AssignNumber("LAG", 12, "fc8eac422eba16e22ffd8c6f94b3f40a6e38162c")
+	}).Return(nil)
+
+	result, err := service.CreateElectronicInvoice(ctx, invoice)
+
+	// Outside contingency the invoice is issued anyway, the provider answers its CUFE
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.ID)
+	assert.Nil(t, result.ExpectedCUFE)
+	assert.Nil(t, result.QRPayload)
+}
+
+func TestCreateElectronicInvoice_InContingencyWithoutNITIsNotSaved(t *testing.T) {
+	ctx := context.Background()
+	mockProductRepo := new(MockProductRepository)
+	mockBillRepo := new(MockBillRepository)
+	contingencyRepo := new(MockContingencyRepository)
+	service := NewInvoiceService(nil, mockProductRepo, mockBillRepo, nil, nil, contingencyRepo, bill.Issuer{})
+
+	product := createTestInvoiceProduct("product-1", 100.0, 0.19, 0.0)
+	invoice := &dto.ElectronicInvoice{
+		PaymentCode: dto.ElectronicInvoicePaymentCodeCash,
+		Items:       []dto.InvoiceItem{{ProductID: "product-1", Quantity: 1}},
+	}
+
+	contingencyRepo.On("FindActive", ctx).Return(createTestContingencyPeriod("period-1", true), nil)
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1"}).Return([]*dto.Product{product}, nil)
+
+	result, err := service.CreateElectronicInvoice(ctx, invoice)
+
+	// The provisional document would be printed without its CUFE
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, domainError.ErrIssuerNotConfigured)
+	mockBillRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateElectronicInvoice_ExactCentAmounts(t *testing.T) {
+	ctx := context.Background()
+	mockProductRepo := new(MockProductRepository)
+	mockBillRepo := new(MockBillRepository)
+	service := createTestInvoiceService(mockProductRepo, mockBillRepo)
+
+	product := createTestInvoiceProduct("product-1", 8403.36, 0.19, 0.0)
+	invoice := &dto.ElectronicInvoice{
+		PaymentCode: dto.ElectronicInvoicePaymentCodeCash,
+		Items:       []dto.InvoiceItem{{ProductID: "product-1", Quantity: 3}},
+	}
+
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1"}).Return([]*dto.Product{product}, nil)
+	mockBillRepo.On("Create", ctx, mock.AnythingOfType("*bill.Aggregate"), []*dto.Product{product}).Return(nil)
+
+	result, err := service.CreateElectronicInvoice(ctx, invoice)
+
+	// 25210.08 * 19% = 4789.9152, rounded half up to the centavo
+	require.NoError(t, err)
+	assert.Equal(t, "25210.08", result.TotalAmount.String())
+	assert.Equal(t, "4789.92", result.VAT.String())
+	assert.Equal(t, "30000.00", result.PayAmount.String())
+	require.Len(t, result.Products[0].Taxes, 1)
+	assert.Equal(t, result.VAT, result.Products[0].Taxes[0].TaxAmount)
+	assert.Equal(t, "19.00", result.Products[0].Taxes[0].Percent)
+}
+
+func TestCreateElectronicInvoice_SinglePaymentByDefault(t *testing.T) {
+	ctx := context.Background()
+	mockProductRepo := new(MockProductRepository)
+	mockBillRepo := new(MockBillRepository)
+	service := createTestInvoiceService(mockProductRepo, mockBillRepo)
+
+	product := createTestInvoiceProduct("product-1", 100.0, 0.19, 0.0)
+	invoice := &dto.ElectronicInvoice{
+		PaymentCode: dto.ElectronicInvoicePaymentCodeCreditCard,
+		Items:       []dto.InvoiceItem{{ProductID: "product-1", Quantity: 1}},
+	}
+
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1"}).Return([]*dto.Product{product}, nil)
+	mockBillRepo.On("Create", ctx, mock.AnythingOfType("*bill.Aggregate"), []*dto.Product{product}).Return(nil)
+
+	result, err := service.CreateElectronicInvoice(ctx, invoice)
+
+	require.NoError(t, err)
+	require.Len(t, result.Payments, 1)
+	assert.Equal(t, dto.ElectronicInvoicePaymentCodeCreditCard, result.Payments[0].Method)
+	assert.Equal(t, result.PayAmount, result.Payments[0].Amount)
+	assert.True(t, result.Change.IsZero())
+}
+
+func TestCreateElectronicInvoice_SplitTenderWithCashChange(t *testing.T) {
+	ctx := context.Background()
+	mockProductRepo := new(MockProductRepository)
+	mockBillRepo := new(MockBillRepository)
+	service := createTestInvoiceService(mockProductRepo, mockBillRepo)
+
+	product1 := createTestInvoiceProduct("product-1", 100.0, 0.19, 0.0)
+	product2 := createTestInvoiceProduct("product-2", 50.0, 0.0, 0.08)
+	invoice := &dto.ElectronicInvoice{
+		Payments: []dto.Payment{
+			{Method: dto.ElectronicInvoicePaymentCodeDebitCard, Amount: dto.NewMoneyFromFloat(200.0), Reference: "voucher-123"},
+			{Method: dto.ElectronicInvoicePaymentCodeCash, Amount: dto.NewMoneyFromFloat(100.0)},
+		},
+		Items: []dto.InvoiceItem{
+			{ProductID: "product-1", Quantity: 2},
+			{ProductID: "product-2", Quantity: 1},
+		},
+	}
+
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1", "product-2"}).Return([]*dto.Product{product1, product2}, nil)
+	mockBillRepo.On("Create", ctx, mock.MatchedBy(func(aggregate *bill.Aggregate) bool {
+		return aggregate.PaymentCode() == dto.ElectronicInvoicePaymentCodeDebitCard && len(aggregate.Payments()) == 2
+	}), []*dto.Product{product1, product2}).Return(nil)
+
+	result, err := service.CreateElectronicInvoice(ctx, invoice)
+
+	// 292.00 to pay with 200.00 by card and 100.00 in cash gives 8.00 back
+	require.NoError(t, err)
+	assert.Equal(t, dto.NewMoneyFromFloat(292.0), result.PayAmount)
+	assert.Equal(t, dto.NewMoneyFromFloat(8.0), result.Change)
+	require.Len(t, result.Payments, 2)
+	assert.Equal(t, "voucher-123", result.Payments[0].Reference)
+	assert.True(t, result.Payments[0].Change.IsZero())
+	assert.Equal(t, dto.NewMoneyFromFloat(8.0), result.Payments[1].Change)
+	mockBillRepo.AssertExpectations(t)
+}
+
+// Error Cases
+func TestCreateElectronicInvoice_InvalidPayments(t *testing.T) {
+	tests := []struct {
+		name     string
+		payments []dto.Payment
+	}{
+		{
+			name: "payments do not cover the amount",
+			payments: []dto.Payment{
+				{Method: dto.ElectronicInvoicePaymentCodeDebitCard, Amount: dto.NewMoneyFromFloat(50.0)},
+				{Method: dto.ElectronicInvoicePaymentCodeCash, Amount: dto.NewMoneyFromFloat(50.0)},
+			},
+		},
+		{
+			name: "card payments exceed the amount",
+			payments: []dto.Payment{
+				{Method: dto.ElectronicInvoicePaymentCodeDebitCard, Amount: dto.NewMoneyFromFloat(100.0)},
+				{Method: dto.ElectronicInvoicePaymentCodeCreditCard, Amount: dto.NewMoneyFromFloat(100.0)},
+			},
+		},
+		{
+			name: "payment without method",
+			payments: []dto.Payment{
+				{Amount: dto.NewMoneyFromFloat(119.0)},
+			},
+		},
+		{
+			name: "payment without amount",
+			payments: []dto.Payment{
+				{Method: dto.ElectronicInvoicePaymentCodeCash, Amount: dto.NewMoneyFromFloat(119.0)},
+				{Method: dto.ElectronicInvoicePaymentCodeDebitCard},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockProductRepo := new(MockProductRepository)
+			mockBillRepo := new(MockBillRepository)
+			service := createTestInvoiceService(mockProductRepo, mockBillRepo)
+
+			product := createTestInvoiceProduct("product-1", 100.0, 0.19, 0.0)
+			invoice := &dto.ElectronicInvoice{
+				Payments: tt.payments,
+				Items:    []dto.InvoiceItem{{ProductID: "product-1", Quantity: 1}},
+			}
+
+			mockProductRepo.On("FindByIDs", ctx, []string{"product-1"}).Return([]*dto.Product{product}, nil)
+
+			result, err := service.CreateElectronicInvoice(ctx, invoice)
+
+			require.Error(t, err)
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, domainError.ErrInvalidInvoice)
+			mockBillRepo.AssertNotCalled(t, "Create")
+		})
+	}
+}
+
+func TestCreateElectronicInvoice_InvalidCustomerFiscalData(t *testing.T) {
+	ctx := context.Background()
+	mockProductRepo := new(MockProductRepository)
+	mockBillRepo := new(MockBillRepository)
+	service := createTestInvoiceService(mockProductRepo, mockBillRepo)
+
+	product := createTestInvoiceProduct("product-1", 100.0, 0.19, 0.0)
+	invoice := &dto.ElectronicInvoice{
+		PaymentCode: dto.ElectronicInvoicePaymentCodeCash,
+		Items:       []dto.InvoiceItem{{ProductID: "product-1", Quantity: 1}},
+		Customer: &dto.Customer{
+			DocumentNumber:   "800197268",
+			DocumentType:     dto.DocumentTypeNIT,
+			Name:             "Empresa SAS",
+			Email:            "facturas@empresa.co",
+			MunicipalityCode: "Bogotá",
+		},
+	}
+
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1"}).Return([]*dto.Product{product}, nil)
+
+	result, err := service.CreateElectronicInvoice(ctx, invoice)
+
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, domainError.ErrInvalidInvoice)
+	mockBillRepo.AssertNotCalled(t, "Create")
+}
+
+func TestCreateElectronicInvoice_NormalizesCustomerDocument(t *testing.T) {
+	ctx := context.Background()
+	mockProductRepo := new(MockProductRepository)
+	mockBillRepo := new(MockBillRepository)
+	service := createTestInvoiceService(mockProductRepo, mockBillRepo)
+
+	product := createTestInvoiceProduct("product-1", 100.0, 0.19, 0.0)
+	invoice := &dto.ElectronicInvoice{
+		PaymentCode: dto.ElectronicInvoicePaymentCodeCash,
+		Items:       []dto.InvoiceItem{{ProductID: "product-1", Quantity: 1}},
+		Customer: &dto.Customer{
+			DocumentNumber: " 800.197.268-4 ",
+			DocumentType:   dto.DocumentTypeNIT,
+			Name:           "Empresa SAS",
+			Email:          "facturas@empresa.co",
+		},
+	}
+
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1"}).Return([]*dto.Product{product}, nil)
+	mockBillRepo.On("Create", ctx, mock.AnythingOfType("*bill.Aggregate"), []*dto.Product{product}).Return(nil)
+
+	result, err := service.CreateElectronicInvoice(ctx, invoice)
+
+	require.NoError(t, err)
+	require.NotNil(t, result.Customer)
+	assert.Equal(t, "800197268", result.Customer.DocumentNumber)
+	mockBillRepo.AssertExpectations(t)
+}
+
+func TestCreateElectronicInvoice_InvalidCustomerCheckDigit(t *testing.T) {
+	ctx := context.Background()
+	mockProductRepo := new(MockProductRepository)
+	mockBillRepo := new(MockBillRepository)
+	service := createTestInvoiceService(mockProductRepo, mockBillRepo)
+
+	product := createTestInvoiceProduct("product-1", 100.0, 0.19, 0.0)
+	invoice := &dto.ElectronicInvoice{
+		PaymentCode: dto.ElectronicInvoicePaymentCodeCash,
+		Items:       []dto.InvoiceItem{{ProductID: "product-1", Quantity: 1}},
+		Customer: &dto.Customer{
+			DocumentNumber: "800197268-5",
+			DocumentType:   dto.DocumentTypeNIT,
+			Name:           "Empresa SAS",
+			Email:          "facturas@empresa.co",
+		},
+	}
+
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1"}).Return([]*dto.Product{product}, nil)
+
+	result, err := service.CreateElectronicInvoice(ctx, invoice)
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, domainError.ErrInvalidInvoice)
+	mockBillRepo.AssertNotCalled(t, "Create")
+}
+
+func TestCreateElectronicInvoice_ProductNotFound(t *testing.T) {
+	ctx := context.Background()
+	mockProductRepo := new(MockProductRepository)
+	mockBillRepo := new(MockBillRepository)
+	service := createTestInvoiceService(mockProductRepo, mockBillRepo)
+
+	invoice := &dto.ElectronicInvoice{
+		PaymentCode: dto.ElectronicInvoicePaymentCodeCash,
+		Items:       []dto.InvoiceItem{{ProductID: "product-1", Quantity: 1}},
+	}
+
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1"}).Return([]*dto.Product{}, nil)
+
+	result, err := service.CreateElectronicInvoice(ctx, invoice)
+
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, domainError.ErrProductNotFound)
+	mockBillRepo.AssertNotCalled(t, "Create")
+}
+
+func TestCreateElectronicInvoice_RepositoryError(t *testing.T) {
+	ctx := context.Background()
+	mockProductRepo := new(MockProductRepository)
+	mockBillRepo := new(MockBillRepository)
+	service := createTestInvoiceService(mockProductRepo, mockBillRepo)
+
+	product := createTestInvoiceProduct("product-1", 100.0, 0.19, 0.0)
+	invoice := &dto.ElectronicInvoice{
+		PaymentCode: dto.ElectronicInvoicePaymentCodeCash,
+		Items:       []dto.InvoiceItem{{ProductID: "product-1", Quantity: 1}},
+	}
+	repoError := errors.New("provider unavailable")
+
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1"}).Return([]*dto.Product{product}, nil)
+	mockBillRepo.On("Create", ctx, mock.AnythingOfType("*bill.Aggregate"), []*dto.Product{product}).Return(repoError)
+
+	result, err := service.CreateElectronicInvoice(ctx, invoice)
+
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, repoError)
+}
+
+// CreateCreditNote Tests
+
+// Success Cases
+func TestCreateCreditNote_TotalCreditNote(t *testing.T) {
+	ctx := context.Background()
+	mockProductRepo := new(MockProductRepository)
+	mockBillRepo := new(MockBillRepository)
+	mockCreditNoteRepo := new(MockCreditNoteRepository)
+	service := createTestCreditNoteService(mockProductRepo, mockBillRepo, mockCreditNoteRepo)
+
+	original := createTestIssuedBill("bill-1")
+	req := &dto.CreateCreditNoteRequest{
+		Reason:      dto.CreditNoteReasonCancellation,
+		Description: "Wrong customer",
+	}
+
+	var created *bill.CreditNote
+	mockBillRepo.On("FindByID", ctx, "bill-1").Return(original, nil)
+	mockBillRepo.On("FindProductsByID", ctx, "bill-1").Return(createTestBilledProducts(), nil)
+	mockCreditNoteRepo.On("FindByBillID", ctx, "bill-1").Return([]*dto.CreditNote{}, nil)
+	mockCreditNoteRepo.On("Create", ctx, mock.AnythingOfType("*bill.CreditNote"), original).
+		Run(func(args mock.Arguments) {
+			created = args.Get(1).(*bill.CreditNote)
+		}).
+		Return(nil)
+	mockCreditNoteRepo.On("FindByID", ctx, mock.AnythingOfType("string")).Return(&dto.CreditNote{ID: "credit-note-1"}, nil)
+
+	_, err := service.CreateCreditNote(ctx, "bill-1", req)
+
+	// The whole bill is reversed, tip included
+	require.NoError(t, err)
+	require.NotNil(t, created)
+	creditNote := created.ToDTO()
+	assert.Equal(t, "bill-1", creditNote.BillID)
+	assert.Equal(t, dto.CreditNoteReasonCancellation, creditNote.Reason)
+	assert.Equal(t, original.PayAmount, creditNote.PayAmount)
+	assert.Equal(t, original.Tip, creditNote.Tip)
+	require.Len(t, creditNote.Products, 1)
+	assert.Equal(t, 2, creditNote.Products[0].Quantity)
+	mockCreditNoteRepo.AssertExpectations(t)
+}
+
+func TestCreateCreditNote_PartialCreditNote(t *testing.T) {
+	ctx := context.Background()
+	mockProductRepo := new(MockProductRepository)
+	mockBillRepo := new(MockBillRepository)
+	mockCreditNoteRepo := new(MockCreditNoteRepository)
+	service := createTestCreditNoteService(mockProductRepo, mockBillRepo, mockCreditNoteRepo)
+
+	original := createTestIssuedBill("bill-1")
+	req := &dto.CreateCreditNoteRequest{
+		Reason:      dto.CreditNoteReasonReturnedGoods,
+		Description: "Returned bottle",
+		Items:       []dto.OrderProductItem{{ProductID: "product-1", Quantity: 1}},
+	}
+
+	mockBillRepo.On("FindByID", ctx, "bill-1").Return(original, nil)
+	mockBillRepo.On("FindProductsByID", ctx, "bill-1").Return(createTestBilledProducts(), nil)
+	mockCreditNoteRepo.On("FindByBillID", ctx, "bill-1").Return([]*dto.CreditNote{}, nil)
+	mockCreditNoteRepo.On("Create", ctx, mock.MatchedBy(func(creditNote *bill.CreditNote) bool {
+		return creditNote.BillID() == "bill-1" &&
+			len(creditNote.Products()) == 1 && creditNote.Products()[0].Quantity() == 1 &&
+			creditNote.PayAmount() == dto.NewMoneyFromFloat(119.0)
+	}), original).Return(nil)
+	mockCreditNoteRepo.On("FindByID", ctx, mock.AnythingOfType("string")).Return(&dto.CreditNote{
+		ID:          "credit-note-1",
+		BillID:      "bill-1",
+		Prefix:      "NC",
+		Consecutive: 1,
+		PayAmount:   dto.NewMoneyFromFloat(119.0),
+	}, nil)
+
+	result, err := service.CreateCreditNote(ctx, "bill-1", req)
+
+	require.NoError(t, err)
+	assert.Equal(t, "NC", result.Prefix)
+	assert.Equal(t, 1, result.Consecutive)
+	assert.Equal(t, dto.NewMoneyFromFloat(119.0), result.PayAmount)
+	mockCreditNoteRepo.AssertExpectations(t)
+}
+
+func TestCreateCreditNote_CreditsWhatIsLeft(t *testing.T) {
+	ctx := context.Background()
+	mockProductRepo := new(MockProductRepository)
+	mockBillRepo := new(MockBillRepository)
+	mockCreditNoteRepo := new(MockCreditNoteRepository)
+	service := createTestCreditNoteService(mockProductRepo, mockBillRepo, mockCreditNoteRepo)
+
+	original := createTestIssuedBill("bill-1")
+	previous := []*dto.CreditNote{{
+		ID:        "credit-note-1",
+		BillID:    "bill-1",
+		PayAmount: dto.NewMoneyFromFloat(119.0),
+		Products:  []dto.BillProduct{{ProductID: "product-1", Quantity: 1}},
+	}}
+
+	mockBillRepo.On("FindByID", ctx, "bill-1").Return(original, nil)
+	mockBillRepo.On("FindProductsByID", ctx, "bill-1").Return(createTestBilledProducts(), nil)
+	mockCreditNoteRepo.On("FindByBillID", ctx, "bill-1").Return(previous, nil)
+	mockCreditNoteRepo.On("Create", ctx, mock.MatchedBy(func(creditNote *bill.CreditNote) bool {
+		// The unit left plus the tip, which is only credited when the bill is fully reversed
+		return creditNote.Products()[0].Quantity() == 1 && creditNote.PayAmount() == dto.NewMoneyFromFloat(129.0)
+	}), original).Return(nil)
+	mockCreditNoteRepo.On("FindByID", ctx, mock.AnythingOfType("string")).Return(&dto.CreditNote{ID: "credit-note-2"}, nil)
+
+	_, err := service.CreateCreditNote(ctx, "bill-1", &dto.CreateCreditNoteRequest{Reason: dto.CreditNoteReasonOther})
+
+	require.NoError(t, err)
+	mockCreditNoteRepo.AssertExpectations(t)
+}
+
+func TestCreateCreditNote_UsesBilledPrices(t *testing.T) {
+	ctx := context.Background()
+	mockProductRepo := new(MockProductRepository)
+	mockBillRepo := new(MockBillRepository)
+	mockCreditNoteRepo := new(MockCreditNoteRepository)
+	service := createTestCreditNoteService(mockProductRepo, mockBillRepo, mockCreditNoteRepo)
+
+	original := createTestIssuedBill("bill-1")
+	original.TotalAmount = dto.NewMoneyFromFloat(300.0)
+	original.VAT = dto.NewMoneyFromFloat(57.0)
+	original.TaxAmount = dto.NewMoneyFromFloat(57.0)
+	original.PayAmount = dto.NewMoneyFromFloat(367.0)
+	billed := createTestBilledProducts()
+	// The same product billed again in a second line, the units are taken in billing order
+	billed = append(billed, bill.NewBillProduct("product-1", 1, dto.NewMoneyFromFloat(100.0), nil, nil, nil, "SKU-product-1", nil, 0.19, 0.0))
+	req := &dto.CreateCreditNoteRequest{
+		Reason: dto.CreditNoteReasonReturnedGoods,
+		Items:  []dto.OrderProductItem{{ProductID: "product-1", Quantity: 3}},
+	}
+
+	mockBillRepo.On("FindByID", ctx, "bill-1").Return(original, nil)
+	mockBillRepo.On("FindProductsByID", ctx, "bill-1").Return(billed, nil)
+	mockCreditNoteRepo.On("FindByBillID", ctx, "bill-1").Return([]*dto.CreditNote{}, nil)
+	mockCreditNoteRepo.On("Create", ctx, mock.MatchedBy(func(creditNote *bill.CreditNote) bool {
+		products := creditNote.Products()
+		return len(products) == 2 &&
+			products[0].Quantity() == 2 && products[0].UnitPrice() == dto.NewMoneyFromFloat(100.0) &&
+			products[1].Quantity() == 1 &&
+			creditNote.PayAmount() == dto.NewMoneyFromFloat(357.0)
+	}), original).Return(nil)
+	mockCreditNoteRepo.On("FindByID", ctx, mock.AnythingOfType("string")).Return(&dto.CreditNote{ID: "credit-note-1"}, nil)
+
+	_, err := service.CreateCreditNote(ctx, "bill-1", req)
+
+	// The catalog is not looked up, its current prices may differ from the billed ones
+	require.NoError(t, err)
+	mockCreditNoteRepo.AssertExpectations(t)
+	mockProductRepo.AssertNotCalled(t, "FindByIDs")
+}
+
+// Error Cases
+func TestCreateCreditNote_InvalidRequest(t *testing.T) {
+	fullyCredited := []*dto.CreditNote{{
+		ID:        "credit-note-1",
+		BillID:    "bill-1",
+		PayAmount: dto.NewMoneyFromFloat(248.0),
+		Tip:       dto.NewMoneyFromFloat(10.0),
+		Products:  []dto.BillProduct{{ProductID: "product-1", Quantity: 2}},
+	}}
+
+	tests := []struct {
+		name     string
+		req      *dto.CreateCreditNoteRequest
+		previous []*dto.CreditNote
+	}{
+		{
+			name: "quantity over what was billed",
+			req: &dto.CreateCreditNoteRequest{
+				Reason: dto.CreditNoteReasonReturnedGoods,
+				Items:  []dto.OrderProductItem{{ProductID: "product-1", Quantity: 3}},
+			},
+		},
+		{
+			name: "product not in the bill",
+			req: &dto.CreateCreditNoteRequest{
+				Reason: dto.CreditNoteReasonReturnedGoods,
+				Items:  []dto.OrderProductItem{{ProductID: "product-2", Quantity: 1}},
+			},
+		},
+		{
+			name: "zero quantity",
+			req: &dto.CreateCreditNoteRequest{
+				Reason: dto.CreditNoteReasonReturnedGoods,
+				Items:  []dto.OrderProductItem{{ProductID: "product-1", Quantity: 0}},
+			},
+		},
+		{
+			name: "partial cancellation",
+			req: &dto.CreateCreditNoteRequest{
+				Reason: dto.CreditNoteReasonCancellation,
+				Items:  []dto.OrderProductItem{{ProductID: "product-1", Quantity: 1}},
+			},
+		},
+		{
+			name:     "bill already fully credited",
+			req:      &dto.CreateCreditNoteRequest{Reason: dto.CreditNoteReasonCancellation},
+			previous: fullyCredited,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockProductRepo := new(MockProductRepository)
+			mockBillRepo := new(MockBillRepository)
+			mockCreditNoteRepo := new(MockCreditNoteRepository)
+			service := createTestCreditNoteService(mockProductRepo, mockBillRepo, mockCreditNoteRepo)
+
+			previous := tt.previous
+			if previous == nil {
+				previous = []*dto.CreditNote{}
+			}
+
+			mockBillRepo.On("FindByID", ctx, "bill-1").Return(createTestIssuedBill("bill-1"), nil)
+			mockBillRepo.On("FindProductsByID", ctx, "bill-1").Return(createTestBilledProducts(), nil)
+			mockCreditNoteRepo.On("FindByBillID", ctx, "bill-1").Return(previous, nil)
+
+			result, err := service.CreateCreditNote(ctx, "bill-1", tt.req)
+
+			require.Error(t, err)
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, domainError.ErrInvalidCreditNote)
+			mockCreditNoteRepo.AssertNotCalled(t, "Create")
+		})
+	}
+}
+
+func TestCreateCreditNote_InvalidReason(t *testing.T) {
+	ctx := context.Background()
+	mockProductRepo := new(MockProductRepository)
+	mockBillRepo := new(MockBillRepository)
+	mockCreditNoteRepo := new(MockCreditNoteRepository)
+	service := createTestCreditNoteService(mockProductRepo, mockBillRepo, mockCreditNoteRepo)
+
+	mockBillRepo.On("FindByID", ctx, "bill-1").Return(createTestIssuedBill("bill-1"), nil)
+	mockBillRepo.On("FindProductsByID", ctx, "bill-1").Return(createTestBilledProducts(), nil)
+	mockCreditNoteRepo.On("FindByBillID", ctx, "bill-1").Return([]*dto.CreditNote{}, nil)
+
+	result, err := service.CreateCreditNote(ctx, "bill-1", &dto.CreateCreditNoteRequest{Reason: "mistake"})
+
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, domainError.ErrInvalidCreditNote)
+	mockCreditNoteRepo.AssertNotCalled(t, "Create")
+}
+
+func TestCreateCreditNote_BillNotIssued(t *testing.T) {
+	ctx := context.Background()
+	mockProductRepo := new(MockProductRepository)
+	mockBillRepo := new(MockBillRepository)
+	mockCreditNoteRepo := new(MockCreditNoteRepository)
+	service := createTestCreditNoteService(mockProductRepo, mockBillRepo, mockCreditNoteRepo)
+
+	original := createTestIssuedBill("bill-1")
+	original.CUFE = nil
+
+	mockBillRepo.On("FindByID", ctx, "bill-1").Return(original, nil)
+
+	result, err := service.CreateCreditNote(ctx, "bill-1", &dto.CreateCreditNoteRequest{Reason: dto.CreditNoteReasonCancellation})
+
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, domainError.ErrBillNotIssued)
+	mockBillRepo.AssertNotCalled(t, "FindProductsByID")
+}
+
+func TestCreateCreditNote_BillNotFound(t *testing.T) {
+	ctx := context.Background()
+	mockProductRepo := new(MockProductRepository)
+	mockBillRepo := new(MockBillRepository)
+	mockCreditNoteRepo := new(MockCreditNoteRepository)
+	service := createTestCreditNoteService(mockProductRepo, mockBillRepo, mockCreditNoteRepo)
+
+	mockBillRepo.On("FindByID", ctx, "bill-1").Return(nil, errors.New("record not found"))
+
+	result, err := service.CreateCreditNote(ctx, "bill-1", &dto.CreateCreditNoteRequest{Reason: dto.CreditNoteReasonCancellation})
+
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, domainError.ErrBillNotFound)
+}
+
+// CreateDebitNote Tests
+
+// Success Cases
+func TestCreateDebitNote_Success(t *testing.T) {
+	ctx := context.Background()
+	mockProductRepo := new(MockProductRepository)
+	mockBillRepo := new(MockBillRepository)
+	mockDebitNoteRepo := new(MockDebitNoteRepository)
+	service := createTestDebitNoteService(mockProductRepo, mockBillRepo, mockDebitNoteRepo)
+
+	original := createTestIssuedBill("bill-1")
+	product := createTestInvoiceProduct("product-1", 100.0, 0.19, 0.0)
+	req := &dto.CreateDebitNoteRequest{
+		Reason:      dto.DebitNoteReasonOther,
+		Description: "Forgotten item and corkage",
+		Items: []dto.DebitNoteItem{
+			{ProductID: "product-1", Quantity: 1},
+			{Description: "Descorche", Code: "CORKAGE", Quantity: 1, UnitPrice: dto.NewMoneyFromFloat(30000.0), ICO: 0.08},
+		},
+	}
+
+	var created *bill.DebitNote
+	mockBillRepo.On("FindByID", ctx, "bill-1").Return(original, nil)
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1"}).Return([]*dto.Product{product}, nil)
+	mockDebitNoteRepo.On("Create", ctx, mock.AnythingOfType("*bill.DebitNote"), original).
+		Run(func(args mock.Arguments) {
+			created = args.Get(1).(*bill.DebitNote)
+		}).
+		Return(nil)
+	mockDebitNoteRepo.On("FindByID", ctx, mock.AnythingOfType("string")).Return(&dto.DebitNote{ID: "debit-note-1", Prefix: "ND", Consecutive: 1}, nil)
+
+	result, err := service.CreateDebitNote(ctx, "bill-1", req)
+
+	require.NoError(t, err)
+	assert.Equal(t, "ND", result.Prefix)
+	require.NotNil(t, created)
+	debitNote := created.ToDTO()
+	assert.Equal(t, "bill-1", debitNote.BillID)
+	assert.Equal(t, "30100.00", debitNote.TotalAmount.String())
+	assert.Equal(t, "19.00", debitNote.VAT.String())
+	assert.Equal(t, "2400.00", debitNote.ICO.String())
+	assert.Equal(t, "32519.00", debitNote.PayAmount.String())
+	require.Len(t, debitNote.Products, 2)
+	assert.Equal(t, "product-1", debitNote.Products[0].ProductID)
+	assert.Empty(t, debitNote.Products[1].ProductID)
+	assert.Equal(t, "Descorche", *debitNote.Products[1].Description)
+	mockDebitNoteRepo.AssertExpectations(t)
+}
+
+// Error Cases
+func TestCreateDebitNote_InvalidRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *dto.CreateDebitNoteRequest
+	}{
+		{
+			name: "no items",
+			req:  &dto.CreateDebitNoteRequest{Reason: dto.DebitNoteReasonOther},
+		},
+		{
+			name: "zero quantity",
+			req: &dto.CreateDebitNoteRequest{
+				Reason: dto.DebitNoteReasonOther,
+				Items:  []dto.DebitNoteItem{{ProductID: "product-1"}},
+			},
+		},
+		{
+			name: "charge without price",
+			req: &dto.CreateDebitNoteRequest{
+				Reason: dto.DebitNoteReasonOther,
+				Items:  []dto.DebitNoteItem{{Description: "Descorche", Quantity: 1}},
+			},
+		},
+		{
+			name: "charge without description",
+			req: &dto.CreateDebitNoteRequest{
+				Reason: dto.DebitNoteReasonOther,
+				Items:  []dto.DebitNoteItem{{Quantity: 1, UnitPrice: dto.NewMoneyFromFloat(30000.0)}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockProductRepo := new(MockProductRepository)
+			mockBillRepo := new(MockBillRepository)
+			mockDebitNoteRepo := new(MockDebitNoteRepository)
+			service := createTestDebitNoteService(mockProductRepo, mockBillRepo, mockDebitNoteRepo)
+
+			mockBillRepo.On("FindByID", ctx, "bill-1").Return(createTestIssuedBill("bill-1"), nil)
+
+			result, err := service.CreateDebitNote(ctx, "bill-1", tt.req)
+
+			require.Error(t, err)
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, domainError.ErrInvalidDebitNote)
+			mockDebitNoteRepo.AssertNotCalled(t, "Create")
+		})
+	}
+}
+
+func TestCreateDebitNote_InvalidReason(t *testing.T) {
+	ctx := context.Background()
+	mockProductRepo := new(MockProductRepository)
+	mockBillRepo := new(MockBillRepository)
+	mockDebitNoteRepo := new(MockDebitNoteRepository)
+	service := createTestDebitNoteService(mockProductRepo, mockBillRepo, mockDebitNoteRepo)
+
+	product := createTestInvoiceProduct("product-1", 100.0, 0.19, 0.0)
+
+	mockBillRepo.On("FindByID", ctx, "bill-1").Return(createTestIssuedBill("bill-1"), nil)
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1"}).Return([]*dto.Product{product}, nil)
+
+	result, err := service.CreateDebitNote(ctx, "bill-1", &dto.CreateDebitNoteRequest{
+		Reason: "mistake",
+		Items:  []dto.DebitNoteItem{{ProductID: "product-1", Quantity: 1}},
+	})
+
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, domainError.ErrInvalidDebitNote)
+	mockDebitNoteRepo.AssertNotCalled(t, "Create")
+}
+
+func TestCreateDebitNote_BillNotIssued(t *testing.T) {
+	ctx := context.Background()
+	mockProductRepo := new(MockProductRepository)
+	mockBillRepo := new(MockBillRepository)
+	mockDebitNoteRepo := new(MockDebitNoteRepository)
+	service := createTestDebitNoteService(mockProductRepo, mockBillRepo, mockDebitNoteRepo)
+
+	original := createTestIssuedBill("bill-1")
+	original.CUFE = nil
+
+	mockBillRepo.On("FindByID", ctx, "bill-1").Return(original, nil)
+
+	result, err := service.CreateDebitNote(ctx, "bill-1", &dto.CreateDebitNoteRequest{
+		Reason: dto.DebitNoteReasonOther,
+		Items:  []dto.DebitNoteItem{{ProductID: "product-1", Quantity: 1}},
+	})
+
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, domainError.ErrBillNotIssued)
+	mockProductRepo.AssertNotCalled(t, "FindByIDs")
+}
+
+func TestCreateDebitNote_RepositoryError(t *testing.T) {
+	ctx := context.Background()
+	mockProductRepo := new(MockProductRepository)
+	mockBillRepo := new(MockBillRepository)
+	mockDebitNoteRepo := new(MockDebitNoteRepository)
+	service := createTestDebitNoteService(mockProductRepo, mockBillRepo, mockDebitNoteRepo)
+
+	original := createTestIssuedBill("bill-1")
+	product := createTestInvoiceProduct("product-1", 100.0, 0.19, 0.0)
+	repoError := errors.New("provider unavailable")
+
+	mockBillRepo.On("FindByID", ctx, "bill-1").Return(original, nil)
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1"}).Return([]*dto.Product{product}, nil)
+	mockDebitNoteRepo.On("Create", ctx, mock.AnythingOfType("*bill.DebitNote"), original).Return(repoError)
+
+	result, err := service.CreateDebitNote(ctx, "bill-1", &dto.CreateDebitNoteRequest{
+		Reason: dto.DebitNoteReasonOther,
+		Items:  []dto.DebitNoteItem{{ProductID: "product-1", Quantity: 1}},
+	})
+
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, domainError.ErrDebitNoteFailed)
+	assert.ErrorIs(t, err, repoError)
+	mockDebitNoteRepo.AssertNotCalled(t, "FindByID")
+}
+
+// GetBill Tests
+
+func TestGetBill_NotFound(t *testing.T) {
+	ctx := context.Background()
+	mockBillRepo := new(MockBillRepository)
+	service := createTestInvoiceService(nil, mockBillRepo)
+
+	mockBillRepo.On("FindByID", ctx, "bill-1").Return(nil, errors.New("record not found"))
+
+	result, err := service.GetBill(ctx, "bill-1")
+
+	assert.ErrorIs(t, err, domainError.ErrBillNotFound)
+	assert.Nil(t, result)
+}
+
+// ListBills Tests
+
+func TestListBills_DefaultPagination(t *testing.T) {
+	ctx := context.Background()
+	mockBillRepo := new(MockBillRepository)
+	service := createTestInvoiceService(nil, mockBillRepo)
+
+	bills := []*dto.Bill{createTestIssuedBill("bill-1"), createTestIssuedBill("bill-2")}
+	expectedFilter := &dto.ListBillsRequest{
+		PaymentMethods: []dto.ElectronicInvoicePaymentCode{dto.ElectronicInvoicePaymentCodeCash},
+		Statuses:       []dto.ElectronicInvoiceDocumentStatus{dto.ElectronicInvoiceDocumentStatusAccepted},
+		CustomerID:     "900123456",
+		Page:           1,
+		PageSize:       defaultBillsPageSize,
+	}
+	mockBillRepo.On("FindAll", ctx, expectedFilter).Return(bills, nil)
+	mockBillRepo.On("Count", ctx, expectedFilter).Return(42, nil)
+
+	result, err := service.ListBills(ctx, &dto.ListBillsRequest{
+		PaymentMethods: []dto.ElectronicInvoicePaymentCode{dto.ElectronicInvoicePaymentCodeCash},
+		Statuses:       []dto.ElectronicInvoiceDocumentStatus{dto.ElectronicInvoiceDocumentStatusAccepted},
+		CustomerID:     "900123456",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, bills, result.Bills)
+	assert.Equal(t, 42, result.Total)
+	assert.Equal(t, 1, result.Page)
+	assert.Equal(t, defaultBillsPageSize, result.PageSize)
+	mockBillRepo.AssertExpectations(t)
+}
+
+func TestListBills_CapsPageSize(t *testing.T) {
+	ctx := context.Background()
+	mockBillRepo := new(MockBillRepository)
+	service := createTestInvoiceService(nil, mockBillRepo)
+
+	expectedFilter := &dto.ListBillsRequest{Page: 3, PageSize: maxBillsPageSize}
+	mockBillRepo.On("FindAll", ctx, expectedFilter).Return([]*dto.Bill{}, nil)
+	mockBillRepo.On("Count", ctx, expectedFilter).Return(0, nil)
+
+	result, err := service.ListBills(ctx, &dto.ListBillsRequest{Page: 3, PageSize: 1000})
+
+	require.NoError(t, err)
+	assert.Equal(t, maxBillsPageSize, result.PageSize)
+}
+
+func TestListBills_InvalidFilter(t *testing.T) {
+	from := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		req  *dto.ListBillsRequest
+	}{
+		{name: "unknown payment method", req: &dto.ListBillsRequest{PaymentMethods: []dto.ElectronicInvoicePaymentCode{"cheque"}}},
+		{name: "unknown status", req: &dto.ListBillsRequest{Statuses: []dto.ElectronicInvoiceDocumentStatus{"lost"}}},
+		{name: "inverted date range", req: &dto.ListBillsRequest{CreatedFrom: &from, CreatedTo: &to}},
+		{name: "negative page", req: &dto.ListBillsRequest{Page: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockBillRepo := new(MockBillRepository)
+			service := createTestInvoiceService(nil, mockBillRepo)
+
+			result, err := service.ListBills(context.Background(), tt.req)
+
+			assert.ErrorIs(t, err, domainError.ErrInvalidBillFilter)
+			assert.Nil(t, result)
+			mockBillRepo.AssertNotCalled(t, "FindAll", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// RefreshBillStatus Tests
+
+func TestRefreshBillStatus_Success(t *testing.T) {
+	ctx := context.Background()
+	client := new(MockElectronicInvoiceClient)
+	billRepo := new(MockBillRepository)
+	service := createTestStatusService(client, billRepo)
+
+	tascode := "tascode-1"
+	issuedBill := createTestIssuedBill("bill-1")
+	issuedBill.CUFE = nil
+	issuedBill.Tascode = &tascode
+
+	status := &dto.ElectronicInvoiceStatus{
+		Tascode:     tascode,
+		CUFE:        "cufe-bill-1",
+		Status:      dto.ElectronicInvoiceDocumentStatusAccepted,
+		DocumentURL: "https://provider.test/documents/bill-1",
+		PDF:         "JVBERi0xLjQ=",
+		XML:         "PD94bWw+",
+	}
+
+	cufe := status.CUFE
+	refreshedBill := createTestIssuedBill("bill-1")
+	refreshedBill.Tascode = &tascode
+	refreshedBill.CUFE = &cufe
+	refreshedBill.DIANStatus = dto.ElectronicInvoiceDocumentStatusAccepted
+	refreshedBill.DocumentURL = &status.DocumentURL
+
+	billRepo.On("FindByID", ctx, "bill-1").Return(issuedBill, nil).Once()
+	client.On("Get", ctx, tascode).Return(status, nil)
+	billRepo.On("UpdateDocumentStatus", ctx, "bill-1", status).Return(nil)
+	billRepo.On("FindByID", ctx, "bill-1").Return(refreshedBill, nil).Once()
+
+	result, err := service.RefreshBillStatus(ctx, "bill-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, dto.ElectronicInvoiceDocumentStatusAccepted, result.DIANStatus)
+	assert.Equal(t, "cufe-bill-1", *result.CUFE)
+	assert.Equal(t, status.DocumentURL, *result.DocumentURL)
+
+	client.AssertExpectations(t)
+	billRepo.AssertExpectations(t)
+}
+
+func TestRefreshBillStatus_BillNotIssued(t *testing.T) {
+	ctx := context.Background()
+	client := new(MockElectronicInvoiceClient)
+	billRepo := new(MockBillRepository)
+	service := createTestStatusService(client, billRepo)
+
+	billRepo.On("FindByID", ctx, "bill-1").Return(&dto.Bill{ID: "bill-1"}, nil)
+
+	result, err := service.RefreshBillStatus(ctx, "bill-1")
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, domainError.ErrBillNotIssued)
+	client.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+}
+
+func TestRefreshBillStatus_ProviderError(t *testing.T) {
+	ctx := context.Background()
+	client := new(MockElectronicInvoiceClient)
+	billRepo := new(MockBillRepository)
+	service := createTestStatusService(client, billRepo)
+
+	tascode := "tascode-1"
+	issuedBill := createTestIssuedBill("bill-1")
+	issuedBill.Tascode = &tascode
+
+	billRepo.On("FindByID", ctx, "bill-1").Return(issuedBill, nil)
+	client.On("Get", ctx, tascode).Return(nil, errors.New("provider unavailable"))
+
+	result, err := service.RefreshBillStatus(ctx, "bill-1")
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, domainError.ErrBillStatusFailed)
+	billRepo.AssertNotCalled(t, "UpdateDocumentStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// ReconcilePendingBills Tests
+
+func createTestPendingBill(id string) *dto.Bill {
+	tascode := "tascode-" + id
+	return &dto.Bill{
+		ID:         id,
+		Tascode:    &tascode,
+		DIANStatus: dto.ElectronicInvoiceDocumentStatusPending,
+	}
+}
+
+func TestReconcilePendingBills_UpdatesEveryBill(t *testing.T) {
+	ctx := context.Background()
+	client := new(MockElectronicInvoiceClient)
+	billRepo := new(MockBillRepository)
+	service := createTestStatusService(client, billRepo)
+
+	accepted := &dto.ElectronicInvoiceStatus{Tascode: "tascode-bill-1", CUFE: "cufe-1", Status: dto.ElectronicInvoiceDocumentStatusAccepted}
+	rejected := &dto.ElectronicInvoiceStatus{Tascode: "tascode-bill-2", Status: dto.ElectronicInvoiceDocumentStatusRejected, Messages: []string{"FAD06: invalid NIT"}}
+
+	billRepo.On("FindPendingStatus", ctx, 50).Return([]*dto.Bill{createTestPendingBill("bill-1"), createTestPendingBill("bill-2")}, nil)
+	client.On("Get", ctx, "tascode-bill-1").Return(accepted, nil)
+	client.On("Get", ctx, "tascode-bill-2").Return(rejected, nil)
+	billRepo.On("UpdateDocumentStatus", ctx, "bill-1", accepted).Return(nil)
+	billRepo.On("UpdateDocumentStatus", ctx, "bill-2", rejected).Return(nil)
+
+	reconciled, err := service.ReconcilePendingBills(ctx, 50)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, reconciled)
+	client.AssertExpectations(t)
+	billRepo.AssertExpectations(t)
+}
+
+func TestReconcilePendingBills_StopsOnProviderError(t *testing.T) {
+	ctx := context.Background()
+	client := new(MockElectronicInvoiceClient)
+	billRepo := new(MockBillRepository)
+	service := createTestStatusService(client, billRepo)
+
+	billRepo.On("FindPendingStatus", ctx, 50).Return([]*dto.Bill{createTestPendingBill("bill-1"), createTestPendingBill("bill-2")}, nil)
+	client.On("Get", ctx, "tascode-bill-1").Return(nil, errors.New("provider unavailable"))
+
+	reconciled, err := service.ReconcilePendingBills(ctx, 50)
+
+	assert.ErrorIs(t, err, domainError.ErrBillStatusFailed)
+	assert.Equal(t, 0, reconciled)
+	client.AssertNotCalled(t, "Get", ctx, "tascode-bill-2")
+	billRepo.AssertNotCalled(t, "UpdateDocumentStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReconcilePendingBills_ProviderWithoutStatus(t *testing.T) {
+	ctx := context.Background()
+	client := new(MockElectronicInvoiceClient)
+	billRepo := new(MockBillRepository)
+	service := createTestStatusService(client, billRepo)
+
+	billRepo.On("FindPendingStatus", ctx, 50).Return([]*dto.Bill{createTestPendingBill("bill-1"), createTestPendingBill("bill-2")}, nil)
+	client.On("Get", ctx, "tascode-bill-1").Return(nil, domainError.ErrStatusUnsupported)
+
+	reconciled, err := service.ReconcilePendingBills(ctx, 50)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, reconciled)
+	client.AssertNotCalled(t, "Get", ctx, "tascode-bill-2")
+	billRepo.AssertNotCalled(t, "UpdateDocumentStatus", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"laguna-escondida/backend/internal/domain/aggregate/resolution"
+	"laguna-escondida/backend/internal/domain/dto"
+	domainError "laguna-escondida/backend/internal/domain/error"
+	"laguna-escondida/backend/internal/domain/ports"
+)
+
+type NumberingResolutionService struct {
+	resolutionRepo ports.NumberingResolutionRepository
+}
+
+func NewNumberingResolutionService(resolutionRepo ports.NumberingResolutionRepository) *NumberingResolutionService {
+	return &NumberingResolutionService{
+		resolutionRepo: resolutionRepo,
+	}
+}
+
+// CreateResolution registers a DIAN resolution, its range cannot overlap another one with the same prefix
+func (s *NumberingResolutionService) CreateResolution(ctx context.Context, req *dto.CreateNumberingResolutionRequest) (*dto.NumberingResolution, error) {
+	newResolution, err := resolution.NewAggregateFromCreateRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrInvalidResolution, err)
+	}
+
+	if err := s.checkOverlaps(ctx, newResolution); err != nil {
+		return nil, err
+	}
+
+	if err := s.resolutionRepo.Create(ctx, newResolution); err != nil {
+		return nil, err
+	}
+
+	return s.withStatus(newResolution, time.Now(), nil), nil
+}
+
+// UpdateResolution replaces the data of a resolution, usually to fix a typo or extend its validity
+func (s *NumberingResolutionService) UpdateResolution(ctx context.Context, id string, req *dto.UpdateNumberingResolutionRequest) (*dto.NumberingResolution, error) {
+	existing, err := s.resolutionRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrResolutionNotFound, err)
+	}
+
+	updated, err := resolution.NewAggregateFromDTO(existing).Update(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrInvalidResolution, err)
+	}
+
+	if err := s.checkOverlaps(ctx, updated); err != nil {
+		return nil, err
+	}
+
+	if err := s.resolutionRepo.Update(ctx, updated, existing.LastConsecutive); err != nil {
+		return nil, err
+	}
+
+	return s.withStatus(updated, time.Now(), nil), nil
+}
+
+// DeleteResolution removes a resolution registered by mistake, one that already numbered
+// invoices is kept since the outbox needs its technical key to check their CUFE
+func (s *NumberingResolutionService) DeleteResolution(ctx context.Context, id string) error {
+	existing, err := s.resolutionRepo.FindByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("%w: %w", domainError.ErrResolutionNotFound, err)
+	}
+
+	if resolution.NewAggregateFromDTO(existing).IsUsed() {
+		return fmt.Errorf("%w: %s", domainError.ErrResolutionInUse, existing.ResolutionNumber)
+	}
+
+	return s.resolutionRepo.Delete(ctx, id)
+}
+
+func (s *NumberingResolutionService) GetResolution(ctx context.Context, id string) (*dto.NumberingResolution, error) {
+	existing, err := s.resolutionRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrResolutionNotFound, err)
+	}
+
+	now := time.Now()
+	// Having no active resolution is not an error when looking at them
+	active, _ := s.resolutionRepo.FindActive(ctx, now)
+
+	return s.withStatus(resolution.NewAggregateFromDTO(existing), now, active), nil
+}
+
+// ListResolutions returns every resolution flagging the one invoices are numbered with
+func (s *NumberingResolutionService) ListResolutions(ctx context.Context) ([]*dto.NumberingResolution, error) {
+	resolutions, err := s.resolutionRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	// Having no active resolution is not an error when looking at them
+	active, _ := s.resolutionRepo.FindActive(ctx, now)
+
+	result := make([]*dto.NumberingResolution, len(resolutions))
+	for i, existing := range resolutions {
+		result[i] = s.withStatus(resolution.NewAggregateFromDTO(existing), now, active)
+	}
+
+	return result, nil
+}
+
+// GetActiveResolution returns the resolution the next invoice will be numbered with along
+// with the warnings about numbers or days running out
+func (s *NumberingResolutionService) GetActiveResolution(ctx context.Context) (*dto.NumberingResolution, error) {
+	now := time.Now()
+	active, err := s.resolutionRepo.FindActive(ctx, now)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrNoActiveResolution, err)
+	}
+
+	activeResolution := resolution.NewAggregateFromDTO(active)
+	if err := activeResolution.CheckUsable(now); err != nil {
+		return nil, fmt.Errorf("%w: %w", domainError.ErrNoActiveResolution, err)
+	}
+
+	return s.withStatus(activeResolution, now, active), nil
+}
+
+func (s *NumberingResolutionService) checkOverlaps(ctx context.Context, candidate *resolution.Aggregate) error {
+	resolutions, err := s.resolutionRepo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, existing := range resolutions {
+		if candidate.Overlaps(existing) {
+			return fmt.Errorf("%w: range overlaps resolution %s", domainError.ErrInvalidResolution, existing.ResolutionNumber)
+		}
+	}
+
+	return nil
+}
+
+func (s *NumberingResolutionService) withStatus(aggregate *resolution.Aggregate, now time.Time, active *dto.NumberingResolution) *dto.NumberingResolution {
+	resolutionDTO := aggregate.ToDTO()
+	resolutionDTO.Active = active != nil && active.ID == resolutionDTO.ID
+	if resolutionDTO.Active {
+		resolutionDTO.Warnings = aggregate.Warnings(now)
+	}
+
+	return resolutionDTO
+}
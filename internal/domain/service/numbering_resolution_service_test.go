@@ -0,0 +1,367 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"laguna-escondida/backend/internal/domain/aggregate/bill"
+	"laguna-escondida/backend/internal/domain/aggregate/resolution"
+	"laguna-escondida/backend/internal/domain/dto"
+	domainError "laguna-escondida/backend/internal/domain/error"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockNumberingResolutionRepository is a mock implementation of ports.NumberingResolutionRepository
+type MockNumberingResolutionRepository struct {
+	mock.Mock
+}
+
+func (m *MockNumberingResolutionRepository) Create(ctx context.Context, resolution *resolution.Aggregate) error {
+	args := m.Called(ctx, resolution)
+	return args.Error(0)
+}
+
+func (m *MockNumberingResolutionRepository) Update(ctx context.Context, resolution *resolution.Aggregate, readLastConsecutive int) error {
+	args := m.Called(ctx, resolution, readLastConsecutive)
+	return args.Error(0)
+}
+
+func (m *MockNumberingResolutionRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockNumberingResolutionRepository) FindByID(ctx context.Context, id string) (*dto.NumberingResolution, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.NumberingResolution), args.Error(1)
+}
+
+func (m *MockNumberingResolutionRepository) FindAll(ctx context.Context) ([]*dto.NumberingResolution, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*dto.NumberingResolution), args.Error(1)
+}
+
+func (m *MockNumberingResolutionRepository) FindActive(ctx context.Context, now time.Time) (*dto.NumberingResolution, error) {
+	args := m.Called(ctx, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.NumberingResolution), args.Error(1)
+}
+
+func (m *MockNumberingResolutionRepository) FindByNumber(ctx context.Context, prefix string, consecutive int) (*dto.NumberingResolution, error) {
+	args := m.Called(ctx, prefix, consecutive)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.NumberingResolution), args.Error(1)
+}
+
+// testIssuer is the restaurant the CUFE of the test invoices is computed for
+var testIssuer = bill.Issuer{NIT: "900123456", Environment: dto.DIANEnvironmentTest}
+
+// newTestResolutionRepo numbers every invoice with a resolution whose technical key is
+// technical-key-resolution-1
+func newTestResolutionRepo() *MockNumberingResolutionRepository {
+	resolutionRepo := new(MockNumberingResolutionRepository)
+	resolutionRepo.On("FindByNumber", mock.Anything, mock.Anything, mock.Anything).
+		Return(createTestResolution("resolution-1", 1, 5000, 0, time.Hour), nil).Maybe()
+	return resolutionRepo
+}
+
+// createTestResolution returns a resolution valid from a year ago until validFor from now
+// that already numbered invoices up to lastConsecutive
+func createTestResolution(id string, from, to, lastConsecutive int, validFor time.Duration) *dto.NumberingResolution {
+	now := time.Now()
+	return &dto.NumberingResolution{
+		ID:               id,
+		ResolutionNumber: "1876" + id,
+		Prefix:           "LAG",
+		From:             from,
+		To:               to,
+		LastConsecutive:  lastConsecutive,
+		ValidFrom:        now.AddDate(-1, 0, 0),
+		ValidTo:          now.Add(validFor),
+		TechnicalKey:     "technical-key-" + id,
+	}
+}
+
+func createTestResolutionRequest(prefix string, from, to int) *dto.CreateNumberingResolutionRequest {
+	return &dto.CreateNumberingResolutionRequest{
+		ResolutionNumber: "18764000001",
+		Prefix:           prefix,
+		From:             from,
+		To:               to,
+		ValidFrom:        "2026-01-01",
+		ValidTo:          "2027-12-31",
+		TechnicalKey:     "fc8eac422eba16e22ffd8c6f94b3f40a6e38162c",
+	}
+}
+
+// CreateResolution Tests
+
+func TestCreateResolution_Success(t *testing.T) {
+	ctx := context.Background()
+	resolutionRepo := new(MockNumberingResolutionRepository)
+	service := NewNumberingResolutionService(resolutionRepo)
+
+	resolutionRepo.On("FindAll", ctx).Return([]*dto.NumberingResolution{createTestResolution("old", 1, 1000, 1000, -24*time.Hour)}, nil)
+	resolutionRepo.On("Create", ctx, mock.AnythingOfType("*resolution.Aggregate")).Return(nil)
+
+	result, err := service.CreateResolution(ctx, createTestResolutionRequest(" lag ", 1001, 5000))
+
+	require.NoError(t, err)
+	assert.Equal(t, "LAG", result.Prefix)
+	assert.Equal(t, 1000, result.LastConsecutive, "the first invoice gets the start of the range")
+	assert.Equal(t, 4000, result.Remaining)
+	assert.Equal(t, "2026-01-01", result.ValidFrom.Format("2006-01-02"))
+	resolutionRepo.AssertExpectations(t)
+}
+
+func TestCreateResolution_InvalidRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(req *dto.CreateNumberingResolutionRequest)
+	}{
+		{"missing resolution number", func(req *dto.CreateNumberingResolutionRequest) { req.ResolutionNumber = "" }},
+		{"prefix too long", func(req *dto.CreateNumberingResolutionRequest) { req.Prefix = "LAGUNA" }},
+		{"range starts at zero", func(req *dto.CreateNumberingResolutionRequest) { req.From = 0 }},
+		{"inverted range", func(req *dto.CreateNumberingResolutionRequest) { req.To = req.From - 1 }},
+		{"malformed date", func(req *dto.CreateNumberingResolutionRequest) { req.ValidTo = "31/12/2027" }},
+		{"inverted dates", func(req *dto.CreateNumberingResolutionRequest) { req.ValidTo = "2025-12-31" }},
+		{"missing technical key", func(req *dto.CreateNumberingResolutionRequest) { req.TechnicalKey = " " }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			resolutionRepo := new(MockNumberingResolutionRepository)
+			service := NewNumberingResolutionService(resolutionRepo)
+
+			req := createTestResolutionRequest("LAG", 1, 5000)
+			tt.modify(req)
+
+			result, err := service.CreateResolution(ctx, req)
+
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, domainError.ErrInvalidResolution)
+			resolutionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCreateResolution_OverlappingRange(t *testing.T) {
+	ctx := context.Background()
+	resolutionRepo := new(MockNumberingResolutionRepository)
+	service := NewNumberingResolutionService(resolutionRepo)
+
+	resolutionRepo.On("FindAll", ctx).Return([]*dto.NumberingResolution{createTestResolution("old", 1, 1000, 10, 24*time.Hour)}, nil)
+
+	result, err := service.CreateResolution(ctx, createTestResolutionRequest("LAG", 900, 5000))
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, domainError.ErrInvalidResolution)
+	resolutionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+// UpdateResolution Tests
+
+func TestUpdateResolution_UsedRangeKeepsItsStart(t *testing.T) {
+	ctx := context.Background()
+	resolutionRepo := new(MockNumberingResolutionRepository)
+	service := NewNumberingResolutionService(resolutionRepo)
+
+	resolutionRepo.On("FindByID", ctx, "res-1").Return(createTestResolution("res-1", 1, 1000, 500, 24*time.Hour), nil)
+
+	tests := []struct {
+		name string
+		req  dto.UpdateNumberingResolutionRequest
+	}{
+		{"prefix changes", dto.UpdateNumberingResolutionRequest(*createTestResolutionRequest("NEW", 1, 1000))},
+		{"start changes", dto.UpdateNumberingResolutionRequest(*createTestResolutionRequest("LAG", 2, 1000))},
+		{"range ends before the last number used", dto.UpdateNumberingResolutionRequest(*createTestResolutionRequest("LAG", 1, 499))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.UpdateResolution(ctx, "res-1", &tt.req)
+
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, domainError.ErrInvalidResolution)
+		})
+	}
+
+	resolutionRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateResolution_ExtendsValidity(t *testing.T) {
+	ctx := context.Background()
+	resolutionRepo := new(MockNumberingResolutionRepository)
+	service := NewNumberingResolutionService(resolutionRepo)
+
+	existing := createTestResolution("res-1", 1, 1000, 500, 24*time.Hour)
+	resolutionRepo.On("FindByID", ctx, "res-1").Return(existing, nil)
+	resolutionRepo.On("FindAll", ctx).Return([]*dto.NumberingResolution{existing}, nil)
+	resolutionRepo.On("Update", ctx, mock.AnythingOfType("*resolution.Aggregate"), 500).Return(nil)
+
+	req := dto.UpdateNumberingResolutionRequest(*createTestResolutionRequest("LAG", 1, 2000))
+	result, err := service.UpdateResolution(ctx, "res-1", &req)
+
+	require.NoError(t, err)
+	assert.Equal(t, 500, result.LastConsecutive)
+	assert.Equal(t, 1500, result.Remaining)
+	assert.Equal(t, "2027-12-31", result.ValidTo.Format("2006-01-02"))
+	resolutionRepo.AssertExpectations(t)
+}
+
+func TestUpdateResolution_NumberedMeanwhile(t *testing.T) {
+	ctx := context.Background()
+	resolutionRepo := new(MockNumberingResolutionRepository)
+	service := NewNumberingResolutionService(resolutionRepo)
+
+	existing := createTestResolution("res-1", 1, 1000, 0, 24*time.Hour)
+	resolutionRepo.On("FindByID", ctx, "res-1").Return(existing, nil)
+	resolutionRepo.On("FindAll", ctx).Return([]*dto.NumberingResolution{existing}, nil)
+	resolutionRepo.On("Update", ctx, mock.AnythingOfType("*resolution.Aggregate"), 0).Return(domainError.ErrResolutionChanged)
+
+	req := dto.UpdateNumberingResolutionRequest(*createTestResolutionRequest("NEW", 1, 1000))
+	result, err := service.UpdateResolution(ctx, "res-1", &req)
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, domainError.ErrResolutionChanged)
+}
+
+// DeleteResolution Tests
+
+func TestDeleteResolution_Unused(t *testing.T) {
+	ctx := context.Background()
+	resolutionRepo := new(MockNumberingResolutionRepository)
+	service := NewNumberingResolutionService(resolutionRepo)
+
+	resolutionRepo.On("FindByID", ctx, "res-1").Return(createTestResolution("res-1", 1, 1000, 0, 24*time.Hour), nil)
+	resolutionRepo.On("Delete", ctx, "res-1").Return(nil)
+
+	err := service.DeleteResolution(ctx, "res-1")
+
+	require.NoError(t, err)
+	resolutionRepo.AssertExpectations(t)
+}
+
+func TestDeleteResolution_UsedResolutionIsKept(t *testing.T) {
+	ctx := context.Background()
+	resolutionRepo := new(MockNumberingResolutionRepository)
+	service := NewNumberingResolutionService(resolutionRepo)
+
+	resolutionRepo.On("FindByID", ctx, "res-1").Return(createTestResolution("res-1", 1, 1000, 1, 24*time.Hour), nil)
+
+	err := service.DeleteResolution(ctx, "res-1")
+
+	assert.ErrorIs(t, err, domainError.ErrResolutionInUse)
+	resolutionRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+// GetActiveResolution Tests
+
+func TestGetActiveResolution_NoWarnings(t *testing.T) {
+	ctx := context.Background()
+	resolutionRepo := new(MockNumberingResolutionRepository)
+	service := NewNumberingResolutionService(resolutionRepo)
+
+	resolutionRepo.On("FindActive", ctx, mock.AnythingOfType("time.Time")).Return(createTestResolution("res-1", 1, 5000, 10, 365*24*time.Hour), nil)
+
+	result, err := service.GetActiveResolution(ctx)
+
+	require.NoError(t, err)
+	assert.True(t, result.Active)
+	assert.Equal(t, 4990, result.Remaining)
+	assert.Empty(t, result.Warnings)
+}
+
+func TestGetActiveResolution_WarnsWhenRunningOut(t *testing.T) {
+	ctx := context.Background()
+	resolutionRepo := new(MockNumberingResolutionRepository)
+	service := NewNumberingResolutionService(resolutionRepo)
+
+	active := createTestResolution("res-1", 1, 5000, 10, 10*24*time.Hour)
+	providerRemaining := 40
+	active.ProviderRemaining = &providerRemaining
+	resolutionRepo.On("FindActive", ctx, mock.AnythingOfType("time.Time")).Return(active, nil)
+
+	result, err := service.GetActiveResolution(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, 40, result.Remaining, "the provider count wins when it is lower")
+	require.Len(t, result.Warnings, 2)
+	assert.Contains(t, result.Warnings[0], "only 40 numbers left")
+	assert.Contains(t, result.Warnings[1], "expires in 11 days")
+}
+
+func TestGetActiveResolution_NoActiveResolution(t *testing.T) {
+	ctx := context.Background()
+	resolutionRepo := new(MockNumberingResolutionRepository)
+	service := NewNumberingResolutionService(resolutionRepo)
+
+	resolutionRepo.On("FindActive", ctx, mock.AnythingOfType("time.Time")).Return(nil, errors.New("record not found"))
+
+	result, err := service.GetActiveResolution(ctx)
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, domainError.ErrNoActiveResolution)
+}
+
+func TestGetActiveResolution_OutOfRangeOrDate(t *testing.T) {
+	tests := []struct {
+		name       string
+		resolution *dto.NumberingResolution
+	}{
+		{"range exhausted", createTestResolution("res-1", 1, 1000, 1000, 24*time.Hour)},
+		{"expired", createTestResolution("res-1", 1, 1000, 10, -48*time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			resolutionRepo := new(MockNumberingResolutionRepository)
+			service := NewNumberingResolutionService(resolutionRepo)
+
+			resolutionRepo.On("FindActive", ctx, mock.AnythingOfType("time.Time")).Return(tt.resolution, nil)
+
+			result, err := service.GetActiveResolution(ctx)
+
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, domainError.ErrNoActiveResolution)
+		})
+	}
+}
+
+// ListResolutions Tests
+
+func TestListResolutions_FlagsActive(t *testing.T) {
+	ctx := context.Background()
+	resolutionRepo := new(MockNumberingResolutionRepository)
+	service := NewNumberingResolutionService(resolutionRepo)
+
+	exhausted := createTestResolution("res-1", 1, 1000, 1000, 24*time.Hour)
+	active := createTestResolution("res-2", 1001, 5000, 1010, 365*24*time.Hour)
+	resolutionRepo.On("FindAll", ctx).Return([]*dto.NumberingResolution{exhausted, active}, nil)
+	resolutionRepo.On("FindActive", ctx, mock.AnythingOfType("time.Time")).Return(active, nil)
+
+	result, err := service.ListResolutions(ctx)
+
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.False(t, result[0].Active)
+	assert.Equal(t, 0, result[0].Remaining)
+	assert.True(t, result[1].Active)
+}
@@ -3,11 +3,15 @@ package service
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"laguna-escondida/backend/internal/domain/dto"
 	orderError "laguna-escondida/backend/internal/domain/error"
 	"laguna-escondida/backend/internal/domain/ports"
+	"laguna-escondida/backend/internal/domain/tax"
+
+	"github.com/samber/lo"
 )
 
 type OrderService struct {
@@ -30,16 +34,32 @@ func NewOrderService(
 	}
 }
 
+const (
+	defaultOrdersPageSize = 20
+	maxOrdersPageSize     = 100
+	// orderPaymentLease is how long an order stays in paying before another payment can take
+	// it over, the bill is saved well before unless the process paying it died
+	orderPaymentLease = 2 * time.Minute
+)
+
 // CreateOrder creates a new open order with the specified products
 // If productIDs is empty, creates an empty order
 func (s *OrderService) CreateOrder(ctx context.Context, req *dto.CreateOrderRequest) (*dto.OpenBill, error) {
-	var products []*dto.Product
-	var totalPrice float64
+	// Convert product IDs to OrderProductItem format with default quantity of 1
+	orderProducts := make([]dto.OrderProductItem, len(req.ProductIDs))
+	for i, productID := range req.ProductIDs {
+		orderProducts[i] = dto.OrderProductItem{
+			ProductID: productID,
+			Quantity:  1, // Default quantity for CreateOrder
+		}
+	}
+
+	var lines []dto.OrderLine
+	var totalPrice, vat, ico dto.Money
 
 	// If products are provided, fetch and validate them
 	if len(req.ProductIDs) > 0 {
-		var err error
-		products, err = s.productRepo.FindByIDs(ctx, req.ProductIDs)
+		products, err := s.productRepo.FindByIDs(ctx, req.ProductIDs)
 		if err != nil {
 			return nil, fmt.Errorf("%w: %w", orderError.ErrOrderCreationFailed, err)
 		}
@@ -49,23 +69,23 @@ func (s *OrderService) CreateOrder(ctx context.Context, req *dto.CreateOrderRequ
 			return nil, orderError.ErrProductNotFound
 		}
 
-		// Calculate total price from products
-		for _, product := range products {
-			totalPrice += product.TotalPriceWithTaxes
+		lines, err = buildOrderLines(products, orderProducts)
+		if err != nil {
+			return nil, err
 		}
+
+		totalPrice, vat, ico = sumOrderLines(lines)
 	}
 
-	// Calculate taxes and tip based on total_price
-	// Note: All taxes are included in the prices, so we calculate based on total_price
-	vat := totalPrice * s.taxConfig.VATPercent
-	ico := totalPrice * s.taxConfig.ICOPercent
-	tip := totalPrice * s.taxConfig.TipPercent
+	// VAT and ICO are the sum of each line's taxes, the tip is suggested over total_price
+	tip := totalPrice.MulRate(s.taxConfig.TipPercent)
 
 	// Generate temporal identifier (simple timestamp-based for now)
 	temporalIdentifier := fmt.Sprintf("ORDER-%d", time.Now().UnixNano())
 
 	openBill := &dto.OpenBill{
 		TemporalIdentifier: temporalIdentifier,
+		Status:             dto.OpenBillStatusOpen,
 		TotalPrice:         totalPrice,
 		VAT:                vat,
 		ICO:                ico,
@@ -75,28 +95,12 @@ func (s *OrderService) CreateOrder(ctx context.Context, req *dto.CreateOrderRequ
 		UpdatedAt:          time.Now(),
 	}
 
-	// Convert product IDs to OrderProductItem format with default quantity of 1
-	orderProducts := make([]dto.OrderProductItem, len(req.ProductIDs))
-	for i, productID := range req.ProductIDs {
-		orderProducts[i] = dto.OrderProductItem{
-			ProductID: productID,
-			Quantity:  1, // Default quantity for CreateOrder
-		}
-	}
-
-	// Create the open bill in the repository
-	if err := s.openBillRepo.Create(ctx, openBill, orderProducts); err != nil {
+	// Create the open bill in the repository, each line keeps the price it was charged at
+	if err := s.openBillRepo.Create(ctx, openBill, pricedOrderItems(lines)); err != nil {
 		return nil, fmt.Errorf("%w: %w", orderError.ErrOrderCreationFailed, err)
 	}
 
-	// Populate products in the response
-	if len(products) > 0 {
-		productDTOs := make([]dto.Product, len(products))
-		for i, p := range products {
-			productDTOs[i] = *p
-		}
-		openBill.Products = productDTOs
-	}
+	openBill.Products = lines
 
 	return openBill, nil
 }
@@ -112,20 +116,27 @@ func (s *OrderService) UpdateOrder(ctx context.Context, openBillID string, req *
 		return nil, fmt.Errorf("%w: %w", orderError.ErrOrderNotFound, err)
 	}
 
+	if existingBill.Status != dto.OpenBillStatusOpen {
+		return nil, orderError.ErrInvalidOrderStatus
+	}
+
+	if existingBill.SplitAmount != nil {
+		return nil, orderError.ErrOrderIsSplitShare
+	}
+
 	// If no products provided, treat as empty order (all products will be soft deleted)
-	var products []*dto.Product
-	var totalPrice float64
-	var productIDs []string
+	var lines []dto.OrderLine
+	var totalPrice, vat, ico dto.Money
 
 	if len(req.Products) > 0 {
 		// Extract product IDs from request
-		productIDs = make([]string, len(req.Products))
+		productIDs := make([]string, len(req.Products))
 		for i, item := range req.Products {
 			productIDs[i] = item.ProductID
 		}
 
 		// Fetch and validate products
-		products, err = s.productRepo.FindByIDs(ctx, productIDs)
+		products, err := s.productRepo.FindByIDs(ctx, productIDs)
 		if err != nil {
 			return nil, fmt.Errorf("%w: %w", orderError.ErrOrderUpdateFailed, err)
 		}
@@ -135,21 +146,22 @@ func (s *OrderService) UpdateOrder(ctx context.Context, openBillID string, req *
 			return nil, orderError.ErrProductNotFound
 		}
 
-		// Calculate total price from products with quantities
-		for i, product := range products {
-			totalPrice += product.TotalPriceWithTaxes * float64(req.Products[i].Quantity)
+		lines, err = buildOrderLines(products, req.Products)
+		if err != nil {
+			return nil, err
 		}
+
+		totalPrice, vat, ico = sumOrderLines(lines)
 	}
 
-	// Calculate taxes and tip based on total_price
-	vat := totalPrice * s.taxConfig.VATPercent
-	ico := totalPrice * s.taxConfig.ICOPercent
-	tip := totalPrice * s.taxConfig.TipPercent
+	// VAT and ICO are the sum of each line's taxes, the tip is suggested over total_price
+	tip := totalPrice.MulRate(s.taxConfig.TipPercent)
 
 	// Prepare updated open bill
 	updatedBill := &dto.OpenBill{
 		ID:                 existingBill.ID,
 		TemporalIdentifier: existingBill.TemporalIdentifier,
+		Status:             existingBill.Status,
 		TotalPrice:         totalPrice,
 		VAT:                vat,
 		ICO:                ico,
@@ -159,37 +171,765 @@ func (s *OrderService) UpdateOrder(ctx context.Context, openBillID string, req *
 		UpdatedAt:          time.Now(),
 	}
 
-	// Update the open bill in the repository
-	if err := s.openBillRepo.Update(ctx, openBillID, updatedBill, req.Products); err != nil {
+	// Update the open bill in the repository, every line is charged at the current catalog price
+	if err := s.openBillRepo.Update(ctx, openBillID, updatedBill, pricedOrderItems(lines)); err != nil {
 		return nil, fmt.Errorf("%w: %w", orderError.ErrOrderUpdateFailed, err)
 	}
 
-	// Populate products in the response
-	if len(products) > 0 {
-		productDTOs := make([]dto.Product, len(products))
-		for i, p := range products {
-			productDTOs[i] = *p
+	updatedBill.Products = lines
+
+	return updatedBill, nil
+}
+
+// buildOrderLines pairs each order item with its product, keeping the items order
+// Line taxes are extracted from the product's price with taxes using its own VAT/ICO rates,
+// items read back from an order are charged at the price they were saved with
+func buildOrderLines(products []*dto.Product, items []dto.OrderProductItem) ([]dto.OrderLine, error) {
+	productsByID := lo.KeyBy(products, func(product *dto.Product) string {
+		return product.ID
+	})
+
+	lines := make([]dto.OrderLine, 0, len(items))
+	for _, item := range items {
+		catalogProduct, ok := productsByID[item.ProductID]
+		if !ok {
+			return nil, orderError.ErrProductNotFound
+		}
+
+		product := *catalogProduct
+		if item.Price != nil {
+			product.TotalPriceWithTaxes = item.Price.TotalPriceWithTaxes
+			product.VAT = item.Price.VAT
+			product.ICO = item.Price.ICO
+		}
+		product.UnitPrice = tax.ExtractFromUnitPrice(product.TotalPriceWithTaxes, 1, product.VAT, product.ICO).Base
+
+		breakdown := tax.ExtractFromUnitPrice(product.TotalPriceWithTaxes, item.Quantity, product.VAT, product.ICO)
+		lines = append(lines, dto.OrderLine{
+			Product:  product,
+			Quantity: item.Quantity,
+			Subtotal: breakdown.Base,
+			VAT:      breakdown.VAT,
+			ICO:      breakdown.ICO,
+			Total:    breakdown.Total,
+		})
+	}
+
+	return lines, nil
+}
+
+// pricedOrderItems returns the items of the lines with the price each one is charged at, so
+// the order is paid at the amounts it showed even if the catalog changes meanwhile
+func pricedOrderItems(lines []dto.OrderLine) []dto.OrderProductItem {
+	return lo.Map(lines, func(line dto.OrderLine, _ int) dto.OrderProductItem {
+		return dto.OrderProductItem{
+			ProductID: line.Product.ID,
+			Quantity:  line.Quantity,
+			Price: &dto.OrderItemPrice{
+				TotalPriceWithTaxes: line.Product.TotalPriceWithTaxes,
+				VAT:                 line.Product.VAT,
+				ICO:                 line.Product.ICO,
+			},
 		}
-		updatedBill.Products = productDTOs
+	})
+}
+
+// sumOrderLines adds up the lines amounts, taxes are rounded per line so the sums are exact
+func sumOrderLines(lines []dto.OrderLine) (dto.Money, dto.Money, dto.Money) {
+	var total, vat, ico dto.Money
+	for _, line := range lines {
+		total = total.Add(line.Total)
+		vat = vat.Add(line.VAT)
+		ico = ico.Add(line.ICO)
 	}
 
-	return updatedBill, nil
+	return total, vat, ico
 }
 
-// PayOrder consolidates an open_bill into a bill
-// Moves all information from open_bill to bill (except temporal_identifier)
-// Only moves open_bill_products where deleted_at IS NULL to bill_products
-func (s *OrderService) PayOrder(ctx context.Context, openBillID string) (*dto.Bill, error) {
-	// Validate that the open bill exists
-	if _, err := s.openBillRepo.FindByID(ctx, openBillID); err != nil {
+// shareOrderLines scales the lines so they add up to amount, a share of the lines total
+// Each line keeps its proportion and the rounding remainder goes to the largest line
+func shareOrderLines(lines []dto.OrderLine, amount dto.Money) []dto.OrderLine {
+	total, _, _ := sumOrderLines(lines)
+	if total.IsZero() {
+		return lines
+	}
+
+	largest := 0
+	allocated := dto.Money(0)
+	shares := make([]dto.Money, len(lines))
+	for i, line := range lines {
+		shares[i] = line.Total.Share(amount, total)
+		allocated = allocated.Add(shares[i])
+		if line.Total > lines[largest].Total {
+			largest = i
+		}
+	}
+	shares[largest] = shares[largest].Add(amount.Sub(allocated))
+
+	shared := make([]dto.OrderLine, len(lines))
+	for i, line := range lines {
+		breakdown := tax.ExtractFromTotal(shares[i], line.Product.VAT, line.Product.ICO)
+		shared[i] = dto.OrderLine{
+			Product:  line.Product,
+			Quantity: line.Quantity,
+			Subtotal: breakdown.Base,
+			VAT:      breakdown.VAT,
+			ICO:      breakdown.ICO,
+			Total:    breakdown.Total,
+		}
+	}
+
+	return shared
+}
+
+// PayOrder pays an open order by emitting an electronic invoice built from the
+// order's products and quantities
+// The order is moved to paying before emission so it cannot be edited or paid twice,
+// the bill is saved in the same transaction that marks it paid and links it to the bill
+// An order left in paying by a payment that never finished is paid again after orderPaymentLease
+func (s *OrderService) PayOrder(ctx context.Context, openBillID string, req *dto.PayOrderRequest) (*dto.Bill, error) {
+	existingBill, err := s.openBillRepo.FindByID(ctx, openBillID)
+	if err != nil {
 		return nil, fmt.Errorf("%w: %w", orderError.ErrOrderNotFound, err)
 	}
 
-	// Consolidate the open bill into a bill
-	bill, err := s.openBillRepo.PayOrder(ctx, openBillID)
+	if existingBill.Status != dto.OpenBillStatusOpen && existingBill.Status != dto.OpenBillStatusPaying {
+		return nil, orderError.ErrInvalidOrderStatus
+	}
+
+	if err := s.openBillRepo.StartPayment(ctx, openBillID, time.Now().Add(-orderPaymentLease)); err != nil {
+		return nil, fmt.Errorf("%w: %w", orderError.ErrOrderPaymentFailed, err)
+	}
+
+	bill, err := s.emitOrderInvoice(ctx, existingBill, req)
+	if err != nil {
+		// Reopen the order so it can be fixed and paid again
+		if releaseErr := s.openBillRepo.UpdateStatus(ctx, openBillID, dto.OpenBillStatusPaying, dto.OpenBillStatusOpen, nil); releaseErr != nil {
+			return nil, fmt.Errorf("%w: %w", err, releaseErr)
+		}
+		return nil, err
+	}
+
+	return bill, nil
+}
+
+// emitOrderInvoice invoices the order lines at the prices and taxes the order was charged at,
+// the bill adds up to the order total whatever the catalog says now
+func (s *OrderService) emitOrderInvoice(ctx context.Context, openBill *dto.OpenBill, req *dto.PayOrderRequest) (*dto.Bill, error) {
+	items, err := s.openBillRepo.FindItemsByID(ctx, openBill.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", orderError.ErrOrderPaymentFailed, err)
+	}
+
+	if len(items) == 0 {
+		return nil, orderError.ErrOrderEmpty
+	}
+
+	products, err := s.productRepo.FindByIDs(ctx, lo.Map(items, func(item dto.OrderProductItem, _ int) string {
+		return item.ProductID
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", orderError.ErrOrderPaymentFailed, err)
+	}
+
+	lines, err := buildOrderLines(products, items)
+	if err != nil {
+		return nil, err
+	}
+
+	invoice := &dto.ElectronicInvoice{
+		PaymentCode: req.PaymentCode,
+		Payments:    req.Payments,
+		Tip:         openBill.Tip,
+		Customer:    req.Customer,
+		OpenBillID:  openBill.ID,
+		Items: lo.Map(items, func(item dto.OrderProductItem, _ int) dto.InvoiceItem {
+			return dto.InvoiceItem{
+				ProductID: item.ProductID,
+				Quantity:  item.Quantity,
+			}
+		}),
+	}
+
+	// The order tip is only a suggestion, the customer can change or decline it when paying
+	if req.Tip != nil {
+		invoice.Tip = *req.Tip
+	}
+
+	var bill *dto.Bill
+	if openBill.SplitAmount != nil {
+		// A split part only pays its share of the lines, lines too small to get a centavo of
+		// the share are left out of the invoice
+		shares := lo.Filter(shareOrderLines(lines, *openBill.SplitAmount), func(line dto.OrderLine, _ int) bool {
+			return line.Total.IsPositive()
+		})
+		bill, err = s.invoiceService.CreateElectronicInvoiceFromShares(ctx, invoice, shares)
+	} else {
+		bill, err = s.invoiceService.CreateElectronicInvoiceFromLines(ctx, invoice, lines)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", orderError.ErrOrderPaymentFailed, err)
 	}
 
 	return bill, nil
 }
+
+// CancelOrder cancels an open order, after which it can no longer be updated or paid
+func (s *OrderService) CancelOrder(ctx context.Context, openBillID string) (*dto.OpenBill, error) {
+	existingBill, err := s.openBillRepo.FindByID(ctx, openBillID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", orderError.ErrOrderNotFound, err)
+	}
+
+	if existingBill.Status != dto.OpenBillStatusOpen {
+		return nil, orderError.ErrInvalidOrderStatus
+	}
+
+	if err := s.openBillRepo.UpdateStatus(ctx, openBillID, dto.OpenBillStatusOpen, dto.OpenBillStatusCancelled, nil); err != nil {
+		return nil, fmt.Errorf("%w: %w", orderError.ErrOrderCancelFailed, err)
+	}
+
+	existingBill.Status = dto.OpenBillStatusCancelled
+	existingBill.UpdatedAt = time.Now()
+
+	return existingBill, nil
+}
+
+// GetOrder returns an order by its ID regardless of its status, with its product lines
+func (s *OrderService) GetOrder(ctx context.Context, openBillID string) (*dto.OpenBill, error) {
+	openBill, err := s.openBillRepo.FindByID(ctx, openBillID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", orderError.ErrOrderNotFound, err)
+	}
+
+	items, err := s.openBillRepo.FindItemsByID(ctx, openBillID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return openBill, nil
+	}
+
+	products, err := s.productRepo.FindByIDs(ctx, lo.Map(items, func(item dto.OrderProductItem, _ int) string {
+		return item.ProductID
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := buildOrderLines(products, items)
+	if err != nil {
+		return nil, err
+	}
+
+	if openBill.SplitAmount != nil {
+		lines = shareOrderLines(lines, *openBill.SplitAmount)
+	}
+	openBill.Products = lines
+
+	return openBill, nil
+}
+
+// SplitOrder splits an open order into parts that are paid separately, each part is a
+// new order with its own payment and electronic invoice and the order is marked as split
+//   - items: each part takes the given products and quantities, leftovers form one more part
+//   - even: the order total is divided evenly between the given number of people
+//   - amounts: each part pays the given amount, the amounts must add up to the order total
+func (s *OrderService) SplitOrder(ctx context.Context, openBillID string, req *dto.SplitOrderRequest) (*dto.SplitOrderResponse, error) {
+	existingBill, err := s.openBillRepo.FindByID(ctx, openBillID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", orderError.ErrOrderNotFound, err)
+	}
+
+	if existingBill.Status != dto.OpenBillStatusOpen {
+		return nil, orderError.ErrInvalidOrderStatus
+	}
+
+	if existingBill.SplitAmount != nil {
+		return nil, orderError.ErrOrderIsSplitShare
+	}
+
+	items, err := s.openBillRepo.FindItemsByID(ctx, openBillID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", orderError.ErrOrderSplitFailed, err)
+	}
+
+	if len(items) == 0 {
+		return nil, orderError.ErrOrderEmpty
+	}
+
+	products, err := s.productRepo.FindByIDs(ctx, lo.Map(items, func(item dto.OrderProductItem, _ int) string {
+		return item.ProductID
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", orderError.ErrOrderSplitFailed, err)
+	}
+
+	lines, err := buildOrderLines(products, items)
+	if err != nil {
+		return nil, err
+	}
+	total, _, _ := sumOrderLines(lines)
+
+	// Item parts get their own products, share parts keep every product and pay an amount
+	var partItems [][]dto.OrderProductItem
+	var partAmounts []dto.Money
+	switch req.Mode {
+	case dto.SplitModeItems:
+		partItems, err = splitOrderItems(items, req.Parts)
+	case dto.SplitModeEven:
+		partAmounts, err = splitOrderEvenly(total, req.People)
+	case dto.SplitModeAmounts:
+		partAmounts, err = validateSplitAmounts(total, req.Amounts)
+	default:
+		err = fmt.Errorf("%w: unknown mode %q", orderError.ErrInvalidOrderSplit, req.Mode)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for range partAmounts {
+		partItems = append(partItems, items)
+	}
+
+	now := time.Now()
+	parts := make([]*dto.OpenBill, len(partItems))
+	partLines := make([][]dto.OrderLine, len(partItems))
+	for i := range partItems {
+		partLines[i], err = buildOrderLines(products, partItems[i])
+		if err != nil {
+			return nil, err
+		}
+
+		var splitAmount *dto.Money
+		if partAmounts != nil {
+			splitAmount = &partAmounts[i]
+			partLines[i] = shareOrderLines(partLines[i], partAmounts[i])
+		}
+
+		partTotal, partVAT, partICO := sumOrderLines(partLines[i])
+		parts[i] = &dto.OpenBill{
+			TemporalIdentifier: fmt.Sprintf("%s-%d", existingBill.TemporalIdentifier, i+1),
+			Status:             dto.OpenBillStatusOpen,
+			ParentID:           &existingBill.ID,
+			SplitAmount:        splitAmount,
+			TotalPrice:         partTotal,
+			VAT:                partVAT,
+			ICO:                partICO,
+			Tip:                partTotal.MulRate(s.taxConfig.TipPercent),
+			CreatedAt:          now,
+			UpdatedAt:          now,
+		}
+	}
+
+	if err := s.openBillRepo.Split(ctx, openBillID, items, parts, partItems); err != nil {
+		return nil, fmt.Errorf("%w: %w", orderError.ErrOrderSplitFailed, err)
+	}
+
+	for i, part := range parts {
+		part.Products = partLines[i]
+	}
+
+	existingBill.Status = dto.OpenBillStatusSplit
+	existingBill.Products = lines
+	existingBill.UpdatedAt = now
+
+	return &dto.SplitOrderResponse{
+		Order: existingBill,
+		Parts: parts,
+	}, nil
+}
+
+// splitOrderItems takes the requested quantities out of the order items for each part,
+// quantities nobody asked for form one more part so the whole order is still paid
+// Every part keeps the price the products were charged at in the order
+func splitOrderItems(items []dto.OrderProductItem, parts []dto.SplitOrderPart) ([][]dto.OrderProductItem, error) {
+	remaining := make(map[string]int, len(items))
+	prices := make(map[string]*dto.OrderItemPrice, len(items))
+	for _, item := range items {
+		remaining[item.ProductID] += item.Quantity
+		prices[item.ProductID] = item.Price
+	}
+
+	partItems := make([][]dto.OrderProductItem, 0, len(parts)+1)
+	for _, part := range parts {
+		if len(part.Products) == 0 {
+			return nil, fmt.Errorf("%w: every part needs at least one product", orderError.ErrInvalidOrderSplit)
+		}
+
+		quantities := make(map[string]int, len(part.Products))
+		productIDs := make([]string, 0, len(part.Products))
+		for _, item := range part.Products {
+			if item.Quantity <= 0 {
+				return nil, fmt.Errorf("%w: quantities must be greater than 0", orderError.ErrInvalidOrderSplit)
+			}
+			if item.Quantity > remaining[item.ProductID] {
+				return nil, fmt.Errorf("%w: product %s exceeds the quantity left in the order", orderError.ErrInvalidOrderSplit, item.ProductID)
+			}
+
+			remaining[item.ProductID] -= item.Quantity
+			if _, ok := quantities[item.ProductID]; !ok {
+				productIDs = append(productIDs, item.ProductID)
+			}
+			quantities[item.ProductID] += item.Quantity
+		}
+
+		partItems = append(partItems, lo.Map(productIDs, func(productID string, _ int) dto.OrderProductItem {
+			return dto.OrderProductItem{ProductID: productID, Quantity: quantities[productID], Price: prices[productID]}
+		}))
+	}
+
+	var leftovers []dto.OrderProductItem
+	for _, item := range items {
+		if remaining[item.ProductID] > 0 {
+			leftovers = append(leftovers, dto.OrderProductItem{ProductID: item.ProductID, Quantity: remaining[item.ProductID], Price: item.Price})
+			remaining[item.ProductID] = 0
+		}
+	}
+	if len(leftovers) > 0 {
+		partItems = append(partItems, leftovers)
+	}
+
+	if len(partItems) < 2 {
+		return nil, fmt.Errorf("%w: an order must be split in at least two parts", orderError.ErrInvalidOrderSplit)
+	}
+
+	return partItems, nil
+}
+
+func splitOrderEvenly(total dto.Money, people int) ([]dto.Money, error) {
+	if people < 2 {
+		return nil, fmt.Errorf("%w: people must be at least 2", orderError.ErrInvalidOrderSplit)
+	}
+
+	if total.Cents() < int64(people) {
+		return nil, fmt.Errorf("%w: order total %s cannot be split between %d people", orderError.ErrInvalidOrderSplit, total, people)
+	}
+
+	return total.SplitEvenly(people), nil
+}
+
+func validateSplitAmounts(total dto.Money, amounts []dto.Money) ([]dto.Money, error) {
+	if len(amounts) < 2 {
+		return nil, fmt.Errorf("%w: an order must be split in at least two parts", orderError.ErrInvalidOrderSplit)
+	}
+
+	var sum dto.Money
+	for _, amount := range amounts {
+		if !amount.IsPositive() {
+			return nil, fmt.Errorf("%w: amounts must be greater than 0", orderError.ErrInvalidOrderSplit)
+		}
+		sum = sum.Add(amount)
+	}
+
+	if sum != total {
+		return nil, fmt.Errorf("%w: amounts add up to %s but the order total is %s", orderError.ErrInvalidOrderSplit, sum, total)
+	}
+
+	return amounts, nil
+}
+
+// TransferOrderItems moves product quantities from an open order to another one, both
+// orders get their totals recalculated and every product moved is recorded with who moved it
+func (s *OrderService) TransferOrderItems(ctx context.Context, sourceID string, req *dto.TransferOrderItemsRequest) (*dto.OrderTransferResponse, error) {
+	if len(req.Products) == 0 {
+		return nil, fmt.Errorf("%w: at least one product must be moved", orderError.ErrInvalidOrderTransfer)
+	}
+
+	return s.transferOrderItems(ctx, sourceID, req.TargetOrderID, req.Products, req.MovedBy)
+}
+
+// MergeOrders moves every product of the source order into the target order,
+// the source order is left empty and marked as merged
+func (s *OrderService) MergeOrders(ctx context.Context, targetID string, req *dto.MergeOrdersRequest) (*dto.OrderTransferResponse, error) {
+	return s.transferOrderItems(ctx, req.SourceOrderID, targetID, nil, req.MovedBy)
+}
+
+// transferOrderItems moves the given products from source to target, or all of them when
+// products is nil, in which case the source order is merged into the target order
+func (s *OrderService) transferOrderItems(ctx context.Context, sourceID, targetID string, products []dto.OrderProductItem, movedBy string) (*dto.OrderTransferResponse, error) {
+	if strings.TrimSpace(movedBy) == "" {
+		return nil, fmt.Errorf("%w: moved_by is required", orderError.ErrInvalidOrderTransfer)
+	}
+
+	if sourceID == targetID {
+		return nil, fmt.Errorf("%w: source and target orders must be different", orderError.ErrInvalidOrderTransfer)
+	}
+
+	source, err := s.findTransferableOrder(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.findTransferableOrder(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	var moved []dto.OrderProductItem
+	if products != nil {
+		moved, err = mergeOrderItems(products)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The quantities are validated against the products the orders hold once the
+	// repository locks them, errors worked out there are returned unwrapped
+	now := time.Now()
+	var applyErr error
+	transfer, err := s.openBillRepo.Transfer(ctx, sourceID, targetID, func(sourceItems, targetItems []dto.OrderProductItem) (*dto.OrderItemsTransfer, error) {
+		transfer, err := s.planOrderTransfer(ctx, source, sourceItems, target, targetItems, moved, movedBy, now)
+		applyErr = err
+		return transfer, err
+	})
+	if applyErr != nil {
+		return nil, applyErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", orderError.ErrOrderTransferFailed, err)
+	}
+
+	return &dto.OrderTransferResponse{
+		Source:    transfer.Source,
+		Target:    transfer.Target,
+		Transfers: transfer.Transfers,
+	}, nil
+}
+
+// planOrderTransfer moves the given items, or every source item when moved is nil, from the
+// source items to the target items and recalculates both orders with their new lines
+func (s *OrderService) planOrderTransfer(ctx context.Context, source *dto.OpenBill, sourceItems []dto.OrderProductItem, target *dto.OpenBill, targetItems []dto.OrderProductItem, moved []dto.OrderProductItem, movedBy string, now time.Time) (*dto.OrderItemsTransfer, error) {
+	merge := moved == nil
+	if merge {
+		moved = sourceItems
+	}
+
+	newSourceItems, moved, err := removeOrderItems(sourceItems, moved)
+	if err != nil {
+		return nil, err
+	}
+	newTargetItems := addOrderItems(targetItems, moved)
+
+	allItems := append(append([]dto.OrderProductItem{}, sourceItems...), targetItems...)
+	orderProducts, err := s.productRepo.FindByIDs(ctx, lo.Uniq(lo.Map(allItems, func(item dto.OrderProductItem, _ int) string {
+		return item.ProductID
+	})))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", orderError.ErrOrderTransferFailed, err)
+	}
+
+	source.Products, err = s.recalculateOrder(source, orderProducts, newSourceItems, now)
+	if err != nil {
+		return nil, err
+	}
+	target.Products, err = s.recalculateOrder(target, orderProducts, newTargetItems, now)
+	if err != nil {
+		return nil, err
+	}
+	if merge {
+		source.Status = dto.OpenBillStatusMerged
+	}
+
+	transfers := lo.Map(moved, func(item dto.OrderProductItem, _ int) dto.OrderTransfer {
+		return dto.OrderTransfer{
+			SourceOrderID: source.ID,
+			TargetOrderID: target.ID,
+			ProductID:     item.ProductID,
+			Quantity:      item.Quantity,
+			MovedBy:       movedBy,
+			CreatedAt:     now,
+		}
+	})
+
+	return &dto.OrderItemsTransfer{
+		Source:      source,
+		SourceItems: newSourceItems,
+		Target:      target,
+		TargetItems: newTargetItems,
+		Transfers:   transfers,
+	}, nil
+}
+
+func (s *OrderService) findTransferableOrder(ctx context.Context, openBillID string) (*dto.OpenBill, error) {
+	openBill, err := s.openBillRepo.FindByID(ctx, openBillID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", orderError.ErrOrderNotFound, err)
+	}
+
+	if openBill.Status != dto.OpenBillStatusOpen {
+		return nil, orderError.ErrInvalidOrderStatus
+	}
+
+	if openBill.SplitAmount != nil {
+		return nil, orderError.ErrOrderIsSplitShare
+	}
+
+	return openBill, nil
+}
+
+// recalculateOrder sets the order totals from its new items and returns its lines
+func (s *OrderService) recalculateOrder(openBill *dto.OpenBill, products []*dto.Product, items []dto.OrderProductItem, now time.Time) ([]dto.OrderLine, error) {
+	lines, err := buildOrderLines(products, items)
+	if err != nil {
+		return nil, err
+	}
+
+	openBill.TotalPrice, openBill.VAT, openBill.ICO = sumOrderLines(lines)
+	openBill.Tip = openBill.TotalPrice.MulRate(s.taxConfig.TipPercent)
+	openBill.UpdatedAt = now
+
+	return lines, nil
+}
+
+// mergeOrderItems validates the requested items and adds up repeated products
+func mergeOrderItems(items []dto.OrderProductItem) ([]dto.OrderProductItem, error) {
+	merged := make([]dto.OrderProductItem, 0, len(items))
+	indexes := make(map[string]int, len(items))
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: quantities must be greater than 0", orderError.ErrInvalidOrderTransfer)
+		}
+
+		if i, ok := indexes[item.ProductID]; ok {
+			merged[i].Quantity += item.Quantity
+			continue
+		}
+		indexes[item.ProductID] = len(merged)
+		merged = append(merged, item)
+	}
+
+	return merged, nil
+}
+
+// removeOrderItems takes the moved quantities out of the items, products left without
+// quantity are dropped from the order. The moved items are returned with the price they
+// were charged at in the order they leave
+func removeOrderItems(items []dto.OrderProductItem, moved []dto.OrderProductItem) ([]dto.OrderProductItem, []dto.OrderProductItem, error) {
+	quantities := make(map[string]int, len(items))
+	prices := make(map[string]*dto.OrderItemPrice, len(items))
+	for _, item := range items {
+		quantities[item.ProductID] = item.Quantity
+		prices[item.ProductID] = item.Price
+	}
+
+	priced := make([]dto.OrderProductItem, len(moved))
+	for i, item := range moved {
+		available, ok := quantities[item.ProductID]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: product %s is not in the source order", orderError.ErrInvalidOrderTransfer, item.ProductID)
+		}
+		if item.Quantity > available {
+			return nil, nil, fmt.Errorf("%w: product %s exceeds the quantity in the source order", orderError.ErrInvalidOrderTransfer, item.ProductID)
+		}
+		quantities[item.ProductID] = available - item.Quantity
+		priced[i] = dto.OrderProductItem{ProductID: item.ProductID, Quantity: item.Quantity, Price: prices[item.ProductID]}
+	}
+
+	remaining := make([]dto.OrderProductItem, 0, len(items))
+	for _, item := range items {
+		if quantity := quantities[item.ProductID]; quantity > 0 {
+			remaining = append(remaining, dto.OrderProductItem{ProductID: item.ProductID, Quantity: quantity, Price: item.Price})
+		}
+	}
+
+	return remaining, priced, nil
+}
+
+// addOrderItems adds the moved quantities to the items, new products go last and a product
+// already in the order keeps the price it is charged at there
+func addOrderItems(items []dto.OrderProductItem, moved []dto.OrderProductItem) []dto.OrderProductItem {
+	result := append([]dto.OrderProductItem{}, items...)
+	indexes := make(map[string]int, len(items))
+	for i, item := range result {
+		indexes[item.ProductID] = i
+	}
+
+	for _, item := range moved {
+		if i, ok := indexes[item.ProductID]; ok {
+			result[i].Quantity += item.Quantity
+			continue
+		}
+		indexes[item.ProductID] = len(result)
+		result = append(result, item)
+	}
+
+	return result
+}
+
+// ListOrders returns a page of orders matching the filter
+// Defaults to the most recent orders first, 20 per page
+func (s *OrderService) ListOrders(ctx context.Context, req *dto.ListOrdersRequest) (*dto.OpenBillListResponse, error) {
+	filter, err := normalizeListOrdersRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := s.openBillRepo.FindAll(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", orderError.ErrOrderListFailed, err)
+	}
+
+	total, err := s.openBillRepo.Count(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", orderError.ErrOrderListFailed, err)
+	}
+
+	return &dto.OpenBillListResponse{
+		Orders:   orders,
+		Total:    total,
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+	}, nil
+}
+
+func normalizeListOrdersRequest(req *dto.ListOrdersRequest) (*dto.ListOrdersRequest, error) {
+	filter := dto.ListOrdersRequest{}
+	if req != nil {
+		filter = *req
+	}
+
+	for _, status := range filter.Statuses {
+		switch status {
+		case dto.OpenBillStatusOpen, dto.OpenBillStatusPaying, dto.OpenBillStatusPaid, dto.OpenBillStatusCancelled, dto.OpenBillStatusSplit, dto.OpenBillStatusMerged:
+		default:
+			return nil, fmt.Errorf("%w: unknown status %q", orderError.ErrInvalidOrderFilter, status)
+		}
+	}
+
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && filter.CreatedFrom.After(*filter.CreatedTo) {
+		return nil, fmt.Errorf("%w: created_from must be before created_to", orderError.ErrInvalidOrderFilter)
+	}
+
+	switch filter.SortBy {
+	case "":
+		filter.SortBy = dto.OpenBillSortFieldCreatedAt
+	case dto.OpenBillSortFieldCreatedAt, dto.OpenBillSortFieldUpdatedAt, dto.OpenBillSortFieldTotalPrice:
+	default:
+		return nil, fmt.Errorf("%w: unknown sort field %q", orderError.ErrInvalidOrderFilter, filter.SortBy)
+	}
+
+	switch filter.SortOrder {
+	case "":
+		filter.SortOrder = dto.SortOrderDesc
+	case dto.SortOrderAsc, dto.SortOrderDesc:
+	default:
+		return nil, fmt.Errorf("%w: unknown sort order %q", orderError.ErrInvalidOrderFilter, filter.SortOrder)
+	}
+
+	if filter.Page < 0 || filter.PageSize < 0 {
+		return nil, fmt.Errorf("%w: page and page_size must be positive", orderError.ErrInvalidOrderFilter)
+	}
+	if filter.Page == 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize == 0 {
+		filter.PageSize = defaultOrdersPageSize
+	}
+	if filter.PageSize > maxOrdersPageSize {
+		filter.PageSize = maxOrdersPageSize
+	}
+
+	return &filter, nil
+}
@@ -3,9 +3,11 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
+	"laguna-escondida/backend/internal/domain/aggregate/bill"
 	"laguna-escondida/backend/internal/domain/aggregate/product"
 	"laguna-escondida/backend/internal/domain/dto"
 	orderError "laguna-escondida/backend/internal/domain/error"
@@ -63,6 +65,8 @@ func (m *MockProductRepository) FindByIDs(ctx context.Context, ids []string) ([]
 // MockOpenBillRepository is a mock implementation of ports.OpenBillRepository
 type MockOpenBillRepository struct {
 	mock.Mock
+	// transferred is what the last successful Transfer saved
+	transferred *dto.OrderItemsTransfer
 }
 
 func (m *MockOpenBillRepository) Create(ctx context.Context, openBill *dto.OpenBill, products []dto.OrderProductItem) error {
@@ -83,12 +87,56 @@ func (m *MockOpenBillRepository) Update(ctx context.Context, openBillID string,
 	return args.Error(0)
 }
 
-func (m *MockOpenBillRepository) PayOrder(ctx context.Context, openBillID string) (*dto.Bill, error) {
-	args := m.Called(ctx, openBillID)
+func (m *MockOpenBillRepository) FindAll(ctx context.Context, filter *dto.ListOrdersRequest) ([]*dto.OpenBill, error) {
+	args := m.Called(ctx, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*dto.Bill), args.Error(1)
+	return args.Get(0).([]*dto.OpenBill), args.Error(1)
+}
+
+func (m *MockOpenBillRepository) Count(ctx context.Context, filter *dto.ListOrdersRequest) (int, error) {
+	args := m.Called(ctx, filter)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockOpenBillRepository) FindItemsByID(ctx context.Context, id string) ([]dto.OrderProductItem, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.OrderProductItem), args.Error(1)
+}
+
+func (m *MockOpenBillRepository) UpdateStatus(ctx context.Context, openBillID string, from dto.OpenBillStatus, to dto.OpenBillStatus, billID *string) error {
+	args := m.Called(ctx, openBillID, from, to, billID)
+	return args.Error(0)
+}
+
+func (m *MockOpenBillRepository) StartPayment(ctx context.Context, openBillID string, staleBefore time.Time) error {
+	args := m.Called(ctx, openBillID, staleBefore)
+	return args.Error(0)
+}
+
+// Transfer hands apply the products the expectation returns as the ones both open bills hold once locked
+func (m *MockOpenBillRepository) Transfer(ctx context.Context, sourceID string, targetID string, apply ports.OrderTransferFunc) (*dto.OrderItemsTransfer, error) {
+	args := m.Called(ctx, sourceID, targetID)
+	if err := args.Error(2); err != nil {
+		return nil, err
+	}
+
+	transfer, err := apply(args.Get(0).([]dto.OrderProductItem), args.Get(1).([]dto.OrderProductItem))
+	if err != nil {
+		return nil, err
+	}
+	m.transferred = transfer
+
+	return transfer, nil
+}
+
+func (m *MockOpenBillRepository) Split(ctx context.Context, openBillID string, splitItems []dto.OrderProductItem, parts []*dto.OpenBill, items [][]dto.OrderProductItem) error {
+	args := m.Called(ctx, openBillID, splitItems, parts, items)
+	return args.Error(0)
 }
 
 // Test helpers
@@ -102,17 +150,28 @@ func createTestProduct(id, name, category string, version int, price, vat float6
 		Name:                name,
 		Category:            category,
 		Version:             version,
-		TotalPriceWithTaxes: price,
+		TotalPriceWithTaxes: dto.NewMoneyFromFloat(price),
 		VAT:                 vat,
 		CreatedAt:           time.Now(),
 		UpdatedAt:           time.Now(),
 	}
 }
 
+// createTestTaxedProduct creates a product whose price includes 19% VAT and 8% ICO
+func createTestTaxedProduct(id, name, category string, version int, price float64) *dto.Product {
+	product := createTestProduct(id, name, category, version, price, 0.19)
+	product.ICO = 0.08
+	return product
+}
+
 func createTestService(productRepo ports.ProductRepository, openBillRepo ports.OpenBillRepository) *OrderService {
 	return NewOrderService(openBillRepo, productRepo, nil)
 }
 
+func createTestServiceWithInvoice(productRepo ports.ProductRepository, openBillRepo ports.OpenBillRepository, billRepo ports.BillRepository) *OrderService {
+	return NewOrderService(openBillRepo, productRepo, NewInvoiceService(nil, productRepo, billRepo, nil, nil, newTestContingencyRepo(), testIssuer))
+}
+
 // Success Cases
 
 func TestCreateOrder_EmptyOrder(t *testing.T) {
@@ -135,10 +194,10 @@ func TestCreateOrder_EmptyOrder(t *testing.T) {
 	// Assert
 	require.NoError(t, err)
 	assert.NotNil(t, result)
-	assert.Equal(t, 0.0, result.TotalPrice)
-	assert.Equal(t, 0.0, result.VAT)
-	assert.Equal(t, 0.0, result.ICO)
-	assert.Equal(t, 0.0, result.Tip)
+	assert.Equal(t, dto.NewMoneyFromFloat(0.0), result.TotalPrice)
+	assert.Equal(t, dto.NewMoneyFromFloat(0.0), result.VAT)
+	assert.Equal(t, dto.NewMoneyFromFloat(0.0), result.ICO)
+	assert.Equal(t, dto.NewMoneyFromFloat(0.0), result.Tip)
 	assert.Contains(t, result.TemporalIdentifier, "ORDER-")
 	assert.Empty(t, result.Products)
 	assert.NotZero(t, result.CreatedAt)
@@ -158,7 +217,7 @@ func TestCreateOrder_SingleProduct(t *testing.T) {
 
 	productID := "product-1"
 	productPrice := 100.0
-	product := createTestProduct(productID, "Test Product", "Category", 1, productPrice, 19.0)
+	product := createTestTaxedProduct(productID, "Test Product", "Category", 1, productPrice)
 
 	req := &dto.CreateOrderRequest{
 		ProductIDs: []string{productID},
@@ -179,13 +238,13 @@ func TestCreateOrder_SingleProduct(t *testing.T) {
 	// Assert
 	require.NoError(t, err)
 	assert.NotNil(t, result)
-	assert.Equal(t, productPrice, result.TotalPrice)
-	assert.InDelta(t, productPrice*0.19, result.VAT, 0.01)
-	assert.InDelta(t, productPrice*0.08, result.ICO, 0.01)
-	assert.InDelta(t, productPrice*0.10, result.Tip, 0.01)
+	assert.Equal(t, dto.NewMoneyFromFloat(productPrice), result.TotalPrice)
+	assert.InDelta(t, productPrice/1.27*0.19, result.VAT.Float64(), 0.05)
+	assert.InDelta(t, productPrice/1.27*0.08, result.ICO.Float64(), 0.05)
+	assert.InDelta(t, productPrice*0.10, result.Tip.Float64(), 0.01)
 	assert.Contains(t, result.TemporalIdentifier, "ORDER-")
 	assert.Len(t, result.Products, 1)
-	assert.Equal(t, productID, result.Products[0].ID)
+	assert.Equal(t, productID, result.Products[0].Product.ID)
 
 	// Verify mocks
 	mockProductRepo.AssertExpectations(t)
@@ -199,9 +258,9 @@ func TestCreateOrder_MultipleProducts(t *testing.T) {
 	mockOpenBillRepo := new(MockOpenBillRepository)
 	service := createTestService(mockProductRepo, mockOpenBillRepo)
 
-	product1 := createTestProduct("product-1", "Product 1", "Category", 1, 50.0, 9.5)
-	product2 := createTestProduct("product-2", "Product 2", "Category", 1, 75.0, 14.25)
-	product3 := createTestProduct("product-3", "Product 3", "Category", 1, 25.0, 4.75)
+	product1 := createTestTaxedProduct("product-1", "Product 1", "Category", 1, 50.0)
+	product2 := createTestTaxedProduct("product-2", "Product 2", "Category", 1, 75.0)
+	product3 := createTestTaxedProduct("product-3", "Product 3", "Category", 1, 25.0)
 
 	productIDs := []string{"product-1", "product-2", "product-3"}
 	expectedTotal := 150.0
@@ -233,10 +292,10 @@ func TestCreateOrder_MultipleProducts(t *testing.T) {
 	// Assert
 	require.NoError(t, err)
 	assert.NotNil(t, result)
-	assert.Equal(t, expectedTotal, result.TotalPrice)
-	assert.InDelta(t, expectedTotal*0.19, result.VAT, 0.01)
-	assert.InDelta(t, expectedTotal*0.08, result.ICO, 0.01)
-	assert.InDelta(t, expectedTotal*0.10, result.Tip, 0.01)
+	assert.Equal(t, dto.NewMoneyFromFloat(expectedTotal), result.TotalPrice)
+	assert.InDelta(t, expectedTotal/1.27*0.19, result.VAT.Float64(), 0.05)
+	assert.InDelta(t, expectedTotal/1.27*0.08, result.ICO.Float64(), 0.05)
+	assert.InDelta(t, expectedTotal*0.10, result.Tip.Float64(), 0.01)
 	assert.Contains(t, result.TemporalIdentifier, "ORDER-")
 	assert.Len(t, result.Products, 3)
 
@@ -398,22 +457,22 @@ func TestCreateOrder_TaxCalculations(t *testing.T) {
 		{
 			name:        "Price 100",
 			price:       100.0,
-			expectedVAT: 19.0,
-			expectedICO: 8.0,
+			expectedVAT: 14.96,
+			expectedICO: 6.30,
 			expectedTip: 10.0,
 		},
 		{
 			name:        "Price 50",
 			price:       50.0,
-			expectedVAT: 9.5,
-			expectedICO: 4.0,
+			expectedVAT: 7.48,
+			expectedICO: 3.15,
 			expectedTip: 5.0,
 		},
 		{
 			name:        "Price 200",
 			price:       200.0,
-			expectedVAT: 38.0,
-			expectedICO: 16.0,
+			expectedVAT: 29.92,
+			expectedICO: 12.60,
 			expectedTip: 20.0,
 		},
 	}
@@ -424,7 +483,7 @@ func TestCreateOrder_TaxCalculations(t *testing.T) {
 			mockProductRepo.ExpectedCalls = nil
 			mockOpenBillRepo.ExpectedCalls = nil
 
-			product := createTestProduct("product-1", "Test Product", "Category", 1, tc.price, tc.expectedVAT)
+			product := createTestTaxedProduct("product-1", "Test Product", "Category", 1, tc.price)
 			req := &dto.CreateOrderRequest{
 				ProductIDs: []string{"product-1"},
 			}
@@ -440,14 +499,50 @@ func TestCreateOrder_TaxCalculations(t *testing.T) {
 
 			// Assert
 			require.NoError(t, err)
-			assert.Equal(t, tc.price, result.TotalPrice)
-			assert.InDelta(t, tc.expectedVAT, result.VAT, 0.01)
-			assert.InDelta(t, tc.expectedICO, result.ICO, 0.01)
-			assert.InDelta(t, tc.expectedTip, result.Tip, 0.01)
+			assert.Equal(t, dto.NewMoneyFromFloat(tc.price), result.TotalPrice)
+			assert.InDelta(t, tc.expectedVAT, result.VAT.Float64(), 0.01)
+			assert.InDelta(t, tc.expectedICO, result.ICO.Float64(), 0.01)
+			assert.InDelta(t, tc.expectedTip, result.Tip.Float64(), 0.01)
 		})
 	}
 }
 
+func TestCreateOrder_PerProductTaxRates(t *testing.T) {
+	// Setup
+	ctx := createTestContext()
+	mockProductRepo := new(MockProductRepository)
+	mockOpenBillRepo := new(MockOpenBillRepository)
+	service := createTestService(mockProductRepo, mockOpenBillRepo)
+
+	// A beer only pays VAT and a dish only pays ICO
+	beer := createTestProduct("product-1", "Beer", "Drinks", 1, 11900.0, 0.19)
+	dish := createTestProduct("product-2", "Dish", "Food", 1, 32400.0, 0.0)
+	dish.ICO = 0.08
+
+	req := &dto.CreateOrderRequest{
+		ProductIDs: []string{"product-1", "product-2"},
+	}
+
+	// Mock expectations
+	mockProductRepo.On("FindByIDs", ctx, req.ProductIDs).Return([]*dto.Product{beer, dish}, nil)
+	mockOpenBillRepo.On("Create", ctx, mock.AnythingOfType("*dto.OpenBill"), mock.Anything).Return(nil)
+
+	// Execute
+	result, err := service.CreateOrder(ctx, req)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, dto.NewMoneyFromFloat(44300.0), result.TotalPrice)
+	assert.Equal(t, dto.NewMoneyFromFloat(1900.0), result.VAT)
+	assert.Equal(t, dto.NewMoneyFromFloat(2400.0), result.ICO)
+	require.Len(t, result.Products, 2)
+	for _, line := range result.Products {
+		assert.Equal(t, line.Total, line.Subtotal.Add(line.VAT).Add(line.ICO))
+	}
+	assert.Equal(t, dto.NewMoneyFromFloat(10000.0), result.Products[0].Subtotal)
+	assert.Equal(t, dto.NewMoneyFromFloat(30000.0), result.Products[1].Subtotal)
+}
+
 func TestCreateOrder_TemporalIdentifierFormat(t *testing.T) {
 	// Setup
 	ctx := createTestContext()
@@ -532,10 +627,10 @@ func TestCreateOrder_ZeroPriceProducts(t *testing.T) {
 
 	// Assert
 	require.NoError(t, err)
-	assert.Equal(t, 0.0, result.TotalPrice)
-	assert.Equal(t, 0.0, result.VAT)
-	assert.Equal(t, 0.0, result.ICO)
-	assert.Equal(t, 0.0, result.Tip)
+	assert.Equal(t, dto.NewMoneyFromFloat(0.0), result.TotalPrice)
+	assert.Equal(t, dto.NewMoneyFromFloat(0.0), result.VAT)
+	assert.Equal(t, dto.NewMoneyFromFloat(0.0), result.ICO)
+	assert.Equal(t, dto.NewMoneyFromFloat(0.0), result.Tip)
 
 	// Verify mocks
 	mockProductRepo.AssertExpectations(t)
@@ -550,7 +645,7 @@ func TestCreateOrder_LargePriceValues(t *testing.T) {
 	service := createTestService(mockProductRepo, mockOpenBillRepo)
 
 	largePrice := 999999999.99
-	product := createTestProduct("product-1", "Expensive Product", "Category", 1, largePrice, 189999999.998)
+	product := createTestTaxedProduct("product-1", "Expensive Product", "Category", 1, largePrice)
 
 	req := &dto.CreateOrderRequest{
 		ProductIDs: []string{"product-1"},
@@ -567,10 +662,10 @@ func TestCreateOrder_LargePriceValues(t *testing.T) {
 
 	// Assert
 	require.NoError(t, err)
-	assert.Equal(t, largePrice, result.TotalPrice)
-	assert.InDelta(t, largePrice*0.19, result.VAT, 0.01)
-	assert.InDelta(t, largePrice*0.08, result.ICO, 0.01)
-	assert.InDelta(t, largePrice*0.10, result.Tip, 0.01)
+	assert.Equal(t, dto.NewMoneyFromFloat(largePrice), result.TotalPrice)
+	assert.InDelta(t, largePrice/1.27*0.19, result.VAT.Float64(), 0.05)
+	assert.InDelta(t, largePrice/1.27*0.08, result.ICO.Float64(), 0.05)
+	assert.InDelta(t, largePrice*0.10, result.Tip.Float64(), 0.01)
 
 	// Verify mocks
 	mockProductRepo.AssertExpectations(t)
@@ -597,7 +692,7 @@ func TestCreateOrder_NilProductIDs(t *testing.T) {
 	// Assert
 	require.NoError(t, err)
 	assert.NotNil(t, result)
-	assert.Equal(t, 0.0, result.TotalPrice)
+	assert.Equal(t, dto.NewMoneyFromFloat(0.0), result.TotalPrice)
 	assert.Empty(t, result.Products)
 
 	// Verify mocks
@@ -625,7 +720,7 @@ func TestCreateOrder_EmptySliceProductIDs(t *testing.T) {
 	// Assert
 	require.NoError(t, err)
 	assert.NotNil(t, result)
-	assert.Equal(t, 0.0, result.TotalPrice)
+	assert.Equal(t, dto.NewMoneyFromFloat(0.0), result.TotalPrice)
 	assert.Empty(t, result.Products)
 
 	// Verify mocks
@@ -648,10 +743,11 @@ func TestUpdateOrder_EmptyOrder(t *testing.T) {
 	existingBill := &dto.OpenBill{
 		ID:                 openBillID,
 		TemporalIdentifier: "ORDER-123",
-		TotalPrice:         100.0,
-		VAT:                19.0,
-		ICO:                8.0,
-		Tip:                10.0,
+		Status:             dto.OpenBillStatusOpen,
+		TotalPrice:         dto.NewMoneyFromFloat(100.0),
+		VAT:                dto.NewMoneyFromFloat(19.0),
+		ICO:                dto.NewMoneyFromFloat(8.0),
+		Tip:                dto.NewMoneyFromFloat(10.0),
 		CreatedAt:          time.Now(),
 		UpdatedAt:          time.Now(),
 	}
@@ -672,10 +768,10 @@ func TestUpdateOrder_EmptyOrder(t *testing.T) {
 	assert.NotNil(t, result)
 	assert.Equal(t, openBillID, result.ID)
 	assert.Equal(t, existingBill.TemporalIdentifier, result.TemporalIdentifier)
-	assert.Equal(t, 0.0, result.TotalPrice)
-	assert.Equal(t, 0.0, result.VAT)
-	assert.Equal(t, 0.0, result.ICO)
-	assert.Equal(t, 0.0, result.Tip)
+	assert.Equal(t, dto.NewMoneyFromFloat(0.0), result.TotalPrice)
+	assert.Equal(t, dto.NewMoneyFromFloat(0.0), result.VAT)
+	assert.Equal(t, dto.NewMoneyFromFloat(0.0), result.ICO)
+	assert.Equal(t, dto.NewMoneyFromFloat(0.0), result.Tip)
 	assert.Empty(t, result.Products)
 
 	// Verify mocks
@@ -694,17 +790,18 @@ func TestUpdateOrder_SingleProduct(t *testing.T) {
 	existingBill := &dto.OpenBill{
 		ID:                 openBillID,
 		TemporalIdentifier: "ORDER-123",
-		TotalPrice:         50.0,
-		VAT:                9.5,
-		ICO:                4.0,
-		Tip:                5.0,
+		Status:             dto.OpenBillStatusOpen,
+		TotalPrice:         dto.NewMoneyFromFloat(50.0),
+		VAT:                dto.NewMoneyFromFloat(9.5),
+		ICO:                dto.NewMoneyFromFloat(4.0),
+		Tip:                dto.NewMoneyFromFloat(5.0),
 		CreatedAt:          time.Now(),
 		UpdatedAt:          time.Now(),
 	}
 
 	productID := "product-1"
 	productPrice := 100.0
-	product := createTestProduct(productID, "Test Product", "Category", 1, productPrice, 19.0)
+	product := createTestTaxedProduct(productID, "Test Product", "Category", 1, productPrice)
 
 	req := &dto.UpdateOrderRequest{
 		Products: []dto.OrderProductItem{
@@ -726,12 +823,12 @@ func TestUpdateOrder_SingleProduct(t *testing.T) {
 	require.NoError(t, err)
 	assert.NotNil(t, result)
 	assert.Equal(t, openBillID, result.ID)
-	assert.Equal(t, productPrice, result.TotalPrice)
-	assert.InDelta(t, productPrice*0.19, result.VAT, 0.01)
-	assert.InDelta(t, productPrice*0.08, result.ICO, 0.01)
-	assert.InDelta(t, productPrice*0.10, result.Tip, 0.01)
+	assert.Equal(t, dto.NewMoneyFromFloat(productPrice), result.TotalPrice)
+	assert.InDelta(t, productPrice/1.27*0.19, result.VAT.Float64(), 0.05)
+	assert.InDelta(t, productPrice/1.27*0.08, result.ICO.Float64(), 0.05)
+	assert.InDelta(t, productPrice*0.10, result.Tip.Float64(), 0.01)
 	assert.Len(t, result.Products, 1)
-	assert.Equal(t, productID, result.Products[0].ID)
+	assert.Equal(t, productID, result.Products[0].Product.ID)
 
 	// Verify mocks
 	mockProductRepo.AssertExpectations(t)
@@ -749,16 +846,17 @@ func TestUpdateOrder_MultipleProductsWithQuantities(t *testing.T) {
 	existingBill := &dto.OpenBill{
 		ID:                 openBillID,
 		TemporalIdentifier: "ORDER-123",
-		TotalPrice:         100.0,
-		VAT:                19.0,
-		ICO:                8.0,
-		Tip:                10.0,
+		Status:             dto.OpenBillStatusOpen,
+		TotalPrice:         dto.NewMoneyFromFloat(100.0),
+		VAT:                dto.NewMoneyFromFloat(19.0),
+		ICO:                dto.NewMoneyFromFloat(8.0),
+		Tip:                dto.NewMoneyFromFloat(10.0),
 		CreatedAt:          time.Now(),
 		UpdatedAt:          time.Now(),
 	}
 
-	product1 := createTestProduct("product-1", "Product 1", "Category", 1, 50.0, 9.5)
-	product2 := createTestProduct("product-2", "Product 2", "Category", 1, 75.0, 14.25)
+	product1 := createTestTaxedProduct("product-1", "Product 1", "Category", 1, 50.0)
+	product2 := createTestTaxedProduct("product-2", "Product 2", "Category", 1, 75.0)
 
 	req := &dto.UpdateOrderRequest{
 		Products: []dto.OrderProductItem{
@@ -785,10 +883,10 @@ func TestUpdateOrder_MultipleProductsWithQuantities(t *testing.T) {
 	require.NoError(t, err)
 	assert.NotNil(t, result)
 	assert.Equal(t, openBillID, result.ID)
-	assert.Equal(t, expectedTotal, result.TotalPrice)
-	assert.InDelta(t, expectedTotal*0.19, result.VAT, 0.01)
-	assert.InDelta(t, expectedTotal*0.08, result.ICO, 0.01)
-	assert.InDelta(t, expectedTotal*0.10, result.Tip, 0.01)
+	assert.Equal(t, dto.NewMoneyFromFloat(expectedTotal), result.TotalPrice)
+	assert.InDelta(t, expectedTotal/1.27*0.19, result.VAT.Float64(), 0.05)
+	assert.InDelta(t, expectedTotal/1.27*0.08, result.ICO.Float64(), 0.05)
+	assert.InDelta(t, expectedTotal*0.10, result.Tip.Float64(), 0.01)
 	assert.Len(t, result.Products, 2)
 
 	// Verify mocks
@@ -807,17 +905,18 @@ func TestUpdateOrder_UpdateQuantity(t *testing.T) {
 	existingBill := &dto.OpenBill{
 		ID:                 openBillID,
 		TemporalIdentifier: "ORDER-123",
-		TotalPrice:         100.0,
-		VAT:                19.0,
-		ICO:                8.0,
-		Tip:                10.0,
+		Status:             dto.OpenBillStatusOpen,
+		TotalPrice:         dto.NewMoneyFromFloat(100.0),
+		VAT:                dto.NewMoneyFromFloat(19.0),
+		ICO:                dto.NewMoneyFromFloat(8.0),
+		Tip:                dto.NewMoneyFromFloat(10.0),
 		CreatedAt:          time.Now(),
 		UpdatedAt:          time.Now(),
 	}
 
 	productID := "product-1"
 	productPrice := 50.0
-	product := createTestProduct(productID, "Test Product", "Category", 1, productPrice, 9.5)
+	product := createTestTaxedProduct(productID, "Test Product", "Category", 1, productPrice)
 
 	req := &dto.UpdateOrderRequest{
 		Products: []dto.OrderProductItem{
@@ -840,10 +939,10 @@ func TestUpdateOrder_UpdateQuantity(t *testing.T) {
 	// Assert
 	require.NoError(t, err)
 	assert.NotNil(t, result)
-	assert.Equal(t, expectedTotal, result.TotalPrice)
-	assert.InDelta(t, expectedTotal*0.19, result.VAT, 0.01)
-	assert.InDelta(t, expectedTotal*0.08, result.ICO, 0.01)
-	assert.InDelta(t, expectedTotal*0.10, result.Tip, 0.01)
+	assert.Equal(t, dto.NewMoneyFromFloat(expectedTotal), result.TotalPrice)
+	assert.InDelta(t, expectedTotal/1.27*0.19, result.VAT.Float64(), 0.05)
+	assert.InDelta(t, expectedTotal/1.27*0.08, result.ICO.Float64(), 0.05)
+	assert.InDelta(t, expectedTotal*0.10, result.Tip.Float64(), 0.01)
 
 	// Verify mocks
 	mockProductRepo.AssertExpectations(t)
@@ -897,10 +996,11 @@ func TestUpdateOrder_ProductNotFound(t *testing.T) {
 	existingBill := &dto.OpenBill{
 		ID:                 openBillID,
 		TemporalIdentifier: "ORDER-123",
-		TotalPrice:         100.0,
-		VAT:                19.0,
-		ICO:                8.0,
-		Tip:                10.0,
+		Status:             dto.OpenBillStatusOpen,
+		TotalPrice:         dto.NewMoneyFromFloat(100.0),
+		VAT:                dto.NewMoneyFromFloat(19.0),
+		ICO:                dto.NewMoneyFromFloat(8.0),
+		Tip:                dto.NewMoneyFromFloat(10.0),
 		CreatedAt:          time.Now(),
 		UpdatedAt:          time.Now(),
 	}
@@ -945,10 +1045,11 @@ func TestUpdateOrder_RepositoryError_ProductFetch(t *testing.T) {
 	existingBill := &dto.OpenBill{
 		ID:                 openBillID,
 		TemporalIdentifier: "ORDER-123",
-		TotalPrice:         100.0,
-		VAT:                19.0,
-		ICO:                8.0,
-		Tip:                10.0,
+		Status:             dto.OpenBillStatusOpen,
+		TotalPrice:         dto.NewMoneyFromFloat(100.0),
+		VAT:                dto.NewMoneyFromFloat(19.0),
+		ICO:                dto.NewMoneyFromFloat(8.0),
+		Tip:                dto.NewMoneyFromFloat(10.0),
 		CreatedAt:          time.Now(),
 		UpdatedAt:          time.Now(),
 	}
@@ -992,10 +1093,11 @@ func TestUpdateOrder_RepositoryError_Update(t *testing.T) {
 	existingBill := &dto.OpenBill{
 		ID:                 openBillID,
 		TemporalIdentifier: "ORDER-123",
-		TotalPrice:         100.0,
-		VAT:                19.0,
-		ICO:                8.0,
-		Tip:                10.0,
+		Status:             dto.OpenBillStatusOpen,
+		TotalPrice:         dto.NewMoneyFromFloat(100.0),
+		VAT:                dto.NewMoneyFromFloat(19.0),
+		ICO:                dto.NewMoneyFromFloat(8.0),
+		Tip:                dto.NewMoneyFromFloat(10.0),
 		CreatedAt:          time.Now(),
 		UpdatedAt:          time.Now(),
 	}
@@ -1039,52 +1141,94 @@ func TestPayOrder_Success(t *testing.T) {
 	ctx := createTestContext()
 	mockProductRepo := new(MockProductRepository)
 	mockOpenBillRepo := new(MockOpenBillRepository)
-	service := createTestService(mockProductRepo, mockOpenBillRepo)
+	mockBillRepo := new(MockBillRepository)
+	service := createTestServiceWithInvoice(mockProductRepo, mockOpenBillRepo, mockBillRepo)
 
 	openBillID := "bill-1"
-	documentURL := "https://example.com/document.pdf"
 	existingBill := &dto.OpenBill{
 		ID:                 openBillID,
 		TemporalIdentifier: "ORDER-123",
-		TotalPrice:         100.0,
-		VAT:                19.0,
-		ICO:                8.0,
-		Tip:                10.0,
+		Status:             dto.OpenBillStatusOpen,
+		TotalPrice:         dto.NewMoneyFromFloat(254.0),
 		CreatedAt:          time.Now(),
 		UpdatedAt:          time.Now(),
 	}
 
-	documentURLPtr := &documentURL
-	expectedBill := &dto.Bill{
-		ID:             "paid-bill-1",
-		TotalAmount:    100.0,
-		DiscountAmount: 0.0,
-		TaxAmount:      27.0,
-		PayAmount:      100.0,
-		VAT:            19.0,
-		ICO:            8.0,
-		Tip:            10.0,
-		DocumentURL:    documentURLPtr,
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
+	product := createTestProduct("product-1", "Test Product", "Category", 1, 127.0, 0.19)
+	product.UnitPrice = dto.NewMoneyFromFloat(100.0)
+	product.ICO = 0.08
+	items := []dto.OrderProductItem{{ProductID: "product-1", Quantity: 2}}
+	customer := &dto.Customer{
+		DocumentNumber: "123456789",
+		DocumentType:   dto.DocumentTypeNationalIdentificationNumber,
+		Name:           "Test Customer",
+		Email:          "customer@example.com",
+	}
+
+	req := &dto.PayOrderRequest{
+		PaymentCode: dto.ElectronicInvoicePaymentCodeCash,
+		Customer:    customer,
 	}
 
 	// Mock expectations
 	mockOpenBillRepo.On("FindByID", ctx, openBillID).Return(existingBill, nil)
-	mockOpenBillRepo.On("PayOrder", ctx, openBillID).Return(expectedBill, nil)
+	mockOpenBillRepo.On("StartPayment", ctx, openBillID, mock.AnythingOfType("time.Time")).Return(nil)
+	mockOpenBillRepo.On("FindItemsByID", ctx, openBillID).Return(items, nil)
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1"}).Return([]*dto.Product{product}, nil)
+	mockBillRepo.On("Create", ctx, mock.MatchedBy(func(b *bill.Aggregate) bool {
+		// The bill marks the order paid in the same transaction it is saved in
+		return b.PaymentCode() == dto.ElectronicInvoicePaymentCodeCash && b.OpenBillID() == openBillID &&
+			len(b.Products()) == 1 && b.Products()[0].Quantity() == 2
+	}), []*dto.Product{product}).Return(nil)
 
 	// Execute
-	result, err := service.PayOrder(ctx, openBillID)
+	result, err := service.PayOrder(ctx, openBillID, req)
 
 	// Assert
 	require.NoError(t, err)
 	assert.NotNil(t, result)
-	assert.Equal(t, expectedBill.ID, result.ID)
-	assert.Equal(t, existingBill.TotalPrice, result.TotalAmount)
-	assert.Equal(t, existingBill.VAT, result.VAT)
-	assert.Equal(t, existingBill.ICO, result.ICO)
-	assert.Equal(t, existingBill.Tip, result.Tip)
-	assert.Equal(t, documentURLPtr, result.DocumentURL)
+	assert.NotEmpty(t, result.ID)
+	assert.Equal(t, dto.NewMoneyFromFloat(200.0), result.TotalAmount)
+	assert.InDelta(t, 38.0, result.VAT.Float64(), 0.01)
+	assert.InDelta(t, 16.0, result.ICO.Float64(), 0.01)
+	assert.InDelta(t, 254.0, result.PayAmount.Float64(), 0.01)
+	assert.Equal(t, customer, result.Customer)
+	require.Len(t, result.Products, 1)
+	assert.Equal(t, 2, result.Products[0].Quantity)
+
+	// Verify mocks
+	mockProductRepo.AssertExpectations(t)
+	mockOpenBillRepo.AssertExpectations(t)
+	mockBillRepo.AssertExpectations(t)
+	mockOpenBillRepo.AssertNotCalled(t, "UpdateStatus", ctx, openBillID, dto.OpenBillStatusPaying, dto.OpenBillStatusPaid, mock.Anything)
+}
+
+// Error Cases
+
+func TestPayOrder_OrderNotFound(t *testing.T) {
+	// Setup
+	ctx := createTestContext()
+	mockProductRepo := new(MockProductRepository)
+	mockOpenBillRepo := new(MockOpenBillRepository)
+	mockBillRepo := new(MockBillRepository)
+	service := createTestServiceWithInvoice(mockProductRepo, mockOpenBillRepo, mockBillRepo)
+
+	openBillID := "bill-1"
+
+	// Mock expectations - order not found
+	mockOpenBillRepo.On("FindByID", ctx, openBillID).Return(nil, errors.New("not found"))
+
+	// Execute
+	result, err := service.PayOrder(ctx, openBillID, &dto.PayOrderRequest{PaymentCode: dto.ElectronicInvoicePaymentCodeCash})
+
+	// Assert
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, orderError.ErrOrderNotFound)
+
+	// Verify no bill was emitted
+	mockBillRepo.AssertNotCalled(t, "Create")
+	mockOpenBillRepo.AssertNotCalled(t, "UpdateStatus")
 
 	// Verify mocks
 	mockProductRepo.AssertExpectations(t)
@@ -1096,118 +1240,1462 @@ func TestPayOrder_EmptyOrder(t *testing.T) {
 	ctx := createTestContext()
 	mockProductRepo := new(MockProductRepository)
 	mockOpenBillRepo := new(MockOpenBillRepository)
-	service := createTestService(mockProductRepo, mockOpenBillRepo)
+	mockBillRepo := new(MockBillRepository)
+	service := createTestServiceWithInvoice(mockProductRepo, mockOpenBillRepo, mockBillRepo)
 
 	openBillID := "bill-1"
-	documentURL := "https://example.com/document.pdf"
 	existingBill := &dto.OpenBill{
 		ID:                 openBillID,
 		TemporalIdentifier: "ORDER-123",
-		TotalPrice:         0.0,
-		VAT:                0.0,
-		ICO:                0.0,
-		Tip:                0.0,
+		Status:             dto.OpenBillStatusOpen,
 		CreatedAt:          time.Now(),
 		UpdatedAt:          time.Now(),
 	}
 
-	documentURLPtr := &documentURL
-	expectedBill := &dto.Bill{
-		ID:             "paid-bill-1",
-		TotalAmount:    0.0,
-		DiscountAmount: 0.0,
-		TaxAmount:      0.0,
-		PayAmount:      0.0,
-		VAT:            0.0,
-		ICO:            0.0,
-		Tip:            0.0,
-		DocumentURL:    documentURLPtr,
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
+	// Mock expectations - the order is reopened after the failed payment
+	mockOpenBillRepo.On("FindByID", ctx, openBillID).Return(existingBill, nil)
+	mockOpenBillRepo.On("StartPayment", ctx, openBillID, mock.AnythingOfType("time.Time")).Return(nil)
+	mockOpenBillRepo.On("FindItemsByID", ctx, openBillID).Return([]dto.OrderProductItem{}, nil)
+	mockOpenBillRepo.On("UpdateStatus", ctx, openBillID, dto.OpenBillStatusPaying, dto.OpenBillStatusOpen, (*string)(nil)).Return(nil)
+
+	// Execute
+	result, err := service.PayOrder(ctx, openBillID, &dto.PayOrderRequest{PaymentCode: dto.ElectronicInvoicePaymentCodeCash})
+
+	// Assert
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, orderError.ErrOrderEmpty)
+
+	// Verify no bill was emitted
+	mockBillRepo.AssertNotCalled(t, "Create")
+
+	// Verify mocks
+	mockProductRepo.AssertExpectations(t)
+	mockOpenBillRepo.AssertExpectations(t)
+}
+
+func TestPayOrder_InvoiceEmissionError(t *testing.T) {
+	// Setup
+	ctx := createTestContext()
+	mockProductRepo := new(MockProductRepository)
+	mockOpenBillRepo := new(MockOpenBillRepository)
+	mockBillRepo := new(MockBillRepository)
+	service := createTestServiceWithInvoice(mockProductRepo, mockOpenBillRepo, mockBillRepo)
+
+	openBillID := "bill-1"
+	existingBill := &dto.OpenBill{
+		ID:                 openBillID,
+		TemporalIdentifier: "ORDER-123",
+		Status:             dto.OpenBillStatusOpen,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
 	}
+	product := createTestProduct("product-1", "Test Product", "Category", 1, 119.0, 0.19)
+	product.UnitPrice = dto.NewMoneyFromFloat(100.0)
 
-	// Mock expectations
+	// Mock expectations - the order is reopened after the failed emission
 	mockOpenBillRepo.On("FindByID", ctx, openBillID).Return(existingBill, nil)
-	mockOpenBillRepo.On("PayOrder", ctx, openBillID).Return(expectedBill, nil)
+	mockOpenBillRepo.On("StartPayment", ctx, openBillID, mock.AnythingOfType("time.Time")).Return(nil)
+	mockOpenBillRepo.On("FindItemsByID", ctx, openBillID).Return([]dto.OrderProductItem{{ProductID: "product-1", Quantity: 1}}, nil)
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1"}).Return([]*dto.Product{product}, nil)
+	mockBillRepo.On("Create", ctx, mock.AnythingOfType("*bill.Aggregate"), []*dto.Product{product}).Return(errors.New("provider unavailable"))
+	mockOpenBillRepo.On("UpdateStatus", ctx, openBillID, dto.OpenBillStatusPaying, dto.OpenBillStatusOpen, (*string)(nil)).Return(nil)
 
 	// Execute
-	result, err := service.PayOrder(ctx, openBillID)
+	result, err := service.PayOrder(ctx, openBillID, &dto.PayOrderRequest{PaymentCode: dto.ElectronicInvoicePaymentCodeCash})
 
 	// Assert
-	require.NoError(t, err)
-	assert.NotNil(t, result)
-	assert.Equal(t, 0.0, result.TotalAmount)
-	assert.Equal(t, documentURLPtr, result.DocumentURL)
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, orderError.ErrOrderPaymentFailed)
 
 	// Verify mocks
 	mockProductRepo.AssertExpectations(t)
 	mockOpenBillRepo.AssertExpectations(t)
+	mockBillRepo.AssertExpectations(t)
 }
 
-// Error Cases
+func TestPayOrder_InvalidStatus(t *testing.T) {
+	testCases := []dto.OpenBillStatus{
+		dto.OpenBillStatusPaid,
+		dto.OpenBillStatusCancelled,
+		dto.OpenBillStatusSplit,
+	}
 
-func TestPayOrder_OrderNotFound(t *testing.T) {
+	for _, status := range testCases {
+		t.Run(string(status), func(t *testing.T) {
+			// Setup
+			ctx := createTestContext()
+			mockProductRepo := new(MockProductRepository)
+			mockOpenBillRepo := new(MockOpenBillRepository)
+			mockBillRepo := new(MockBillRepository)
+			service := createTestServiceWithInvoice(mockProductRepo, mockOpenBillRepo, mockBillRepo)
+
+			openBillID := "bill-1"
+			existingBill := &dto.OpenBill{
+				ID:                 openBillID,
+				TemporalIdentifier: "ORDER-123",
+				Status:             status,
+				CreatedAt:          time.Now(),
+				UpdatedAt:          time.Now(),
+			}
+
+			// Mock expectations
+			mockOpenBillRepo.On("FindByID", ctx, openBillID).Return(existingBill, nil)
+
+			// Execute
+			result, err := service.PayOrder(ctx, openBillID, &dto.PayOrderRequest{PaymentCode: dto.ElectronicInvoicePaymentCodeCash})
+
+			// Assert
+			require.Error(t, err)
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, orderError.ErrInvalidOrderStatus)
+			mockOpenBillRepo.AssertNotCalled(t, "StartPayment")
+			mockBillRepo.AssertNotCalled(t, "Create")
+		})
+	}
+}
+
+func TestPayOrder_ConcurrentPayment(t *testing.T) {
 	// Setup
 	ctx := createTestContext()
 	mockProductRepo := new(MockProductRepository)
 	mockOpenBillRepo := new(MockOpenBillRepository)
-	service := createTestService(mockProductRepo, mockOpenBillRepo)
+	mockBillRepo := new(MockBillRepository)
+	service := createTestServiceWithInvoice(mockProductRepo, mockOpenBillRepo, mockBillRepo)
 
 	openBillID := "bill-1"
+	existingBill := &dto.OpenBill{
+		ID:                 openBillID,
+		TemporalIdentifier: "ORDER-123",
+		Status:             dto.OpenBillStatusOpen,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	}
 
-	// Mock expectations - order not found
-	mockOpenBillRepo.On("FindByID", ctx, openBillID).Return(nil, errors.New("not found"))
+	// Mock expectations - another request moved the order to paying first
+	mockOpenBillRepo.On("FindByID", ctx, openBillID).Return(existingBill, nil)
+	mockOpenBillRepo.On("StartPayment", ctx, openBillID, mock.AnythingOfType("time.Time")).Return(orderError.ErrInvalidOrderStatus)
 
 	// Execute
-	result, err := service.PayOrder(ctx, openBillID)
+	result, err := service.PayOrder(ctx, openBillID, &dto.PayOrderRequest{PaymentCode: dto.ElectronicInvoicePaymentCodeCash})
 
 	// Assert
 	require.Error(t, err)
 	assert.Nil(t, result)
-	assert.ErrorIs(t, err, orderError.ErrOrderNotFound)
+	assert.ErrorIs(t, err, orderError.ErrInvalidOrderStatus)
+	mockBillRepo.AssertNotCalled(t, "Create")
+	mockOpenBillRepo.AssertNotCalled(t, "FindItemsByID")
+}
+
+func TestPayOrder_TakesOverInterruptedPayment(t *testing.T) {
+	// Setup
+	ctx := createTestContext()
+	mockProductRepo := new(MockProductRepository)
+	mockOpenBillRepo := new(MockOpenBillRepository)
+	mockBillRepo := new(MockBillRepository)
+	service := createTestServiceWithInvoice(mockProductRepo, mockOpenBillRepo, mockBillRepo)
+
+	openBillID := "bill-1"
+	existingBill := &dto.OpenBill{
+		ID:                 openBillID,
+		TemporalIdentifier: "ORDER-123",
+		Status:             dto.OpenBillStatusPaying,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	}
+	product := createTestProduct("product-1", "Test Product", "Category", 1, 119.0, 0.19)
+	product.UnitPrice = dto.NewMoneyFromFloat(100.0)
+
+	// Mock expectations - the payment that left the order in paying died before saving the bill
+	mockOpenBillRepo.On("FindByID", ctx, openBillID).Return(existingBill, nil)
+	mockOpenBillRepo.On("StartPayment", ctx, openBillID, mock.MatchedBy(func(staleBefore time.Time) bool {
+		return time.Since(staleBefore) >= orderPaymentLease
+	})).Return(nil)
+	mockOpenBillRepo.On("FindItemsByID", ctx, openBillID).Return([]dto.OrderProductItem{{ProductID: "product-1", Quantity: 1}}, nil)
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1"}).Return([]*dto.Product{product}, nil)
+	mockBillRepo.On("Create", ctx, mock.AnythingOfType("*bill.Aggregate"), []*dto.Product{product}).Return(nil)
 
-	// Verify PayOrder was not called
-	mockOpenBillRepo.AssertNotCalled(t, "PayOrder")
+	// Execute
+	result, err := service.PayOrder(ctx, openBillID, &dto.PayOrderRequest{PaymentCode: dto.ElectronicInvoicePaymentCodeCash})
 
-	// Verify mocks
-	mockProductRepo.AssertExpectations(t)
+	// Assert
+	require.NoError(t, err)
+	assert.NotNil(t, result)
 	mockOpenBillRepo.AssertExpectations(t)
+	mockBillRepo.AssertExpectations(t)
 }
 
-func TestPayOrder_RepositoryError_Payment(t *testing.T) {
+func TestPayOrder_InvoicesTheOrderPrices(t *testing.T) {
 	// Setup
 	ctx := createTestContext()
 	mockProductRepo := new(MockProductRepository)
 	mockOpenBillRepo := new(MockOpenBillRepository)
-	service := createTestService(mockProductRepo, mockOpenBillRepo)
+	mockBillRepo := new(MockBillRepository)
+	service := createTestServiceWithInvoice(mockProductRepo, mockOpenBillRepo, mockBillRepo)
+
+	openBillID := "bill-1"
+	existingBill := createTestOpenOrder(openBillID)
+	existingBill.TotalPrice = dto.NewMoneyFromFloat(29700.0)
+
+	// The beer went up to 12000 after it was ordered at 9900
+	beer := createTestTaxedProduct("product-1", "Beer", "Drinks", 2, 12000.0)
+	items := []dto.OrderProductItem{{
+		ProductID: "product-1",
+		Quantity:  3,
+		Price:     &dto.OrderItemPrice{TotalPriceWithTaxes: dto.NewMoneyFromFloat(9900.0), VAT: 0.19, ICO: 0.08},
+	}}
+
+	// Mock expectations
+	mockOpenBillRepo.On("FindByID", ctx, openBillID).Return(existingBill, nil)
+	mockOpenBillRepo.On("StartPayment", ctx, openBillID, mock.AnythingOfType("time.Time")).Return(nil)
+	mockOpenBillRepo.On("FindItemsByID", ctx, openBillID).Return(items, nil)
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1"}).Return([]*dto.Product{beer}, nil)
+	mockBillRepo.On("Create", ctx, mock.MatchedBy(func(b *bill.Aggregate) bool {
+		return len(b.Products()) == 1 && b.Products()[0].Quantity() == 3
+	}), mock.Anything).Return(nil)
+
+	// Execute
+	result, err := service.PayOrder(ctx, openBillID, &dto.PayOrderRequest{PaymentCode: dto.ElectronicInvoicePaymentCodeCash})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, existingBill.TotalPrice, result.PayAmount)
+	assert.Equal(t, existingBill.TotalPrice, result.TotalAmount.Add(result.VAT).Add(result.ICO))
+	require.Len(t, result.Products, 1)
+	assert.Equal(t, 3, result.Products[0].Quantity)
+	mockBillRepo.AssertExpectations(t)
+}
+
+func TestPayOrder_SplitTender(t *testing.T) {
+	// Setup
+	ctx := createTestContext()
+	mockProductRepo := new(MockProductRepository)
+	mockOpenBillRepo := new(MockOpenBillRepository)
+	mockBillRepo := new(MockBillRepository)
+	service := createTestServiceWithInvoice(mockProductRepo, mockOpenBillRepo, mockBillRepo)
 
 	openBillID := "bill-1"
 	existingBill := &dto.OpenBill{
 		ID:                 openBillID,
 		TemporalIdentifier: "ORDER-123",
-		TotalPrice:         100.0,
-		VAT:                19.0,
-		ICO:                8.0,
-		Tip:                10.0,
+		Status:             dto.OpenBillStatusOpen,
 		CreatedAt:          time.Now(),
 		UpdatedAt:          time.Now(),
 	}
 
-	repoError := errors.New("payment failed")
+	product := createTestProduct("product-1", "Test Product", "Category", 1, 119.0, 0.19)
+	product.UnitPrice = dto.NewMoneyFromFloat(100.0)
+	items := []dto.OrderProductItem{{ProductID: "product-1", Quantity: 2}}
+
+	req := &dto.PayOrderRequest{
+		Payments: []dto.Payment{
+			{Method: dto.ElectronicInvoicePaymentCodeCash, Amount: dto.NewMoneyFromFloat(50000.0)},
+			{Method: dto.ElectronicInvoicePaymentCodeCreditCard, Amount: dto.NewMoneyFromFloat(138.0), Reference: "auth-998"},
+		},
+	}
 
 	// Mock expectations
 	mockOpenBillRepo.On("FindByID", ctx, openBillID).Return(existingBill, nil)
-	mockOpenBillRepo.On("PayOrder", ctx, openBillID).Return(nil, repoError)
+	mockOpenBillRepo.On("StartPayment", ctx, openBillID, mock.AnythingOfType("time.Time")).Return(nil)
+	mockOpenBillRepo.On("FindItemsByID", ctx, openBillID).Return(items, nil)
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1"}).Return([]*dto.Product{product}, nil)
+	mockBillRepo.On("Create", ctx, mock.MatchedBy(func(b *bill.Aggregate) bool {
+		return b.PaymentCode() == dto.ElectronicInvoicePaymentCodeCash && len(b.Payments()) == 2
+	}), []*dto.Product{product}).Return(nil)
+
+	// Execute
+	result, err := service.PayOrder(ctx, openBillID, req)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "238.00", result.PayAmount.String())
+	assert.Equal(t, "49900.00", result.Change.String())
+	require.Len(t, result.Payments, 2)
+	assert.Equal(t, result.Change, result.Payments[0].Change)
+	assert.True(t, result.Payments[1].Change.IsZero())
+
+	// Verify mocks
+	mockOpenBillRepo.AssertExpectations(t)
+	mockBillRepo.AssertExpectations(t)
+}
+
+func TestPayOrder_PaymentsDoNotCoverTotal(t *testing.T) {
+	// Setup
+	ctx := createTestContext()
+	mockProductRepo := new(MockProductRepository)
+	mockOpenBillRepo := new(MockOpenBillRepository)
+	mockBillRepo := new(MockBillRepository)
+	service := createTestServiceWithInvoice(mockProductRepo, mockOpenBillRepo, mockBillRepo)
+
+	openBillID := "bill-1"
+	existingBill := &dto.OpenBill{
+		ID:                 openBillID,
+		TemporalIdentifier: "ORDER-123",
+		Status:             dto.OpenBillStatusOpen,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	}
+
+	product := createTestProduct("product-1", "Test Product", "Category", 1, 119.0, 0.19)
+	product.UnitPrice = dto.NewMoneyFromFloat(100.0)
+	items := []dto.OrderProductItem{{ProductID: "product-1", Quantity: 1}}
+
+	req := &dto.PayOrderRequest{
+		Payments: []dto.Payment{
+			{Method: dto.ElectronicInvoicePaymentCodeCash, Amount: dto.NewMoneyFromFloat(50.0)},
+			{Method: dto.ElectronicInvoicePaymentCodeDebitCard, Amount: dto.NewMoneyFromFloat(50.0)},
+		},
+	}
+
+	// Mock expectations - the order is reopened once the invoice is rejected
+	mockOpenBillRepo.On("FindByID", ctx, openBillID).Return(existingBill, nil)
+	mockOpenBillRepo.On("StartPayment", ctx, openBillID, mock.AnythingOfType("time.Time")).Return(nil)
+	mockOpenBillRepo.On("FindItemsByID", ctx, openBillID).Return(items, nil)
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1"}).Return([]*dto.Product{product}, nil)
+	mockOpenBillRepo.On("UpdateStatus", ctx, openBillID, dto.OpenBillStatusPaying, dto.OpenBillStatusOpen, (*string)(nil)).Return(nil)
 
 	// Execute
-	result, err := service.PayOrder(ctx, openBillID)
+	result, err := service.PayOrder(ctx, openBillID, req)
 
 	// Assert
 	require.Error(t, err)
 	assert.Nil(t, result)
-	assert.ErrorIs(t, err, orderError.ErrOrderPaymentFailed)
+	assert.ErrorIs(t, err, orderError.ErrInvalidInvoice)
 
 	// Verify mocks
-	mockProductRepo.AssertExpectations(t)
 	mockOpenBillRepo.AssertExpectations(t)
+	mockBillRepo.AssertNotCalled(t, "Create")
+}
+
+func TestPayOrder_Tip(t *testing.T) {
+	editedTip := dto.NewMoneyFromFloat(30.0)
+	declinedTip := dto.NewMoneyFromFloat(0.0)
+
+	testCases := []struct {
+		name        string
+		tip         *dto.Money
+		expectedTip string
+		expectedPay string
+	}{
+		{
+			name:        "suggested tip accepted",
+			tip:         nil,
+			expectedTip: "23.80",
+			expectedPay: "261.80",
+		},
+		{
+			name:        "tip edited by the customer",
+			tip:         &editedTip,
+			expectedTip: "30.00",
+			expectedPay: "268.00",
+		},
+		{
+			name:        "tip declined",
+			tip:         &declinedTip,
+			expectedTip: "0.00",
+			expectedPay: "238.00",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup
+			ctx := createTestContext()
+			mockProductRepo := new(MockProductRepository)
+			mockOpenBillRepo := new(MockOpenBillRepository)
+			mockBillRepo := new(MockBillRepository)
+			service := createTestServiceWithInvoice(mockProductRepo, mockOpenBillRepo, mockBillRepo)
+
+			openBillID := "bill-1"
+			existingBill := &dto.OpenBill{
+				ID:                 openBillID,
+				TemporalIdentifier: "ORDER-123",
+				Status:             dto.OpenBillStatusOpen,
+				TotalPrice:         dto.NewMoneyFromFloat(238.0),
+				Tip:                dto.NewMoneyFromFloat(23.80),
+				CreatedAt:          time.Now(),
+				UpdatedAt:          time.Now(),
+			}
+
+			product := createTestProduct("product-1", "Test Product", "Category", 1, 119.0, 0.19)
+			product.UnitPrice = dto.NewMoneyFromFloat(100.0)
+			items := []dto.OrderProductItem{{ProductID: "product-1", Quantity: 2}}
+
+			req := &dto.PayOrderRequest{
+				PaymentCode: dto.ElectronicInvoicePaymentCodeCreditCard,
+				Tip:         tc.tip,
+			}
+
+			// Mock expectations
+			mockOpenBillRepo.On("FindByID", ctx, openBillID).Return(existingBill, nil)
+			mockOpenBillRepo.On("StartPayment", ctx, openBillID, mock.AnythingOfType("time.Time")).Return(nil)
+			mockOpenBillRepo.On("FindItemsByID", ctx, openBillID).Return(items, nil)
+			mockProductRepo.On("FindByIDs", ctx, []string{"product-1"}).Return([]*dto.Product{product}, nil)
+			mockBillRepo.On("Create", ctx, mock.AnythingOfType("*bill.Aggregate"), []*dto.Product{product}).Return(nil)
+
+			// Execute
+			result, err := service.PayOrder(ctx, openBillID, req)
+
+			// Assert - the tip is paid but the taxes stay on the products only
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedTip, result.Tip.String())
+			assert.Equal(t, "38.00", result.TaxAmount.String())
+			assert.Equal(t, tc.expectedPay, result.PayAmount.String())
+			require.Len(t, result.Payments, 1)
+			assert.Equal(t, result.PayAmount, result.Payments[0].Amount)
+		})
+	}
+}
+
+func TestPayOrder_NegativeTip(t *testing.T) {
+	// Setup
+	ctx := createTestContext()
+	mockProductRepo := new(MockProductRepository)
+	mockOpenBillRepo := new(MockOpenBillRepository)
+	mockBillRepo := new(MockBillRepository)
+	service := createTestServiceWithInvoice(mockProductRepo, mockOpenBillRepo, mockBillRepo)
+
+	openBillID := "bill-1"
+	existingBill := &dto.OpenBill{
+		ID:                 openBillID,
+		TemporalIdentifier: "ORDER-123",
+		Status:             dto.OpenBillStatusOpen,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	}
+
+	product := createTestProduct("product-1", "Test Product", "Category", 1, 119.0, 0.19)
+	items := []dto.OrderProductItem{{ProductID: "product-1", Quantity: 1}}
+	tip := dto.NewMoneyFromFloat(-5.0)
+
+	// Mock expectations
+	mockOpenBillRepo.On("FindByID", ctx, openBillID).Return(existingBill, nil)
+	mockOpenBillRepo.On("StartPayment", ctx, openBillID, mock.AnythingOfType("time.Time")).Return(nil)
+	mockOpenBillRepo.On("FindItemsByID", ctx, openBillID).Return(items, nil)
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1"}).Return([]*dto.Product{product}, nil)
+	mockOpenBillRepo.On("UpdateStatus", ctx, openBillID, dto.OpenBillStatusPaying, dto.OpenBillStatusOpen, (*string)(nil)).Return(nil)
+
+	// Execute
+	result, err := service.PayOrder(ctx, openBillID, &dto.PayOrderRequest{
+		PaymentCode: dto.ElectronicInvoicePaymentCodeCash,
+		Tip:         &tip,
+	})
+
+	// Assert
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, orderError.ErrInvalidInvoice)
+
+	// Verify mocks
+	mockOpenBillRepo.AssertExpectations(t)
+	mockBillRepo.AssertNotCalled(t, "Create")
+}
+
+func TestUpdateOrder_LinesFollowRequestOrder(t *testing.T) {
+	// Setup
+	ctx := createTestContext()
+	mockProductRepo := new(MockProductRepository)
+	mockOpenBillRepo := new(MockOpenBillRepository)
+	service := createTestService(mockProductRepo, mockOpenBillRepo)
+
+	openBillID := "bill-1"
+	existingBill := &dto.OpenBill{
+		ID:                 openBillID,
+		TemporalIdentifier: "ORDER-123",
+		Status:             dto.OpenBillStatusOpen,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	}
+
+	product1 := createTestProduct("product-1", "Product 1", "Category", 1, 10.0, 0.0)
+	product2 := createTestProduct("product-2", "Product 2", "Category", 1, 20.0, 0.0)
+	req := &dto.UpdateOrderRequest{
+		Products: []dto.OrderProductItem{
+			{ProductID: "product-1", Quantity: 1},
+			{ProductID: "product-2", Quantity: 5},
+		},
+	}
+
+	// Mock expectations - repository returns products in a different order
+	mockOpenBillRepo.On("FindByID", ctx, openBillID).Return(existingBill, nil)
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1", "product-2"}).Return([]*dto.Product{product2, product1}, nil)
+	mockOpenBillRepo.On("Update", ctx, openBillID, mock.AnythingOfType("*dto.OpenBill"), []dto.OrderProductItem{
+		{ProductID: "product-1", Quantity: 1, Price: &dto.OrderItemPrice{TotalPriceWithTaxes: dto.NewMoneyFromFloat(10.0)}},
+		{ProductID: "product-2", Quantity: 5, Price: &dto.OrderItemPrice{TotalPriceWithTaxes: dto.NewMoneyFromFloat(20.0)}},
+	}).Return(nil)
+
+	// Execute
+	result, err := service.UpdateOrder(ctx, openBillID, req)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, dto.NewMoneyFromFloat(110.0), result.TotalPrice)
+	require.Len(t, result.Products, 2)
+	assert.Equal(t, "product-1", result.Products[0].Product.ID)
+	assert.Equal(t, 1, result.Products[0].Quantity)
+	assert.Equal(t, dto.NewMoneyFromFloat(10.0), result.Products[0].Total)
+	assert.Equal(t, "product-2", result.Products[1].Product.ID)
+	assert.Equal(t, 5, result.Products[1].Quantity)
+	assert.Equal(t, dto.NewMoneyFromFloat(100.0), result.Products[1].Total)
+}
+
+// UpdateOrder status Tests
+
+func TestUpdateOrder_InvalidStatus(t *testing.T) {
+	testCases := []dto.OpenBillStatus{
+		dto.OpenBillStatusPaid,
+		dto.OpenBillStatusCancelled,
+		dto.OpenBillStatusSplit,
+	}
+
+	for _, status := range testCases {
+		t.Run(string(status), func(t *testing.T) {
+			// Setup
+			ctx := createTestContext()
+			mockProductRepo := new(MockProductRepository)
+			mockOpenBillRepo := new(MockOpenBillRepository)
+			service := createTestService(mockProductRepo, mockOpenBillRepo)
+
+			openBillID := "bill-1"
+			existingBill := &dto.OpenBill{
+				ID:                 openBillID,
+				TemporalIdentifier: "ORDER-123",
+				Status:             status,
+				CreatedAt:          time.Now(),
+				UpdatedAt:          time.Now(),
+			}
+
+			req := &dto.UpdateOrderRequest{
+				Products: []dto.OrderProductItem{{ProductID: "product-1", Quantity: 1}},
+			}
+
+			// Mock expectations
+			mockOpenBillRepo.On("FindByID", ctx, openBillID).Return(existingBill, nil)
+
+			// Execute
+			result, err := service.UpdateOrder(ctx, openBillID, req)
+
+			// Assert
+			require.Error(t, err)
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, orderError.ErrInvalidOrderStatus)
+			mockProductRepo.AssertNotCalled(t, "FindByIDs")
+			mockOpenBillRepo.AssertNotCalled(t, "Update")
+		})
+	}
+}
+
+// CancelOrder Tests
+
+func TestCancelOrder_Success(t *testing.T) {
+	// Setup
+	ctx := createTestContext()
+	mockProductRepo := new(MockProductRepository)
+	mockOpenBillRepo := new(MockOpenBillRepository)
+	service := createTestService(mockProductRepo, mockOpenBillRepo)
+
+	openBillID := "bill-1"
+	existingBill := &dto.OpenBill{
+		ID:                 openBillID,
+		TemporalIdentifier: "ORDER-123",
+		Status:             dto.OpenBillStatusOpen,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	}
+
+	// Mock expectations
+	mockOpenBillRepo.On("FindByID", ctx, openBillID).Return(existingBill, nil)
+	mockOpenBillRepo.On("UpdateStatus", ctx, openBillID, dto.OpenBillStatusOpen, dto.OpenBillStatusCancelled, (*string)(nil)).Return(nil)
+
+	// Execute
+	result, err := service.CancelOrder(ctx, openBillID)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, dto.OpenBillStatusCancelled, result.Status)
+
+	// Verify mocks
+	mockOpenBillRepo.AssertExpectations(t)
+}
+
+func TestCancelOrder_AlreadyPaid(t *testing.T) {
+	// Setup
+	ctx := createTestContext()
+	mockProductRepo := new(MockProductRepository)
+	mockOpenBillRepo := new(MockOpenBillRepository)
+	service := createTestService(mockProductRepo, mockOpenBillRepo)
+
+	openBillID := "bill-1"
+	billID := "paid-bill-1"
+	existingBill := &dto.OpenBill{
+		ID:                 openBillID,
+		TemporalIdentifier: "ORDER-123",
+		Status:             dto.OpenBillStatusPaid,
+		BillID:             &billID,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	}
+
+	// Mock expectations
+	mockOpenBillRepo.On("FindByID", ctx, openBillID).Return(existingBill, nil)
+
+	// Execute
+	result, err := service.CancelOrder(ctx, openBillID)
+
+	// Assert
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, orderError.ErrInvalidOrderStatus)
+	mockOpenBillRepo.AssertNotCalled(t, "UpdateStatus")
+}
+
+func TestCancelOrder_OrderNotFound(t *testing.T) {
+	// Setup
+	ctx := createTestContext()
+	mockProductRepo := new(MockProductRepository)
+	mockOpenBillRepo := new(MockOpenBillRepository)
+	service := createTestService(mockProductRepo, mockOpenBillRepo)
+
+	// Mock expectations
+	mockOpenBillRepo.On("FindByID", ctx, "bill-1").Return(nil, errors.New("not found"))
+
+	// Execute
+	result, err := service.CancelOrder(ctx, "bill-1")
+
+	// Assert
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, orderError.ErrOrderNotFound)
+}
+
+// GetOrder Tests
+
+func TestGetOrder_Success(t *testing.T) {
+	// Setup
+	ctx := createTestContext()
+	mockProductRepo := new(MockProductRepository)
+	mockOpenBillRepo := new(MockOpenBillRepository)
+	service := createTestService(mockProductRepo, mockOpenBillRepo)
+
+	existingBill := &dto.OpenBill{
+		ID:                 "bill-1",
+		TemporalIdentifier: "ORDER-123",
+		Status:             dto.OpenBillStatusOpen,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	}
+
+	product := createTestProduct("product-1", "Test Product", "Category", 1, 119.0, 0.19)
+	product.UnitPrice = dto.NewMoneyFromFloat(100.0)
+	items := []dto.OrderProductItem{{ProductID: "product-1", Quantity: 3}}
+
+	// Mock expectations
+	mockOpenBillRepo.On("FindByID", ctx, "bill-1").Return(existingBill, nil)
+	mockOpenBillRepo.On("FindItemsByID", ctx, "bill-1").Return(items, nil)
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1"}).Return([]*dto.Product{product}, nil)
+
+	// Execute
+	result, err := service.GetOrder(ctx, "bill-1")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "bill-1", result.ID)
+	require.Len(t, result.Products, 1)
+	line := result.Products[0]
+	assert.Equal(t, "product-1", line.Product.ID)
+	assert.Equal(t, 3, line.Quantity)
+	assert.InDelta(t, 300.0, line.Subtotal.Float64(), 0.01)
+	assert.InDelta(t, 57.0, line.VAT.Float64(), 0.01)
+	assert.InDelta(t, 0.0, line.ICO.Float64(), 0.01)
+	assert.InDelta(t, 357.0, line.Total.Float64(), 0.01)
+	mockOpenBillRepo.AssertExpectations(t)
+	mockProductRepo.AssertExpectations(t)
+}
+
+func TestGetOrder_EmptyOrder(t *testing.T) {
+	// Setup
+	ctx := createTestContext()
+	mockProductRepo := new(MockProductRepository)
+	mockOpenBillRepo := new(MockOpenBillRepository)
+	service := createTestService(mockProductRepo, mockOpenBillRepo)
+
+	existingBill := &dto.OpenBill{
+		ID:                 "bill-1",
+		TemporalIdentifier: "ORDER-123",
+		Status:             dto.OpenBillStatusOpen,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	}
+
+	// Mock expectations
+	mockOpenBillRepo.On("FindByID", ctx, "bill-1").Return(existingBill, nil)
+	mockOpenBillRepo.On("FindItemsByID", ctx, "bill-1").Return([]dto.OrderProductItem{}, nil)
+
+	// Execute
+	result, err := service.GetOrder(ctx, "bill-1")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, result.Products)
+	mockProductRepo.AssertNotCalled(t, "FindByIDs")
+}
+
+func TestGetOrder_OrderNotFound(t *testing.T) {
+	// Setup
+	ctx := createTestContext()
+	mockProductRepo := new(MockProductRepository)
+	mockOpenBillRepo := new(MockOpenBillRepository)
+	service := createTestService(mockProductRepo, mockOpenBillRepo)
+
+	// Mock expectations
+	mockOpenBillRepo.On("FindByID", ctx, "bill-1").Return(nil, errors.New("not found"))
+
+	// Execute
+	result, err := service.GetOrder(ctx, "bill-1")
+
+	// Assert
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, orderError.ErrOrderNotFound)
+}
+
+// ListOrders Tests
+
+func TestListOrders_Defaults(t *testing.T) {
+	// Setup
+	ctx := createTestContext()
+	mockProductRepo := new(MockProductRepository)
+	mockOpenBillRepo := new(MockOpenBillRepository)
+	service := createTestService(mockProductRepo, mockOpenBillRepo)
+
+	orders := []*dto.OpenBill{
+		{ID: "bill-1", Status: dto.OpenBillStatusOpen},
+		{ID: "bill-2", Status: dto.OpenBillStatusPaying},
+	}
+	expectedFilter := &dto.ListOrdersRequest{
+		SortBy:    dto.OpenBillSortFieldCreatedAt,
+		SortOrder: dto.SortOrderDesc,
+		Page:      1,
+		PageSize:  20,
+	}
+
+	// Mock expectations
+	mockOpenBillRepo.On("FindAll", ctx, expectedFilter).Return(orders, nil)
+	mockOpenBillRepo.On("Count", ctx, expectedFilter).Return(2, nil)
+
+	// Execute
+	result, err := service.ListOrders(ctx, &dto.ListOrdersRequest{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, orders, result.Orders)
+	assert.Equal(t, 2, result.Total)
+	assert.Equal(t, 1, result.Page)
+	assert.Equal(t, 20, result.PageSize)
+	mockOpenBillRepo.AssertExpectations(t)
+}
+
+func TestListOrders_WithFilters(t *testing.T) {
+	// Setup
+	ctx := createTestContext()
+	mockProductRepo := new(MockProductRepository)
+	mockOpenBillRepo := new(MockOpenBillRepository)
+	service := createTestService(mockProductRepo, mockOpenBillRepo)
+
+	createdFrom := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	createdTo := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	req := &dto.ListOrdersRequest{
+		Statuses:    []dto.OpenBillStatus{dto.OpenBillStatusOpen, dto.OpenBillStatusPaying},
+		CreatedFrom: &createdFrom,
+		CreatedTo:   &createdTo,
+		SortBy:      dto.OpenBillSortFieldTotalPrice,
+		SortOrder:   dto.SortOrderAsc,
+		Page:        3,
+		PageSize:    500,
+	}
+
+	// Mock expectations - page size is capped
+	matchesFilter := mock.MatchedBy(func(filter *dto.ListOrdersRequest) bool {
+		return len(filter.Statuses) == 2 &&
+			filter.CreatedFrom.Equal(createdFrom) &&
+			filter.CreatedTo.Equal(createdTo) &&
+			filter.SortBy == dto.OpenBillSortFieldTotalPrice &&
+			filter.SortOrder == dto.SortOrderAsc &&
+			filter.Page == 3 &&
+			filter.PageSize == 100
+	})
+	mockOpenBillRepo.On("FindAll", ctx, matchesFilter).Return([]*dto.OpenBill{}, nil)
+	mockOpenBillRepo.On("Count", ctx, matchesFilter).Return(0, nil)
+
+	// Execute
+	result, err := service.ListOrders(ctx, req)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, result.Orders)
+	assert.Equal(t, 100, result.PageSize)
+	mockOpenBillRepo.AssertExpectations(t)
+}
+
+func TestListOrders_InvalidFilter(t *testing.T) {
+	createdFrom := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	createdTo := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name string
+		req  *dto.ListOrdersRequest
+	}{
+		{name: "Unknown status", req: &dto.ListOrdersRequest{Statuses: []dto.OpenBillStatus{"closed"}}},
+		{name: "Inverted window", req: &dto.ListOrdersRequest{CreatedFrom: &createdFrom, CreatedTo: &createdTo}},
+		{name: "Unknown sort field", req: &dto.ListOrdersRequest{SortBy: "temporal_identifier"}},
+		{name: "Unknown sort order", req: &dto.ListOrdersRequest{SortOrder: "up"}},
+		{name: "Negative page", req: &dto.ListOrdersRequest{Page: -1}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup
+			ctx := createTestContext()
+			mockProductRepo := new(MockProductRepository)
+			mockOpenBillRepo := new(MockOpenBillRepository)
+			service := createTestService(mockProductRepo, mockOpenBillRepo)
+
+			// Execute
+			result, err := service.ListOrders(ctx, tc.req)
+
+			// Assert
+			require.Error(t, err)
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, orderError.ErrInvalidOrderFilter)
+			mockOpenBillRepo.AssertNotCalled(t, "FindAll")
+		})
+	}
+}
+
+func TestListOrders_RepositoryError(t *testing.T) {
+	// Setup
+	ctx := createTestContext()
+	mockProductRepo := new(MockProductRepository)
+	mockOpenBillRepo := new(MockOpenBillRepository)
+	service := createTestService(mockProductRepo, mockOpenBillRepo)
+
+	// Mock expectations
+	mockOpenBillRepo.On("FindAll", ctx, mock.AnythingOfType("*dto.ListOrdersRequest")).Return(nil, errors.New("query failed"))
+
+	// Execute
+	result, err := service.ListOrders(ctx, nil)
+
+	// Assert
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, orderError.ErrOrderListFailed)
+	mockOpenBillRepo.AssertNotCalled(t, "Count")
+}
+
+// SplitOrder Tests
+
+// createTestOpenOrder creates an open order without products or totals
+func createTestOpenOrder(openBillID string) *dto.OpenBill {
+	return &dto.OpenBill{
+		ID:                 openBillID,
+		TemporalIdentifier: "ORDER-123",
+		Status:             dto.OpenBillStatusOpen,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	}
+}
+
+func TestSplitOrder_ByItemsWithPartialQuantities(t *testing.T) {
+	// Setup
+	ctx := createTestContext()
+	mockProductRepo := new(MockProductRepository)
+	mockOpenBillRepo := new(MockOpenBillRepository)
+	service := createTestService(mockProductRepo, mockOpenBillRepo)
+
+	openBillID := "bill-1"
+	beer := createTestTaxedProduct("product-1", "Beer", "Drinks", 1, 10000.0)
+	fries := createTestTaxedProduct("product-2", "Fries", "Food", 1, 5000.0)
+	items := []dto.OrderProductItem{
+		{ProductID: "product-1", Quantity: 3},
+		{ProductID: "product-2", Quantity: 1},
+	}
+
+	req := &dto.SplitOrderRequest{
+		Mode: dto.SplitModeItems,
+		Parts: []dto.SplitOrderPart{
+			{Products: []dto.OrderProductItem{{ProductID: "product-1", Quantity: 1}}},
+			{Products: []dto.OrderProductItem{{ProductID: "product-1", Quantity: 1}, {ProductID: "product-2", Quantity: 1}}},
+		},
+	}
+
+	expectedItems := [][]dto.OrderProductItem{
+		{{ProductID: "product-1", Quantity: 1}},
+		{{ProductID: "product-1", Quantity: 1}, {ProductID: "product-2", Quantity: 1}},
+		{{ProductID: "product-1", Quantity: 1}},
+	}
+
+	// Mock expectations
+	mockOpenBillRepo.On("FindByID", ctx, openBillID).Return(createTestOpenOrder(openBillID), nil)
+	mockOpenBillRepo.On("FindItemsByID", ctx, openBillID).Return(items, nil)
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1", "product-2"}).Return([]*dto.Product{beer, fries}, nil)
+	mockOpenBillRepo.On("Split", ctx, openBillID, items, mock.AnythingOfType("[]*dto.OpenBill"), expectedItems).Return(nil)
+
+	// Execute
+	result, err := service.SplitOrder(ctx, openBillID, req)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, dto.OpenBillStatusSplit, result.Order.Status)
+	require.Len(t, result.Parts, 3)
+	assert.Equal(t, dto.NewMoneyFromFloat(10000.0), result.Parts[0].TotalPrice)
+	assert.Equal(t, dto.NewMoneyFromFloat(15000.0), result.Parts[1].TotalPrice)
+	assert.Equal(t, dto.NewMoneyFromFloat(10000.0), result.Parts[2].TotalPrice)
+	for i, part := range result.Parts {
+		assert.Equal(t, dto.OpenBillStatusOpen, part.Status)
+		assert.Equal(t, openBillID, *part.ParentID)
+		assert.Nil(t, part.SplitAmount)
+		assert.Equal(t, fmt.Sprintf("ORDER-123-%d", i+1), part.TemporalIdentifier)
+	}
+
+	// Verify mocks
+	mockOpenBillRepo.AssertExpectations(t)
+	mockProductRepo.AssertExpectations(t)
+}
+
+func TestSplitOrder_Evenly(t *testing.T) {
+	// Setup
+	ctx := createTestContext()
+	mockProductRepo := new(MockProductRepository)
+	mockOpenBillRepo := new(MockOpenBillRepository)
+	service := createTestService(mockProductRepo, mockOpenBillRepo)
+
+	openBillID := "bill-1"
+	product := createTestTaxedProduct("product-1", "Fish", "Food", 1, 10000.0)
+	items := []dto.OrderProductItem{{ProductID: "product-1", Quantity: 1}}
+	req := &dto.SplitOrderRequest{Mode: dto.SplitModeEven, People: 3}
+
+	// Mock expectations
+	mockOpenBillRepo.On("FindByID", ctx, openBillID).Return(createTestOpenOrder(openBillID), nil)
+	mockOpenBillRepo.On("FindItemsByID", ctx, openBillID).Return(items, nil)
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1"}).Return([]*dto.Product{product}, nil)
+	mockOpenBillRepo.On("Split", ctx, openBillID, items, mock.AnythingOfType("[]*dto.OpenBill"), [][]dto.OrderProductItem{items, items, items}).Return(nil)
+
+	// Execute
+	result, err := service.SplitOrder(ctx, openBillID, req)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, result.Parts, 3)
+	expectedAmounts := []string{"3333.34", "3333.33", "3333.33"}
+	for i, part := range result.Parts {
+		require.NotNil(t, part.SplitAmount)
+		assert.Equal(t, expectedAmounts[i], part.SplitAmount.String())
+		assert.Equal(t, *part.SplitAmount, part.TotalPrice)
+		assert.Equal(t, part.TotalPrice, part.Products[0].Subtotal.Add(part.VAT).Add(part.ICO))
+	}
+
+	// Verify mocks
+	mockOpenBillRepo.AssertExpectations(t)
+}
+
+func TestSplitOrder_ByAmounts(t *testing.T) {
+	// Setup
+	ctx := createTestContext()
+	mockProductRepo := new(MockProductRepository)
+	mockOpenBillRepo := new(MockOpenBillRepository)
+	service := createTestService(mockProductRepo, mockOpenBillRepo)
+
+	openBillID := "bill-1"
+	beer := createTestTaxedProduct("product-1", "Beer", "Drinks", 1, 10000.0)
+	fries := createTestTaxedProduct("product-2", "Fries", "Food", 1, 5000.0)
+	items := []dto.OrderProductItem{
+		{ProductID: "product-1", Quantity: 1},
+		{ProductID: "product-2", Quantity: 1},
+	}
+	req := &dto.SplitOrderRequest{
+		Mode:    dto.SplitModeAmounts,
+		Amounts: []dto.Money{dto.NewMoneyFromFloat(9000.0), dto.NewMoneyFromFloat(6000.0)},
+	}
+
+	// Mock expectations
+	mockOpenBillRepo.On("FindByID", ctx, openBillID).Return(createTestOpenOrder(openBillID), nil)
+	mockOpenBillRepo.On("FindItemsByID", ctx, openBillID).Return(items, nil)
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1", "product-2"}).Return([]*dto.Product{beer, fries}, nil)
+	mockOpenBillRepo.On("Split", ctx, openBillID, items, mock.AnythingOfType("[]*dto.OpenBill"), [][]dto.OrderProductItem{items, items}).Return(nil)
+
+	// Execute
+	result, err := service.SplitOrder(ctx, openBillID, req)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, result.Parts, 2)
+	assert.Equal(t, dto.NewMoneyFromFloat(9000.0), result.Parts[0].TotalPrice)
+	assert.Equal(t, dto.NewMoneyFromFloat(6000.0), result.Parts[0].Products[0].Total)
+	assert.Equal(t, dto.NewMoneyFromFloat(3000.0), result.Parts[0].Products[1].Total)
+	assert.Equal(t, dto.NewMoneyFromFloat(6000.0), result.Parts[1].TotalPrice)
+
+	// Verify mocks
+	mockOpenBillRepo.AssertExpectations(t)
+}
+
+// Error Cases
+
+func TestSplitOrder_InvalidSplit(t *testing.T) {
+	testCases := []struct {
+		name string
+		req  *dto.SplitOrderRequest
+	}{
+		{
+			name: "amounts do not add up to the total",
+			req:  &dto.SplitOrderRequest{Mode: dto.SplitModeAmounts, Amounts: []dto.Money{dto.NewMoneyFromFloat(5000.0), dto.NewMoneyFromFloat(4000.0)}},
+		},
+		{
+			name: "single amount",
+			req:  &dto.SplitOrderRequest{Mode: dto.SplitModeAmounts, Amounts: []dto.Money{dto.NewMoneyFromFloat(10000.0)}},
+		},
+		{
+			name: "less than two people",
+			req:  &dto.SplitOrderRequest{Mode: dto.SplitModeEven, People: 1},
+		},
+		{
+			name: "quantity exceeds the order",
+			req: &dto.SplitOrderRequest{Mode: dto.SplitModeItems, Parts: []dto.SplitOrderPart{
+				{Products: []dto.OrderProductItem{{ProductID: "product-1", Quantity: 3}}},
+			}},
+		},
+		{
+			name: "single part with every product",
+			req: &dto.SplitOrderRequest{Mode: dto.SplitModeItems, Parts: []dto.SplitOrderPart{
+				{Products: []dto.OrderProductItem{{ProductID: "product-1", Quantity: 2}}},
+			}},
+		},
+		{
+			name: "unknown mode",
+			req:  &dto.SplitOrderRequest{Mode: "random"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup
+			ctx := createTestContext()
+			mockProductRepo := new(MockProductRepository)
+			mockOpenBillRepo := new(MockOpenBillRepository)
+			service := createTestService(mockProductRepo, mockOpenBillRepo)
+
+			openBillID := "bill-1"
+			product := createTestTaxedProduct("product-1", "Beer", "Drinks", 1, 5000.0)
+			items := []dto.OrderProductItem{{ProductID: "product-1", Quantity: 2}}
+
+			// Mock expectations
+			mockOpenBillRepo.On("FindByID", ctx, openBillID).Return(createTestOpenOrder(openBillID), nil)
+			mockOpenBillRepo.On("FindItemsByID", ctx, openBillID).Return(items, nil)
+			mockProductRepo.On("FindByIDs", ctx, []string{"product-1"}).Return([]*dto.Product{product}, nil)
+
+			// Execute
+			result, err := service.SplitOrder(ctx, openBillID, tc.req)
+
+			// Assert
+			require.Error(t, err)
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, orderError.ErrInvalidOrderSplit)
+			mockOpenBillRepo.AssertNotCalled(t, "Split")
+		})
+	}
+}
+
+func TestSplitOrder_InvalidStatus(t *testing.T) {
+	// Setup
+	ctx := createTestContext()
+	mockProductRepo := new(MockProductRepository)
+	mockOpenBillRepo := new(MockOpenBillRepository)
+	service := createTestService(mockProductRepo, mockOpenBillRepo)
+
+	openBillID := "bill-1"
+	existingBill := createTestOpenOrder(openBillID)
+	existingBill.Status = dto.OpenBillStatusPaid
+
+	// Mock expectations
+	mockOpenBillRepo.On("FindByID", ctx, openBillID).Return(existingBill, nil)
+
+	// Execute
+	result, err := service.SplitOrder(ctx, openBillID, &dto.SplitOrderRequest{Mode: dto.SplitModeEven, People: 2})
+
+	// Assert
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, orderError.ErrInvalidOrderStatus)
+	mockOpenBillRepo.AssertNotCalled(t, "FindItemsByID")
+	mockOpenBillRepo.AssertNotCalled(t, "Split")
+}
+
+func TestSplitOrder_RepositoryError(t *testing.T) {
+	// Setup
+	ctx := createTestContext()
+	mockProductRepo := new(MockProductRepository)
+	mockOpenBillRepo := new(MockOpenBillRepository)
+	service := createTestService(mockProductRepo, mockOpenBillRepo)
+
+	openBillID := "bill-1"
+	product := createTestTaxedProduct("product-1", "Beer", "Drinks", 1, 5000.0)
+	items := []dto.OrderProductItem{{ProductID: "product-1", Quantity: 2}}
+
+	// Mock expectations
+	mockOpenBillRepo.On("FindByID", ctx, openBillID).Return(createTestOpenOrder(openBillID), nil)
+	mockOpenBillRepo.On("FindItemsByID", ctx, openBillID).Return(items, nil)
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1"}).Return([]*dto.Product{product}, nil)
+	mockOpenBillRepo.On("Split", ctx, openBillID, items, mock.AnythingOfType("[]*dto.OpenBill"), mock.Anything).Return(orderError.ErrInvalidOrderStatus)
+
+	// Execute
+	result, err := service.SplitOrder(ctx, openBillID, &dto.SplitOrderRequest{Mode: dto.SplitModeEven, People: 2})
+
+	// Assert
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, orderError.ErrOrderSplitFailed)
+	assert.ErrorIs(t, err, orderError.ErrInvalidOrderStatus)
+}
+
+func TestSplitOrder_OrderChangedWhileSplitting(t *testing.T) {
+	// Setup
+	ctx := createTestContext()
+	mockProductRepo := new(MockProductRepository)
+	mockOpenBillRepo := new(MockOpenBillRepository)
+	service := createTestService(mockProductRepo, mockOpenBillRepo)
+
+	openBillID := "bill-1"
+	product := createTestTaxedProduct("product-1", "Beer", "Drinks", 1, 5000.0)
+	items := []dto.OrderProductItem{{ProductID: "product-1", Quantity: 2}}
+
+	// Mock expectations: the order no longer holds the items it was split from
+	mockOpenBillRepo.On("FindByID", ctx, openBillID).Return(createTestOpenOrder(openBillID), nil)
+	mockOpenBillRepo.On("FindItemsByID", ctx, openBillID).Return(items, nil)
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1"}).Return([]*dto.Product{product}, nil)
+	mockOpenBillRepo.On("Split", ctx, openBillID, items, mock.AnythingOfType("[]*dto.OpenBill"), mock.Anything).Return(orderError.ErrOrderChanged)
+
+	// Execute
+	result, err := service.SplitOrder(ctx, openBillID, &dto.SplitOrderRequest{Mode: dto.SplitModeEven, People: 2})
+
+	// Assert
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, orderError.ErrOrderChanged)
+}
+
+func TestPayOrder_SplitShare(t *testing.T) {
+	// Setup
+	ctx := createTestContext()
+	mockProductRepo := new(MockProductRepository)
+	mockOpenBillRepo := new(MockOpenBillRepository)
+	mockBillRepo := new(MockBillRepository)
+	service := createTestServiceWithInvoice(mockProductRepo, mockOpenBillRepo, mockBillRepo)
+
+	openBillID := "part-1"
+	splitAmount := dto.NewMoneyFromFloat(6000.0)
+	part := createTestOpenOrder(openBillID)
+	part.SplitAmount = &splitAmount
+
+	beer := createTestProduct("product-1", "Beer", "Drinks", 1, 10000.0, 0.19)
+	fries := createTestProduct("product-2", "Fries", "Food", 1, 5000.0, 0.19)
+	items := []dto.OrderProductItem{
+		{ProductID: "product-1", Quantity: 1},
+		{ProductID: "product-2", Quantity: 1},
+	}
+	req := &dto.PayOrderRequest{PaymentCode: dto.ElectronicInvoicePaymentCodeCash}
+
+	// Mock expectations
+	mockOpenBillRepo.On("FindByID", ctx, openBillID).Return(part, nil)
+	mockOpenBillRepo.On("StartPayment", ctx, openBillID, mock.AnythingOfType("time.Time")).Return(nil)
+	mockOpenBillRepo.On("FindItemsByID", ctx, openBillID).Return(items, nil)
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1", "product-2"}).Return([]*dto.Product{beer, fries}, nil)
+	mockBillRepo.On("Create", ctx, mock.MatchedBy(func(b *bill.Aggregate) bool {
+		return len(b.Products()) == 2 && b.Products()[0].Quantity() == 1 && b.Products()[1].Quantity() == 1
+	}), mock.AnythingOfType("[]*dto.Product")).Return(nil)
+
+	// Execute
+	result, err := service.PayOrder(ctx, openBillID, req)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "3361.34", result.Products[0].UnitPrice.String())
+	assert.Equal(t, "1680.67", result.Products[1].UnitPrice.String())
+	assert.Equal(t, splitAmount, result.PayAmount)
+
+	// Verify mocks
+	mockOpenBillRepo.AssertExpectations(t)
+	mockBillRepo.AssertExpectations(t)
+}
+
+func TestUpdateOrder_SplitShare(t *testing.T) {
+	// Setup
+	ctx := createTestContext()
+	mockProductRepo := new(MockProductRepository)
+	mockOpenBillRepo := new(MockOpenBillRepository)
+	service := createTestService(mockProductRepo, mockOpenBillRepo)
+
+	openBillID := "part-1"
+	splitAmount := dto.NewMoneyFromFloat(6000.0)
+	part := createTestOpenOrder(openBillID)
+	part.SplitAmount = &splitAmount
+
+	// Mock expectations
+	mockOpenBillRepo.On("FindByID", ctx, openBillID).Return(part, nil)
+
+	// Execute
+	result, err := service.UpdateOrder(ctx, openBillID, &dto.UpdateOrderRequest{})
+
+	// Assert
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, orderError.ErrOrderIsSplitShare)
+	mockOpenBillRepo.AssertNotCalled(t, "Update")
+}
+
+// TransferOrderItems and MergeOrders Tests
+
+func TestTransferOrderItems_PartialQuantities(t *testing.T) {
+	// Setup
+	ctx := createTestContext()
+	mockProductRepo := new(MockProductRepository)
+	mockOpenBillRepo := new(MockOpenBillRepository)
+	service := createTestService(mockProductRepo, mockOpenBillRepo)
+
+	source := createTestOpenOrder("table-1")
+	target := createTestOpenOrder("bar-1")
+	beer := createTestTaxedProduct("product-1", "Beer", "Drinks", 1, 10000.0)
+	fries := createTestTaxedProduct("product-2", "Fries", "Food", 1, 5000.0)
+
+	req := &dto.TransferOrderItemsRequest{
+		TargetOrderID: "bar-1",
+		Products: []dto.OrderProductItem{
+			{ProductID: "product-1", Quantity: 1},
+			{ProductID: "product-2", Quantity: 1},
+		},
+		MovedBy: "waiter-1",
+	}
+
+	expectedSourceItems := []dto.OrderProductItem{{ProductID: "product-1", Quantity: 2}}
+	expectedTargetItems := []dto.OrderProductItem{
+		{ProductID: "product-2", Quantity: 3},
+		{ProductID: "product-1", Quantity: 1},
+	}
+
+	// Mock expectations
+	mockOpenBillRepo.On("FindByID", ctx, "table-1").Return(source, nil)
+	mockOpenBillRepo.On("FindByID", ctx, "bar-1").Return(target, nil)
+	mockOpenBillRepo.On("Transfer", ctx, "table-1", "bar-1").Return([]dto.OrderProductItem{
+		{ProductID: "product-1", Quantity: 3},
+		{ProductID: "product-2", Quantity: 1},
+	}, []dto.OrderProductItem{{ProductID: "product-2", Quantity: 2}}, nil)
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1", "product-2"}).Return([]*dto.Product{beer, fries}, nil)
+
+	// Execute
+	result, err := service.TransferOrderItems(ctx, "table-1", req)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, mockOpenBillRepo.transferred)
+	assert.Equal(t, expectedSourceItems, mockOpenBillRepo.transferred.SourceItems)
+	assert.Equal(t, expectedTargetItems, mockOpenBillRepo.transferred.TargetItems)
+	transfers := mockOpenBillRepo.transferred.Transfers
+	require.Len(t, transfers, 2)
+	assert.Equal(t, "product-1", transfers[0].ProductID)
+	assert.Equal(t, 1, transfers[0].Quantity)
+	assert.Equal(t, "product-2", transfers[1].ProductID)
+	assert.Equal(t, 1, transfers[1].Quantity)
+	assert.Equal(t, "waiter-1", transfers[0].MovedBy)
+	assert.Equal(t, "table-1", transfers[0].SourceOrderID)
+	assert.Equal(t, "bar-1", transfers[0].TargetOrderID)
+	assert.Equal(t, dto.OpenBillStatusOpen, result.Source.Status)
+	assert.Equal(t, dto.NewMoneyFromFloat(20000.0), result.Source.TotalPrice)
+	assert.Equal(t, dto.NewMoneyFromFloat(2000.0), result.Source.Tip)
+	assert.Equal(t, dto.NewMoneyFromFloat(25000.0), result.Target.TotalPrice)
+	require.Len(t, result.Target.Products, 2)
+	assert.Len(t, result.Transfers, 2)
+
+	// Verify mocks
+	mockOpenBillRepo.AssertExpectations(t)
+	mockProductRepo.AssertExpectations(t)
+}
+
+func TestMergeOrders_Success(t *testing.T) {
+	// Setup
+	ctx := createTestContext()
+	mockProductRepo := new(MockProductRepository)
+	mockOpenBillRepo := new(MockOpenBillRepository)
+	service := createTestService(mockProductRepo, mockOpenBillRepo)
+
+	source := createTestOpenOrder("table-2")
+	target := createTestOpenOrder("table-1")
+	beer := createTestTaxedProduct("product-1", "Beer", "Drinks", 1, 10000.0)
+	sourceItems := []dto.OrderProductItem{{ProductID: "product-1", Quantity: 2}}
+
+	// Mock expectations
+	mockOpenBillRepo.On("FindByID", ctx, "table-2").Return(source, nil)
+	mockOpenBillRepo.On("FindByID", ctx, "table-1").Return(target, nil)
+	mockOpenBillRepo.On("Transfer", ctx, "table-2", "table-1").Return(sourceItems, []dto.OrderProductItem{{ProductID: "product-1", Quantity: 1}}, nil)
+	mockProductRepo.On("FindByIDs", ctx, []string{"product-1"}).Return([]*dto.Product{beer}, nil)
+
+	// Execute
+	result, err := service.MergeOrders(ctx, "table-1", &dto.MergeOrdersRequest{SourceOrderID: "table-2", MovedBy: "waiter-1"})
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, mockOpenBillRepo.transferred)
+	assert.Empty(t, mockOpenBillRepo.transferred.SourceItems)
+	assert.Equal(t, []dto.OrderProductItem{{ProductID: "product-1", Quantity: 3}}, mockOpenBillRepo.transferred.TargetItems)
+	assert.Equal(t, dto.OpenBillStatusMerged, result.Source.Status)
+	assert.True(t, result.Source.TotalPrice.IsZero())
+	assert.Equal(t, dto.NewMoneyFromFloat(30000.0), result.Target.TotalPrice)
+	require.Len(t, result.Transfers, 1)
+	assert.Equal(t, 2, result.Transfers[0].Quantity)
+
+	// Verify mocks
+	mockOpenBillRepo.AssertExpectations(t)
+}
+
+// Error Cases
+
+func TestTransferOrderItems_InvalidTransfer(t *testing.T) {
+	testCases := []struct {
+		name string
+		req  *dto.TransferOrderItemsRequest
+	}{
+		{
+			name: "quantity exceeds the source order",
+			req:  &dto.TransferOrderItemsRequest{TargetOrderID: "bar-1", MovedBy: "waiter-1", Products: []dto.OrderProductItem{{ProductID: "product-1", Quantity: 3}}},
+		},
+		{
+			name: "product not in the source order",
+			req:  &dto.TransferOrderItemsRequest{TargetOrderID: "bar-1", MovedBy: "waiter-1", Products: []dto.OrderProductItem{{ProductID: "product-9", Quantity: 1}}},
+		},
+		{
+			name: "zero quantity",
+			req:  &dto.TransferOrderItemsRequest{TargetOrderID: "bar-1", MovedBy: "waiter-1", Products: []dto.OrderProductItem{{ProductID: "product-1", Quantity: 0}}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup
+			ctx := createTestContext()
+			mockProductRepo := new(MockProductRepository)
+			mockOpenBillRepo := new(MockOpenBillRepository)
+			service := createTestService(mockProductRepo, mockOpenBillRepo)
+
+			// Mock expectations
+			mockOpenBillRepo.On("FindByID", ctx, "table-1").Return(createTestOpenOrder("table-1"), nil)
+			mockOpenBillRepo.On("FindByID", ctx, "bar-1").Return(createTestOpenOrder("bar-1"), nil)
+			mockOpenBillRepo.On("Transfer", ctx, "table-1", "bar-1").Return([]dto.OrderProductItem{{ProductID: "product-1", Quantity: 2}}, []dto.OrderProductItem{}, nil).Maybe()
+
+			// Execute
+			result, err := service.TransferOrderItems(ctx, "table-1", tc.req)
+
+			// Assert
+			require.Error(t, err)
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, orderError.ErrInvalidOrderTransfer)
+			assert.Nil(t, mockOpenBillRepo.transferred)
+		})
+	}
+}
+
+func TestTransferOrderItems_SourceChangedBeforeLock(t *testing.T) {
+	// Setup
+	ctx := createTestContext()
+	mockProductRepo := new(MockProductRepository)
+	mockOpenBillRepo := new(MockOpenBillRepository)
+	service := createTestService(mockProductRepo, mockOpenBillRepo)
+
+	// The waiter saw three beers but another request took two of them before the orders were locked
+	mockOpenBillRepo.On("FindByID", ctx, "table-1").Return(createTestOpenOrder("table-1"), nil)
+	mockOpenBillRepo.On("FindByID", ctx, "bar-1").Return(createTestOpenOrder("bar-1"), nil)
+	mockOpenBillRepo.On("Transfer", ctx, "table-1", "bar-1").Return([]dto.OrderProductItem{{ProductID: "product-1", Quantity: 1}}, []dto.OrderProductItem{}, nil)
+
+	// Execute
+	result, err := service.TransferOrderItems(ctx, "table-1", &dto.TransferOrderItemsRequest{
+		TargetOrderID: "bar-1",
+		MovedBy:       "waiter-1",
+		Products:      []dto.OrderProductItem{{ProductID: "product-1", Quantity: 2}},
+	})
+
+	// Assert
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, orderError.ErrInvalidOrderTransfer)
+	assert.NotErrorIs(t, err, orderError.ErrOrderTransferFailed)
+	assert.Nil(t, mockOpenBillRepo.transferred)
+	mockProductRepo.AssertNotCalled(t, "FindByIDs")
+}
+
+func TestTransferOrderItems_InvalidRequest(t *testing.T) {
+	testCases := []struct {
+		name     string
+		sourceID string
+		req      *dto.TransferOrderItemsRequest
+	}{
+		{
+			name:     "same order",
+			sourceID: "table-1",
+			req:      &dto.TransferOrderItemsRequest{TargetOrderID: "table-1", MovedBy: "waiter-1", Products: []dto.OrderProductItem{{ProductID: "product-1", Quantity: 1}}},
+		},
+		{
+			name:     "missing moved_by",
+			sourceID: "table-1",
+			req:      &dto.TransferOrderItemsRequest{TargetOrderID: "bar-1", Products: []dto.OrderProductItem{{ProductID: "product-1", Quantity: 1}}},
+		},
+		{
+			name:     "no products",
+			sourceID: "table-1",
+			req:      &dto.TransferOrderItemsRequest{TargetOrderID: "bar-1", MovedBy: "waiter-1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup
+			ctx := createTestContext()
+			mockProductRepo := new(MockProductRepository)
+			mockOpenBillRepo := new(MockOpenBillRepository)
+			service := createTestService(mockProductRepo, mockOpenBillRepo)
+
+			// Execute
+			result, err := service.TransferOrderItems(ctx, tc.sourceID, tc.req)
+
+			// Assert
+			require.Error(t, err)
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, orderError.ErrInvalidOrderTransfer)
+			mockOpenBillRepo.AssertNotCalled(t, "FindByID")
+		})
+	}
+}
+
+func TestTransferOrderItems_TargetNotOpen(t *testing.T) {
+	// Setup
+	ctx := createTestContext()
+	mockProductRepo := new(MockProductRepository)
+	mockOpenBillRepo := new(MockOpenBillRepository)
+	service := createTestService(mockProductRepo, mockOpenBillRepo)
+
+	target := createTestOpenOrder("bar-1")
+	target.Status = dto.OpenBillStatusPaid
+
+	// Mock expectations
+	mockOpenBillRepo.On("FindByID", ctx, "table-1").Return(createTestOpenOrder("table-1"), nil)
+	mockOpenBillRepo.On("FindByID", ctx, "bar-1").Return(target, nil)
+
+	// Execute
+	result, err := service.TransferOrderItems(ctx, "table-1", &dto.TransferOrderItemsRequest{
+		TargetOrderID: "bar-1",
+		MovedBy:       "waiter-1",
+		Products:      []dto.OrderProductItem{{ProductID: "product-1", Quantity: 1}},
+	})
+
+	// Assert
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, orderError.ErrInvalidOrderStatus)
+	mockOpenBillRepo.AssertNotCalled(t, "Transfer")
 }
@@ -71,7 +71,7 @@ func createTestProductDTO(id, name, category string, version int, price, vat flo
 		Name:                name,
 		Category:            category,
 		Version:             version,
-		TotalPriceWithTaxes: price,
+		TotalPriceWithTaxes: dto.NewMoneyFromFloat(price),
 		VAT:                 vat,
 		CreatedAt:           time.Now(),
 		UpdatedAt:           time.Now(),
@@ -99,7 +99,7 @@ func TestCreateProduct_Success(t *testing.T) {
 	mockRepo.On("Create", ctx, mock.MatchedBy(func(p *product.Aggregate) bool {
 		dto := p.ToDTO()
 		return dto.Name == req.Name && dto.Category == req.Category &&
-			dto.TotalPriceWithTaxes == 127.0 && dto.VAT == 0.19 &&
+			dto.TotalPriceWithTaxes.String() == "127.00" && dto.VAT == 0.19 &&
 			dto.Version == 1 // Version should always be 1
 	})).Return(nil)
 
@@ -111,10 +111,10 @@ func TestCreateProduct_Success(t *testing.T) {
 	assert.Equal(t, req.Name, result.Name)
 	assert.Equal(t, req.Category, result.Category)
 	assert.Equal(t, 1, result.Version) // Version should be 1
-	assert.Equal(t, 127.0, result.TotalPriceWithTaxes)
+	assert.Equal(t, dto.NewMoneyFromFloat(127.0), result.TotalPriceWithTaxes)
 	assert.Equal(t, 0.19, result.VAT)
 	assert.Equal(t, 0.08, result.ICO)
-	assert.Equal(t, 100.0, result.UnitPrice)
+	assert.Equal(t, dto.NewMoneyFromFloat(100.0), result.UnitPrice)
 	assert.Equal(t, req.SKU, result.SKU)
 
 	mockRepo.AssertExpectations(t)
@@ -179,7 +179,7 @@ func TestUpdateProduct_Success(t *testing.T) {
 	mockRepo.On("Update", ctx, productID, mock.MatchedBy(func(p *product.Aggregate) bool {
 		dto := p.ToDTO()
 		return dto.Name == req.Name && dto.Category == req.Category &&
-			dto.TotalPriceWithTaxes == 200.0 && dto.VAT == 0.38 &&
+			dto.TotalPriceWithTaxes.String() == "200.00" && dto.VAT == 0.38 &&
 			dto.Version == 1 // Version should remain 1
 	})).Return(nil)
 
@@ -191,10 +191,10 @@ func TestUpdateProduct_Success(t *testing.T) {
 	assert.Equal(t, req.Name, result.Name)
 	assert.Equal(t, req.Category, result.Category)
 	assert.Equal(t, 1, result.Version) // Version should remain 1
-	assert.Equal(t, 200.0, result.TotalPriceWithTaxes)
+	assert.Equal(t, dto.NewMoneyFromFloat(200.0), result.TotalPriceWithTaxes)
 	assert.Equal(t, 0.38, result.VAT)
 	assert.Equal(t, 0.12, result.ICO)
-	assert.Equal(t, 133.33, result.UnitPrice)
+	assert.Equal(t, dto.NewMoneyFromFloat(133.33), result.UnitPrice)
 	assert.Equal(t, req.SKU, result.SKU)
 
 	mockRepo.AssertExpectations(t)
@@ -0,0 +1,61 @@
+package tax
+
+import "laguna-escondida/backend/internal/domain/dto"
+
+// Breakdown splits an amount into its taxable base and the VAT and ICO charged on it
+// Rates are always decimals (0.19 for 19%) and every amount follows the dto.Money rounding rules
+type Breakdown struct {
+	Base  dto.Money
+	VAT   dto.Money
+	ICO   dto.Money
+	Total dto.Money
+}
+
+// ExtractFromTotal splits a price that already includes taxes, as menu prices do
+// The taxes absorb the rounding difference so Base + VAT + ICO always equals the total
+func ExtractFromTotal(total dto.Money, vatRate, icoRate float64) Breakdown {
+	return splitTaxes(total, total.DivRate(vatRate+icoRate), vatRate, icoRate)
+}
+
+// ExtractFromUnitPrice splits quantity units of a price that already includes taxes, the
+// base is taken per unit so it is an exact multiple of the unit price the invoice shows
+// Like ExtractFromTotal, Base + VAT + ICO always equals the unit price times quantity
+func ExtractFromUnitPrice(unitTotal dto.Money, quantity int, vatRate, icoRate float64) Breakdown {
+	return splitTaxes(unitTotal.Mul(quantity), unitTotal.DivRate(vatRate+icoRate).Mul(quantity), vatRate, icoRate)
+}
+
+// splitTaxes divides what the total charges over the base between VAT and ICO
+func splitTaxes(total, base dto.Money, vatRate, icoRate float64) Breakdown {
+	taxes := total.Sub(base)
+
+	var vat, ico dto.Money
+	switch {
+	case vatRate > 0 && icoRate > 0:
+		vat = base.MulRate(vatRate)
+		ico = taxes.Sub(vat)
+	case vatRate > 0:
+		vat = taxes
+	case icoRate > 0:
+		ico = taxes
+	}
+
+	return Breakdown{
+		Base:  base,
+		VAT:   vat,
+		ICO:   ico,
+		Total: total,
+	}
+}
+
+// ApplyToBase charges the taxes on top of a base amount, as the electronic invoice does
+func ApplyToBase(base dto.Money, vatRate, icoRate float64) Breakdown {
+	vat := base.MulRate(vatRate)
+	ico := base.MulRate(icoRate)
+
+	return Breakdown{
+		Base:  base,
+		VAT:   vat,
+		ICO:   ico,
+		Total: base.Add(vat).Add(ico),
+	}
+}
@@ -2,32 +2,215 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultInvoiceReconciliationInterval   = time.Minute
+	defaultInvoiceReconciliationMaxBackoff = 15 * time.Minute
+	defaultInvoiceReconciliationBatchSize  = 50
+	defaultInvoiceOutboxInterval           = 5 * time.Second
+	defaultInvoiceOutboxMaxBackoff         = 5 * time.Minute
+	defaultInvoiceOutboxBatchSize          = 20
+	defaultIdempotencyKeyTTL               = 24 * time.Hour
+	defaultIdempotencyProcessingLease      = time.Minute
+	defaultIdempotencyCleanupInterval      = time.Hour
+	defaultElectronicInvoiceEnvironment    = "test"
+	defaultElectronicInvoiceTimeout        = 20 * time.Second
+	// maxElectronicInvoiceTimeout keeps a request to the provider well under the 2 minute
+	// lease of the outbox entries
+	maxElectronicInvoiceTimeout = time.Minute
+)
+
+// Electronic invoicing providers, the invoices are sent through the adapter of the selected one
+const (
+	InvoiceProviderFacturacionV30 = "facturacion_v30"
+	InvoiceProviderUBL21          = "ubl21"
 )
 
 type Config struct {
+	ElectronicInvoiceProvider string
+
 	ElectronicInvoiceURL      string
 	ElectronicInvoiceUser     string
 	ElectronicInvoicePassword string
+	// ElectronicInvoiceTimeout bounds each request to the provider
+	ElectronicInvoiceTimeout time.Duration
+
+	// ElectronicInvoiceUBLDir is where the UBL adapter writes the documents, issued by the
+	// supplier with the NIT and name below. The CUFE of the invoices is computed with the NIT,
+	// facturacion_v30 can run without it but the CUFE it answers is not checked then
+	ElectronicInvoiceUBLDir       string
+	ElectronicInvoiceSupplierNIT  string
+	ElectronicInvoiceSupplierName string
+	// ElectronicInvoiceEnvironment is the DIAN environment the provider sends the documents
+	// to, production or test, the CUFE computed by the backend depends on it
+	ElectronicInvoiceEnvironment string
+
+	InvoiceReconciliationInterval   time.Duration
+	InvoiceReconciliationMaxBackoff time.Duration
+	InvoiceReconciliationBatchSize  int
+
+	InvoiceOutboxInterval   time.Duration
+	InvoiceOutboxMaxBackoff time.Duration
+	InvoiceOutboxBatchSize  int
+
+	// IdempotencyKeyTTL is how long a response is replayed for retries with the same key
+	IdempotencyKeyTTL time.Duration
+	// IdempotencyProcessingLease is how long a retry waits for a request that never answered
+	// before it runs in its place
+	IdempotencyProcessingLease time.Duration
+	IdempotencyCleanupInterval time.Duration
 }
 
 func NewConfig() (*Config, error) {
-	url := os.Getenv("ELECTRONIC_INVOICE_URL")
-	if url == "" {
-		return nil, errors.New("ELECTRONIC_INVOICE_URL is not set")
+	provider := os.Getenv("ELECTRONIC_INVOICE_PROVIDER")
+	if provider == "" {
+		provider = InvoiceProviderFacturacionV30
 	}
+
+	url := os.Getenv("ELECTRONIC_INVOICE_URL")
 	user := os.Getenv("ELECTRONIC_INVOICE_USER")
-	if user == "" {
-		return nil, errors.New("ELECTRONIC_INVOICE_USER is not set")
-	}
 	password := os.Getenv("ELECTRONIC_INVOICE_PASSWORD")
-	if password == "" {
-		return nil, errors.New("ELECTRONIC_INVOICE_PASSWORD is not set")
+	ublDir := os.Getenv("ELECTRONIC_INVOICE_UBL_DIR")
+	supplierNIT := os.Getenv("ELECTRONIC_INVOICE_SUPPLIER_NIT")
+	supplierName := os.Getenv("ELECTRONIC_INVOICE_SUPPLIER_NAME")
+
+	environment := os.Getenv("ELECTRONIC_INVOICE_ENVIRONMENT")
+	if environment == "" {
+		environment = defaultElectronicInvoiceEnvironment
+	}
+	if environment != "production" && environment != "test" {
+		return nil, fmt.Errorf("ELECTRONIC_INVOICE_ENVIRONMENT must be production or test, got %q", environment)
+	}
+
+	switch provider {
+	case InvoiceProviderFacturacionV30:
+		if url == "" {
+			return nil, errors.New("ELECTRONIC_INVOICE_URL is not set")
+		}
+		if user == "" {
+			return nil, errors.New("ELECTRONIC_INVOICE_USER is not set")
+		}
+		if password == "" {
+			return nil, errors.New("ELECTRONIC_INVOICE_PASSWORD is not set")
+		}
+	case InvoiceProviderUBL21:
+		if ublDir == "" {
+			return nil, errors.New("ELECTRONIC_INVOICE_UBL_DIR is not set")
+		}
+		if supplierNIT == "" {
+			return nil, errors.New("ELECTRONIC_INVOICE_SUPPLIER_NIT is not set")
+		}
+		if supplierName == "" {
+			return nil, errors.New("ELECTRONIC_INVOICE_SUPPLIER_NAME is not set")
+		}
+	default:
+		return nil, fmt.Errorf("ELECTRONIC_INVOICE_PROVIDER must be %s or %s, got %q", InvoiceProviderFacturacionV30, InvoiceProviderUBL21, provider)
+	}
+
+	timeout, err := getDuration("ELECTRONIC_INVOICE_TIMEOUT", defaultElectronicInvoiceTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if timeout > maxElectronicInvoiceTimeout {
+		return nil, fmt.Errorf("ELECTRONIC_INVOICE_TIMEOUT must be at most %s, got %s", maxElectronicInvoiceTimeout, timeout)
+	}
+
+	reconciliationInterval, err := getDuration("INVOICE_RECONCILIATION_INTERVAL", defaultInvoiceReconciliationInterval)
+	if err != nil {
+		return nil, err
+	}
+	reconciliationMaxBackoff, err := getDuration("INVOICE_RECONCILIATION_MAX_BACKOFF", defaultInvoiceReconciliationMaxBackoff)
+	if err != nil {
+		return nil, err
+	}
+	reconciliationBatchSize, err := getInt("INVOICE_RECONCILIATION_BATCH_SIZE", defaultInvoiceReconciliationBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	outboxInterval, err := getDuration("INVOICE_OUTBOX_INTERVAL", defaultInvoiceOutboxInterval)
+	if err != nil {
+		return nil, err
+	}
+	outboxMaxBackoff, err := getDuration("INVOICE_OUTBOX_MAX_BACKOFF", defaultInvoiceOutboxMaxBackoff)
+	if err != nil {
+		return nil, err
+	}
+	outboxBatchSize, err := getInt("INVOICE_OUTBOX_BATCH_SIZE", defaultInvoiceOutboxBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	idempotencyKeyTTL, err := getDuration("IDEMPOTENCY_KEY_TTL", defaultIdempotencyKeyTTL)
+	if err != nil {
+		return nil, err
+	}
+	idempotencyProcessingLease, err := getDuration("IDEMPOTENCY_PROCESSING_LEASE", defaultIdempotencyProcessingLease)
+	if err != nil {
+		return nil, err
+	}
+	idempotencyCleanupInterval, err := getDuration("IDEMPOTENCY_CLEANUP_INTERVAL", defaultIdempotencyCleanupInterval)
+	if err != nil {
+		return nil, err
 	}
 
 	return &Config{
+		ElectronicInvoiceProvider: provider,
+
 		ElectronicInvoiceURL:      url,
 		ElectronicInvoiceUser:     user,
 		ElectronicInvoicePassword: password,
+		ElectronicInvoiceTimeout:  timeout,
+
+		ElectronicInvoiceUBLDir:       ublDir,
+		ElectronicInvoiceSupplierNIT:  supplierNIT,
+		ElectronicInvoiceSupplierName: supplierName,
+		ElectronicInvoiceEnvironment:  environment,
+
+		InvoiceReconciliationInterval:   reconciliationInterval,
+		InvoiceReconciliationMaxBackoff: reconciliationMaxBackoff,
+		InvoiceReconciliationBatchSize:  reconciliationBatchSize,
+
+		InvoiceOutboxInterval:   outboxInterval,
+		InvoiceOutboxMaxBackoff: outboxMaxBackoff,
+		InvoiceOutboxBatchSize:  outboxBatchSize,
+
+		IdempotencyKeyTTL:          idempotencyKeyTTL,
+		IdempotencyProcessingLease: idempotencyProcessingLease,
+		IdempotencyCleanupInterval: idempotencyCleanupInterval,
 	}, nil
 }
+
+// getDuration reads an optional duration such as "30s" or "5m"
+func getDuration(key string, defaultValue time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("%s must be a positive duration, got %q", key, value)
+	}
+
+	return duration, nil
+}
+
+func getInt(key string, defaultValue int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number <= 0 {
+		return 0, fmt.Errorf("%s must be a positive integer, got %q", key, value)
+	}
+
+	return number, nil
+}
@@ -0,0 +1,142 @@
+// Package dian holds the code lists of the DIAN technical annex, every provider adapter
+// reports the documents with them
+package dian
+
+import (
+	"strings"
+	"time"
+
+	"laguna-escondida/backend/internal/domain/dto"
+
+	"github.com/samber/lo"
+)
+
+const (
+	// NotReported, NotReportedTelephone and DefaultMunicipalityCode fill the fiscal data a
+	// customer did not give, the DIAN rejects empty fields
+	NotReported             = "No Reporta"
+	NotReportedTelephone    = "00000000"
+	DefaultMunicipalityCode = "11001"
+	// CountryCode is where the customers of the restaurant are registered
+	CountryCode = "CO"
+	Currency    = "COP"
+)
+
+// ColombiaTime is the offset of the issue dates and times, the CUFE is computed with them as
+// they are in Colombia, which has no daylight saving
+var ColombiaTime = time.FixedZone("COT", -5*60*60)
+
+// TaxSchemeID maps the tax to its DIAN tribute code
+func TaxSchemeID(taxCode dto.TaxCode) string {
+	switch taxCode {
+	case dto.TaxCodeVAT:
+		return "01"
+	case dto.TaxCodeICO:
+		return "04"
+	default:
+		return string(taxCode)
+	}
+}
+
+// TaxSchemeName is the name the DIAN gives to the tribute
+func TaxSchemeName(taxCode dto.TaxCode) string {
+	switch taxCode {
+	case dto.TaxCodeVAT:
+		return "IVA"
+	case dto.TaxCodeICO:
+		return "INC"
+	default:
+		return string(taxCode)
+	}
+}
+
+func DocumentTypeCode(documentType dto.DocumentType) string {
+	switch documentType {
+	case dto.DocumentTypeNIT:
+		return "31"
+	case dto.DocumentTypeNationalIdentificationNumber:
+		return "13"
+	case dto.DocumentTypeForeignerID:
+		return "22"
+	case dto.DocumentTypePassport:
+		return "41"
+	case dto.DocumentTypeForeignID:
+		return "42"
+	case dto.DocumentTypeSpecialPermitID:
+		return "47"
+	default:
+		return "13"
+	}
+}
+
+// AdditionalAccountID tells a legal person (1) from a natural person (2)
+func AdditionalAccountID(documentType dto.DocumentType) string {
+	switch documentType {
+	case dto.DocumentTypeNIT:
+		return "1"
+	default:
+		return "2"
+	}
+}
+
+// TaxRegimeCode maps the VAT regime to its DIAN code, customers who did not state it
+// are taken as not responsible for VAT
+func TaxRegimeCode(regime dto.TaxRegime) string {
+	switch regime {
+	case dto.TaxRegimeVATResponsible:
+		return "48"
+	default:
+		return "49"
+	}
+}
+
+// TaxLevelCode lists the fiscal responsibilities separated by semicolons, R-99-PN when
+// the customer has none
+func TaxLevelCode(responsibilities []dto.FiscalResponsibility) string {
+	if len(responsibilities) == 0 {
+		responsibilities = []dto.FiscalResponsibility{dto.FiscalResponsibilityNotApplicable}
+	}
+
+	return strings.Join(lo.Map(responsibilities, func(responsibility dto.FiscalResponsibility, _ int) string {
+		return string(responsibility)
+	}), ";")
+}
+
+// InvoiceTypeCode is 01 for sales invoices and 03 for contingency invoices
+func InvoiceTypeCode(documentType dto.ElectronicDocumentType) string {
+	if documentType == dto.ElectronicDocumentTypeContingencyInvoice {
+		return "03"
+	}
+
+	return "01"
+}
+
+// CorrectionConceptCode maps the reason of a note to the DIAN correction concept, credit
+// and debit notes have their own lists
+func CorrectionConceptCode(documentType dto.ElectronicDocumentType, reason string) string {
+	if documentType == dto.ElectronicDocumentTypeDebitNote {
+		switch dto.DebitNoteReason(reason) {
+		case dto.DebitNoteReasonInterest:
+			return "1"
+		case dto.DebitNoteReasonExpenses:
+			return "2"
+		case dto.DebitNoteReasonPriceChange:
+			return "3"
+		default:
+			return "4"
+		}
+	}
+
+	switch dto.CreditNoteReason(reason) {
+	case dto.CreditNoteReasonReturnedGoods:
+		return "1"
+	case dto.CreditNoteReasonCancellation:
+		return "2"
+	case dto.CreditNoteReasonDiscount:
+		return "3"
+	case dto.CreditNoteReasonPriceAdjustment:
+		return "4"
+	default:
+		return "5"
+	}
+}
@@ -1,4 +1,4 @@
-package httpclient
+package dian
 
 import "laguna-escondida/backend/internal/domain/dto"
 
@@ -90,7 +90,8 @@ const (
 	DebitCard              = "49"
 )
 
-func paymentCodeToCode(paymentCode dto.ElectronicInvoicePaymentCode) string {
+// PaymentMeansCode maps the payment method to its DIAN payment means code
+func PaymentMeansCode(paymentCode dto.ElectronicInvoicePaymentCode) string {
 	switch paymentCode {
 	case dto.ElectronicInvoicePaymentCodeCreditCard:
 		return CrediCard
@@ -0,0 +1,53 @@
+// Package einvoice selects the adapter of the electronic invoicing provider
+package einvoice
+
+import (
+	"fmt"
+	"os"
+
+	"laguna-escondida/backend/internal/domain/aggregate/billowner"
+	"laguna-escondida/backend/internal/domain/dto"
+	"laguna-escondida/backend/internal/domain/ports"
+	"laguna-escondida/backend/internal/platform/config"
+	"laguna-escondida/backend/internal/platform/einvoice/ubl"
+	"laguna-escondida/backend/internal/platform/httpclient"
+)
+
+// Factory builds the adapter of a provider from the configuration
+type Factory func(cfg *config.Config) (ports.ElectronicInvoiceClient, error)
+
+// providers maps each provider name of the configuration to its adapter
+var providers = map[string]Factory{
+	config.InvoiceProviderFacturacionV30: newFacturacionV30Client,
+	config.InvoiceProviderUBL21:          newUBLClient,
+}
+
+// NewElectronicInvoiceClient returns the adapter of the provider selected in the configuration
+func NewElectronicInvoiceClient(cfg *config.Config) (ports.ElectronicInvoiceClient, error) {
+	factory, found := providers[cfg.ElectronicInvoiceProvider]
+	if !found {
+		return nil, fmt.Errorf("unknown electronic invoice provider %q", cfg.ElectronicInvoiceProvider)
+	}
+
+	return factory(cfg)
+}
+
+func newFacturacionV30Client(cfg *config.Config) (ports.ElectronicInvoiceClient, error) {
+	return httpclient.NewElectronicInvoiceClient(cfg), nil
+}
+
+func newUBLClient(cfg *config.Config) (ports.ElectronicInvoiceClient, error) {
+	supplierNIT := billowner.NormalizeDocumentNumber(cfg.ElectronicInvoiceSupplierNIT)
+	if err := billowner.ValidateDocument(dto.DocumentTypeNIT, supplierNIT); err != nil {
+		return nil, fmt.Errorf("invalid ELECTRONIC_INVOICE_SUPPLIER_NIT: %w", err)
+	}
+
+	if err := os.MkdirAll(cfg.ElectronicInvoiceUBLDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create ELECTRONIC_INVOICE_UBL_DIR: %w", err)
+	}
+
+	return ubl.NewClient(cfg.ElectronicInvoiceUBLDir, ubl.Supplier{
+		NIT:  supplierNIT,
+		Name: cfg.ElectronicInvoiceSupplierName,
+	}), nil
+}
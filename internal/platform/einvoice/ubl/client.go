@@ -0,0 +1,52 @@
+package ubl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"laguna-escondida/backend/internal/domain/dto"
+	domainError "laguna-escondida/backend/internal/domain/error"
+)
+
+// Client is the adapter of providers that take UBL 2.1 documents. It does not transmit
+// them yet, every document is written to a directory so it can be validated offline
+// while the provider is evaluated
+type Client struct {
+	dir      string
+	supplier Supplier
+}
+
+func NewClient(dir string, supplier Supplier) *Client {
+	return &Client{
+		dir:      dir,
+		supplier: supplier,
+	}
+}
+
+// Send writes the document as <prefix><consecutive>.xml, the file name is its tascode.
+// Sending the same document again replaces the file
+func (c *Client) Send(ctx context.Context, document *dto.ElectronicDocument) (*dto.CreateElectronicInvoiceResponse, error) {
+	body, err := Marshal(document, c.supplier)
+	if err != nil {
+		return nil, err
+	}
+
+	tascode := documentNumber(document.Prefix, document.Consecutive)
+	if err := os.WriteFile(c.path(tascode), body, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write UBL document: %w", err)
+	}
+
+	return &dto.CreateElectronicInvoiceResponse{Tascode: tascode}, nil
+}
+
+// Get has no status to report, the documents never reach the DIAN. The written document is
+// read from ELECTRONIC_INVOICE_UBL_DIR
+func (c *Client) Get(ctx context.Context, tascode string) (*dto.ElectronicInvoiceStatus, error) {
+	return nil, fmt.Errorf("%w: UBL document %s", domainError.ErrStatusUnsupported, tascode)
+}
+
+func (c *Client) path(tascode string) string {
+	return filepath.Join(c.dir, tascode+".xml")
+}
@@ -0,0 +1,386 @@
+package ubl
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"time"
+
+	"laguna-escondida/backend/internal/domain/aggregate/billowner"
+	"laguna-escondida/backend/internal/domain/dto"
+	"laguna-escondida/backend/internal/platform/einvoice/dian"
+	"laguna-escondida/backend/internal/platform/shared/utils"
+
+	"github.com/samber/lo"
+)
+
+const (
+	ublVersion = "UBL 2.1"
+	// profileExecutionID 2 marks the documents for the DIAN test environment, the adapter
+	// only writes them to be validated
+	profileExecutionID = "2"
+	// unitCode 94 is the unit of measure of things sold by the unit
+	unitCode = "94"
+	// dianAgencyID identifies the DIAN as the agency that issues the document numbers
+	dianAgencyID = "195"
+	// creditNoteTypeCode 91 is the only credit note type of the DIAN
+	creditNoteTypeCode = "91"
+)
+
+// Supplier is the restaurant as the issuer of the documents
+type Supplier struct {
+	NIT  string
+	Name string
+}
+
+// Marshal renders the document as a UBL 2.1 Invoice, CreditNote or DebitNote, it can be
+// checked against the schemas without sending it anywhere. The document is not signed,
+// the DIAN extensions are added by whoever signs it
+func Marshal(document *dto.ElectronicDocument, supplier Supplier) ([]byte, error) {
+	root := newDocument(document, supplier)
+
+	body, err := xml.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal UBL document: %w", err)
+	}
+
+	return append([]byte(xml.Header), body...), nil
+}
+
+func newDocument(document *dto.ElectronicDocument, supplier Supplier) *xmlDocument {
+	issuedAt := document.IssuedAt.In(dian.ColombiaTime)
+	lines := lo.Map(document.Lines, newLine)
+
+	root := &xmlDocument{
+		CACNamespace:         cacNamespace,
+		CBCNamespace:         cbcNamespace,
+		UBLVersionID:         ublVersion,
+		ProfileExecutionID:   profileExecutionID,
+		ID:                   documentNumber(document.Prefix, document.Consecutive),
+		IssueDate:            issuedAt.Format(time.DateOnly),
+		IssueTime:            issuedAt.Format("15:04:05-07:00"),
+		Note:                 utils.NumberToWords(document.Totals.PayAmount.String()),
+		DocumentCurrencyCode: dian.Currency,
+		LineCountNumeric:     len(lines),
+		SupplierParty:        newSupplierParty(supplier),
+		CustomerParty:        newCustomerParty(document.Customer),
+		PaymentMeans:         newPaymentMeans(document),
+		AllowanceCharges:     newTipCharge(document.Totals),
+		TaxTotals:            newTaxTotals(document.Lines),
+	}
+
+	total := newMonetaryTotal(document)
+	switch document.Type {
+	case dto.ElectronicDocumentTypeCreditNote:
+		root.XMLName = xml.Name{Local: "CreditNote"}
+		root.Namespace = creditNoteNamespace
+		// 20 is a credit note that references an electronic invoice
+		root.CustomizationID = "20"
+		root.ProfileID = "DIAN 2.1: Nota Crédito de Factura Electrónica de Venta"
+		root.CreditNoteTypeCode = creditNoteTypeCode
+		root.DiscrepancyResponse = newDiscrepancyResponse(document)
+		root.BillingReference = newBillingReference(document.Reference)
+		root.LegalMonetaryTotal = total
+		root.CreditNoteLines = lo.Map(lines, func(documentLine line, index int) line {
+			documentLine.CreditedQuantity = newQuantity(document.Lines[index].Quantity)
+			return documentLine
+		})
+	case dto.ElectronicDocumentTypeDebitNote:
+		root.XMLName = xml.Name{Local: "DebitNote"}
+		root.Namespace = debitNoteNamespace
+		// 30 is a debit note that references an electronic invoice
+		root.CustomizationID = "30"
+		root.ProfileID = "DIAN 2.1: Nota Débito de Factura Electrónica de Venta"
+		root.DiscrepancyResponse = newDiscrepancyResponse(document)
+		root.BillingReference = newBillingReference(document.Reference)
+		root.RequestedMonetaryTotal = total
+		root.DebitNoteLines = lo.Map(lines, func(documentLine line, index int) line {
+			documentLine.DebitedQuantity = newQuantity(document.Lines[index].Quantity)
+			return documentLine
+		})
+	default:
+		root.XMLName = xml.Name{Local: "Invoice"}
+		root.Namespace = invoiceNamespace
+		// 10 is a standard sale
+		root.CustomizationID = "10"
+		root.ProfileID = "DIAN 2.1: Factura Electrónica de Venta"
+		root.InvoiceTypeCode = dian.InvoiceTypeCode(document.Type)
+		root.LegalMonetaryTotal = total
+		root.InvoiceLines = lo.Map(lines, func(documentLine line, index int) line {
+			documentLine.InvoicedQuantity = newQuantity(document.Lines[index].Quantity)
+			return documentLine
+		})
+	}
+
+	return root
+}
+
+func documentNumber(prefix string, consecutive int) string {
+	return prefix + strconv.Itoa(consecutive)
+}
+
+func newAmount(money dto.Money) amount {
+	return amount{CurrencyID: dian.Currency, Value: money.String()}
+}
+
+func newQuantity(units int) *quantity {
+	return &quantity{UnitCode: unitCode, Value: strconv.FormatFloat(float64(units), 'f', 2, 64)}
+}
+
+func newIdentifier(documentType dto.DocumentType, number string) identifier {
+	checkDigit := ""
+	if documentType == dto.DocumentTypeNIT {
+		checkDigit = billowner.NITCheckDigit(number)
+	}
+
+	return identifier{
+		SchemeAgencyID: dianAgencyID,
+		SchemeID:       checkDigit,
+		SchemeName:     dian.DocumentTypeCode(documentType),
+		Value:          number,
+	}
+}
+
+func newSupplierParty(supplier Supplier) supplierParty {
+	companyID := newIdentifier(dto.DocumentTypeNIT, supplier.NIT)
+
+	return supplierParty{
+		AdditionalAccountID: dian.AdditionalAccountID(dto.DocumentTypeNIT),
+		Party: party{
+			PartyName: partyName{Name: supplier.Name},
+			PartyTaxScheme: partyTaxScheme{
+				RegistrationName: supplier.Name,
+				CompanyID:        companyID,
+				TaxScheme:        taxScheme{ID: dian.TaxSchemeID(dto.TaxCodeVAT), Name: dian.TaxSchemeName(dto.TaxCodeVAT)},
+			},
+			PartyLegalEntity: partyLegalEntity{
+				RegistrationName: supplier.Name,
+				CompanyID:        companyID,
+			},
+		},
+	}
+}
+
+// newCustomerParty reports the fiscal data a customer did not give with the values the
+// DIAN takes as not given
+func newCustomerParty(customer dto.Customer) customerParty {
+	companyID := newIdentifier(customer.DocumentType, customer.DocumentNumber)
+	municipalityCode := lo.CoalesceOrEmpty(customer.MunicipalityCode, dian.DefaultMunicipalityCode)
+
+	return customerParty{
+		AdditionalAccountID: dian.AdditionalAccountID(customer.DocumentType),
+		Party: party{
+			PartyName: partyName{Name: customer.Name},
+			PartyTaxScheme: partyTaxScheme{
+				RegistrationName: customer.Name,
+				CompanyID:        companyID,
+				TaxLevelCode:     dian.TaxLevelCode(customer.FiscalResponsibilities),
+				RegistrationAddress: &address{
+					ID:                   municipalityCode,
+					CityName:             lo.CoalesceOrEmpty(customer.City, dian.NotReported),
+					CountrySubentity:     lo.CoalesceOrEmpty(customer.Department, dian.NotReported),
+					CountrySubentityCode: municipalityCode[:2],
+					AddressLine:          addressLine{Line: lo.CoalesceOrEmpty(customer.Address, dian.NotReported)},
+					Country:              country{IdentificationCode: dian.CountryCode},
+				},
+				TaxScheme: customerTaxScheme(customer.TaxRegime),
+			},
+			PartyLegalEntity: partyLegalEntity{
+				RegistrationName: customer.Name,
+				CompanyID:        companyID,
+			},
+			Contact: &contact{
+				Telephone:      lo.CoalesceOrEmpty(customer.Telephone, dian.NotReportedTelephone),
+				ElectronicMail: customer.Email,
+			},
+		},
+	}
+}
+
+// customerTaxScheme is VAT for customers responsible for it, ZZ (not applicable) for the rest
+func customerTaxScheme(regime dto.TaxRegime) taxScheme {
+	if regime == dto.TaxRegimeVATResponsible {
+		return taxScheme{ID: dian.TaxSchemeID(dto.TaxCodeVAT), Name: dian.TaxSchemeName(dto.TaxCodeVAT)}
+	}
+
+	return taxScheme{ID: "ZZ", Name: "No aplica"}
+}
+
+// newPaymentMeans lists every method used when the bill was paid with more than one, UBL
+// does not carry the amount paid with each
+func newPaymentMeans(document *dto.ElectronicDocument) []paymentMeans {
+	if len(document.PaymentMeans) == 0 {
+		return []paymentMeans{{ID: "1", PaymentMeansCode: dian.PaymentMeansCode(document.PaymentCode)}}
+	}
+
+	return lo.Map(document.PaymentMeans, func(payment dto.ElectronicDocumentPayment, _ int) paymentMeans {
+		return paymentMeans{
+			ID:               "1",
+			PaymentMeansCode: dian.PaymentMeansCode(payment.Method),
+			PaymentID:        payment.Reference,
+		}
+	})
+}
+
+// newTipCharge sends the voluntary tip as a charge without taxes, it is added to the
+// amount to pay but not to the taxable base of the items
+func newTipCharge(totals dto.ElectronicDocumentTotals) []allowanceCharge {
+	if !totals.Tip.IsPositive() {
+		return nil
+	}
+
+	return []allowanceCharge{{
+		ID:                    1,
+		ChargeIndicator:       true,
+		AllowanceChargeReason: "Propina voluntaria",
+		Amount:                newAmount(totals.Tip),
+		BaseAmount:            newAmount(totals.LineAmount.Sub(totals.DiscountAmount)),
+	}}
+}
+
+func newDiscrepancyResponse(document *dto.ElectronicDocument) *discrepancyResponse {
+	return &discrepancyResponse{
+		ReferenceID:  documentNumber(document.Reference.Prefix, document.Reference.Consecutive),
+		ResponseCode: dian.CorrectionConceptCode(document.Type, document.Correction.Reason),
+		Description:  document.Correction.Description,
+	}
+}
+
+func newBillingReference(reference *dto.ElectronicDocumentReference) *billingReference {
+	var cufe *uuid
+	if reference.CUFE != "" {
+		cufe = &uuid{SchemeName: "CUFE-SHA384", Value: reference.CUFE}
+	}
+
+	return &billingReference{
+		InvoiceDocumentReference: documentReference{
+			ID:        documentNumber(reference.Prefix, reference.Consecutive),
+			UUID:      cufe,
+			IssueDate: reference.IssuedAt.In(dian.ColombiaTime).Format(time.DateOnly),
+		},
+	}
+}
+
+// newMonetaryTotal reports the line amounts after their discounts, the taxes are computed on
+// the line amounts before them, as the bill does
+func newMonetaryTotal(document *dto.ElectronicDocument) *monetaryTotal {
+	totals := document.Totals
+	lineExtension := totals.LineAmount.Sub(totals.DiscountAmount)
+
+	taxExclusive := dto.Money(0)
+	for _, product := range document.Lines {
+		if len(product.Taxes) > 0 {
+			taxExclusive = taxExclusive.Add(product.UnitPrice.Mul(product.Quantity))
+		}
+	}
+
+	var chargeTotal *amount
+	if totals.Tip.IsPositive() {
+		tip := newAmount(totals.Tip)
+		chargeTotal = &tip
+	}
+
+	return &monetaryTotal{
+		LineExtensionAmount: newAmount(lineExtension),
+		TaxExclusiveAmount:  newAmount(taxExclusive),
+		TaxInclusiveAmount:  newAmount(lineExtension.Add(totals.TaxAmount)),
+		ChargeTotalAmount:   chargeTotal,
+		PayableAmount:       newAmount(totals.PayAmount),
+	}
+}
+
+// newTaxTotals adds up the taxes of the lines, one total per tax with a subtotal per rate
+func newTaxTotals(products []dto.BillProduct) []taxTotal {
+	type rateTotal struct {
+		tax  dto.InvoiceTax
+		base dto.Money
+	}
+
+	codes := []dto.TaxCode{}
+	rates := map[dto.TaxCode][]*rateTotal{}
+	for _, product := range products {
+		base := product.UnitPrice.Mul(product.Quantity)
+		for _, tax := range product.Taxes {
+			if _, found := rates[tax.TaxCode]; !found {
+				codes = append(codes, tax.TaxCode)
+			}
+
+			rate, found := lo.Find(rates[tax.TaxCode], func(rate *rateTotal) bool {
+				return rate.tax.Percent == tax.Percent
+			})
+			if !found {
+				rate = &rateTotal{tax: dto.InvoiceTax{TaxCode: tax.TaxCode, Percent: tax.Percent}}
+				rates[tax.TaxCode] = append(rates[tax.TaxCode], rate)
+			}
+			rate.tax.TaxAmount = rate.tax.TaxAmount.Add(tax.TaxAmount)
+			rate.base = rate.base.Add(base)
+		}
+	}
+
+	return lo.Map(codes, func(code dto.TaxCode, _ int) taxTotal {
+		return taxTotal{
+			TaxAmount: newAmount(lo.SumBy(rates[code], func(rate *rateTotal) dto.Money {
+				return rate.tax.TaxAmount
+			})),
+			TaxSubtotals: lo.Map(rates[code], func(rate *rateTotal, _ int) taxSubtotal {
+				return newTaxSubtotal(rate.tax, rate.base)
+			}),
+		}
+	})
+}
+
+func newTaxSubtotal(tax dto.InvoiceTax, base dto.Money) taxSubtotal {
+	return taxSubtotal{
+		TaxableAmount: newAmount(base),
+		TaxAmount:     newAmount(tax.TaxAmount),
+		TaxCategory: taxCategory{
+			Percent:   tax.Percent,
+			TaxScheme: taxScheme{ID: dian.TaxSchemeID(tax.TaxCode), Name: dian.TaxSchemeName(tax.TaxCode)},
+		},
+	}
+}
+
+// newLine renders a line without its quantity, whose element depends on the document type.
+// Allowances are always discounts, the bill subtracts every one of them
+func newLine(product dto.BillProduct, index int) line {
+	base := product.UnitPrice.Mul(product.Quantity)
+
+	lineExtension := base
+	allowances := lo.Map(product.Allowance, func(allowance dto.InvoiceAllowance, i int) allowanceCharge {
+		lineExtension = lineExtension.Sub(allowance.Amount)
+		return allowanceCharge{
+			ID:                        i + 1,
+			AllowanceChargeReasonCode: allowance.ReasonCode,
+			AllowanceChargeReason:     allowance.Description,
+			Amount:                    newAmount(allowance.Amount),
+			BaseAmount:                newAmount(allowance.BaseAmount),
+		}
+	})
+
+	var sellersItem *itemIdentifier
+	if product.Code != "" {
+		sellersItem = &itemIdentifier{ID: product.Code}
+	}
+
+	return line{
+		ID:                  index + 1,
+		LineExtensionAmount: newAmount(lineExtension),
+		AllowanceCharges:    allowances,
+		TaxTotals: lo.Map(product.Taxes, func(tax dto.InvoiceTax, _ int) taxTotal {
+			return taxTotal{
+				TaxAmount:    newAmount(tax.TaxAmount),
+				TaxSubtotals: []taxSubtotal{newTaxSubtotal(tax, base)},
+			}
+		}),
+		Item: item{
+			Description:               lo.CoalesceOrEmpty(lo.FromPtr(product.Description), "unknown"),
+			BrandName:                 lo.FromPtr(product.Brand),
+			ModelName:                 lo.FromPtr(product.Model),
+			SellersItemIdentification: sellersItem,
+		},
+		Price: price{
+			PriceAmount:  newAmount(product.UnitPrice),
+			BaseQuantity: *newQuantity(1),
+		},
+	}
+}
@@ -0,0 +1,218 @@
+package ubl
+
+import "encoding/xml"
+
+const (
+	invoiceNamespace    = "urn:oasis:names:specification:ubl:schema:xsd:Invoice-2"
+	creditNoteNamespace = "urn:oasis:names:specification:ubl:schema:xsd:CreditNote-2"
+	debitNoteNamespace  = "urn:oasis:names:specification:ubl:schema:xsd:DebitNote-2"
+	cacNamespace        = "urn:oasis:names:specification:ubl:schema:xsd:CommonAggregateComponents-2"
+	cbcNamespace        = "urn:oasis:names:specification:ubl:schema:xsd:CommonBasicComponents-2"
+)
+
+// xmlDocument is the root of an Invoice, CreditNote or DebitNote. The three share their
+// elements, the fields that only apply to one of them are left empty on the others, so
+// the order of the fields follows the sequence of the UBL 2.1 schemas
+type xmlDocument struct {
+	XMLName            xml.Name
+	Namespace          string `xml:"xmlns,attr"`
+	CACNamespace       string `xml:"xmlns:cac,attr"`
+	CBCNamespace       string `xml:"xmlns:cbc,attr"`
+	UBLVersionID       string `xml:"cbc:UBLVersionID"`
+	CustomizationID    string `xml:"cbc:CustomizationID"`
+	ProfileID          string `xml:"cbc:ProfileID"`
+	ProfileExecutionID string `xml:"cbc:ProfileExecutionID"`
+	ID                 string `xml:"cbc:ID"`
+	IssueDate          string `xml:"cbc:IssueDate"`
+	IssueTime          string `xml:"cbc:IssueTime"`
+	InvoiceTypeCode    string `xml:"cbc:InvoiceTypeCode,omitempty"`
+	CreditNoteTypeCode string `xml:"cbc:CreditNoteTypeCode,omitempty"`
+	Note               string `xml:"cbc:Note,omitempty"`
+	// DocumentCurrencyCode is always in pesos
+	DocumentCurrencyCode   string               `xml:"cbc:DocumentCurrencyCode"`
+	LineCountNumeric       int                  `xml:"cbc:LineCountNumeric"`
+	DiscrepancyResponse    *discrepancyResponse `xml:"cac:DiscrepancyResponse"`
+	BillingReference       *billingReference    `xml:"cac:BillingReference"`
+	SupplierParty          supplierParty        `xml:"cac:AccountingSupplierParty"`
+	CustomerParty          customerParty        `xml:"cac:AccountingCustomerParty"`
+	PaymentMeans           []paymentMeans       `xml:"cac:PaymentMeans"`
+	AllowanceCharges       []allowanceCharge    `xml:"cac:AllowanceCharge"`
+	TaxTotals              []taxTotal           `xml:"cac:TaxTotal"`
+	LegalMonetaryTotal     *monetaryTotal       `xml:"cac:LegalMonetaryTotal"`
+	RequestedMonetaryTotal *monetaryTotal       `xml:"cac:RequestedMonetaryTotal"`
+	InvoiceLines           []line               `xml:"cac:InvoiceLine"`
+	CreditNoteLines        []line               `xml:"cac:CreditNoteLine"`
+	DebitNoteLines         []line               `xml:"cac:DebitNoteLine"`
+}
+
+type amount struct {
+	CurrencyID string `xml:"currencyID,attr"`
+	Value      string `xml:",chardata"`
+}
+
+type quantity struct {
+	UnitCode string `xml:"unitCode,attr"`
+	Value    string `xml:",chardata"`
+}
+
+// identifier is a document number, schemeID holds the check digit of a NIT and schemeName
+// the DIAN code of the document type
+type identifier struct {
+	SchemeAgencyID string `xml:"schemeAgencyID,attr"`
+	SchemeID       string `xml:"schemeID,attr,omitempty"`
+	SchemeName     string `xml:"schemeName,attr"`
+	Value          string `xml:",chardata"`
+}
+
+type discrepancyResponse struct {
+	ReferenceID  string `xml:"cbc:ReferenceID"`
+	ResponseCode string `xml:"cbc:ResponseCode"`
+	Description  string `xml:"cbc:Description"`
+}
+
+type billingReference struct {
+	InvoiceDocumentReference documentReference `xml:"cac:InvoiceDocumentReference"`
+}
+
+type documentReference struct {
+	ID        string `xml:"cbc:ID"`
+	UUID      *uuid  `xml:"cbc:UUID"`
+	IssueDate string `xml:"cbc:IssueDate"`
+}
+
+type uuid struct {
+	SchemeName string `xml:"schemeName,attr"`
+	Value      string `xml:",chardata"`
+}
+
+type supplierParty struct {
+	AdditionalAccountID string `xml:"cbc:AdditionalAccountID"`
+	Party               party  `xml:"cac:Party"`
+}
+
+type customerParty struct {
+	AdditionalAccountID string `xml:"cbc:AdditionalAccountID"`
+	Party               party  `xml:"cac:Party"`
+}
+
+type party struct {
+	PartyName        partyName        `xml:"cac:PartyName"`
+	PartyTaxScheme   partyTaxScheme   `xml:"cac:PartyTaxScheme"`
+	PartyLegalEntity partyLegalEntity `xml:"cac:PartyLegalEntity"`
+	Contact          *contact         `xml:"cac:Contact"`
+}
+
+type partyName struct {
+	Name string `xml:"cbc:Name"`
+}
+
+type partyTaxScheme struct {
+	RegistrationName    string     `xml:"cbc:RegistrationName"`
+	CompanyID           identifier `xml:"cbc:CompanyID"`
+	TaxLevelCode        string     `xml:"cbc:TaxLevelCode,omitempty"`
+	RegistrationAddress *address   `xml:"cac:RegistrationAddress"`
+	TaxScheme           taxScheme  `xml:"cac:TaxScheme"`
+}
+
+type partyLegalEntity struct {
+	RegistrationName string     `xml:"cbc:RegistrationName"`
+	CompanyID        identifier `xml:"cbc:CompanyID"`
+}
+
+type contact struct {
+	Telephone      string `xml:"cbc:Telephone,omitempty"`
+	ElectronicMail string `xml:"cbc:ElectronicMail,omitempty"`
+}
+
+// address identifies the municipality by its DANE code, the department is its first two digits
+type address struct {
+	ID                   string      `xml:"cbc:ID"`
+	CityName             string      `xml:"cbc:CityName"`
+	CountrySubentity     string      `xml:"cbc:CountrySubentity"`
+	CountrySubentityCode string      `xml:"cbc:CountrySubentityCode"`
+	AddressLine          addressLine `xml:"cac:AddressLine"`
+	Country              country     `xml:"cac:Country"`
+}
+
+type addressLine struct {
+	Line string `xml:"cbc:Line"`
+}
+
+type country struct {
+	IdentificationCode string `xml:"cbc:IdentificationCode"`
+}
+
+type taxScheme struct {
+	ID   string `xml:"cbc:ID"`
+	Name string `xml:"cbc:Name"`
+}
+
+type paymentMeans struct {
+	// ID is 1 for cash payments and 2 for credit
+	ID               string `xml:"cbc:ID"`
+	PaymentMeansCode string `xml:"cbc:PaymentMeansCode"`
+	PaymentID        string `xml:"cbc:PaymentID,omitempty"`
+}
+
+type allowanceCharge struct {
+	ID                        int    `xml:"cbc:ID"`
+	ChargeIndicator           bool   `xml:"cbc:ChargeIndicator"`
+	AllowanceChargeReasonCode string `xml:"cbc:AllowanceChargeReasonCode,omitempty"`
+	AllowanceChargeReason     string `xml:"cbc:AllowanceChargeReason,omitempty"`
+	Amount                    amount `xml:"cbc:Amount"`
+	BaseAmount                amount `xml:"cbc:BaseAmount"`
+}
+
+type taxTotal struct {
+	TaxAmount    amount        `xml:"cbc:TaxAmount"`
+	TaxSubtotals []taxSubtotal `xml:"cac:TaxSubtotal"`
+}
+
+type taxSubtotal struct {
+	TaxableAmount amount      `xml:"cbc:TaxableAmount"`
+	TaxAmount     amount      `xml:"cbc:TaxAmount"`
+	TaxCategory   taxCategory `xml:"cac:TaxCategory"`
+}
+
+type taxCategory struct {
+	Percent   string    `xml:"cbc:Percent"`
+	TaxScheme taxScheme `xml:"cac:TaxScheme"`
+}
+
+type monetaryTotal struct {
+	LineExtensionAmount amount  `xml:"cbc:LineExtensionAmount"`
+	TaxExclusiveAmount  amount  `xml:"cbc:TaxExclusiveAmount"`
+	TaxInclusiveAmount  amount  `xml:"cbc:TaxInclusiveAmount"`
+	ChargeTotalAmount   *amount `xml:"cbc:ChargeTotalAmount"`
+	PayableAmount       amount  `xml:"cbc:PayableAmount"`
+}
+
+// line is an InvoiceLine, CreditNoteLine or DebitNoteLine, only the quantity of the
+// document type is set
+type line struct {
+	ID                  int               `xml:"cbc:ID"`
+	InvoicedQuantity    *quantity         `xml:"cbc:InvoicedQuantity"`
+	CreditedQuantity    *quantity         `xml:"cbc:CreditedQuantity"`
+	DebitedQuantity     *quantity         `xml:"cbc:DebitedQuantity"`
+	LineExtensionAmount amount            `xml:"cbc:LineExtensionAmount"`
+	AllowanceCharges    []allowanceCharge `xml:"cac:AllowanceCharge"`
+	TaxTotals           []taxTotal        `xml:"cac:TaxTotal"`
+	Item                item              `xml:"cac:Item"`
+	Price               price             `xml:"cac:Price"`
+}
+
+type item struct {
+	Description               string          `xml:"cbc:Description"`
+	BrandName                 string          `xml:"cbc:BrandName,omitempty"`
+	ModelName                 string          `xml:"cbc:ModelName,omitempty"`
+	SellersItemIdentification *itemIdentifier `xml:"cac:SellersItemIdentification"`
+}
+
+type itemIdentifier struct {
+	ID string `xml:"cbc:ID"`
+}
+
+type price struct {
+	PriceAmount  amount   `xml:"cbc:PriceAmount"`
+	BaseQuantity quantity `xml:"cbc:BaseQuantity"`
+}
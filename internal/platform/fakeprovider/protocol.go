@@ -0,0 +1,98 @@
+package fakeprovider
+
+// The payloads of the facturacion.v30 API, only the fields the fake reads or answers
+
+type documentRequest struct {
+	Prefix    string          `json:"prefix"`
+	IntID     string          `json:"intID"`
+	IssueDate string          `json:"issueDate"`
+	IssueTime string          `json:"issueTime"`
+	Customer  requestCustomer `json:"customer"`
+	Amounts   requestAmounts  `json:"amounts"`
+	Items     []requestItem   `json:"items"`
+}
+
+type requestItem struct {
+	Taxes []requestTax `json:"taxes"`
+}
+
+type requestTax struct {
+	ID        string `json:"ID"`
+	TaxAmount string `json:"taxAmount"`
+}
+
+type requestCustomer struct {
+	DocumentNumber string `json:"documentNumber"`
+}
+
+type requestAmounts struct {
+	TotalAmount    string `json:"totalAmount"`
+	DiscountAmount string `json:"discountAmount"`
+	TaxAmount      string `json:"taxAmount"`
+	PayAmount      string `json:"payAmount"`
+}
+
+type verifyStatusRequest struct {
+	Tascode string `json:"tascode"`
+}
+
+type resultStatus struct {
+	Code int    `json:"code"`
+	Text string `json:"text"`
+}
+
+type documentResponse struct {
+	InvoiceResult documentResult `json:"invoiceResult"`
+}
+
+type documentResult struct {
+	Status   resultStatus   `json:"status"`
+	Document resultDocument `json:"document"`
+	Prefix   resultPrefix   `json:"prefix"`
+}
+
+// resultDocument is the document of a creation response, which sends its numbers as strings
+type resultDocument struct {
+	Type     string `json:"type"`
+	Mode     string `json:"mode"`
+	Tascode  string `json:"tascode"`
+	IntID    string `json:"intID"`
+	Document string `json:"document"`
+	Process  string `json:"process"`
+	Retries  string `json:"retries"`
+	Customer string `json:"customer"`
+	CUFE     string `json:"CUFE"`
+}
+
+type resultPrefix struct {
+	Prefix    string `json:"prefix"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Last      string `json:"last"`
+	Remaining string `json:"remaining"`
+}
+
+type verifyStatusResponse struct {
+	InvoiceResult verifyStatusResult `json:"invoiceResult"`
+}
+
+type verifyStatusResult struct {
+	Status   resultStatus         `json:"status"`
+	Document verifyStatusDocument `json:"document"`
+}
+
+type verifyStatusDocument struct {
+	Type         string   `json:"type"`
+	Mode         string   `json:"mode"`
+	Tascode      string   `json:"tascode"`
+	IntID        string   `json:"intID"`
+	Document     string   `json:"document"`
+	Process      int      `json:"process"`
+	Retries      int      `json:"retries"`
+	Customer     string   `json:"customer"`
+	EnhancedInfo []string `json:"enhancedInfo"`
+	CUFE         string   `json:"CUFE"`
+	URL          string   `json:"URL"`
+	PDF          string   `json:"PDF"`
+	ATTACHED     string   `json:"ATTACHED"`
+}
@@ -0,0 +1,475 @@
+// Package fakeprovider is a stand-in for the facturacion.v30 provider. It answers the
+// invoice, note and verifyStatus requests like the real API, so the backend can run and be
+// tested without credentials. It can be started with cmd/fakeprovider or embedded in tests
+// through httptest.NewServer(fakeprovider.New(options))
+package fakeprovider
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"laguna-escondida/backend/internal/domain/aggregate/bill"
+	"laguna-escondida/backend/internal/domain/dto"
+	"laguna-escondida/backend/internal/platform/einvoice/dian"
+
+	"github.com/google/uuid"
+)
+
+const (
+	invoicePath = "/facturacion.v30/invoice/"
+	// mode is how the provider tells documents sent to the DIAN test environment
+	mode = "test"
+)
+
+// defaultRejection is the message of the documents the DIAN rejects at random
+var defaultRejection = []string{"Regla: FAD06, Rechazo: simulated rejection of the fake provider"}
+
+// Options tune how the fake behaves, the zero value accepts every document right away
+type Options struct {
+	// User and Password are the basic credentials it requires, any are taken when empty
+	User     string
+	Password string
+	// Latency delays every response
+	Latency time.Duration
+	// ProcessingTime is how long a document stays pending before the DIAN answers, until then
+	// it has no CUFE
+	ProcessingTime time.Duration
+	// ErrorRate is the fraction of requests answered with a 503, RejectionRate the fraction
+	// of documents the DIAN rejects
+	ErrorRate     float64
+	RejectionRate float64
+	// ResolutionSize is how many numbers each prefix has, the remaining ones are reported
+	// after every invoice. Nothing is reported when zero
+	ResolutionSize int
+	// Issuer and TechnicalKey compute the CUFE of the invoices as the DIAN does, they must be
+	// the supplier NIT and the technical key of the resolution registered in the backend or
+	// every bill is flagged as a CUFE mismatch. Notes get a CUDE the backend does not check
+	Issuer       bill.Issuer
+	TechnicalKey string
+	// CUFEMismatch answers every invoice with a CUFE other than the DIAN one, to try how the
+	// backend flags the bills whose CUFE does not match
+	CUFEMismatch bool
+}
+
+// Document is a document the fake received
+type Document struct {
+	// Type is the root key it was sent with: invoice, creditNote or debitNote
+	Type       string
+	Prefix     string
+	IntID      string
+	Tascode    string
+	CUFE       string
+	Customer   string
+	Payload    json.RawMessage
+	ReceivedAt time.Time
+	// Messages are the DIAN rejection messages, a rejected document never gets its CUFE
+	Messages []string
+}
+
+func (d *Document) processedAt(processingTime time.Duration) time.Time {
+	return d.ReceivedAt.Add(processingTime)
+}
+
+func (d *Document) rejected() bool {
+	return len(d.Messages) > 0
+}
+
+type Server struct {
+	options Options
+
+	mu        sync.Mutex
+	random    *rand.Rand
+	documents map[string]*Document
+	// numbers maps type, prefix and number to the tascode, a document sent again gets the same answer
+	numbers    map[string]string
+	failures   []int
+	rejections [][]string
+}
+
+func New(options Options) *Server {
+	return &Server{
+		options:   options,
+		random:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		documents: map[string]*Document{},
+		numbers:   map[string]string{},
+	}
+}
+
+// FailNext answers the next times requests with the HTTP status code, such as a 503
+func (s *Server) FailNext(statusCode int, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := 0; i < times; i++ {
+		s.failures = append(s.failures, statusCode)
+	}
+}
+
+// RejectNext makes the DIAN reject the next document received with the messages
+func (s *Server) RejectNext(messages ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(messages) == 0 {
+		messages = defaultRejection
+	}
+	s.rejections = append(s.rejections, messages)
+}
+
+// Documents returns the documents received, in no particular order
+func (s *Server) Documents() []Document {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	documents := make([]Document, 0, len(s.documents))
+	for _, document := range s.documents {
+		documents = append(documents, *document)
+	}
+
+	return documents
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != invoicePath {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.authorized(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// The body is read first, the server only notices a client that gave up once it was read
+	var payload map[string]json.RawMessage
+	decodeErr := json.NewDecoder(r.Body).Decode(&payload)
+
+	if s.options.Latency > 0 {
+		select {
+		case <-time.After(s.options.Latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if statusCode := s.nextFailure(); statusCode != 0 {
+		http.Error(w, http.StatusText(statusCode), statusCode)
+		return
+	}
+
+	if decodeErr != nil || len(payload) != 1 {
+		writeJSON(w, documentResponse{InvoiceResult: documentResult{
+			Status: resultStatus{Code: http.StatusBadRequest, Text: "El cuerpo debe tener una sola operación"},
+		}})
+		return
+	}
+
+	for operation, body := range payload {
+		switch operation {
+		case "invoice", "creditNote", "debitNote":
+			writeJSON(w, s.receive(operation, body))
+		case "verifyStatus":
+			writeJSON(w, s.verifyStatus(body))
+		default:
+			writeJSON(w, documentResponse{InvoiceResult: documentResult{
+				Status: resultStatus{Code: http.StatusBadRequest, Text: "Operación desconocida: " + operation},
+			}})
+		}
+	}
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	if s.options.User == "" && s.options.Password == "" {
+		return true
+	}
+
+	user, password, ok := r.BasicAuth()
+	return ok && user == s.options.User && password == s.options.Password
+}
+
+// nextFailure returns the status code to fail the request with, or zero to answer it
+func (s *Server) nextFailure() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.failures) > 0 {
+		statusCode := s.failures[0]
+		s.failures = s.failures[1:]
+		return statusCode
+	}
+
+	if s.options.ErrorRate > 0 && s.random.Float64() < s.options.ErrorRate {
+		return http.StatusServiceUnavailable
+	}
+
+	return 0
+}
+
+// receive takes an invoice or a note. A document already received with the same number is
+// answered with a 409 and the tascode of the first one, as the real API does, so retries do
+// not issue it twice
+func (s *Server) receive(operation string, body json.RawMessage) documentResponse {
+	var request documentRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		return documentResponse{InvoiceResult: documentResult{
+			Status: resultStatus{Code: http.StatusBadRequest, Text: "Documento inválido: " + err.Error()},
+		}}
+	}
+	if text := validate(request); text != "" {
+		return documentResponse{InvoiceResult: documentResult{
+			Status: resultStatus{Code: http.StatusBadRequest, Text: text},
+		}}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := operation + ":" + request.Prefix + request.IntID
+	document, found := s.documents[s.numbers[key]]
+	if found {
+		return documentResponse{InvoiceResult: documentResult{
+			Status: resultStatus{Code: http.StatusConflict, Text: "El documento ya fue recibido"},
+			Document: resultDocument{
+				Type:     operation,
+				Mode:     mode,
+				Tascode:  document.Tascode,
+				IntID:    document.IntID,
+				Document: document.Prefix + document.IntID,
+				Customer: document.Customer,
+			},
+		}}
+	}
+
+	document = &Document{
+		Type:       operation,
+		Prefix:     request.Prefix,
+		IntID:      request.IntID,
+		Tascode:    uuid.NewString(),
+		CUFE:       s.cufe(operation, request),
+		Customer:   request.Customer.DocumentNumber,
+		Payload:    body,
+		ReceivedAt: time.Now(),
+		Messages:   s.nextRejection(),
+	}
+	s.documents[document.Tascode] = document
+	s.numbers[key] = document.Tascode
+	log.Printf("Fake provider received %s %s%s as %s", operation, request.Prefix, request.IntID, document.Tascode)
+
+	response := documentResponse{InvoiceResult: documentResult{
+		Status: resultStatus{Code: http.StatusOK, Text: "Documento recibido"},
+		Document: resultDocument{
+			Type:     operation,
+			Mode:     mode,
+			Tascode:  document.Tascode,
+			IntID:    document.IntID,
+			Document: document.Prefix + document.IntID,
+			Process:  "0",
+			Retries:  "0",
+			Customer: document.Customer,
+			CUFE:     s.acceptedCUFE(document),
+		},
+	}}
+
+	if operation == "invoice" {
+		response.InvoiceResult.Prefix = s.prefixStatus(request)
+	}
+
+	return response
+}
+
+func validate(request documentRequest) string {
+	switch {
+	case request.Prefix == "":
+		return "El prefijo es obligatorio"
+	case request.IntID == "":
+		return "El consecutivo es obligatorio"
+	case request.Customer.DocumentNumber == "":
+		return "El documento del adquiriente es obligatorio"
+	case len(request.Items) == 0:
+		return "El documento no tiene items"
+	}
+
+	return ""
+}
+
+// nextRejection returns the messages the DIAN will reject the document with, nil when it
+// is accepted
+func (s *Server) nextRejection() []string {
+	if len(s.rejections) > 0 {
+		messages := s.rejections[0]
+		s.rejections = s.rejections[1:]
+		return messages
+	}
+
+	if s.options.RejectionRate > 0 && s.random.Float64() < s.options.RejectionRate {
+		return defaultRejection
+	}
+
+	return nil
+}
+
+// acceptedCUFE returns the CUFE once the DIAN accepted the document, empty while it is
+// being processed or when it was rejected
+func (s *Server) acceptedCUFE(document *Document) string {
+	if document.rejected() || time.Now().Before(document.processedAt(s.options.ProcessingTime)) {
+		return ""
+	}
+
+	return document.CUFE
+}
+
+func (s *Server) prefixStatus(request documentRequest) resultPrefix {
+	status := resultPrefix{Prefix: request.Prefix, Last: request.IntID}
+	if s.options.ResolutionSize <= 0 {
+		return status
+	}
+
+	status.From = "1"
+	status.To = strconv.Itoa(s.options.ResolutionSize)
+	if last, err := strconv.Atoi(request.IntID); err == nil {
+		status.Remaining = strconv.Itoa(max(s.options.ResolutionSize-last, 0))
+	}
+
+	return status
+}
+
+func (s *Server) verifyStatus(body json.RawMessage) verifyStatusResponse {
+	var request verifyStatusRequest
+	if err := json.Unmarshal(body, &request); err != nil || request.Tascode == "" {
+		return verifyStatusResponse{InvoiceResult: verifyStatusResult{
+			Status: resultStatus{Code: http.StatusBadRequest, Text: "El tascode es obligatorio"},
+		}}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	document, found := s.documents[request.Tascode]
+	if !found {
+		return verifyStatusResponse{InvoiceResult: verifyStatusResult{
+			Status: resultStatus{Code: http.StatusNotFound, Text: "Documento no encontrado: " + request.Tascode},
+		}}
+	}
+
+	processed := !time.Now().Before(document.processedAt(s.options.ProcessingTime))
+	status := verifyStatusDocument{
+		Type:         document.Type,
+		Mode:         mode,
+		Tascode:      document.Tascode,
+		IntID:        document.IntID,
+		Document:     document.Prefix + document.IntID,
+		Customer:     document.Customer,
+		EnhancedInfo: []string{},
+		CUFE:         s.acceptedCUFE(document),
+	}
+	if processed {
+		status.Process = 1
+		if document.rejected() {
+			status.EnhancedInfo = document.Messages
+		}
+	}
+
+	return verifyStatusResponse{InvoiceResult: verifyStatusResult{
+		Status:   resultStatus{Code: http.StatusOK, Text: "Consulta exitosa"},
+		Document: status,
+	}}
+}
+
+// cufe computes the CUFE of an invoice from what the backend sent, as the DIAN does. Notes,
+// documents it cannot read and every invoice when CUFEMismatch is set get a code derived
+// from their number, date, amounts and customer that looks like a real one but is not
+func (s *Server) cufe(operation string, request documentRequest) string {
+	if operation == "invoice" && !s.options.CUFEMismatch {
+		if document, err := request.document(); err == nil {
+			return bill.InvoiceCUFE(document, s.options.Issuer, s.options.TechnicalKey)
+		}
+	}
+
+	sum := sha512.Sum384([]byte(request.Prefix + request.IntID + request.IssueDate + request.IssueTime +
+		request.Amounts.TotalAmount + request.Amounts.TaxAmount + request.Amounts.PayAmount + request.Customer.DocumentNumber))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// document rebuilds the part of the invoice the CUFE is computed with
+func (r documentRequest) document() (*dto.ElectronicDocument, error) {
+	consecutive, err := strconv.Atoi(r.IntID)
+	if err != nil {
+		return nil, err
+	}
+
+	issuedAt, err := time.ParseInLocation("20060102150405", r.IssueDate+r.IssueTime, dian.ColombiaTime)
+	if err != nil {
+		return nil, err
+	}
+
+	var totals dto.ElectronicDocumentTotals
+	if totals.LineAmount, err = parseAmount(r.Amounts.TotalAmount); err != nil {
+		return nil, err
+	}
+	if totals.DiscountAmount, err = parseAmount(r.Amounts.DiscountAmount); err != nil {
+		return nil, err
+	}
+	if totals.PayAmount, err = parseAmount(r.Amounts.PayAmount); err != nil {
+		return nil, err
+	}
+
+	lines := make([]dto.BillProduct, len(r.Items))
+	for i, item := range r.Items {
+		for _, tax := range item.Taxes {
+			amount, err := parseAmount(tax.TaxAmount)
+			if err != nil {
+				return nil, err
+			}
+			lines[i].Taxes = append(lines[i].Taxes, dto.InvoiceTax{TaxCode: taxCode(tax.ID), TaxAmount: amount})
+		}
+	}
+
+	return &dto.ElectronicDocument{
+		Type:        dto.ElectronicDocumentTypeInvoice,
+		Prefix:      r.Prefix,
+		Consecutive: consecutive,
+		IssuedAt:    issuedAt,
+		Customer:    dto.Customer{DocumentNumber: r.Customer.DocumentNumber},
+		Lines:       lines,
+		Totals:      totals,
+	}, nil
+}
+
+// parseAmount reads an amount of the request, the ones left out are zero
+func parseAmount(amount string) (dto.Money, error) {
+	if amount == "" {
+		return 0, nil
+	}
+
+	return dto.ParseMoney(amount)
+}
+
+// taxCode maps the DIAN tribute code of a tax back to the one of the backend
+func taxCode(id string) dto.TaxCode {
+	for _, code := range []dto.TaxCode{dto.TaxCodeVAT, dto.TaxCodeICO} {
+		if dian.TaxSchemeID(code) == id {
+			return code
+		}
+	}
+
+	return dto.TaxCode(id)
+}
+
+func writeJSON(w http.ResponseWriter, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
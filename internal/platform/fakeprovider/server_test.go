@@ -0,0 +1,228 @@
+package fakeprovider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"laguna-escondida/backend/internal/domain/aggregate/bill"
+	"laguna-escondida/backend/internal/domain/dto"
+	domainError "laguna-escondida/backend/internal/domain/error"
+	"laguna-escondida/backend/internal/platform/config"
+	"laguna-escondida/backend/internal/platform/httpclient"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient starts the fake and returns the facturacion.v30 adapter pointed at it
+func newTestClient(t *testing.T, options Options) (*Server, *httpclient.ElectronicInvoiceClient) {
+	options.User = "user"
+	options.Password = "password"
+	fake := New(options)
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	return fake, httpclient.NewElectronicInvoiceClient(&config.Config{
+		ElectronicInvoiceURL:      server.URL,
+		ElectronicInvoiceUser:     "user",
+		ElectronicInvoicePassword: "password",
+	})
+}
+
+func createTestDocument(consecutive int) *dto.ElectronicDocument {
+	description := "Limonada"
+	return &dto.ElectronicDocument{
+		Type:        dto.ElectronicDocumentTypeInvoice,
+		Prefix:      "SETP",
+		Consecutive: consecutive,
+		IssuedAt:    time.Now(),
+		Customer: dto.Customer{
+			DocumentNumber: "222222222222",
+			DocumentType:   dto.DocumentTypeNIT,
+			Name:           "consumidor final",
+			Email:          "noenviar@noenviar.com",
+		},
+		PaymentCode: dto.ElectronicInvoicePaymentCodeCash,
+		Lines: []dto.BillProduct{{
+			ProductID:   "product-1",
+			Quantity:    2,
+			UnitPrice:   dto.NewMoneyFromFloat(10000),
+			Description: &description,
+			Code:        "LIM",
+		}},
+		Totals: dto.ElectronicDocumentTotals{
+			LineAmount: dto.NewMoneyFromFloat(20000),
+			PayAmount:  dto.NewMoneyFromFloat(20000),
+		},
+	}
+}
+
+func TestSend_AcceptsInvoice(t *testing.T) {
+	ctx := context.Background()
+	fake, client := newTestClient(t, Options{ResolutionSize: 100})
+
+	response, err := client.Send(ctx, createTestDocument(7))
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, response.Tascode)
+	assert.Len(t, response.CUFE, 96)
+	require.NotNil(t, response.PrefixRemaining)
+	assert.Equal(t, 93, *response.PrefixRemaining)
+
+	status, err := client.Get(ctx, response.Tascode)
+
+	require.NoError(t, err)
+	assert.Equal(t, dto.ElectronicInvoiceDocumentStatusAccepted, status.Status)
+	assert.Equal(t, response.CUFE, status.CUFE)
+
+	documents := fake.Documents()
+	require.Len(t, documents, 1)
+	assert.Equal(t, "invoice", documents[0].Type)
+	assert.Equal(t, "SETP", documents[0].Prefix)
+	assert.Equal(t, "7", documents[0].IntID)
+}
+
+func TestSend_SameNumberGetsSameTascode(t *testing.T) {
+	ctx := context.Background()
+	fake, client := newTestClient(t, Options{})
+
+	first, err := client.Send(ctx, createTestDocument(1))
+	require.NoError(t, err)
+	// The fake answers the resend with a 409, the adapter fetches the first document
+	second, err := client.Send(ctx, createTestDocument(1))
+	require.NoError(t, err)
+
+	assert.Equal(t, first.Tascode, second.Tascode)
+	assert.Equal(t, first.CUFE, second.CUFE)
+	assert.Len(t, fake.Documents(), 1)
+}
+
+func TestSend_ComputesTheDIANCUFE(t *testing.T) {
+	ctx := context.Background()
+	issuer := bill.Issuer{NIT: "900123456", Environment: dto.DIANEnvironmentTest}
+	_, client := newTestClient(t, Options{Issuer: issuer, TechnicalKey: "technical-key"})
+	document := createTestDocument(3)
+	document.Lines[0].Taxes = []dto.InvoiceTax{{TaxCode: dto.TaxCodeICO, TaxAmount: dto.NewMoneyFromFloat(1600), Percent: "8.00"}}
+	document.Totals.DiscountAmount = dto.NewMoneyFromFloat(1000)
+	document.Totals.TaxAmount = dto.NewMoneyFromFloat(1600)
+	document.Totals.PayAmount = dto.NewMoneyFromFloat(20600)
+
+	response, err := client.Send(ctx, document)
+
+	require.NoError(t, err)
+	assert.Equal(t, bill.InvoiceCUFE(document, issuer, "technical-key"), response.CUFE)
+}
+
+func TestSend_CUFEMismatch(t *testing.T) {
+	ctx := context.Background()
+	issuer := bill.Issuer{NIT: "900123456", Environment: dto.DIANEnvironmentTest}
+	_, client := newTestClient(t, Options{Issuer: issuer, TechnicalKey: "technical-key", CUFEMismatch: true})
+	document := createTestDocument(3)
+
+	response, err := client.Send(ctx, document)
+
+	require.NoError(t, err)
+	assert.Len(t, response.CUFE, 96)
+	assert.NotEqual(t, bill.InvoiceCUFE(document, issuer, "technical-key"), response.CUFE)
+}
+
+func TestSend_PendingWhileProcessing(t *testing.T) {
+	ctx := context.Background()
+	_, client := newTestClient(t, Options{ProcessingTime: time.Hour})
+
+	response, err := client.Send(ctx, createTestDocument(1))
+
+	require.NoError(t, err)
+	assert.Empty(t, response.CUFE)
+
+	status, err := client.Get(ctx, response.Tascode)
+
+	require.NoError(t, err)
+	assert.Equal(t, dto.ElectronicInvoiceDocumentStatusPending, status.Status)
+}
+
+func TestSend_Rejected(t *testing.T) {
+	ctx := context.Background()
+	fake, client := newTestClient(t, Options{})
+	fake.RejectNext("Regla: FAK24, Rechazo: NIT del adquiriente inválido")
+
+	response, err := client.Send(ctx, createTestDocument(1))
+
+	require.NoError(t, err)
+	assert.Empty(t, response.CUFE)
+
+	status, err := client.Get(ctx, response.Tascode)
+
+	require.NoError(t, err)
+	assert.Equal(t, dto.ElectronicInvoiceDocumentStatusRejected, status.Status)
+	assert.Equal(t, []string{"Regla: FAK24, Rechazo: NIT del adquiriente inválido"}, status.Messages)
+
+	// Only the next document is rejected
+	next, err := client.Send(ctx, createTestDocument(2))
+	require.NoError(t, err)
+	assert.NotEmpty(t, next.CUFE)
+}
+
+func TestSend_InvalidDocument(t *testing.T) {
+	ctx := context.Background()
+	_, client := newTestClient(t, Options{})
+	document := createTestDocument(1)
+	document.Lines = nil
+
+	_, err := client.Send(ctx, document)
+
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, domainError.ErrProviderUnavailable)
+}
+
+func TestSend_ServerErrorIsProviderUnavailable(t *testing.T) {
+	ctx := context.Background()
+	fake, client := newTestClient(t, Options{})
+	fake.FailNext(http.StatusServiceUnavailable, 1)
+
+	_, err := client.Send(ctx, createTestDocument(1))
+
+	assert.ErrorIs(t, err, domainError.ErrProviderUnavailable)
+	assert.Empty(t, fake.Documents())
+
+	_, err = client.Send(ctx, createTestDocument(1))
+
+	assert.NoError(t, err)
+}
+
+func TestSend_LatencyBeyondDeadlineIsProviderUnavailable(t *testing.T) {
+	fake, client := newTestClient(t, Options{Latency: time.Second})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.Send(ctx, createTestDocument(1))
+
+	assert.ErrorIs(t, err, domainError.ErrProviderUnavailable)
+	assert.Empty(t, fake.Documents())
+}
+
+func TestSend_WrongCredentials(t *testing.T) {
+	server := httptest.NewServer(New(Options{User: "user", Password: "password"}))
+	defer server.Close()
+	client := httpclient.NewElectronicInvoiceClient(&config.Config{
+		ElectronicInvoiceURL:      server.URL,
+		ElectronicInvoiceUser:     "user",
+		ElectronicInvoicePassword: "wrong",
+	})
+
+	_, err := client.Send(context.Background(), createTestDocument(1))
+
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, domainError.ErrProviderUnavailable)
+}
+
+func TestGet_UnknownTascode(t *testing.T) {
+	_, client := newTestClient(t, Options{})
+
+	_, err := client.Get(context.Background(), "unknown")
+
+	assert.Error(t, err)
+}
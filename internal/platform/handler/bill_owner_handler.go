@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"laguna-escondida/backend/internal/domain/dto"
+	domainError "laguna-escondida/backend/internal/domain/error"
+	"laguna-escondida/backend/internal/domain/service"
+
+	"github.com/gorilla/mux"
+)
+
+type BillOwnerHandler struct {
+	billOwnerService *service.BillOwnerService
+}
+
+func NewBillOwnerHandler(billOwnerService *service.BillOwnerService) *BillOwnerHandler {
+	return &BillOwnerHandler{
+		billOwnerService: billOwnerService,
+	}
+}
+
+func (h *BillOwnerHandler) CreateBillOwnerHandler(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateBillOwnerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error decoding request: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	owner, err := h.billOwnerService.CreateBillOwner(r.Context(), &req)
+	if err != nil {
+		log.Printf("Error creating bill owner: %v", err)
+
+		if errors.Is(err, domainError.ErrInvalidBillOwner) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, domainError.ErrBillOwnerAlreadyExists) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, "Failed to create bill owner", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(owner); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *BillOwnerHandler) UpdateBillOwnerHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	ownerID := vars["id"]
+	if ownerID == "" {
+		http.Error(w, "Bill owner ID is required", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.UpdateBillOwnerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error decoding request: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	owner, err := h.billOwnerService.UpdateBillOwner(r.Context(), ownerID, &req)
+	if err != nil {
+		log.Printf("Error updating bill owner: %v", err)
+
+		if errors.Is(err, domainError.ErrBillOwnerNotFound) {
+			http.Error(w, "Bill owner not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domainError.ErrInvalidBillOwner) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Failed to update bill owner", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(owner); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *BillOwnerHandler) DeleteBillOwnerHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	ownerID := vars["id"]
+	if ownerID == "" {
+		http.Error(w, "Bill owner ID is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.billOwnerService.DeleteBillOwner(r.Context(), ownerID); err != nil {
+		log.Printf("Error deleting bill owner: %v", err)
+
+		if errors.Is(err, domainError.ErrBillOwnerNotFound) {
+			http.Error(w, "Bill owner not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to delete bill owner", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *BillOwnerHandler) GetBillOwnerHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	ownerID := vars["id"]
+	if ownerID == "" {
+		http.Error(w, "Bill owner ID is required", http.StatusBadRequest)
+		return
+	}
+
+	owner, err := h.billOwnerService.GetBillOwner(r.Context(), ownerID)
+	if err != nil {
+		log.Printf("Error getting bill owner: %v", err)
+
+		if errors.Is(err, domainError.ErrBillOwnerNotFound) {
+			http.Error(w, "Bill owner not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to get bill owner", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(owner); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *BillOwnerHandler) ListBillOwnersHandler(w http.ResponseWriter, r *http.Request) {
+	req, err := parseListBillOwnersQuery(r.URL.Query())
+	if err != nil {
+		log.Printf("Error parsing list bill owners query: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.billOwnerService.ListBillOwners(r.Context(), req)
+	if err != nil {
+		log.Printf("Error listing bill owners: %v", err)
+
+		if errors.Is(err, domainError.ErrInvalidBillOwnerFilter) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Failed to list bill owners", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func parseListBillOwnersQuery(query url.Values) (*dto.ListBillOwnersRequest, error) {
+	req := &dto.ListBillOwnersRequest{
+		DocumentNumber: strings.TrimSpace(query.Get("document_number")),
+	}
+
+	if value := query.Get("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid page: %w", err)
+		}
+		req.Page = page
+	}
+
+	if value := query.Get("page_size"); value != "" {
+		pageSize, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid page_size: %w", err)
+		}
+		req.PageSize = pageSize
+	}
+
+	return req, nil
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"laguna-escondida/backend/internal/domain/dto"
+	domainError "laguna-escondida/backend/internal/domain/error"
+	"laguna-escondida/backend/internal/domain/service"
+)
+
+type ContingencyHandler struct {
+	contingencyService *service.ContingencyService
+}
+
+func NewContingencyHandler(contingencyService *service.ContingencyService) *ContingencyHandler {
+	return &ContingencyHandler{
+		contingencyService: contingencyService,
+	}
+}
+
+func (h *ContingencyHandler) GetStatusHandler(w http.ResponseWriter, r *http.Request) {
+	status, err := h.contingencyService.Status(r.Context())
+	if err != nil {
+		log.Printf("Error getting contingency status: %v", err)
+		http.Error(w, "Failed to get contingency status", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *ContingencyHandler) ActivateHandler(w http.ResponseWriter, r *http.Request) {
+	var req dto.ActivateContingencyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error decoding request: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	period, err := h.contingencyService.Activate(r.Context(), &req)
+	if err != nil {
+		log.Printf("Error activating contingency: %v", err)
+
+		switch {
+		case errors.Is(err, domainError.ErrInvalidContingency):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, domainError.ErrContingencyAlreadyActive):
+			http.Error(w, "Contingency mode is already active", http.StatusConflict)
+		default:
+			http.Error(w, "Failed to activate contingency", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(period); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *ContingencyHandler) DeactivateHandler(w http.ResponseWriter, r *http.Request) {
+	if err := h.contingencyService.Deactivate(r.Context()); err != nil {
+		log.Printf("Error deactivating contingency: %v", err)
+
+		if errors.Is(err, domainError.ErrContingencyNotActive) {
+			http.Error(w, "Contingency mode is not active", http.StatusConflict)
+			return
+		}
+		http.Error(w, "Failed to deactivate contingency", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ContingencyHandler) ListPeriodsHandler(w http.ResponseWriter, r *http.Request) {
+	periods, err := h.contingencyService.ListPeriods(r.Context())
+	if err != nil {
+		log.Printf("Error listing contingency periods: %v", err)
+		http.Error(w, "Failed to list contingency periods", http.StatusInternalServerError)
+		return
+	}
+
+	response := dto.ContingencyPeriodListResponse{
+		Periods: periods,
+		Total:   len(periods),
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
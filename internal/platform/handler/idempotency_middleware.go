@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	domainError "laguna-escondida/backend/internal/domain/error"
+	"laguna-escondida/backend/internal/domain/service"
+)
+
+// IdempotencyKeyHeader is the header clients send to make a request safe to retry
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotencyMiddleware replays the stored response when a request comes again with the same
+// Idempotency-Key, instead of running it twice. Requests without the header run as usual.
+// The same key with a different method, path or body is rejected, and a retry that arrives
+// while the first request is still running gets a conflict. Every response the handler gave
+// is stored, server errors included, since the bill may have been saved before the failure
+func IdempotencyMiddleware(idempotencyService *service.IdempotencyService, scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				log.Printf("Error reading request body: %v", err)
+				http.Error(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			record, err := idempotencyService.Begin(r.Context(), scope, key, requestHash(r, body))
+			if err != nil {
+				log.Printf("Error reserving idempotency key: %v", err)
+
+				switch {
+				case errors.Is(err, domainError.ErrInvalidIdempotencyKey):
+					http.Error(w, err.Error(), http.StatusBadRequest)
+				case errors.Is(err, domainError.ErrIdempotencyKeyReused):
+					http.Error(w, "Idempotency key was used for a different request", http.StatusUnprocessableEntity)
+				case errors.Is(err, domainError.ErrIdempotencyKeyInProgress):
+					http.Error(w, "A request with this idempotency key is in progress", http.StatusConflict)
+				default:
+					http.Error(w, "Failed to process idempotency key", http.StatusInternalServerError)
+				}
+				return
+			}
+
+			if record != nil {
+				if record.ContentType != "" {
+					w.Header().Set("Content-Type", record.ContentType)
+				}
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(record.ResponseStatus)
+				if _, err := w.Write(record.ResponseBody); err != nil {
+					log.Printf("Error writing replayed response: %v", err)
+				}
+				return
+			}
+
+			recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+
+			// The response is stored even if the client already hung up, its retry will need it
+			ctx := context.WithoutCancel(r.Context())
+			if err := idempotencyService.Complete(ctx, scope, key, recorder.status, w.Header().Get("Content-Type"), recorder.body.Bytes()); err != nil {
+				log.Printf("Error storing idempotent response: %v", err)
+			}
+		})
+	}
+}
+
+// requestHash identifies the request a key was first used for
+func requestHash(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder writes the response through while keeping a copy to store
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
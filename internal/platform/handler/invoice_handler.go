@@ -2,10 +2,19 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
 
 	"laguna-escondida/backend/internal/domain/dto"
+	domainError "laguna-escondida/backend/internal/domain/error"
 	"laguna-escondida/backend/internal/domain/service"
 )
 
@@ -27,11 +36,268 @@ func (h *InvoiceHandler) CreateElectronicInvoiceHandler(w http.ResponseWriter, r
 		return
 	}
 
-	if err := h.invoiceService.CreateElectronicInvoice(r.Context(), &invoice); err != nil {
+	bill, err := h.invoiceService.CreateElectronicInvoice(r.Context(), &invoice)
+	if err != nil {
 		log.Printf("Error creating electronic invoice: %v", err)
+
+		if errors.Is(err, domainError.ErrInvalidInvoice) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, domainError.ErrProductNotFound) {
+			http.Error(w, "One or more products not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domainError.ErrNoActiveResolution) {
+			http.Error(w, "No numbering resolution is valid today with numbers left", http.StatusConflict)
+			return
+		}
 		http.Error(w, "Failed to create electronic invoice", http.StatusInternalServerError)
 		return
 	}
 
 	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(bill); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *InvoiceHandler) CreateCreditNoteHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract bill_id from URL path
+	vars := mux.Vars(r)
+	billID := vars["id"]
+	if billID == "" {
+		http.Error(w, "Bill ID is required", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.CreateCreditNoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error decoding request: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	creditNote, err := h.invoiceService.CreateCreditNote(r.Context(), billID, &req)
+	if err != nil {
+		log.Printf("Error creating credit note: %v", err)
+
+		if errors.Is(err, domainError.ErrBillNotFound) {
+			http.Error(w, "Bill not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domainError.ErrBillNotIssued) {
+			http.Error(w, "Bill has not been issued", http.StatusConflict)
+			return
+		}
+		if errors.Is(err, domainError.ErrInvalidCreditNote) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, domainError.ErrProductNotFound) {
+			http.Error(w, "One or more products not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to create credit note", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(creditNote); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *InvoiceHandler) CreateDebitNoteHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract bill_id from URL path
+	vars := mux.Vars(r)
+	billID := vars["id"]
+	if billID == "" {
+		http.Error(w, "Bill ID is required", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.CreateDebitNoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error decoding request: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	debitNote, err := h.invoiceService.CreateDebitNote(r.Context(), billID, &req)
+	if err != nil {
+		log.Printf("Error creating debit note: %v", err)
+
+		if errors.Is(err, domainError.ErrBillNotFound) {
+			http.Error(w, "Bill not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domainError.ErrBillNotIssued) {
+			http.Error(w, "Bill has not been issued", http.StatusConflict)
+			return
+		}
+		if errors.Is(err, domainError.ErrInvalidDebitNote) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, domainError.ErrProductNotFound) {
+			http.Error(w, "One or more products not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to create debit note", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(debitNote); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *InvoiceHandler) GetBillHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	billID := vars["id"]
+	if billID == "" {
+		http.Error(w, "Bill ID is required", http.StatusBadRequest)
+		return
+	}
+
+	bill, err := h.invoiceService.GetBill(r.Context(), billID)
+	if err != nil {
+		log.Printf("Error getting bill: %v", err)
+
+		if errors.Is(err, domainError.ErrBillNotFound) {
+			http.Error(w, "Bill not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to get bill", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(bill); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *InvoiceHandler) ListBillsHandler(w http.ResponseWriter, r *http.Request) {
+	req, err := parseListBillsQuery(r.URL.Query())
+	if err != nil {
+		log.Printf("Error parsing list bills query: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.invoiceService.ListBills(r.Context(), req)
+	if err != nil {
+		log.Printf("Error listing bills: %v", err)
+
+		if errors.Is(err, domainError.ErrInvalidBillFilter) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Failed to list bills", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func parseListBillsQuery(query url.Values) (*dto.ListBillsRequest, error) {
+	req := &dto.ListBillsRequest{
+		CustomerID: strings.TrimSpace(query.Get("customer_id")),
+	}
+
+	for _, method := range splitQueryList(query["payment_method"]) {
+		req.PaymentMethods = append(req.PaymentMethods, dto.ElectronicInvoicePaymentCode(method))
+	}
+	for _, status := range splitQueryList(query["status"]) {
+		req.Statuses = append(req.Statuses, dto.ElectronicInvoiceDocumentStatus(status))
+	}
+
+	if value := query.Get("created_from"); value != "" {
+		createdFrom, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid created_from: %w", err)
+		}
+		req.CreatedFrom = &createdFrom
+	}
+
+	if value := query.Get("created_to"); value != "" {
+		createdTo, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid created_to: %w", err)
+		}
+		req.CreatedTo = &createdTo
+	}
+
+	if value := query.Get("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid page: %w", err)
+		}
+		req.Page = page
+	}
+
+	if value := query.Get("page_size"); value != "" {
+		pageSize, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid page_size: %w", err)
+		}
+		req.PageSize = pageSize
+	}
+
+	return req, nil
+}
+
+// splitQueryList accepts both repeated parameters and comma separated values
+func splitQueryList(values []string) []string {
+	var result []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				result = append(result, item)
+			}
+		}
+	}
+
+	return result
+}
+
+func (h *InvoiceHandler) RefreshBillStatusHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract bill_id from URL path
+	vars := mux.Vars(r)
+	billID := vars["id"]
+	if billID == "" {
+		http.Error(w, "Bill ID is required", http.StatusBadRequest)
+		return
+	}
+
+	bill, err := h.invoiceService.RefreshBillStatus(r.Context(), billID)
+	if err != nil {
+		log.Printf("Error refreshing bill status: %v", err)
+
+		if errors.Is(err, domainError.ErrBillNotFound) {
+			http.Error(w, "Bill not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domainError.ErrBillNotIssued) {
+			http.Error(w, "Bill has not been issued", http.StatusConflict)
+			return
+		}
+		if errors.Is(err, domainError.ErrStatusUnsupported) {
+			http.Error(w, "The electronic invoice provider does not report the DIAN status", http.StatusNotImplemented)
+			return
+		}
+		http.Error(w, "Failed to refresh bill status", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(bill); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
 }
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"laguna-escondida/backend/internal/domain/dto"
+	domainError "laguna-escondida/backend/internal/domain/error"
+	"laguna-escondida/backend/internal/domain/service"
+)
+
+type InvoiceOutboxHandler struct {
+	outboxService *service.InvoiceOutboxService
+}
+
+func NewInvoiceOutboxHandler(outboxService *service.InvoiceOutboxService) *InvoiceOutboxHandler {
+	return &InvoiceOutboxHandler{
+		outboxService: outboxService,
+	}
+}
+
+func (h *InvoiceOutboxHandler) ListDeadLettersHandler(w http.ResponseWriter, r *http.Request) {
+	entries, err := h.outboxService.ListDeadLetters(r.Context())
+	if err != nil {
+		log.Printf("Error listing dead letters: %v", err)
+		http.Error(w, "Failed to list dead letters", http.StatusInternalServerError)
+		return
+	}
+
+	response := dto.InvoiceOutboxListResponse{
+		Entries: entries,
+		Total:   len(entries),
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *InvoiceOutboxHandler) RetryDeadLetterHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	entryID := vars["id"]
+	if entryID == "" {
+		http.Error(w, "Entry ID is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.outboxService.RetryDeadLetter(r.Context(), entryID); err != nil {
+		log.Printf("Error retrying dead letter: %v", err)
+
+		if errors.Is(err, domainError.ErrOutboxEntryNotFound) {
+			http.Error(w, "Dead letter not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to retry dead letter", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -20,7 +20,7 @@ func CORSMiddleware(allowedMethods []string) func(http.Handler) http.Handler {
 				methodsStr += method
 			}
 			w.Header().Set("Access-Control-Allow-Methods", methodsStr)
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, "+IdempotencyKeyHeader)
 
 			// Handle OPTIONS preflight requests
 			if r.Method == "OPTIONS" {
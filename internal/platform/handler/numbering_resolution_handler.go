@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"laguna-escondida/backend/internal/domain/dto"
+	domainError "laguna-escondida/backend/internal/domain/error"
+	"laguna-escondida/backend/internal/domain/service"
+
+	"github.com/gorilla/mux"
+)
+
+type NumberingResolutionHandler struct {
+	resolutionService *service.NumberingResolutionService
+}
+
+func NewNumberingResolutionHandler(resolutionService *service.NumberingResolutionService) *NumberingResolutionHandler {
+	return &NumberingResolutionHandler{
+		resolutionService: resolutionService,
+	}
+}
+
+func (h *NumberingResolutionHandler) CreateResolutionHandler(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateNumberingResolutionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error decoding request: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resolution, err := h.resolutionService.CreateResolution(r.Context(), &req)
+	if err != nil {
+		log.Printf("Error creating numbering resolution: %v", err)
+
+		if errors.Is(err, domainError.ErrInvalidResolution) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Failed to create numbering resolution", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(resolution); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *NumberingResolutionHandler) UpdateResolutionHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	resolutionID := vars["id"]
+	if resolutionID == "" {
+		http.Error(w, "Resolution ID is required", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.UpdateNumberingResolutionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error decoding request: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resolution, err := h.resolutionService.UpdateResolution(r.Context(), resolutionID, &req)
+	if err != nil {
+		log.Printf("Error updating numbering resolution: %v", err)
+
+		if errors.Is(err, domainError.ErrResolutionNotFound) {
+			http.Error(w, "Numbering resolution not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domainError.ErrInvalidResolution) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, domainError.ErrResolutionChanged) {
+			http.Error(w, "Numbering resolution numbered invoices meanwhile, try again", http.StatusConflict)
+			return
+		}
+		http.Error(w, "Failed to update numbering resolution", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resolution); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *NumberingResolutionHandler) DeleteResolutionHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	resolutionID := vars["id"]
+	if resolutionID == "" {
+		http.Error(w, "Resolution ID is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.resolutionService.DeleteResolution(r.Context(), resolutionID); err != nil {
+		log.Printf("Error deleting numbering resolution: %v", err)
+
+		if errors.Is(err, domainError.ErrResolutionNotFound) {
+			http.Error(w, "Numbering resolution not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domainError.ErrResolutionInUse) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, "Failed to delete numbering resolution", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *NumberingResolutionHandler) GetResolutionHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	resolutionID := vars["id"]
+	if resolutionID == "" {
+		http.Error(w, "Resolution ID is required", http.StatusBadRequest)
+		return
+	}
+
+	resolution, err := h.resolutionService.GetResolution(r.Context(), resolutionID)
+	if err != nil {
+		log.Printf("Error getting numbering resolution: %v", err)
+
+		if errors.Is(err, domainError.ErrResolutionNotFound) {
+			http.Error(w, "Numbering resolution not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to get numbering resolution", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resolution); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *NumberingResolutionHandler) ListResolutionsHandler(w http.ResponseWriter, r *http.Request) {
+	resolutions, err := h.resolutionService.ListResolutions(r.Context())
+	if err != nil {
+		log.Printf("Error listing numbering resolutions: %v", err)
+		http.Error(w, "Failed to list numbering resolutions", http.StatusInternalServerError)
+		return
+	}
+
+	response := dto.NumberingResolutionListResponse{
+		Resolutions: resolutions,
+		Total:       len(resolutions),
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *NumberingResolutionHandler) GetActiveResolutionHandler(w http.ResponseWriter, r *http.Request) {
+	resolution, err := h.resolutionService.GetActiveResolution(r.Context())
+	if err != nil {
+		log.Printf("Error getting active numbering resolution: %v", err)
+
+		if errors.Is(err, domainError.ErrNoActiveResolution) {
+			http.Error(w, "No numbering resolution is valid today with numbers left", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to get active numbering resolution", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resolution); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
@@ -3,8 +3,13 @@ package handler
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"laguna-escondida/backend/internal/domain/dto"
 	orderError "laguna-escondida/backend/internal/domain/error"
@@ -87,6 +92,14 @@ func (h *OrderHandler) UpdateOrderHandler(w http.ResponseWriter, r *http.Request
 			http.Error(w, "Order not found", http.StatusNotFound)
 			return
 		}
+		if errors.Is(err, orderError.ErrInvalidOrderStatus) {
+			http.Error(w, "Order can no longer be updated", http.StatusConflict)
+			return
+		}
+		if errors.Is(err, orderError.ErrOrderIsSplitShare) {
+			http.Error(w, "Order is a share of a split order and its products cannot change", http.StatusConflict)
+			return
+		}
 		if errors.Is(err, orderError.ErrProductNotFound) {
 			http.Error(w, "One or more products not found", http.StatusNotFound)
 			return
@@ -114,7 +127,19 @@ func (h *OrderHandler) PayOrderHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	bill, err := h.orderService.PayOrder(r.Context(), openBillID)
+	var req dto.PayOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error decoding request: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.PaymentCode == "" && len(req.Payments) == 0 {
+		http.Error(w, "Payment code or payments are required", http.StatusBadRequest)
+		return
+	}
+
+	bill, err := h.orderService.PayOrder(r.Context(), openBillID, &req)
 	if err != nil {
 		log.Printf("Error paying order: %v", err)
 
@@ -122,6 +147,26 @@ func (h *OrderHandler) PayOrderHandler(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Order not found", http.StatusNotFound)
 			return
 		}
+		if errors.Is(err, orderError.ErrInvalidOrderStatus) {
+			http.Error(w, "Order is not open for payment", http.StatusConflict)
+			return
+		}
+		if errors.Is(err, orderError.ErrOrderEmpty) {
+			http.Error(w, "Order has no products", http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, orderError.ErrProductNotFound) {
+			http.Error(w, "One or more products not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, orderError.ErrInvalidInvoice) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, orderError.ErrNoActiveResolution) {
+			http.Error(w, "No numbering resolution is valid today with numbers left", http.StatusConflict)
+			return
+		}
 		if errors.Is(err, orderError.ErrOrderPaymentFailed) {
 			http.Error(w, "Failed to pay order", http.StatusInternalServerError)
 			return
@@ -135,3 +180,290 @@ func (h *OrderHandler) PayOrderHandler(w http.ResponseWriter, r *http.Request) {
 		log.Printf("Error encoding response: %v", err)
 	}
 }
+
+func (h *OrderHandler) SplitOrderHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract open_bill_id from URL path
+	vars := mux.Vars(r)
+	openBillID := vars["id"]
+	if openBillID == "" {
+		http.Error(w, "Order ID is required", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.SplitOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.orderService.SplitOrder(r.Context(), openBillID, &req)
+	if err != nil {
+		log.Printf("Error splitting order: %v", err)
+
+		if errors.Is(err, orderError.ErrOrderNotFound) {
+			http.Error(w, "Order not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, orderError.ErrInvalidOrderStatus) {
+			http.Error(w, "Order is not open to be split", http.StatusConflict)
+			return
+		}
+		if errors.Is(err, orderError.ErrOrderChanged) {
+			http.Error(w, "Order changed while it was being split, try again", http.StatusConflict)
+			return
+		}
+		if errors.Is(err, orderError.ErrOrderIsSplitShare) {
+			http.Error(w, "Order is already a share of a split order", http.StatusConflict)
+			return
+		}
+		if errors.Is(err, orderError.ErrInvalidOrderSplit) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, orderError.ErrOrderEmpty) {
+			http.Error(w, "Order has no products", http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, orderError.ErrProductNotFound) {
+			http.Error(w, "One or more products not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to split order", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *OrderHandler) TransferOrderItemsHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract the source open_bill_id from URL path
+	vars := mux.Vars(r)
+	openBillID := vars["id"]
+	if openBillID == "" {
+		http.Error(w, "Order ID is required", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.TransferOrderItemsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.TargetOrderID == "" {
+		http.Error(w, "Target order ID is required", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.orderService.TransferOrderItems(r.Context(), openBillID, &req)
+	if err != nil {
+		log.Printf("Error transferring order products: %v", err)
+		writeOrderTransferError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *OrderHandler) MergeOrdersHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract the target open_bill_id from URL path
+	vars := mux.Vars(r)
+	openBillID := vars["id"]
+	if openBillID == "" {
+		http.Error(w, "Order ID is required", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.MergeOrdersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.SourceOrderID == "" {
+		http.Error(w, "Source order ID is required", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.orderService.MergeOrders(r.Context(), openBillID, &req)
+	if err != nil {
+		log.Printf("Error merging orders: %v", err)
+		writeOrderTransferError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// writeOrderTransferError maps the errors of moving products between orders to a response
+func writeOrderTransferError(w http.ResponseWriter, err error) {
+	if errors.Is(err, orderError.ErrOrderNotFound) {
+		http.Error(w, "Order not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, orderError.ErrInvalidOrderStatus) {
+		http.Error(w, "Both orders must be open", http.StatusConflict)
+		return
+	}
+	if errors.Is(err, orderError.ErrOrderIsSplitShare) {
+		http.Error(w, "Products of a split order share cannot be moved", http.StatusConflict)
+		return
+	}
+	if errors.Is(err, orderError.ErrInvalidOrderTransfer) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, orderError.ErrProductNotFound) {
+		http.Error(w, "One or more products not found", http.StatusNotFound)
+		return
+	}
+	http.Error(w, "Failed to move order products", http.StatusInternalServerError)
+}
+
+func (h *OrderHandler) CancelOrderHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract open_bill_id from URL path
+	vars := mux.Vars(r)
+	openBillID := vars["id"]
+	if openBillID == "" {
+		http.Error(w, "Order ID is required", http.StatusBadRequest)
+		return
+	}
+
+	openBill, err := h.orderService.CancelOrder(r.Context(), openBillID)
+	if err != nil {
+		log.Printf("Error cancelling order: %v", err)
+
+		if errors.Is(err, orderError.ErrOrderNotFound) {
+			http.Error(w, "Order not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, orderError.ErrInvalidOrderStatus) {
+			http.Error(w, "Order can no longer be cancelled", http.StatusConflict)
+			return
+		}
+		if errors.Is(err, orderError.ErrOrderCancelFailed) {
+			http.Error(w, "Failed to cancel order", http.StatusInternalServerError)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(openBill); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *OrderHandler) GetOrderHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	openBillID := vars["id"]
+	if openBillID == "" {
+		http.Error(w, "Order ID is required", http.StatusBadRequest)
+		return
+	}
+
+	openBill, err := h.orderService.GetOrder(r.Context(), openBillID)
+	if err != nil {
+		log.Printf("Error getting order: %v", err)
+
+		if errors.Is(err, orderError.ErrOrderNotFound) {
+			http.Error(w, "Order not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(openBill); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// ListOrdersHandler lists orders filtered by the query string:
+// status (comma separated), created_from and created_to (RFC3339),
+// sort_by, sort_order, page and page_size
+func (h *OrderHandler) ListOrdersHandler(w http.ResponseWriter, r *http.Request) {
+	req, err := parseListOrdersQuery(r.URL.Query())
+	if err != nil {
+		log.Printf("Error parsing list orders query: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.orderService.ListOrders(r.Context(), req)
+	if err != nil {
+		log.Printf("Error listing orders: %v", err)
+
+		if errors.Is(err, orderError.ErrInvalidOrderFilter) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Failed to list orders", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func parseListOrdersQuery(query url.Values) (*dto.ListOrdersRequest, error) {
+	req := &dto.ListOrdersRequest{
+		SortBy:    dto.OpenBillSortField(query.Get("sort_by")),
+		SortOrder: dto.SortOrder(strings.ToLower(query.Get("sort_order"))),
+	}
+
+	for _, value := range query["status"] {
+		for _, status := range strings.Split(value, ",") {
+			if status = strings.TrimSpace(status); status != "" {
+				req.Statuses = append(req.Statuses, dto.OpenBillStatus(status))
+			}
+		}
+	}
+
+	if value := query.Get("created_from"); value != "" {
+		createdFrom, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid created_from: %w", err)
+		}
+		req.CreatedFrom = &createdFrom
+	}
+
+	if value := query.Get("created_to"); value != "" {
+		createdTo, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid created_to: %w", err)
+		}
+		req.CreatedTo = &createdTo
+	}
+
+	if value := query.Get("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid page: %w", err)
+		}
+		req.Page = page
+	}
+
+	if value := query.Get("page_size"); value != "" {
+		pageSize, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid page_size: %w", err)
+		}
+		req.PageSize = pageSize
+	}
+
+	return req, nil
+}
@@ -7,16 +7,28 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"laguna-escondida/backend/internal/domain/aggregate/billowner"
 	"laguna-escondida/backend/internal/domain/dto"
+	domainError "laguna-escondida/backend/internal/domain/error"
 	"laguna-escondida/backend/internal/platform/config"
+	"laguna-escondida/backend/internal/platform/einvoice/dian"
 	"laguna-escondida/backend/internal/platform/shared/utils"
 	"net/http"
 	"strconv"
-	"time"
+	"strings"
 
 	"github.com/samber/lo"
 )
 
+// duplicateDocumentCode is the status the provider answers a number it already received with,
+// the answer carries the tascode of the document registered the first time
+const duplicateDocumentCode = http.StatusConflict
+
+// documentNotProcessed is the process flag of a document the provider is still sending, or
+// retrying, to the DIAN
+const documentNotProcessed = 0
+
+// ElectronicInvoiceClient is the adapter of the facturacion.v30 JSON API
 type ElectronicInvoiceClient struct {
 	client   *http.Client
 	url      string
@@ -26,79 +38,67 @@ type ElectronicInvoiceClient struct {
 
 func NewElectronicInvoiceClient(cfg *config.Config) *ElectronicInvoiceClient {
 	return &ElectronicInvoiceClient{
-		client:   &http.Client{},
+		client:   &http.Client{Timeout: cfg.ElectronicInvoiceTimeout},
 		url:      cfg.ElectronicInvoiceURL,
 		user:     cfg.ElectronicInvoiceUser,
 		password: cfg.ElectronicInvoicePassword,
 	}
 }
 
-func mapTaxCodeToID(taxCode dto.TaxCode) string {
-	switch taxCode {
-	case dto.TaxCodeVAT:
-		return "01"
-	case dto.TaxCodeICO:
-		return "04"
-	default:
-		return string(taxCode)
-	}
-}
-
-func mapDocumentTypeToCode(documentType dto.DocumentType) string {
-	switch documentType {
-	case dto.DocumentTypeNIT:
-		return "31"
-	case dto.DocumentTypeNationalIdentificationNumber:
-		return "13"
-	default:
-		return "13"
-	}
-}
-
-func mapDocumentTypeToAdditionalAccountID(documentType dto.DocumentType) string {
-	switch documentType {
-	case dto.DocumentTypeNIT:
-		return "1"
-	case dto.DocumentTypeNationalIdentificationNumber:
-		return "2"
-	default:
-		return "2"
-	}
-}
-
 type invoiceRequest struct {
 	Invoice invoiceRequestData `json:"invoice"`
 }
 
 type invoiceRequestData struct {
-	Prefix      string          `json:"prefix"`
-	IntID       string          `json:"intID"`
-	IssueDate   string          `json:"issueDate"`
-	IssueTime   string          `json:"issueTime"`
-	PaymentType string          `json:"paymentType"`
-	PaymentCode string          `json:"paymentCode"`
-	Note1       string          `json:"note1"`
-	Customer    invoiceCustomer `json:"customer"`
-	Amounts     invoiceAmounts  `json:"amounts"`
-	Items       []invoiceItem   `json:"items"`
+	Prefix    string `json:"prefix"`
+	IntID     string `json:"intID"`
+	IssueDate string `json:"issueDate"`
+	IssueTime string `json:"issueTime"`
+	// InvoiceType is only sent for contingency invoices, the provider defaults to a sales invoice
+	InvoiceType string `json:"invoiceType,omitempty"`
+	PaymentType string `json:"paymentType"`
+	PaymentCode string `json:"paymentCode"`
+	// PaymentMeans lists every method used when the bill is paid with more than one
+	PaymentMeans []invoicePaymentMeans `json:"paymentMeans,omitempty"`
+	Note1        string                `json:"note1"`
+	Customer     invoiceCustomer       `json:"customer"`
+	Amounts      invoiceAmounts        `json:"amounts"`
+	// Charges are document level charges outside the taxable base, such as the tip
+	Charges []invoiceAllowance `json:"charges,omitempty"`
+	Items   []invoiceItem      `json:"items"`
+}
+
+type invoicePaymentMeans struct {
+	PaymentType string `json:"paymentType"`
+	PaymentCode string `json:"paymentCode"`
+	Amount      string `json:"amount"`
+	Reference   string `json:"reference,omitempty"`
 }
 
 type invoiceCustomer struct {
 	AdditionalAccountID string `json:"additionalAccountID"`
 	Name                string `json:"name"`
 	City                string `json:"city"`
-	CountrySubentity    string `json:"countrySubentity"`
-	AddressLine         string `json:"addressLine"`
-	DocumentNumber      string `json:"documentNumber"`
-	DocumentType        string `json:"documentType"`
-	Telephone           string `json:"telephone"`
-	Email               string `json:"email"`
+	// CountrySubentity is the DANE code of the municipality
+	CountrySubentity string `json:"countrySubentity"`
+	Department       string `json:"department"`
+	AddressLine      string `json:"addressLine"`
+	DocumentNumber   string `json:"documentNumber"`
+	DocumentType     string `json:"documentType"`
+	// CheckDigit is only sent for a NIT
+	CheckDigit string `json:"checkDigit,omitempty"`
+	Telephone  string `json:"telephone"`
+	Email      string `json:"email"`
+	TaxRegime  string `json:"taxRegime"`
+	// TaxLevelCode lists the fiscal responsibilities separated by semicolons
+	TaxLevelCode string `json:"taxLevelCode"`
 }
 
 type invoiceAmounts struct {
 	TotalAmount    string `json:"totalAmount"`
 	DiscountAmount string `json:"discountAmount"`
 	TaxAmount      string `json:"taxAmount"`
+	ChargeAmount   string `json:"chargeAmount"`
 	PayAmount      string `json:"payAmount"`
 }
 
@@ -128,6 +128,40 @@ type invoiceTax struct {
 	Percent   string `json:"percent"`
 }
 
+type creditNoteRequest struct {
+	CreditNote noteRequestData `json:"creditNote"`
+}
+
+type debitNoteRequest struct {
+	DebitNote noteRequestData `json:"debitNote"`
+}
+
+// noteRequestData is the payload shared by credit and debit notes
+type noteRequestData struct {
+	Prefix              string               `json:"prefix"`
+	IntID               string               `json:"intID"`
+	IssueDate           string               `json:"issueDate"`
+	IssueTime           string               `json:"issueTime"`
+	DiscrepancyResponse noteDiscrepancy      `json:"discrepancyResponse"`
+	BillingReference    noteBillingReference `json:"billingReference"`
+	Note1               string               `json:"note1"`
+	Customer            invoiceCustomer      `json:"customer"`
+	Amounts             invoiceAmounts       `json:"amounts"`
+	Items               []invoiceItem        `json:"items"`
+}
+
+type noteDiscrepancy struct {
+	ResponseCode string `json:"responseCode"`
+	Description  string `json:"description"`
+}
+
+type noteBillingReference struct {
+	Prefix    string `json:"prefix"`
+	IntID     string `json:"intID"`
+	CUFE      string `json:"CUFE"`
+	IssueDate string `json:"issueDate"`
+}
+
 type invoiceResponse struct {
 	InvoiceResult invoiceResult `json:"invoiceResult"`
 }
@@ -201,176 +235,218 @@ type invoicePrefix struct {
 	Auth        string `json:"auth"`
 }
 
-func (c *ElectronicInvoiceClient) Create(
+// Send emits the document, the root key of the payload tells the provider whether it is an
+// invoice, a credit note or a debit note
+func (c *ElectronicInvoiceClient) Send(
 	ctx context.Context,
-	createReq *dto.CreateElectronicInvoiceRequest,
+	document *dto.ElectronicDocument,
 ) (*dto.CreateElectronicInvoiceResponse, error) {
-	now := time.Now()
-	issueDate := now.Format("20060102")
-	issueTime := now.Format("150405")
-
-	totalAmount := strconv.FormatFloat(createReq.Bill.TotalAmount, 'f', 2, 64)
-	discountAmount := strconv.FormatFloat(createReq.Bill.DiscountAmount, 'f', 2, 64)
-	taxAmount := strconv.FormatFloat(createReq.Bill.TaxAmount, 'f', 2, 64)
-	payAmount := strconv.FormatFloat(createReq.Bill.PayAmount, 'f', 2, 64)
-
-	customer := createReq.Bill.Customer
-	if customer == nil {
-		customer = &dto.Customer{
-			DocumentNumber: "222222222222",
-			DocumentType:   dto.DocumentTypeNIT,
-			Name:           "consumidor final",
-			Email:          "noenviar@noenviar.com",
-		}
+	var payload any
+	switch document.Type {
+	case dto.ElectronicDocumentTypeCreditNote:
+		payload = creditNoteRequest{CreditNote: mapNote(document)}
+	case dto.ElectronicDocumentTypeDebitNote:
+		payload = debitNoteRequest{DebitNote: mapNote(document)}
+	default:
+		payload = mapInvoice(document)
+	}
+
+	body, err := c.post(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var invoiceResp invoiceResponse
+	if err := json.Unmarshal(body, &invoiceResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if invoiceResp.InvoiceResult.Status.Code == duplicateDocumentCode {
+		return c.alreadyReceived(ctx, document, invoiceResp.InvoiceResult)
+	}
+	if invoiceResp.InvoiceResult.Status.Code != 200 {
+		return nil, fmt.Errorf("invoice API error: %s", invoiceResp.InvoiceResult.Status.Text)
+	}
+
+	response := &dto.CreateElectronicInvoiceResponse{
+		Tascode: invoiceResp.InvoiceResult.Document.Tascode,
+		CUFE:    invoiceResp.InvoiceResult.Document.CUFE,
+	}
+	// Only invoices are numbered with the resolution the provider reports on
+	if document.Reference == nil {
+		response.PrefixRemaining = parseRemaining(invoiceResp.InvoiceResult.Prefix.Remaining)
+	}
+
+	return response, nil
+}
+
+// alreadyReceived answers a document the provider registered before with the status of the
+// first one, so sending it again after a crash is the same as sending it once. The numbers
+// left in the resolution are not known and are reported by the next invoice
+func (c *ElectronicInvoiceClient) alreadyReceived(
+	ctx context.Context,
+	document *dto.ElectronicDocument,
+	result invoiceResult,
+) (*dto.CreateElectronicInvoiceResponse, error) {
+	duplicateErr := fmt.Errorf("%w: %s%d: %s", domainError.ErrDocumentAlreadyReceived, document.Prefix, document.Consecutive, result.Status.Text)
+	if result.Document.Tascode == "" {
+		return nil, duplicateErr
 	}
 
-	requestData := invoiceRequest{
+	status, err := c.Get(ctx, result.Document.Tascode)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", duplicateErr, err)
+	}
+
+	return &dto.CreateElectronicInvoiceResponse{
+		Tascode: status.Tascode,
+		CUFE:    status.CUFE,
+	}, nil
+}
+
+func mapInvoice(document *dto.ElectronicDocument) invoiceRequest {
+	payAmount := document.Totals.PayAmount.String()
+
+	return invoiceRequest{
 		Invoice: invoiceRequestData{
-			Prefix:      createReq.Prefix,
-			IntID:       strconv.Itoa(createReq.Consecutive),
-			IssueDate:   issueDate,
-			IssueTime:   issueTime,
-			PaymentType: "1", // Contado->1 / Credito->2 // We are not using loans to pay anything in our system so always use "1"
-			PaymentCode: paymentCodeToCode(createReq.PaymentCode),
-			Note1:       utils.NumberToWords(payAmount),
-			Customer: invoiceCustomer{
-				AdditionalAccountID: mapDocumentTypeToAdditionalAccountID(customer.DocumentType),
-				Name:                customer.Name,
-				City:                "No Reporta",
-				CountrySubentity:    "11001",
-				AddressLine:         "No Reporta",
-				DocumentNumber:      customer.DocumentNumber,
-				DocumentType:        mapDocumentTypeToCode(customer.DocumentType),
-				Telephone:           "00000000",
-				Email:               customer.Email,
-			},
-			Amounts: invoiceAmounts{
-				TotalAmount:    totalAmount,
-				DiscountAmount: discountAmount,
-				TaxAmount:      taxAmount,
-				PayAmount:      payAmount,
-			},
-			Items: lo.Map(createReq.Bill.Products, func(billProduct dto.BillProduct, _ int) invoiceItem {
-				total := billProduct.UnitPrice * float64(billProduct.Quantity)
-
-				description := ""
-				if billProduct.Description != nil {
-					description = *billProduct.Description
-				}
-
-				if description == "" {
-					description = "unknown"
-				}
-
-				brand := "unknown"
-				if billProduct.Brand != nil {
-					brand = *billProduct.Brand
-				}
-
-				model := "unknown"
-				if billProduct.Model != nil {
-					model = *billProduct.Model
-				}
-
-				code := billProduct.Code
-
-				return invoiceItem{
-					Quantity:    strconv.FormatFloat(float64(billProduct.Quantity), 'f', 2, 64),
-					UnitPrice:   strconv.FormatFloat(billProduct.UnitPrice, 'f', -1, 64),
-					Total:       strconv.FormatFloat(total, 'f', -1, 64),
-					Description: description,
-					Brand:       brand,
-					Model:       model,
-					Code:        code,
-					Allowance: lo.Map(billProduct.Allowance, func(allowance dto.InvoiceAllowance, index int) invoiceAllowance {
-						return invoiceAllowance{
-							Charge:      allowance.Charge,
-							ReasonCode:  allowance.ReasonCode,
-							Description: allowance.Description,
-							BaseAmount:  allowance.BaseAmount,
-							Amount:      allowance.Amount,
-						}
-					}),
-					Taxes: lo.Map(billProduct.Taxes, func(tax dto.InvoiceTax, index int) invoiceTax {
-						return invoiceTax{
-							ID:        mapTaxCodeToID(tax.TaxCode),
-							TaxAmount: tax.TaxAmount,
-							Percent:   tax.Percent,
-						}
-					}),
-				}
-			}),
+			Prefix:       document.Prefix,
+			IntID:        strconv.Itoa(document.Consecutive),
+			IssueDate:    document.IssuedAt.In(dian.ColombiaTime).Format("20060102"),
+			IssueTime:    document.IssuedAt.In(dian.ColombiaTime).Format("150405"),
+			InvoiceType:  invoiceTypeCode(document.Type),
+			PaymentType:  "1", // Contado->1 / Credito->2 // We are not using loans to pay anything in our system so always use "1"
+			PaymentCode:  dian.PaymentMeansCode(document.PaymentCode),
+			PaymentMeans: mapPaymentMeans(document.PaymentMeans),
+			Note1:        utils.NumberToWords(payAmount),
+			Customer:     mapCustomer(document.Customer),
+			Amounts:      mapAmounts(document.Totals),
+			Charges:      mapTipCharge(document.Totals),
+			Items:        lo.Map(document.Lines, mapInvoiceItem),
 		},
 	}
+}
 
-	jsonData, err := json.Marshal(requestData)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal invoice request: %w", err)
+func mapNote(document *dto.ElectronicDocument) noteRequestData {
+	return noteRequestData{
+		Prefix:    document.Prefix,
+		IntID:     strconv.Itoa(document.Consecutive),
+		IssueDate: document.IssuedAt.In(dian.ColombiaTime).Format("20060102"),
+		IssueTime: document.IssuedAt.In(dian.ColombiaTime).Format("150405"),
+		DiscrepancyResponse: noteDiscrepancy{
+			ResponseCode: dian.CorrectionConceptCode(document.Type, document.Correction.Reason),
+			Description:  document.Correction.Description,
+		},
+		BillingReference: mapBillingReference(document.Reference),
+		Note1:            utils.NumberToWords(document.Totals.PayAmount.String()),
+		Customer:         mapCustomer(document.Customer),
+		Amounts:          mapAmounts(document.Totals),
+		Items:            lo.Map(document.Lines, mapInvoiceItem),
 	}
+}
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/facturacion.v30/invoice/", c.url), bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+func mapAmounts(totals dto.ElectronicDocumentTotals) invoiceAmounts {
+	return invoiceAmounts{
+		TotalAmount:    totals.LineAmount.String(),
+		DiscountAmount: totals.DiscountAmount.String(),
+		TaxAmount:      totals.TaxAmount.String(),
+		ChargeAmount:   totals.Tip.String(),
+		PayAmount:      totals.PayAmount.String(),
 	}
+}
 
-	auth := base64.StdEncoding.EncodeToString([]byte(c.user + ":" + c.password))
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Basic "+auth)
+// invoiceTypeCode returns the DIAN document type of contingency invoices, sales invoices
+// are left to the provider default
+func invoiceTypeCode(documentType dto.ElectronicDocumentType) string {
+	if documentType == dto.ElectronicDocumentTypeContingencyInvoice {
+		return dian.InvoiceTypeCode(documentType)
+	}
 
-	resp, err := c.client.Do(httpReq)
+	return ""
+}
+
+// parseRemaining reads the numbers left in the resolution, the provider omits them on some responses
+func parseRemaining(remaining string) *int {
+	value, err := strconv.Atoi(strings.TrimSpace(remaining))
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+	return &value
+}
+
+func (c *ElectronicInvoiceClient) Get(ctx context.Context, tascode string) (*dto.ElectronicInvoiceStatus, error) {
+	requestData := verifyStatusRequest{
+		VerifyStatus: verifyStatusData{
+			Tascode: tascode,
+		},
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("invoice API returned status %d: %s", resp.StatusCode, string(body))
+	body, err := c.post(ctx, requestData)
+	if err != nil {
+		return nil, err
 	}
 
-	var invoiceResp invoiceResponse
-	if err := json.Unmarshal(body, &invoiceResp); err != nil {
+	var verifyResp verifyStatusResponse
+	if err := json.Unmarshal(body, &verifyResp); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	if invoiceResp.InvoiceResult.Status.Code != 200 {
-		return nil, fmt.Errorf("invoice API error: %s", invoiceResp.InvoiceResult.Status.Text)
+	if verifyResp.InvoiceResult.Status.Code != 200 {
+		return nil, fmt.Errorf("invoice API error: %s", verifyResp.InvoiceResult.Status.Text)
 	}
 
-	return &dto.CreateElectronicInvoiceResponse{
-		Tascode: invoiceResp.InvoiceResult.Document.Tascode,
-		CUFE:    invoiceResp.InvoiceResult.Document.CUFE,
+	document := verifyResp.InvoiceResult.Document
+
+	return &dto.ElectronicInvoiceStatus{
+		Tascode:     document.Tascode,
+		CUFE:        document.CUFE,
+		Status:      mapDocumentStatus(document),
+		Process:     document.Process,
+		Retries:     document.Retries,
+		Messages:    document.EnhancedInfo,
+		DocumentURL: document.URL,
+		PDF:         document.PDF,
+		XML:         document.ATTACHED,
 	}, nil
 }
 
-func (c *ElectronicInvoiceClient) Get(ctx context.Context, invoiceID string) (*dto.ElectronicInvoice, error) {
-	requestData := verifyStatusRequest{
-		VerifyStatus: verifyStatusData{
-			Tascode: invoiceID,
-		},
+// mapDocumentStatus reads the outcome from the provider processing status first, a document
+// the provider has not finished processing is pending even when it already carries a CUFE.
+// Once processed it was accepted when the DIAN gave it a CUFE, rejected when it came back
+// with validation messages instead and is still pending otherwise, as the provider retries it
+func mapDocumentStatus(document verifyStatusDocument) dto.ElectronicInvoiceDocumentStatus {
+	switch {
+	case document.Process == documentNotProcessed:
+		return dto.ElectronicInvoiceDocumentStatusPending
+	case document.CUFE != "":
+		return dto.ElectronicInvoiceDocumentStatusAccepted
+	case len(document.EnhancedInfo) > 0:
+		return dto.ElectronicInvoiceDocumentStatusRejected
+	default:
+		return dto.ElectronicInvoiceDocumentStatusPending
 	}
+}
 
-	jsonData, err := json.Marshal(requestData)
+// post sends a request to the provider invoice endpoint, the root key of the payload
+// selects the operation
+func (c *ElectronicInvoiceClient) post(ctx context.Context, payload any) ([]byte, error) {
+	jsonData, err := json.Marshal(payload)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal verify status request: %w", err)
+		return nil, fmt.Errorf("failed to marshal invoice request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/facturacion.v30/invoice/", c.url), bytes.NewBuffer(jsonData))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/facturacion.v30/invoice/", c.url), bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	auth := base64.StdEncoding.EncodeToString([]byte(c.user + ":" + c.password))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Basic "+auth)
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Basic "+auth)
 
-	resp, err := c.client.Do(req)
+	resp, err := c.client.Do(httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("%w: failed to send request: %w", domainError.ErrProviderUnavailable, err)
 	}
 	defer resp.Body.Close()
 
@@ -379,18 +455,125 @@ func (c *ElectronicInvoiceClient) Get(ctx context.Context, invoiceID string) (*d
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
+	// Server errors and throttling mean the provider cannot take documents right now, any other
+	// status is an answer about the document itself
+	if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+		return nil, fmt.Errorf("%w: invoice API returned status %d: %s", domainError.ErrProviderUnavailable, resp.StatusCode, string(body))
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("invoice API returned status %d: %s", resp.StatusCode, string(body))
 	}
 
-	var verifyResp verifyStatusResponse
-	if err := json.Unmarshal(body, &verifyResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	return body, nil
+}
+
+func mapBillingReference(reference *dto.ElectronicDocumentReference) noteBillingReference {
+	return noteBillingReference{
+		Prefix:    reference.Prefix,
+		IntID:     strconv.Itoa(reference.Consecutive),
+		CUFE:      reference.CUFE,
+		IssueDate: reference.IssuedAt.In(dian.ColombiaTime).Format("20060102"),
 	}
+}
 
-	if verifyResp.InvoiceResult.Status.Code != 200 {
-		return nil, fmt.Errorf("invoice API error: %s", verifyResp.InvoiceResult.Status.Text)
+// mapCustomer reports the fiscal data a customer did not give with the values the DIAN
+// takes as not given
+func mapCustomer(customer dto.Customer) invoiceCustomer {
+	checkDigit := ""
+	if customer.DocumentType == dto.DocumentTypeNIT {
+		checkDigit = billowner.NITCheckDigit(customer.DocumentNumber)
+	}
+
+	return invoiceCustomer{
+		AdditionalAccountID: dian.AdditionalAccountID(customer.DocumentType),
+		Name:                customer.Name,
+		City:                lo.CoalesceOrEmpty(customer.City, dian.NotReported),
+		CountrySubentity:    lo.CoalesceOrEmpty(customer.MunicipalityCode, dian.DefaultMunicipalityCode),
+		Department:          lo.CoalesceOrEmpty(customer.Department, dian.NotReported),
+		AddressLine:         lo.CoalesceOrEmpty(customer.Address, dian.NotReported),
+		DocumentNumber:      customer.DocumentNumber,
+		DocumentType:        dian.DocumentTypeCode(customer.DocumentType),
+		CheckDigit:          checkDigit,
+		Telephone:           lo.CoalesceOrEmpty(customer.Telephone, dian.NotReportedTelephone),
+		Email:               customer.Email,
+		TaxRegime:           dian.TaxRegimeCode(customer.TaxRegime),
+		TaxLevelCode:        dian.TaxLevelCode(customer.FiscalResponsibilities),
+	}
+}
+
+func mapInvoiceItem(billProduct dto.BillProduct, _ int) invoiceItem {
+	total := billProduct.UnitPrice.Mul(billProduct.Quantity)
+
+	description := ""
+	if billProduct.Description != nil {
+		description = *billProduct.Description
+	}
+
+	if description == "" {
+		description = "unknown"
+	}
+
+	brand := "unknown"
+	if billProduct.Brand != nil {
+		brand = *billProduct.Brand
+	}
+
+	model := "unknown"
+	if billProduct.Model != nil {
+		model = *billProduct.Model
+	}
+
+	code := billProduct.Code
+
+	return invoiceItem{
+		Quantity:    strconv.FormatFloat(float64(billProduct.Quantity), 'f', 2, 64),
+		UnitPrice:   billProduct.UnitPrice.String(),
+		Total:       total.String(),
+		Description: description,
+		Brand:       brand,
+		Model:       model,
+		Code:        code,
+		Allowance: lo.Map(billProduct.Allowance, func(allowance dto.InvoiceAllowance, index int) invoiceAllowance {
+			return invoiceAllowance{
+				Charge:      allowance.Charge,
+				ReasonCode:  allowance.ReasonCode,
+				Description: allowance.Description,
+				BaseAmount:  allowance.BaseAmount.String(),
+				Amount:      allowance.Amount.String(),
+			}
+		}),
+		Taxes: lo.Map(billProduct.Taxes, func(tax dto.InvoiceTax, index int) invoiceTax {
+			return invoiceTax{
+				ID:        dian.TaxSchemeID(tax.TaxCode),
+				TaxAmount: tax.TaxAmount.String(),
+				Percent:   tax.Percent,
+			}
+		}),
+	}
+}
+
+func mapPaymentMeans(payments []dto.ElectronicDocumentPayment) []invoicePaymentMeans {
+	return lo.Map(payments, func(payment dto.ElectronicDocumentPayment, _ int) invoicePaymentMeans {
+		return invoicePaymentMeans{
+			PaymentType: "1",
+			PaymentCode: dian.PaymentMeansCode(payment.Method),
+			Amount:      payment.Amount.String(),
+			Reference:   payment.Reference,
+		}
+	})
+}
+
+// mapTipCharge sends the voluntary tip as a charge without taxes, it is added to the
+// amount to pay but not to the taxable base of the items
+func mapTipCharge(totals dto.ElectronicDocumentTotals) []invoiceAllowance {
+	if !totals.Tip.IsPositive() {
+		return nil
 	}
 
-	return &dto.ElectronicInvoice{}, nil
+	return []invoiceAllowance{{
+		Charge:      "true",
+		Description: "Propina voluntaria",
+		BaseAmount:  totals.LineAmount.Sub(totals.DiscountAmount).String(),
+		Amount:      totals.Tip.String(),
+	}}
 }
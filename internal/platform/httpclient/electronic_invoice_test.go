@@ -0,0 +1,49 @@
+package httpclient
+
+import (
+	"testing"
+
+	"laguna-escondida/backend/internal/domain/dto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapDocumentStatus(t *testing.T) {
+	testCases := []struct {
+		name     string
+		document verifyStatusDocument
+		expected dto.ElectronicInvoiceDocumentStatus
+	}{
+		{
+			name:     "processing with CUFE",
+			document: verifyStatusDocument{Process: 0, CUFE: "cufe-1"},
+			expected: dto.ElectronicInvoiceDocumentStatusPending,
+		},
+		{
+			name:     "processing without CUFE",
+			document: verifyStatusDocument{Process: 0, Retries: 2},
+			expected: dto.ElectronicInvoiceDocumentStatusPending,
+		},
+		{
+			name:     "processed with CUFE",
+			document: verifyStatusDocument{Process: 1, CUFE: "cufe-1"},
+			expected: dto.ElectronicInvoiceDocumentStatusAccepted,
+		},
+		{
+			name:     "processed with validation messages",
+			document: verifyStatusDocument{Process: 1, EnhancedInfo: []string{"Regla: FAD06, Rechazo: el CUFE no es válido"}},
+			expected: dto.ElectronicInvoiceDocumentStatusRejected,
+		},
+		{
+			name:     "processed without CUFE nor messages",
+			document: verifyStatusDocument{Process: 1, Retries: 1},
+			expected: dto.ElectronicInvoiceDocumentStatusPending,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, mapDocumentStatus(tc.document))
+		})
+	}
+}
@@ -0,0 +1,11 @@
+-- Migration: add_status_to_open_bills
+-- Version: 000012
+
+DROP INDEX IF EXISTS idx_open_bills_status;
+
+ALTER TABLE open_bills
+DROP CONSTRAINT IF EXISTS open_bills_status_check;
+
+ALTER TABLE open_bills
+DROP COLUMN IF EXISTS bill_id,
+DROP COLUMN IF EXISTS status;
@@ -0,0 +1,14 @@
+-- Migration: add_status_to_open_bills
+-- Version: 000012
+
+ALTER TABLE open_bills
+ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'open',
+ADD COLUMN IF NOT EXISTS bill_id UUID NULL REFERENCES bills(id);
+
+ALTER TABLE open_bills
+ADD CONSTRAINT open_bills_status_check CHECK (status IN ('open', 'paying', 'paid', 'cancelled'));
+
+-- Open bills that were soft deleted before statuses existed were already paid
+UPDATE open_bills SET status = 'paid' WHERE deleted_at IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_open_bills_status ON open_bills(status);
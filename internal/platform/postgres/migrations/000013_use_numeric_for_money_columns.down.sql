@@ -0,0 +1,19 @@
+-- Migration: use_numeric_for_money_columns
+-- Version: 000013
+
+ALTER TABLE bills
+ALTER COLUMN total_amount TYPE DOUBLE PRECISION,
+ALTER COLUMN discount_amount TYPE DOUBLE PRECISION,
+ALTER COLUMN vat TYPE DOUBLE PRECISION,
+ALTER COLUMN ico TYPE DOUBLE PRECISION,
+ALTER COLUMN tip TYPE DOUBLE PRECISION;
+
+ALTER TABLE open_bills
+ALTER COLUMN total_price TYPE DOUBLE PRECISION,
+ALTER COLUMN vat TYPE DOUBLE PRECISION,
+ALTER COLUMN ico TYPE DOUBLE PRECISION,
+ALTER COLUMN tip TYPE DOUBLE PRECISION;
+
+ALTER TABLE products
+ALTER COLUMN unit_price TYPE DOUBLE PRECISION,
+ALTER COLUMN total_price_with_taxes TYPE DOUBLE PRECISION;
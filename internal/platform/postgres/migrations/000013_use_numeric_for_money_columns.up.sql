@@ -0,0 +1,20 @@
+-- Migration: use_numeric_for_money_columns
+-- Version: 000013
+
+-- Money is stored as exact COP amounts with two decimals instead of floating point
+ALTER TABLE products
+ALTER COLUMN unit_price TYPE NUMERIC(14,2) USING ROUND(unit_price::NUMERIC, 2),
+ALTER COLUMN total_price_with_taxes TYPE NUMERIC(14,2) USING ROUND(total_price_with_taxes::NUMERIC, 2);
+
+ALTER TABLE open_bills
+ALTER COLUMN total_price TYPE NUMERIC(14,2) USING ROUND(total_price::NUMERIC, 2),
+ALTER COLUMN vat TYPE NUMERIC(14,2) USING ROUND(vat::NUMERIC, 2),
+ALTER COLUMN ico TYPE NUMERIC(14,2) USING ROUND(ico::NUMERIC, 2),
+ALTER COLUMN tip TYPE NUMERIC(14,2) USING ROUND(tip::NUMERIC, 2);
+
+ALTER TABLE bills
+ALTER COLUMN total_amount TYPE NUMERIC(14,2) USING ROUND(total_amount::NUMERIC, 2),
+ALTER COLUMN discount_amount TYPE NUMERIC(14,2) USING ROUND(discount_amount::NUMERIC, 2),
+ALTER COLUMN vat TYPE NUMERIC(14,2) USING ROUND(vat::NUMERIC, 2),
+ALTER COLUMN ico TYPE NUMERIC(14,2) USING ROUND(ico::NUMERIC, 2),
+ALTER COLUMN tip TYPE NUMERIC(14,2) USING ROUND(tip::NUMERIC, 2);
@@ -0,0 +1,14 @@
+-- Migration: add_split_to_open_bills
+-- Version: 000014
+
+DROP INDEX IF EXISTS idx_open_bills_parent_id;
+
+ALTER TABLE open_bills
+DROP CONSTRAINT IF EXISTS open_bills_status_check;
+
+ALTER TABLE open_bills
+ADD CONSTRAINT open_bills_status_check CHECK (status IN ('open', 'paying', 'paid', 'cancelled'));
+
+ALTER TABLE open_bills
+DROP COLUMN IF EXISTS split_amount,
+DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,14 @@
+-- Migration: add_split_to_open_bills
+-- Version: 000014
+
+ALTER TABLE open_bills
+ADD COLUMN IF NOT EXISTS parent_id UUID NULL REFERENCES open_bills(id),
+ADD COLUMN IF NOT EXISTS split_amount NUMERIC(14,2) NULL;
+
+ALTER TABLE open_bills
+DROP CONSTRAINT IF EXISTS open_bills_status_check;
+
+ALTER TABLE open_bills
+ADD CONSTRAINT open_bills_status_check CHECK (status IN ('open', 'paying', 'paid', 'cancelled', 'split'));
+
+CREATE INDEX IF NOT EXISTS idx_open_bills_parent_id ON open_bills(parent_id);
@@ -0,0 +1,12 @@
+-- Migration: create_open_bill_transfers_table
+-- Version: 000015
+
+ALTER TABLE open_bills
+DROP CONSTRAINT IF EXISTS open_bills_status_check;
+
+ALTER TABLE open_bills
+ADD CONSTRAINT open_bills_status_check CHECK (status IN ('open', 'paying', 'paid', 'cancelled', 'split'));
+
+DROP INDEX IF EXISTS idx_open_bill_transfers_target;
+DROP INDEX IF EXISTS idx_open_bill_transfers_source;
+DROP TABLE IF EXISTS open_bill_transfers;
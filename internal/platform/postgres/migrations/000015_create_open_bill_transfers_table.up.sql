@@ -0,0 +1,22 @@
+-- Migration: create_open_bill_transfers_table
+-- Version: 000015
+
+-- Audit of products moved between open bills, one row per product moved
+CREATE TABLE IF NOT EXISTS open_bill_transfers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    source_open_bill_id UUID NOT NULL REFERENCES open_bills(id),
+    target_open_bill_id UUID NOT NULL REFERENCES open_bills(id),
+    product_id UUID NOT NULL REFERENCES products(id),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    moved_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_open_bill_transfers_source ON open_bill_transfers(source_open_bill_id);
+CREATE INDEX IF NOT EXISTS idx_open_bill_transfers_target ON open_bill_transfers(target_open_bill_id);
+
+ALTER TABLE open_bills
+DROP CONSTRAINT IF EXISTS open_bills_status_check;
+
+ALTER TABLE open_bills
+ADD CONSTRAINT open_bills_status_check CHECK (status IN ('open', 'paying', 'paid', 'cancelled', 'split', 'merged'));
@@ -0,0 +1,5 @@
+-- Migration: create_bill_payments_table
+-- Version: 000016
+
+DROP INDEX IF EXISTS idx_bill_payments_bill_id;
+DROP TABLE IF EXISTS bill_payments;
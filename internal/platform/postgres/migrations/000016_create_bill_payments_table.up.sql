@@ -0,0 +1,15 @@
+-- Migration: create_bill_payments_table
+-- Version: 000016
+
+-- Means used to pay a bill, a bill paid with cash and card has one row per method
+CREATE TABLE IF NOT EXISTS bill_payments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    bill_id UUID NOT NULL REFERENCES bills(id),
+    method VARCHAR(50) NOT NULL,
+    amount NUMERIC(14,2) NOT NULL CHECK (amount > 0),
+    change_amount NUMERIC(14,2) NOT NULL DEFAULT 0 CHECK (change_amount >= 0),
+    reference VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_bill_payments_bill_id ON bill_payments(bill_id);
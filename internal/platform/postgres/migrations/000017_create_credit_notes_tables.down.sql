@@ -0,0 +1,14 @@
+-- Migration: create_credit_notes_tables
+-- Version: 000017
+
+DELETE FROM invoice_sequences WHERE prefix = 'NC';
+
+DROP INDEX IF EXISTS idx_credit_note_products_credit_note_id;
+DROP TABLE IF EXISTS credit_note_products;
+
+DROP INDEX IF EXISTS idx_credit_notes_bill_id;
+DROP TABLE IF EXISTS credit_notes;
+
+ALTER TABLE bills
+DROP COLUMN IF EXISTS consecutive,
+DROP COLUMN IF EXISTS prefix;
@@ -0,0 +1,44 @@
+-- Migration: create_credit_notes_tables
+-- Version: 000017
+
+-- Number of the invoice at the DIAN, credit notes reference it along with the CUFE
+ALTER TABLE bills
+ADD COLUMN IF NOT EXISTS prefix VARCHAR(10) NULL,
+ADD COLUMN IF NOT EXISTS consecutive INTEGER NULL;
+
+CREATE TABLE IF NOT EXISTS credit_notes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    bill_id UUID NOT NULL REFERENCES bills(id),
+    prefix VARCHAR(10) NOT NULL,
+    consecutive INTEGER NOT NULL,
+    reason VARCHAR(50) NOT NULL,
+    description TEXT NOT NULL,
+    total_amount NUMERIC(14,2) NOT NULL,
+    discount_amount NUMERIC(14,2) NOT NULL DEFAULT 0,
+    vat NUMERIC(14,2) NOT NULL,
+    ico NUMERIC(14,2) NOT NULL,
+    tip NUMERIC(14,2) NOT NULL DEFAULT 0,
+    cufe VARCHAR(255) NULL,
+    tascode VARCHAR(255) NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL,
+    UNIQUE(prefix, consecutive)
+);
+
+CREATE INDEX IF NOT EXISTS idx_credit_notes_bill_id ON credit_notes(bill_id);
+
+CREATE TABLE IF NOT EXISTS credit_note_products (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    credit_note_id UUID NOT NULL REFERENCES credit_notes(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_price NUMERIC(14,2) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_credit_note_products_credit_note_id ON credit_note_products(credit_note_id);
+
+-- Credit notes have their own numbering, the first credit note gets 1
+INSERT INTO invoice_sequences (prefix, last_consecutive) VALUES ('NC', 0)
+ON CONFLICT (prefix) DO NOTHING;
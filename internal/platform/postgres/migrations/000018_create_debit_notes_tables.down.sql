@@ -0,0 +1,10 @@
+-- Migration: create_debit_notes_tables
+-- Version: 000018
+
+DELETE FROM invoice_sequences WHERE prefix = 'ND';
+
+DROP INDEX IF EXISTS idx_debit_note_products_debit_note_id;
+DROP TABLE IF EXISTS debit_note_products;
+
+DROP INDEX IF EXISTS idx_debit_notes_bill_id;
+DROP TABLE IF EXISTS debit_notes;
@@ -0,0 +1,43 @@
+-- Migration: create_debit_notes_tables
+-- Version: 000018
+
+CREATE TABLE IF NOT EXISTS debit_notes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    bill_id UUID NOT NULL REFERENCES bills(id),
+    prefix VARCHAR(10) NOT NULL,
+    consecutive INTEGER NOT NULL,
+    reason VARCHAR(50) NOT NULL,
+    description TEXT NOT NULL,
+    total_amount NUMERIC(14,2) NOT NULL,
+    discount_amount NUMERIC(14,2) NOT NULL DEFAULT 0,
+    vat NUMERIC(14,2) NOT NULL,
+    ico NUMERIC(14,2) NOT NULL,
+    cufe VARCHAR(255) NULL,
+    tascode VARCHAR(255) NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL,
+    UNIQUE(prefix, consecutive)
+);
+
+CREATE INDEX IF NOT EXISTS idx_debit_notes_bill_id ON debit_notes(bill_id);
+
+-- Lines charged by a debit note, charges outside the catalog have no product
+CREATE TABLE IF NOT EXISTS debit_note_products (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    debit_note_id UUID NOT NULL REFERENCES debit_notes(id) ON DELETE CASCADE,
+    product_id UUID NULL REFERENCES products(id),
+    description TEXT NULL,
+    code VARCHAR(255) NOT NULL DEFAULT '',
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_price NUMERIC(14,2) NOT NULL,
+    vat NUMERIC(14,2) NOT NULL DEFAULT 0,
+    ico NUMERIC(14,2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_debit_note_products_debit_note_id ON debit_note_products(debit_note_id);
+
+-- Debit notes have their own numbering, the first debit note gets 1
+INSERT INTO invoice_sequences (prefix, last_consecutive) VALUES ('ND', 0)
+ON CONFLICT (prefix) DO NOTHING;
@@ -0,0 +1,7 @@
+-- Migration: add_dian_documents_to_bills
+-- Version: 000019
+
+ALTER TABLE bills
+DROP COLUMN IF EXISTS xml,
+DROP COLUMN IF EXISTS pdf,
+DROP COLUMN IF EXISTS dian_status;
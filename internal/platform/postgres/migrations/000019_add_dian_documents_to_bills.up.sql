@@ -0,0 +1,9 @@
+-- Migration: add_dian_documents_to_bills
+-- Version: 000019
+
+-- Status synced from the provider and the official documents, PDF and XML are stored in
+-- base64 as the provider returns them
+ALTER TABLE bills
+ADD COLUMN IF NOT EXISTS dian_status VARCHAR(20) NULL,
+ADD COLUMN IF NOT EXISTS pdf TEXT NULL,
+ADD COLUMN IF NOT EXISTS xml TEXT NULL;
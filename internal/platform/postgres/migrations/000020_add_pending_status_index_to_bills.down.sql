@@ -0,0 +1,4 @@
+-- Migration: add_pending_status_index_to_bills
+-- Version: 000020
+
+DROP INDEX IF EXISTS idx_bills_pending_status;
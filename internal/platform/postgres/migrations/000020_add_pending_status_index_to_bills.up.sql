@@ -0,0 +1,6 @@
+-- Migration: add_pending_status_index_to_bills
+-- Version: 000020
+
+-- The reconciliation worker polls the bills sent to the provider whose status is not final
+CREATE INDEX IF NOT EXISTS idx_bills_pending_status ON bills(updated_at)
+WHERE tascode IS NOT NULL AND deleted_at IS NULL AND (dian_status IS NULL OR dian_status = 'pending');
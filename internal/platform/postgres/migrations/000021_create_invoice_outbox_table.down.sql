@@ -0,0 +1,4 @@
+-- Migration: create_invoice_outbox_table
+-- Version: 000021
+
+DROP TABLE IF EXISTS invoice_outbox;
@@ -0,0 +1,24 @@
+-- Migration: create_invoice_outbox_table
+-- Version: 000021
+
+-- Invoices are saved here in the same transaction as their bill and sent to the provider
+-- afterwards by the outbox dispatcher
+CREATE TABLE IF NOT EXISTS invoice_outbox (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    bill_id UUID NOT NULL REFERENCES bills(id),
+    prefix VARCHAR(10) NOT NULL,
+    consecutive INTEGER NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NULL,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(prefix, consecutive),
+    UNIQUE(bill_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_invoice_outbox_due ON invoice_outbox(next_attempt_at)
+WHERE status = 'pending';
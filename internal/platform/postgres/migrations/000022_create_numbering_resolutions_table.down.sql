@@ -0,0 +1,9 @@
+-- Migration: create_numbering_resolutions_table
+-- Version: 000022
+
+INSERT INTO invoice_sequences (prefix, last_consecutive)
+SELECT 'LAG', COALESCE(MAX(consecutive), -1) FROM bills WHERE prefix = 'SETP'
+ON CONFLICT (prefix) DO NOTHING;
+
+DROP INDEX IF EXISTS idx_numbering_resolutions_validity;
+DROP TABLE IF EXISTS numbering_resolutions;
@@ -0,0 +1,39 @@
+-- Migration: create_numbering_resolutions_table
+-- Version: 000022
+
+-- DIAN resolutions authorizing a prefix and a range of consecutives between two dates
+-- Invoices take their number from the oldest resolution valid today with numbers left
+CREATE TABLE IF NOT EXISTS numbering_resolutions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    resolution_number VARCHAR(50) NOT NULL,
+    prefix VARCHAR(10) NOT NULL,
+    range_from INTEGER NOT NULL CHECK (range_from > 0),
+    range_to INTEGER NOT NULL,
+    last_consecutive INTEGER NOT NULL,
+    valid_from DATE NOT NULL,
+    valid_to DATE NOT NULL,
+    technical_key VARCHAR(255) NOT NULL,
+    provider_remaining INTEGER NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL,
+    CHECK (range_to >= range_from),
+    CHECK (valid_to >= valid_from),
+    CHECK (last_consecutive BETWEEN range_from - 1 AND range_to)
+);
+
+CREATE INDEX IF NOT EXISTS idx_numbering_resolutions_validity ON numbering_resolutions(valid_from, valid_to)
+WHERE deleted_at IS NULL;
+
+-- Testing set resolution the provider accepts with the SETP prefix, invoices were sent with it
+-- until now so numbering continues after the last bill. Production resolutions are added
+-- through the API
+INSERT INTO numbering_resolutions (resolution_number, prefix, range_from, range_to, last_consecutive, valid_from, valid_to, technical_key)
+SELECT '18760000001', 'SETP', 990000000, 995000000,
+       GREATEST(989999999, COALESCE(MAX(consecutive), 0)),
+       DATE '2019-01-19', DATE '2030-01-19', 'fc8eac422eba16e22ffd8c6f94b3f40a6e38162c'
+FROM bills
+WHERE prefix = 'SETP';
+
+-- The LAG sequence only counted invoices, they are numbered by their resolution now
+DELETE FROM invoice_sequences WHERE prefix = 'LAG';
@@ -0,0 +1,12 @@
+-- Migration: create_contingency_periods_table
+-- Version: 000023
+
+ALTER TABLE numbering_resolutions DROP COLUMN IF EXISTS kind;
+
+DROP INDEX IF EXISTS idx_bills_contingency_period_id;
+
+ALTER TABLE bills DROP COLUMN IF EXISTS contingency_period_id;
+
+DROP INDEX IF EXISTS idx_contingency_periods_open;
+
+DROP TABLE IF EXISTS contingency_periods;
@@ -0,0 +1,24 @@
+-- Migration: create_contingency_periods_table
+-- Version: 000023
+
+-- Periods in which bills were issued locally because the invoicing provider could not be reached
+CREATE TABLE IF NOT EXISTS contingency_periods (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    reason TEXT NOT NULL,
+    automatic BOOLEAN NOT NULL DEFAULT FALSE,
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ended_at TIMESTAMP NULL,
+    CHECK (ended_at IS NULL OR ended_at >= started_at)
+);
+
+-- Only one period can be open at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_contingency_periods_open ON contingency_periods((ended_at IS NULL))
+WHERE ended_at IS NULL;
+
+ALTER TABLE bills ADD COLUMN IF NOT EXISTS contingency_period_id UUID NULL REFERENCES contingency_periods(id);
+
+CREATE INDEX IF NOT EXISTS idx_bills_contingency_period_id ON bills(contingency_period_id)
+WHERE contingency_period_id IS NOT NULL;
+
+-- Contingency invoices are numbered with their own resolution when there is one
+ALTER TABLE numbering_resolutions ADD COLUMN IF NOT EXISTS kind VARCHAR(20) NOT NULL DEFAULT 'electronic';
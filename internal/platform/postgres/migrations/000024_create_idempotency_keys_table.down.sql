@@ -0,0 +1,5 @@
+-- Migration: create_idempotency_keys_table
+-- Version: 000024
+
+DROP INDEX IF EXISTS idx_idempotency_keys_expires_at;
+DROP TABLE IF EXISTS idempotency_keys;
@@ -0,0 +1,7 @@
+-- Migration: add_bill_owner_to_bills
+-- Version: 000025
+
+DROP INDEX IF EXISTS idx_bills_created_at;
+DROP INDEX IF EXISTS idx_bills_bill_owner_id;
+
+ALTER TABLE bills DROP COLUMN IF EXISTS bill_owner_id;
@@ -0,0 +1,12 @@
+-- Migration: add_bill_owner_to_bills
+-- Version: 000025
+
+-- Customer the bill was issued to, bills without one were issued to the final consumer
+ALTER TABLE bills ADD COLUMN IF NOT EXISTS bill_owner_id VARCHAR(255) NULL REFERENCES bill_owners(id);
+
+CREATE INDEX IF NOT EXISTS idx_bills_bill_owner_id ON bills(bill_owner_id)
+WHERE bill_owner_id IS NOT NULL;
+
+-- Bills are listed by date, the most recent first
+CREATE INDEX IF NOT EXISTS idx_bills_created_at ON bills(created_at DESC)
+WHERE deleted_at IS NULL;
@@ -0,0 +1,37 @@
+-- Migration: add_snapshots_to_bill_products
+-- Version: 000026
+
+DROP INDEX IF EXISTS idx_bill_products_bill_id_line_number;
+
+-- Lines of the same product are merged back so the unique constraint can be restored
+UPDATE bill_products
+SET quantity = merged.quantity
+FROM (
+    SELECT bill_id, product_id, SUM(quantity) AS quantity, MIN(line_number) AS line_number
+    FROM bill_products
+    GROUP BY bill_id, product_id
+) AS merged
+WHERE merged.bill_id = bill_products.bill_id
+  AND merged.product_id = bill_products.product_id
+  AND merged.line_number = bill_products.line_number;
+
+DELETE FROM bill_products
+USING bill_products AS first_line
+WHERE first_line.bill_id = bill_products.bill_id
+  AND first_line.product_id = bill_products.product_id
+  AND first_line.line_number < bill_products.line_number;
+
+ALTER TABLE bill_products ADD CONSTRAINT bill_products_bill_id_product_id_key UNIQUE (bill_id, product_id);
+
+ALTER TABLE bill_products
+DROP COLUMN IF EXISTS allowances,
+DROP COLUMN IF EXISTS ico_amount,
+DROP COLUMN IF EXISTS vat_amount,
+DROP COLUMN IF EXISTS ico_rate,
+DROP COLUMN IF EXISTS vat_rate,
+DROP COLUMN IF EXISTS code,
+DROP COLUMN IF EXISTS model,
+DROP COLUMN IF EXISTS brand,
+DROP COLUMN IF EXISTS description,
+DROP COLUMN IF EXISTS unit_price,
+DROP COLUMN IF EXISTS line_number;
@@ -0,0 +1,52 @@
+-- Migration: add_snapshots_to_bill_products
+-- Version: 000026
+
+-- Each bill line keeps what was invoiced, later catalog changes must not alter issued bills
+ALTER TABLE bill_products
+ADD COLUMN IF NOT EXISTS line_number INTEGER,
+ADD COLUMN IF NOT EXISTS unit_price NUMERIC(14,2),
+ADD COLUMN IF NOT EXISTS description TEXT,
+ADD COLUMN IF NOT EXISTS brand VARCHAR(255),
+ADD COLUMN IF NOT EXISTS model VARCHAR(255),
+ADD COLUMN IF NOT EXISTS code VARCHAR(255),
+ADD COLUMN IF NOT EXISTS vat_rate DOUBLE PRECISION,
+ADD COLUMN IF NOT EXISTS ico_rate DOUBLE PRECISION,
+ADD COLUMN IF NOT EXISTS vat_amount NUMERIC(14,2),
+ADD COLUMN IF NOT EXISTS ico_amount NUMERIC(14,2),
+ADD COLUMN IF NOT EXISTS allowances JSONB NOT NULL DEFAULT '[]';
+
+-- Lines billed before this migration only have the current catalog data to go by
+UPDATE bill_products
+SET unit_price = products.unit_price,
+    description = products.description,
+    brand = products.brand,
+    model = products.model,
+    code = products.sku,
+    vat_rate = products.vat,
+    ico_rate = products.ico,
+    vat_amount = ROUND(products.unit_price * bill_products.quantity * products.vat::NUMERIC, 2),
+    ico_amount = ROUND(products.unit_price * bill_products.quantity * products.ico::NUMERIC, 2)
+FROM products
+WHERE products.id = bill_products.product_id;
+
+UPDATE bill_products
+SET line_number = numbered.line_number
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY bill_id ORDER BY created_at, id) AS line_number
+    FROM bill_products
+) AS numbered
+WHERE numbered.id = bill_products.id;
+
+ALTER TABLE bill_products
+ALTER COLUMN line_number SET NOT NULL,
+ALTER COLUMN unit_price SET NOT NULL,
+ALTER COLUMN code SET NOT NULL,
+ALTER COLUMN vat_rate SET NOT NULL,
+ALTER COLUMN ico_rate SET NOT NULL,
+ALTER COLUMN vat_amount SET NOT NULL,
+ALTER COLUMN ico_amount SET NOT NULL;
+
+-- A product can be billed in several lines, for instance with different allowances
+ALTER TABLE bill_products DROP CONSTRAINT IF EXISTS bill_products_bill_id_product_id_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bill_products_bill_id_line_number ON bill_products(bill_id, line_number);
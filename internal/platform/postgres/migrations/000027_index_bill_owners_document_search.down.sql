@@ -0,0 +1,4 @@
+-- Migration: index_bill_owners_document_search
+-- Version: 000027
+
+DROP INDEX IF EXISTS idx_bill_owners_id_pattern;
@@ -0,0 +1,11 @@
+-- Migration: index_bill_owners_document_search
+-- Version: 000027
+
+-- Bill owners written before the type was required are taken as citizens, the same
+-- default the invoice uses when sending them
+UPDATE bill_owners SET identification_type = 'CC'
+WHERE identification_type IS NULL OR identification_type = '';
+
+-- The cashier looks customers up by the start of their document number
+CREATE INDEX IF NOT EXISTS idx_bill_owners_id_pattern ON bill_owners(id varchar_pattern_ops)
+WHERE deleted_at IS NULL;
@@ -0,0 +1,10 @@
+-- Migration: add_fiscal_data_to_bill_owners
+-- Version: 000028
+
+ALTER TABLE bill_owners
+DROP COLUMN IF EXISTS fiscal_responsibilities,
+DROP COLUMN IF EXISTS tax_regime,
+DROP COLUMN IF EXISTS department,
+DROP COLUMN IF EXISTS municipality_code,
+DROP COLUMN IF EXISTS city,
+DROP COLUMN IF EXISTS address;
@@ -0,0 +1,12 @@
+-- Migration: add_fiscal_data_to_bill_owners
+-- Version: 000028
+
+-- Fiscal data sent on the invoices of corporate customers, celphone is sent as their telephone
+ALTER TABLE bill_owners
+ADD COLUMN IF NOT EXISTS address TEXT NULL,
+ADD COLUMN IF NOT EXISTS city VARCHAR(255) NULL,
+ADD COLUMN IF NOT EXISTS municipality_code VARCHAR(5) NULL,
+ADD COLUMN IF NOT EXISTS department VARCHAR(255) NULL,
+ADD COLUMN IF NOT EXISTS tax_regime VARCHAR(30) NULL,
+-- RUT responsibilities separated by semicolons, as the DIAN lists them (O-13;O-15)
+ADD COLUMN IF NOT EXISTS fiscal_responsibilities VARCHAR(255) NULL;
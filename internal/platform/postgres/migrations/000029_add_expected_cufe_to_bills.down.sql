@@ -0,0 +1,6 @@
+-- Migration: add_expected_cufe_to_bills
+-- Version: 000029
+
+ALTER TABLE bills
+DROP COLUMN IF EXISTS cufe_mismatch,
+DROP COLUMN IF EXISTS expected_cufe;
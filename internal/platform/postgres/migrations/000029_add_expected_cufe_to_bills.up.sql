@@ -0,0 +1,8 @@
+-- Migration: add_expected_cufe_to_bills
+-- Version: 000029
+
+-- CUFE computed by the backend when the invoice is sent, the bill is flagged when the
+-- provider answers a different one
+ALTER TABLE bills
+ADD COLUMN IF NOT EXISTS expected_cufe VARCHAR(96) NULL,
+ADD COLUMN IF NOT EXISTS cufe_mismatch BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,12 @@
+-- Migration: add_notes_to_invoice_outbox
+-- Version: 000030
+
+DELETE FROM invoice_outbox WHERE document_type <> 'invoice';
+
+DROP INDEX IF EXISTS idx_invoice_outbox_invoice_bill;
+
+ALTER TABLE invoice_outbox ADD CONSTRAINT invoice_outbox_bill_id_key UNIQUE (bill_id);
+
+ALTER TABLE invoice_outbox
+DROP COLUMN IF EXISTS note_id,
+DROP COLUMN IF EXISTS document_type;
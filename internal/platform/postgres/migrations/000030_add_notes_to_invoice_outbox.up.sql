@@ -0,0 +1,13 @@
+-- Migration: add_notes_to_invoice_outbox
+-- Version: 000030
+
+-- Credit and debit notes are queued with the invoices, bill_id is the invoice they correct
+-- so a bill is only unique among the invoices
+ALTER TABLE invoice_outbox
+ADD COLUMN IF NOT EXISTS document_type VARCHAR(20) NOT NULL DEFAULT 'invoice',
+ADD COLUMN IF NOT EXISTS note_id UUID NULL;
+
+ALTER TABLE invoice_outbox DROP CONSTRAINT IF EXISTS invoice_outbox_bill_id_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invoice_outbox_invoice_bill ON invoice_outbox(bill_id)
+WHERE document_type = 'invoice';
@@ -0,0 +1,5 @@
+-- Migration: add_paying_since_to_open_bills
+-- Version: 000031
+
+ALTER TABLE open_bills
+DROP COLUMN IF EXISTS paying_since;
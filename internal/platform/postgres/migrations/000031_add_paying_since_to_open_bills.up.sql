@@ -0,0 +1,10 @@
+-- Migration: add_paying_since_to_open_bills
+-- Version: 000031
+
+-- When the payment of the order started, a payment that never finished is taken over once
+-- it is old enough
+ALTER TABLE open_bills
+ADD COLUMN IF NOT EXISTS paying_since TIMESTAMP NULL;
+
+-- Orders left in paying before the column existed can be paid again right away
+UPDATE open_bills SET paying_since = updated_at WHERE status = 'paying';
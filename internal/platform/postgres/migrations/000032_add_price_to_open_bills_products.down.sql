@@ -0,0 +1,7 @@
+-- Migration: add_price_to_open_bills_products
+-- Version: 000032
+
+ALTER TABLE open_bills_products
+DROP COLUMN IF EXISTS ico,
+DROP COLUMN IF EXISTS vat,
+DROP COLUMN IF EXISTS total_price_with_taxes;
@@ -0,0 +1,17 @@
+-- Migration: add_price_to_open_bills_products
+-- Version: 000032
+
+-- Price with taxes and tax rates each order line is charged at, the order is invoiced at
+-- them even if the catalog changes before it is paid
+ALTER TABLE open_bills_products
+ADD COLUMN IF NOT EXISTS total_price_with_taxes NUMERIC(14,2) NULL,
+ADD COLUMN IF NOT EXISTS vat DOUBLE PRECISION NULL,
+ADD COLUMN IF NOT EXISTS ico DOUBLE PRECISION NULL;
+
+-- Lines saved before keep the current catalog price
+UPDATE open_bills_products
+SET total_price_with_taxes = products.total_price_with_taxes,
+    vat = products.vat,
+    ico = products.ico
+FROM products
+WHERE products.id = open_bills_products.product_id;
@@ -1,18 +1,266 @@
 package repository
 
-import "time"
+import (
+	"context"
+	"strings"
+	"time"
+
+	"laguna-escondida/backend/internal/domain/aggregate/billowner"
+	"laguna-escondida/backend/internal/domain/dto"
+	domainError "laguna-escondida/backend/internal/domain/error"
+	"laguna-escondida/backend/internal/domain/ports"
+
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
 
 type billOwnerModel struct {
-	ID                 string     `gorm:"type:varchar(255);primaryKey"`
-	Celphone           *string    `gorm:"type:varchar(50)"`
-	Email              string     `gorm:"type:varchar(255);not null"`
-	Name               string     `gorm:"type:varchar(255);not null"`
-	IdentificationType *string    `gorm:"type:varchar(50);column:identification_type"`
-	CreatedAt          time.Time  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
-	UpdatedAt          time.Time  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
-	DeletedAt          *time.Time `gorm:"type:timestamp"`
+	ID                 string  `gorm:"type:varchar(255);primaryKey"`
+	Celphone           *string `gorm:"type:varchar(50)"`
+	Email              string  `gorm:"type:varchar(255);not null"`
+	Name               string  `gorm:"type:varchar(255);not null"`
+	IdentificationType *string `gorm:"type:varchar(50);column:identification_type"`
+	Address            *string `gorm:"type:text"`
+	City               *string `gorm:"type:varchar(255)"`
+	MunicipalityCode   *string `gorm:"type:varchar(5)"`
+	Department         *string `gorm:"type:varchar(255)"`
+	TaxRegime          *string `gorm:"type:varchar(30)"`
+	// FiscalResponsibilities holds the RUT responsibilities separated by semicolons
+	FiscalResponsibilities *string    `gorm:"type:varchar(255)"`
+	CreatedAt              time.Time  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt              time.Time  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+	DeletedAt              *time.Time `gorm:"type:timestamp"`
 }
 
 func (billOwnerModel) TableName() string {
 	return "bill_owners"
 }
+
+type BillOwnerRepository struct {
+	db *gorm.DB
+}
+
+func NewBillOwnerRepository(db *gorm.DB) ports.BillOwnerRepository {
+	return &BillOwnerRepository{db: db}
+}
+
+func (r *BillOwnerRepository) Create(ctx context.Context, owner *billowner.Aggregate) error {
+	model := billOwnerToModel(owner.ToDTO())
+
+	// A deleted bill owner keeps its document number, registering it again brings it back
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "id"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"celphone":                model.Celphone,
+			"email":                   model.Email,
+			"name":                    model.Name,
+			"identification_type":     model.IdentificationType,
+			"address":                 model.Address,
+			"city":                    model.City,
+			"municipality_code":       model.MunicipalityCode,
+			"department":              model.Department,
+			"tax_regime":              model.TaxRegime,
+			"fiscal_responsibilities": model.FiscalResponsibilities,
+			"created_at":              model.CreatedAt,
+			"updated_at":              model.UpdatedAt,
+			"deleted_at":              nil,
+		}),
+		Where: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "bill_owners.deleted_at IS NOT NULL"}}},
+	}).Create(model)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domainError.ErrBillOwnerAlreadyExists
+	}
+
+	return nil
+}
+
+func (r *BillOwnerRepository) Update(ctx context.Context, owner *billowner.Aggregate) error {
+	model := billOwnerToModel(owner.ToDTO())
+	result := r.db.WithContext(ctx).Model(&billOwnerModel{}).
+		Where("id = ? AND deleted_at IS NULL", model.ID).
+		Updates(map[string]any{
+			"celphone":                model.Celphone,
+			"email":                   model.Email,
+			"name":                    model.Name,
+			"identification_type":     model.IdentificationType,
+			"address":                 model.Address,
+			"city":                    model.City,
+			"municipality_code":       model.MunicipalityCode,
+			"department":              model.Department,
+			"tax_regime":              model.TaxRegime,
+			"fiscal_responsibilities": model.FiscalResponsibilities,
+			"updated_at":              model.UpdatedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *BillOwnerRepository) Delete(ctx context.Context, id string) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).Model(&billOwnerModel{}).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Updates(map[string]any{
+			"deleted_at": now,
+			"updated_at": now,
+		}).Error
+}
+
+func (r *BillOwnerRepository) FindByID(ctx context.Context, id string) (*dto.BillOwner, error) {
+	var model billOwnerModel
+	if err := r.db.WithContext(ctx).Where("id = ? AND deleted_at IS NULL", id).First(&model).Error; err != nil {
+		return nil, err
+	}
+
+	return billOwnerModelToDTO(&model), nil
+}
+
+func (r *BillOwnerRepository) FindAll(ctx context.Context, filter *dto.ListBillOwnersRequest) ([]*dto.BillOwner, error) {
+	query := r.filteredQuery(ctx, filter).Order("id")
+	if filter.PageSize > 0 {
+		query = query.Limit(filter.PageSize).Offset((filter.Page - 1) * filter.PageSize)
+	}
+
+	var models []billOwnerModel
+	if err := query.Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	owners := make([]*dto.BillOwner, len(models))
+	for i := range models {
+		owners[i] = billOwnerModelToDTO(&models[i])
+	}
+
+	return owners, nil
+}
+
+func (r *BillOwnerRepository) Count(ctx context.Context, filter *dto.ListBillOwnersRequest) (int, error) {
+	var total int64
+	if err := r.filteredQuery(ctx, filter).Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	return int(total), nil
+}
+
+func (r *BillOwnerRepository) filteredQuery(ctx context.Context, filter *dto.ListBillOwnersRequest) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&billOwnerModel{}).Where("deleted_at IS NULL")
+
+	if filter.DocumentNumber != "" {
+		// The search only ever matches the start of the document, which the pattern index covers
+		query = query.Where("id LIKE ?", escapeLike(filter.DocumentNumber)+"%")
+	}
+
+	return query
+}
+
+// escapeLike keeps the wildcards a document could contain from matching other documents
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+func billOwnerToModel(owner *dto.BillOwner) *billOwnerModel {
+	identificationType := string(owner.IdentificationType)
+	return &billOwnerModel{
+		ID:                     owner.ID,
+		Celphone:               owner.Celphone,
+		Email:                  owner.Email,
+		Name:                   owner.Name,
+		IdentificationType:     &identificationType,
+		Address:                owner.Address,
+		City:                   owner.City,
+		MunicipalityCode:       owner.MunicipalityCode,
+		Department:             owner.Department,
+		TaxRegime:              lo.EmptyableToPtr(string(owner.TaxRegime)),
+		FiscalResponsibilities: joinResponsibilities(owner.FiscalResponsibilities),
+		CreatedAt:              owner.CreatedAt,
+		UpdatedAt:              owner.UpdatedAt,
+	}
+}
+
+// customerToBillOwnerModel keeps the customer a bill was issued to, empty fields are left
+// nil so they do not overwrite the data already stored
+func customerToBillOwnerModel(customer *dto.Customer, now time.Time) *billOwnerModel {
+	identificationType := string(customer.DocumentType)
+	return &billOwnerModel{
+		ID:                     customer.DocumentNumber,
+		Celphone:               lo.EmptyableToPtr(customer.Telephone),
+		Email:                  customer.Email,
+		Name:                   customer.Name,
+		IdentificationType:     &identificationType,
+		Address:                lo.EmptyableToPtr(customer.Address),
+		City:                   lo.EmptyableToPtr(customer.City),
+		MunicipalityCode:       lo.EmptyableToPtr(customer.MunicipalityCode),
+		Department:             lo.EmptyableToPtr(customer.Department),
+		TaxRegime:              lo.EmptyableToPtr(string(customer.TaxRegime)),
+		FiscalResponsibilities: joinResponsibilities(customer.FiscalResponsibilities),
+		CreatedAt:              now,
+		UpdatedAt:              now,
+	}
+}
+
+func billOwnerModelToDTO(model *billOwnerModel) *dto.BillOwner {
+	return &dto.BillOwner{
+		ID:                     model.ID,
+		Celphone:               model.Celphone,
+		Email:                  model.Email,
+		Name:                   model.Name,
+		IdentificationType:     dto.DocumentType(lo.FromPtr(model.IdentificationType)),
+		Address:                model.Address,
+		City:                   model.City,
+		MunicipalityCode:       model.MunicipalityCode,
+		Department:             model.Department,
+		TaxRegime:              dto.TaxRegime(lo.FromPtr(model.TaxRegime)),
+		FiscalResponsibilities: splitResponsibilities(model.FiscalResponsibilities),
+		CreatedAt:              model.CreatedAt,
+		UpdatedAt:              model.UpdatedAt,
+	}
+}
+
+func billOwnerModelToCustomer(model *billOwnerModel) *dto.Customer {
+	return &dto.Customer{
+		DocumentNumber:         model.ID,
+		DocumentType:           dto.DocumentType(lo.FromPtr(model.IdentificationType)),
+		Name:                   model.Name,
+		Email:                  model.Email,
+		Address:                lo.FromPtr(model.Address),
+		City:                   lo.FromPtr(model.City),
+		MunicipalityCode:       lo.FromPtr(model.MunicipalityCode),
+		Department:             lo.FromPtr(model.Department),
+		Telephone:              lo.FromPtr(model.Celphone),
+		TaxRegime:              dto.TaxRegime(lo.FromPtr(model.TaxRegime)),
+		FiscalResponsibilities: splitResponsibilities(model.FiscalResponsibilities),
+	}
+}
+
+func joinResponsibilities(responsibilities []dto.FiscalResponsibility) *string {
+	if len(responsibilities) == 0 {
+		return nil
+	}
+
+	joined := strings.Join(lo.Map(responsibilities, func(responsibility dto.FiscalResponsibility, _ int) string {
+		return string(responsibility)
+	}), ";")
+	return &joined
+}
+
+func splitResponsibilities(joined *string) []dto.FiscalResponsibility {
+	if joined == nil || *joined == "" {
+		return nil
+	}
+
+	return lo.Map(strings.Split(*joined, ";"), func(responsibility string, _ int) dto.FiscalResponsibility {
+		return dto.FiscalResponsibility(responsibility)
+	})
+}
@@ -2,150 +2,460 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"laguna-escondida/backend/internal/domain/aggregate/bill"
 	"laguna-escondida/backend/internal/domain/dto"
+	orderError "laguna-escondida/backend/internal/domain/error"
 	"laguna-escondida/backend/internal/domain/ports"
-	"laguna-escondida/backend/internal/platform/shared/constants"
 	"time"
 
+	"github.com/samber/lo"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
 type BillRepository struct {
-	db                      *gorm.DB
-	electronicInvoiceClient ports.ElectronicInvoiceClient
+	db *gorm.DB
 }
 
-func NewBillRepository(db *gorm.DB, electronicInvoiceClient ports.ElectronicInvoiceClient) ports.BillRepository {
-	return &BillRepository{db: db, electronicInvoiceClient: electronicInvoiceClient}
+func NewBillRepository(db *gorm.DB) ports.BillRepository {
+	return &BillRepository{db: db}
 }
 
-func (r *BillRepository) GetNextConsecutive(ctx context.Context, prefix string) (int, error) {
+// nextConsecutive takes the next number of the prefix sequence in invoice_sequences,
+// failing when the sequence does not exist instead of numbering the document 0
+func nextConsecutive(ctx context.Context, db *gorm.DB, prefix string) (int, error) {
 	var lastConsecutive int
-	err := r.db.WithContext(ctx).
+	err := db.WithContext(ctx).
 		Raw("UPDATE invoice_sequences SET last_consecutive = last_consecutive + 1 WHERE prefix = ? RETURNING last_consecutive", prefix).
-		Scan(&lastConsecutive).Error
+		Row().
+		Scan(&lastConsecutive)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("invoice sequence %s does not exist", prefix)
+		}
 		return 0, err
 	}
 	return lastConsecutive, nil
 }
 
 func (r *BillRepository) Create(ctx context.Context, bill *bill.Aggregate, products []*dto.Product) error {
-	consecutive, err := r.GetNextConsecutive(ctx, constants.InvoicePrefix)
-	if err != nil {
-		return err
+	billDTO := bill.ToDTO()
+
+	kind := dto.NumberingResolutionKindElectronic
+	if billDTO.ContingencyPeriodID != nil {
+		kind = dto.NumberingResolutionKindContingency
 	}
 
-	billDTO := bill.ToDTO()
+	var resolution *numberingResolutionModel
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		resolution, err = takeInvoiceNumber(tx, kind, time.Now())
+		if err != nil {
+			return err
+		}
 
-	var response *dto.CreateElectronicInvoiceResponse
-	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		billModel := &billModel{
-			ID:             billDTO.ID,
-			TotalAmount:    billDTO.TotalAmount,
-			DiscountAmount: billDTO.DiscountAmount,
-			VAT:            billDTO.VAT,
-			ICO:            billDTO.ICO,
-			Tip:            billDTO.Tip,
-			DocumentURL:    billDTO.DocumentURL,
-			CreatedAt:      billDTO.CreatedAt,
-			UpdatedAt:      billDTO.UpdatedAt,
+			ID:                  billDTO.ID,
+			ContingencyPeriodID: billDTO.ContingencyPeriodID,
+			BillOwnerID:         customerID(billDTO.Customer),
+			TotalAmount:         billDTO.TotalAmount,
+			DiscountAmount:      billDTO.DiscountAmount,
+			VAT:                 billDTO.VAT,
+			ICO:                 billDTO.ICO,
+			Tip:                 billDTO.Tip,
+			Prefix:              &resolution.Prefix,
+			Consecutive:         &resolution.LastConsecutive,
+			DocumentURL:         billDTO.DocumentURL,
+			CreatedAt:           billDTO.CreatedAt,
+			UpdatedAt:           billDTO.UpdatedAt,
 		}
 
 		if err := tx.Create(billModel).Error; err != nil {
 			return err
 		}
 
-		for _, product := range products {
-			billProduct := &billProductModel{
-				BillID:    billModel.ID,
-				ProductID: product.ID,
-				CreatedAt: time.Now(),
-				UpdatedAt: time.Now(),
+		// The order is paid with the bill, a bill is never saved for an order left in paying
+		if openBillID := bill.OpenBillID(); openBillID != "" {
+			result := tx.Model(&openBillModel{}).
+				Where("id = ? AND status = ? AND deleted_at IS NULL", openBillID, dto.OpenBillStatusPaying).
+				Updates(map[string]any{
+					"status":     dto.OpenBillStatusPaid,
+					"bill_id":    billModel.ID,
+					"updated_at": time.Now(),
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return orderError.ErrInvalidOrderStatus
+			}
+		}
+
+		for i, product := range bill.Products() {
+			billProduct, err := billProductSnapshot(billModel.ID, i+1, product)
+			if err != nil {
+				return err
 			}
 			if err := tx.Create(billProduct).Error; err != nil {
 				return err
 			}
 		}
 
-		if billDTO.Customer != nil {
-			identificationType := string(billDTO.Customer.DocumentType)
-			now := time.Now()
-			billOwner := &billOwnerModel{
-				ID:                 billDTO.Customer.DocumentNumber,
-				Email:              billDTO.Customer.Email,
-				Name:               billDTO.Customer.Name,
-				IdentificationType: &identificationType,
-				CreatedAt:          now,
-				UpdatedAt:          now,
+		for _, payment := range billDTO.Payments {
+			var reference *string
+			if payment.Reference != "" {
+				reference = &payment.Reference
+			}
+
+			billPayment := &billPaymentModel{
+				BillID:       billModel.ID,
+				Method:       string(payment.Method),
+				Amount:       payment.Amount,
+				ChangeAmount: payment.Change,
+				Reference:    reference,
+				CreatedAt:    time.Now(),
 			}
+			if err := tx.Create(billPayment).Error; err != nil {
+				return err
+			}
+		}
+
+		if billDTO.Customer != nil {
+			billOwner := customerToBillOwnerModel(billDTO.Customer, time.Now())
 
+			// Fiscal data left out of the sale keeps the one stored, for instance when the
+			// cashier only typed the document of a registered customer
 			if err := tx.Clauses(clause.OnConflict{
 				Columns: []clause.Column{{Name: "id"}},
 				DoUpdates: clause.Assignments(map[string]any{
-					"email":               billOwner.Email,
-					"name":                billOwner.Name,
-					"identification_type": billOwner.IdentificationType,
-					"updated_at":          now,
+					"email":                   billOwner.Email,
+					"name":                    billOwner.Name,
+					"identification_type":     billOwner.IdentificationType,
+					"celphone":                gorm.Expr("COALESCE(EXCLUDED.celphone, bill_owners.celphone)"),
+					"address":                 gorm.Expr("COALESCE(EXCLUDED.address, bill_owners.address)"),
+					"city":                    gorm.Expr("COALESCE(EXCLUDED.city, bill_owners.city)"),
+					"municipality_code":       gorm.Expr("COALESCE(EXCLUDED.municipality_code, bill_owners.municipality_code)"),
+					"department":              gorm.Expr("COALESCE(EXCLUDED.department, bill_owners.department)"),
+					"tax_regime":              gorm.Expr("COALESCE(EXCLUDED.tax_regime, bill_owners.tax_regime)"),
+					"fiscal_responsibilities": gorm.Expr("COALESCE(EXCLUDED.fiscal_responsibilities, bill_owners.fiscal_responsibilities)"),
+					"updated_at":              billOwner.UpdatedAt,
+					"deleted_at":              nil,
 				}),
 			}).Create(billOwner).Error; err != nil {
 				return err
 			}
 		}
 
-		req := &dto.CreateElectronicInvoiceRequest{
-			// Prefix:      constants.InvoicePrefix,
-			Prefix:      "SETP",
-			Consecutive: consecutive,
+		// The invoice is sent by the outbox dispatcher once the bill is committed
+		return enqueueInvoice(tx, &dto.CreateElectronicInvoiceRequest{
+			Prefix:      resolution.Prefix,
+			Consecutive: resolution.LastConsecutive,
 			PaymentCode: bill.PaymentCode(),
 			Bill:        billDTO,
 			Products:    products,
+			Contingency: billDTO.ContingencyPeriodID != nil,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	bill.AssignNumber(resolution.Prefix, resolution.LastConsecutive, resolution.TechnicalKey)
+	return nil
+}
+
+func (r *BillRepository) FindByID(ctx context.Context, id string) (*dto.Bill, error) {
+	var model billModel
+	if err := r.db.WithContext(ctx).Where("id = ? AND deleted_at IS NULL", id).First(&model).Error; err != nil {
+		return nil, err
+	}
+
+	bills, err := r.billsWithDetails(ctx, []billModel{model})
+	if err != nil {
+		return nil, err
+	}
+
+	return bills[0], nil
+}
+
+func (r *BillRepository) FindAll(ctx context.Context, filter *dto.ListBillsRequest) ([]*dto.Bill, error) {
+	query := r.filteredQuery(ctx, filter).Order("created_at DESC, id")
+	if filter.PageSize > 0 {
+		query = query.Limit(filter.PageSize).Offset((filter.Page - 1) * filter.PageSize)
+	}
+
+	var models []billModel
+	if err := query.Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	return r.billsWithDetails(ctx, models)
+}
+
+func (r *BillRepository) Count(ctx context.Context, filter *dto.ListBillsRequest) (int, error) {
+	var total int64
+	if err := r.filteredQuery(ctx, filter).Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	return int(total), nil
+}
+
+func (r *BillRepository) filteredQuery(ctx context.Context, filter *dto.ListBillsRequest) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&billModel{}).Where("deleted_at IS NULL")
+
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("created_at <= ?", *filter.CreatedTo)
+	}
+	if len(filter.PaymentMethods) > 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM bill_payments WHERE bill_payments.bill_id = bills.id AND bill_payments.method IN ?)", filter.PaymentMethods)
+	}
+	if filter.CustomerID != "" {
+		query = query.Where("bill_owner_id = ?", filter.CustomerID)
+	}
+	if len(filter.Statuses) > 0 {
+		// Bills not sent yet or without an answer from the DIAN have no status stored
+		if lo.Contains(filter.Statuses, dto.ElectronicInvoiceDocumentStatusPending) {
+			query = query.Where("dian_status IN ? OR dian_status IS NULL", filter.Statuses)
+		} else {
+			query = query.Where("dian_status IN ?", filter.Statuses)
 		}
+	}
+
+	return query
+}
 
-		apiResponse, err := r.electronicInvoiceClient.Create(ctx, req)
+// billProductSnapshot stores the line with the price, taxes and allowances it was billed with
+func billProductSnapshot(billID string, lineNumber int, product *bill.BillProduct) (*billProductModel, error) {
+	allowance := product.Allowance()
+	if allowance == nil {
+		allowance = []dto.InvoiceAllowance{}
+	}
+
+	allowances, err := json.Marshal(allowance)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &billProductModel{
+		BillID:      billID,
+		ProductID:   product.ID(),
+		LineNumber:  lineNumber,
+		Quantity:    product.Quantity(),
+		UnitPrice:   product.UnitPrice(),
+		Description: product.Description(),
+		Brand:       product.Brand(),
+		Model:       product.Model(),
+		Code:        product.Code(),
+		VATRate:     product.VATRate(),
+		ICORate:     product.ICORate(),
+		VATAmount:   product.TaxAmount(dto.TaxCodeVAT),
+		ICOAmount:   product.TaxAmount(dto.TaxCodeICO),
+		Allowances:  string(allowances),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// billProductFromSnapshot rebuilds the line with the exact amounts it was billed with
+func billProductFromSnapshot(model *billProductModel) (*bill.BillProduct, error) {
+	var allowances []dto.InvoiceAllowance
+	if err := json.Unmarshal([]byte(model.Allowances), &allowances); err != nil {
+		return nil, err
+	}
+	if len(allowances) == 0 {
+		allowances = nil
+	}
+
+	return bill.NewBillProductWithTaxes(model.ProductID, model.Quantity, model.UnitPrice, model.Description, model.Brand, model.Model, model.Code, allowances, model.VATRate, model.ICORate, model.VATAmount, model.ICOAmount), nil
+}
+
+// billsWithDetails maps the bills and loads their products, payments and customers with
+// one query each, whatever the number of bills
+func (r *BillRepository) billsWithDetails(ctx context.Context, models []billModel) ([]*dto.Bill, error) {
+	bills := make([]*dto.Bill, len(models))
+	billsByID := make(map[string]*dto.Bill, len(models))
+	ownerIDs := []string{}
+	for i := range models {
+		bills[i] = billModelToDTO(&models[i])
+		billsByID[models[i].ID] = bills[i]
+		if models[i].BillOwnerID != nil {
+			ownerIDs = append(ownerIDs, *models[i].BillOwnerID)
+		}
+	}
+
+	if len(bills) == 0 {
+		return bills, nil
+	}
+	billIDs := lo.Keys(billsByID)
+
+	var productModels []billProductModel
+	if err := r.db.WithContext(ctx).
+		Where("bill_id IN ? AND deleted_at IS NULL", billIDs).
+		Order("bill_id, line_number").
+		Find(&productModels).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range productModels {
+		product, err := billProductFromSnapshot(&productModels[i])
 		if err != nil {
-			return err
+			return nil, err
 		}
-		response = apiResponse
+		billDTO := billsByID[productModels[i].BillID]
+		billDTO.Products = append(billDTO.Products, product.ToDTO())
+	}
 
-		return nil
-	})
+	var paymentModels []billPaymentModel
+	if err := r.db.WithContext(ctx).Where("bill_id IN ?", billIDs).Order("created_at, id").Find(&paymentModels).Error; err != nil {
+		return nil, err
+	}
 
-	if err != nil {
-		return err
+	for _, model := range paymentModels {
+		billDTO := billsByID[model.BillID]
+		billDTO.Payments = append(billDTO.Payments, dto.Payment{
+			Method:    dto.ElectronicInvoicePaymentCode(model.Method),
+			Amount:    model.Amount,
+			Reference: lo.FromPtr(model.Reference),
+			Change:    model.ChangeAmount,
+		})
+		billDTO.Change = billDTO.Change.Add(model.ChangeAmount)
 	}
 
-	if response != nil {
-		if err := r.db.WithContext(ctx).Model(&billModel{}).
-			Where("id = ?", billDTO.ID).
-			Updates(map[string]any{
-				"cufe":    response.CUFE,
-				"tascode": response.Tascode,
-			}).Error; err != nil {
-			return err
+	if len(ownerIDs) == 0 {
+		return bills, nil
+	}
+
+	var ownerModels []billOwnerModel
+	if err := r.db.WithContext(ctx).Where("id IN ?", lo.Uniq(ownerIDs)).Find(&ownerModels).Error; err != nil {
+		return nil, err
+	}
+
+	customers := lo.SliceToMap(ownerModels, func(model billOwnerModel) (string, *dto.Customer) {
+		return model.ID, billOwnerModelToCustomer(&model)
+	})
+	for i := range models {
+		if models[i].BillOwnerID != nil {
+			bills[i].Customer = customers[*models[i].BillOwnerID]
 		}
 	}
 
-	return nil
+	return bills, nil
 }
 
-func (r *BillRepository) FindByID(ctx context.Context, id string) (*dto.Bill, error) {
-	var billModel billModel
-	if err := r.db.WithContext(ctx).Where("id = ? AND deleted_at IS NULL", id).First(&billModel).Error; err != nil {
+// customerID is the bill owner a bill is issued to, nil for the final consumer
+func customerID(customer *dto.Customer) *string {
+	if customer == nil {
+		return nil
+	}
+
+	return &customer.DocumentNumber
+}
+
+func (r *BillRepository) FindPendingStatus(ctx context.Context, limit int) ([]*dto.Bill, error) {
+	var billModels []billModel
+	// The least recently checked bills go first so a bill the provider keeps failing on
+	// does not starve the others
+	if err := r.db.WithContext(ctx).
+		Where("tascode IS NOT NULL AND tascode <> '' AND deleted_at IS NULL").
+		Where("dian_status IS NULL OR dian_status = ?", string(dto.ElectronicInvoiceDocumentStatusPending)).
+		Order("updated_at").
+		Limit(limit).
+		Find(&billModels).Error; err != nil {
 		return nil, err
 	}
 
+	bills := make([]*dto.Bill, len(billModels))
+	for i := range billModels {
+		bills[i] = billModelToDTO(&billModels[i])
+	}
+
+	return bills, nil
+}
+
+func billModelToDTO(billModel *billModel) *dto.Bill {
+	taxAmount := billModel.VAT.Add(billModel.ICO)
+
 	return &dto.Bill{
-		ID:             billModel.ID,
-		TotalAmount:    billModel.TotalAmount,
-		DiscountAmount: billModel.DiscountAmount,
-		VAT:            billModel.VAT,
-		ICO:            billModel.ICO,
-		Tip:            billModel.Tip,
-		DocumentURL:    billModel.DocumentURL,
-		CreatedAt:      billModel.CreatedAt,
-		UpdatedAt:      billModel.UpdatedAt,
-	}, nil
+		ID:                  billModel.ID,
+		Prefix:              lo.FromPtr(billModel.Prefix),
+		Consecutive:         lo.FromPtr(billModel.Consecutive),
+		CUFE:                billModel.CUFE,
+		ExpectedCUFE:        billModel.ExpectedCUFE,
+		CUFEMismatch:        billModel.CUFEMismatch,
+		Tascode:             billModel.Tascode,
+		ContingencyPeriodID: billModel.ContingencyPeriodID,
+		DIANStatus:          dto.ElectronicInvoiceDocumentStatus(lo.FromPtr(billModel.DIANStatus)),
+		TotalAmount:         billModel.TotalAmount,
+		DiscountAmount:      billModel.DiscountAmount,
+		TaxAmount:           taxAmount,
+		PayAmount:           billModel.TotalAmount.Add(taxAmount).Sub(billModel.DiscountAmount).Add(billModel.Tip),
+		VAT:                 billModel.VAT,
+		ICO:                 billModel.ICO,
+		Tip:                 billModel.Tip,
+		DocumentURL:         billModel.DocumentURL,
+		CreatedAt:           billModel.CreatedAt,
+		UpdatedAt:           billModel.UpdatedAt,
+	}
+}
+
+func (r *BillRepository) FindProductsByID(ctx context.Context, id string) ([]*bill.BillProduct, error) {
+	var productModels []billProductModel
+	if err := r.db.WithContext(ctx).Where("bill_id = ? AND deleted_at IS NULL", id).Order("line_number").Find(&productModels).Error; err != nil {
+		return nil, err
+	}
+
+	products := make([]*bill.BillProduct, len(productModels))
+	for i := range productModels {
+		product, err := billProductFromSnapshot(&productModels[i])
+		if err != nil {
+			return nil, err
+		}
+		products[i] = product
+	}
+
+	return products, nil
+}
+
+func (r *BillRepository) UpdateDocumentStatus(ctx context.Context, id string, status *dto.ElectronicInvoiceStatus) error {
+	updates := map[string]any{
+		"dian_status": string(status.Status),
+		"updated_at":  time.Now(),
+	}
+
+	// Documents are only stored once the provider has them, a pending status keeps the previous ones
+	if status.CUFE != "" {
+		updates["cufe"] = status.CUFE
+		updates["cufe_mismatch"] = gorm.Expr("COALESCE(expected_cufe <> ?, FALSE)", status.CUFE)
+	}
+	if status.DocumentURL != "" {
+		updates["document_url"] = status.DocumentURL
+	}
+	if status.PDF != "" {
+		updates["pdf"] = status.PDF
+	}
+	if status.XML != "" {
+		updates["xml"] = status.XML
+	}
+
+	result := r.db.WithContext(ctx).Model(&billModel{}).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
 }
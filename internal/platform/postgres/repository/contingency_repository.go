@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"laguna-escondida/backend/internal/domain/dto"
+	domainError "laguna-escondida/backend/internal/domain/error"
+	"laguna-escondida/backend/internal/domain/ports"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type contingencyPeriodModel struct {
+	ID        string     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Reason    string     `gorm:"type:text;not null"`
+	Automatic bool       `gorm:"type:boolean;not null;default:false"`
+	StartedAt time.Time  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+	EndedAt   *time.Time `gorm:"type:timestamp"`
+}
+
+func (contingencyPeriodModel) TableName() string {
+	return "contingency_periods"
+}
+
+// contingencyPeriodRow is a period with the counts of the bills issued in it
+type contingencyPeriodRow struct {
+	contingencyPeriodModel
+	BillCount    int
+	PendingCount int
+}
+
+type ContingencyRepository struct {
+	db *gorm.DB
+}
+
+func NewContingencyRepository(db *gorm.DB) ports.ContingencyRepository {
+	return &ContingencyRepository{db: db}
+}
+
+func (r *ContingencyRepository) FindActive(ctx context.Context) (*dto.ContingencyPeriod, error) {
+	var rows []contingencyPeriodRow
+	if err := r.periodsQuery(ctx).Where("contingency_periods.ended_at IS NULL").Limit(1).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	return contingencyPeriodRowToDTO(&rows[0]), nil
+}
+
+func (r *ContingencyRepository) Start(ctx context.Context, reason string, automatic bool, now time.Time) (*dto.ContingencyPeriod, error) {
+	model := &contingencyPeriodModel{
+		Reason:    reason,
+		Automatic: automatic,
+		StartedAt: now,
+	}
+
+	// The unique index on open periods skips a second one started concurrently
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(model)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, domainError.ErrContingencyAlreadyActive
+	}
+
+	return contingencyPeriodRowToDTO(&contingencyPeriodRow{contingencyPeriodModel: *model}), nil
+}
+
+func (r *ContingencyRepository) End(ctx context.Context, id string, now time.Time) error {
+	result := r.db.WithContext(ctx).Model(&contingencyPeriodModel{}).
+		Where("id = ? AND ended_at IS NULL", id).
+		Update("ended_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domainError.ErrContingencyNotActive
+	}
+
+	return nil
+}
+
+func (r *ContingencyRepository) FindAll(ctx context.Context) ([]*dto.ContingencyPeriod, error) {
+	var rows []contingencyPeriodRow
+	if err := r.periodsQuery(ctx).Order("contingency_periods.started_at DESC").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	periods := make([]*dto.ContingencyPeriod, len(rows))
+	for i := range rows {
+		periods[i] = contingencyPeriodRowToDTO(&rows[i])
+	}
+
+	return periods, nil
+}
+
+// periodsQuery selects the periods with how many bills were issued in each and how many of
+// them still have no CUFE
+func (r *ContingencyRepository) periodsQuery(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&contingencyPeriodModel{}).
+		Select(`contingency_periods.*,
+			COUNT(bills.id) AS bill_count,
+			COUNT(bills.id) FILTER (WHERE bills.cufe IS NULL OR bills.cufe = '') AS pending_count`).
+		Joins("LEFT JOIN bills ON bills.contingency_period_id = contingency_periods.id AND bills.deleted_at IS NULL").
+		Group("contingency_periods.id")
+}
+
+func contingencyPeriodRowToDTO(row *contingencyPeriodRow) *dto.ContingencyPeriod {
+	return &dto.ContingencyPeriod{
+		ID:           row.ID,
+		Reason:       row.Reason,
+		Automatic:    row.Automatic,
+		StartedAt:    row.StartedAt,
+		EndedAt:      row.EndedAt,
+		BillCount:    row.BillCount,
+		PendingCount: row.PendingCount,
+	}
+}
@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"laguna-escondida/backend/internal/domain/aggregate/bill"
+	"laguna-escondida/backend/internal/domain/dto"
+	domainError "laguna-escondida/backend/internal/domain/error"
+	"laguna-escondida/backend/internal/domain/ports"
+	"laguna-escondida/backend/internal/platform/shared/constants"
+
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CreditNoteRepository struct {
+	db *gorm.DB
+}
+
+func NewCreditNoteRepository(db *gorm.DB) ports.CreditNoteRepository {
+	return &CreditNoteRepository{db: db}
+}
+
+type creditNoteModel struct {
+	ID             string     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	BillID         string     `gorm:"type:uuid;not null"`
+	Prefix         string     `gorm:"type:varchar(10);not null"`
+	Consecutive    int        `gorm:"type:integer;not null"`
+	Reason         string     `gorm:"type:varchar(50);not null"`
+	Description    string     `gorm:"type:text;not null"`
+	TotalAmount    dto.Money  `gorm:"type:numeric(14,2);not null;column:total_amount"`
+	DiscountAmount dto.Money  `gorm:"type:numeric(14,2);not null;default:0;column:discount_amount"`
+	VAT            dto.Money  `gorm:"type:numeric(14,2);not null"`
+	ICO            dto.Money  `gorm:"type:numeric(14,2);not null"`
+	Tip            dto.Money  `gorm:"type:numeric(14,2);not null"`
+	CUFE           *string    `gorm:"type:varchar(255)"`
+	Tascode        *string    `gorm:"type:varchar(255)"`
+	CreatedAt      time.Time  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt      time.Time  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+	DeletedAt      *time.Time `gorm:"type:timestamp"`
+}
+
+func (creditNoteModel) TableName() string {
+	return "credit_notes"
+}
+
+type creditNoteProductModel struct {
+	ID           string    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	CreditNoteID string    `gorm:"type:uuid;not null"`
+	ProductID    string    `gorm:"type:uuid;not null"`
+	Quantity     int       `gorm:"type:integer;not null"`
+	UnitPrice    dto.Money `gorm:"type:numeric(14,2);not null;column:unit_price"`
+	CreatedAt    time.Time `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (creditNoteProductModel) TableName() string {
+	return "credit_note_products"
+}
+
+// Create numbers the credit note within its transaction, so a failed note does not burn a
+// number, and queues it in the outbox that sends it once committed
+func (r *CreditNoteRepository) Create(ctx context.Context, creditNote *bill.CreditNote, original *dto.Bill) error {
+	creditNoteDTO := creditNote.ToDTO()
+	creditNoteDTO.Prefix = constants.CreditNotePrefix
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkCreditable(tx, creditNoteDTO); err != nil {
+			return err
+		}
+
+		consecutive, err := nextConsecutive(ctx, tx, constants.CreditNotePrefix)
+		if err != nil {
+			return err
+		}
+		creditNoteDTO.Consecutive = consecutive
+
+		model := &creditNoteModel{
+			ID:             creditNoteDTO.ID,
+			BillID:         creditNoteDTO.BillID,
+			Prefix:         creditNoteDTO.Prefix,
+			Consecutive:    creditNoteDTO.Consecutive,
+			Reason:         string(creditNoteDTO.Reason),
+			Description:    creditNoteDTO.Description,
+			TotalAmount:    creditNoteDTO.TotalAmount,
+			DiscountAmount: creditNoteDTO.DiscountAmount,
+			VAT:            creditNoteDTO.VAT,
+			ICO:            creditNoteDTO.ICO,
+			Tip:            creditNoteDTO.Tip,
+			CreatedAt:      creditNoteDTO.CreatedAt,
+			UpdatedAt:      creditNoteDTO.UpdatedAt,
+		}
+
+		if err := tx.Create(model).Error; err != nil {
+			return err
+		}
+
+		for _, product := range creditNoteDTO.Products {
+			productModel := &creditNoteProductModel{
+				CreditNoteID: model.ID,
+				ProductID:    product.ProductID,
+				Quantity:     product.Quantity,
+				UnitPrice:    product.UnitPrice,
+				CreatedAt:    time.Now(),
+			}
+			if err := tx.Create(productModel).Error; err != nil {
+				return err
+			}
+		}
+
+		return enqueueCreditNote(tx, &dto.CreateElectronicCreditNoteRequest{
+			Prefix:      creditNoteDTO.Prefix,
+			Consecutive: creditNoteDTO.Consecutive,
+			Bill:        original,
+			CreditNote:  creditNoteDTO,
+		})
+	})
+}
+
+type productQuantity struct {
+	ProductID string
+	Quantity  int
+}
+
+// checkCreditable locks the bill and fails when the credit note credits more of it than its
+// previous credit notes left, so concurrent credit notes cannot both credit the same amount
+func checkCreditable(tx *gorm.DB, creditNote *dto.CreditNote) error {
+	var original billModel
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND deleted_at IS NULL", creditNote.BillID).
+		First(&original).Error; err != nil {
+		return err
+	}
+
+	var credited dto.Money
+	if err := tx.Model(&creditNoteModel{}).
+		Select("COALESCE(SUM(total_amount + vat + ico - discount_amount + tip), 0)").
+		Where("bill_id = ? AND deleted_at IS NULL", creditNote.BillID).
+		Scan(&credited).Error; err != nil {
+		return err
+	}
+
+	payAmount := original.TotalAmount.Add(original.VAT).Add(original.ICO).Sub(original.DiscountAmount).Add(original.Tip)
+	if creditable := payAmount.Sub(credited); creditNote.PayAmount > creditable {
+		return fmt.Errorf("%w: only %s of the bill is left to credit", domainError.ErrInvalidCreditNote, creditable)
+	}
+
+	var billed []productQuantity
+	if err := tx.Model(&billProductModel{}).
+		Select("product_id, SUM(quantity) AS quantity").
+		Where("bill_id = ? AND deleted_at IS NULL", creditNote.BillID).
+		Group("product_id").
+		Scan(&billed).Error; err != nil {
+		return err
+	}
+
+	var creditedProducts []productQuantity
+	if err := tx.Model(&creditNoteProductModel{}).
+		Select("credit_note_products.product_id, SUM(credit_note_products.quantity) AS quantity").
+		Joins("JOIN credit_notes ON credit_notes.id = credit_note_products.credit_note_id").
+		Where("credit_notes.bill_id = ? AND credit_notes.deleted_at IS NULL", creditNote.BillID).
+		Group("credit_note_products.product_id").
+		Scan(&creditedProducts).Error; err != nil {
+		return err
+	}
+
+	available := make(map[string]int, len(billed))
+	for _, product := range billed {
+		available[product.ProductID] = product.Quantity
+	}
+	for _, product := range creditedProducts {
+		available[product.ProductID] -= product.Quantity
+	}
+
+	requested := make(map[string]int, len(creditNote.Products))
+	for _, product := range creditNote.Products {
+		requested[product.ProductID] += product.Quantity
+	}
+	for productID, quantity := range requested {
+		if quantity > available[productID] {
+			return fmt.Errorf("%w: product %s has only %d units left to credit", domainError.ErrInvalidCreditNote, productID, max(available[productID], 0))
+		}
+	}
+
+	return nil
+}
+
+func (r *CreditNoteRepository) FindByID(ctx context.Context, id string) (*dto.CreditNote, error) {
+	var model creditNoteModel
+	if err := r.db.WithContext(ctx).Where("id = ? AND deleted_at IS NULL", id).First(&model).Error; err != nil {
+		return nil, err
+	}
+
+	creditNotes, err := r.withProducts(ctx, []creditNoteModel{model})
+	if err != nil {
+		return nil, err
+	}
+
+	return creditNotes[0], nil
+}
+
+func (r *CreditNoteRepository) FindByBillID(ctx context.Context, billID string) ([]*dto.CreditNote, error) {
+	var models []creditNoteModel
+	if err := r.db.WithContext(ctx).Where("bill_id = ? AND deleted_at IS NULL", billID).Order("created_at").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	return r.withProducts(ctx, models)
+}
+
+func (r *CreditNoteRepository) withProducts(ctx context.Context, models []creditNoteModel) ([]*dto.CreditNote, error) {
+	if len(models) == 0 {
+		return []*dto.CreditNote{}, nil
+	}
+
+	var productModels []creditNoteProductModel
+	ids := lo.Map(models, func(model creditNoteModel, _ int) string {
+		return model.ID
+	})
+	if err := r.db.WithContext(ctx).Where("credit_note_id IN ?", ids).Order("created_at").Find(&productModels).Error; err != nil {
+		return nil, err
+	}
+
+	productsByCreditNote := lo.GroupBy(productModels, func(model creditNoteProductModel) string {
+		return model.CreditNoteID
+	})
+
+	creditNotes := make([]*dto.CreditNote, len(models))
+	for i, model := range models {
+		creditNotes[i] = r.toDTO(&model, productsByCreditNote[model.ID])
+	}
+
+	return creditNotes, nil
+}
+
+func (r *CreditNoteRepository) toDTO(model *creditNoteModel, products []creditNoteProductModel) *dto.CreditNote {
+	taxAmount := model.VAT.Add(model.ICO)
+
+	return &dto.CreditNote{
+		ID:             model.ID,
+		BillID:         model.BillID,
+		Prefix:         model.Prefix,
+		Consecutive:    model.Consecutive,
+		Reason:         dto.CreditNoteReason(model.Reason),
+		Description:    model.Description,
+		TotalAmount:    model.TotalAmount,
+		DiscountAmount: model.DiscountAmount,
+		TaxAmount:      taxAmount,
+		PayAmount:      model.TotalAmount.Add(taxAmount).Sub(model.DiscountAmount).Add(model.Tip),
+		VAT:            model.VAT,
+		ICO:            model.ICO,
+		Tip:            model.Tip,
+		CUFE:           model.CUFE,
+		Products: lo.Map(products, func(product creditNoteProductModel, _ int) dto.BillProduct {
+			return dto.BillProduct{
+				ProductID: product.ProductID,
+				Quantity:  product.Quantity,
+				UnitPrice: product.UnitPrice,
+			}
+		}),
+		CreatedAt: model.CreatedAt,
+		UpdatedAt: model.UpdatedAt,
+	}
+}
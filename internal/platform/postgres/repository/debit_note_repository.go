@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"laguna-escondida/backend/internal/domain/aggregate/bill"
+	"laguna-escondida/backend/internal/domain/dto"
+	"laguna-escondida/backend/internal/domain/ports"
+	"laguna-escondida/backend/internal/platform/shared/constants"
+
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+type DebitNoteRepository struct {
+	db *gorm.DB
+}
+
+func NewDebitNoteRepository(db *gorm.DB) ports.DebitNoteRepository {
+	return &DebitNoteRepository{db: db}
+}
+
+type debitNoteModel struct {
+	ID             string     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	BillID         string     `gorm:"type:uuid;not null"`
+	Prefix         string     `gorm:"type:varchar(10);not null"`
+	Consecutive    int        `gorm:"type:integer;not null"`
+	Reason         string     `gorm:"type:varchar(50);not null"`
+	Description    string     `gorm:"type:text;not null"`
+	TotalAmount    dto.Money  `gorm:"type:numeric(14,2);not null;column:total_amount"`
+	DiscountAmount dto.Money  `gorm:"type:numeric(14,2);not null;default:0;column:discount_amount"`
+	VAT            dto.Money  `gorm:"type:numeric(14,2);not null"`
+	ICO            dto.Money  `gorm:"type:numeric(14,2);not null"`
+	CUFE           *string    `gorm:"type:varchar(255)"`
+	Tascode        *string    `gorm:"type:varchar(255)"`
+	CreatedAt      time.Time  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt      time.Time  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+	DeletedAt      *time.Time `gorm:"type:timestamp"`
+}
+
+func (debitNoteModel) TableName() string {
+	return "debit_notes"
+}
+
+// debitNoteProductModel keeps what was charged on each line, charges outside the
+// catalog have no product
+type debitNoteProductModel struct {
+	ID          string    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	DebitNoteID string    `gorm:"type:uuid;not null"`
+	ProductID   *string   `gorm:"type:uuid"`
+	Description *string   `gorm:"type:text"`
+	Code        string    `gorm:"type:varchar(255);not null"`
+	Quantity    int       `gorm:"type:integer;not null"`
+	UnitPrice   dto.Money `gorm:"type:numeric(14,2);not null;column:unit_price"`
+	VAT         dto.Money `gorm:"type:numeric(14,2);not null"`
+	ICO         dto.Money `gorm:"type:numeric(14,2);not null"`
+	CreatedAt   time.Time `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (debitNoteProductModel) TableName() string {
+	return "debit_note_products"
+}
+
+// Create numbers the debit note within its transaction, so a failed note does not burn a
+// number, and queues it in the outbox that sends it once committed
+func (r *DebitNoteRepository) Create(ctx context.Context, debitNote *bill.DebitNote, original *dto.Bill) error {
+	debitNoteDTO := debitNote.ToDTO()
+	debitNoteDTO.Prefix = constants.DebitNotePrefix
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		consecutive, err := nextConsecutive(ctx, tx, constants.DebitNotePrefix)
+		if err != nil {
+			return err
+		}
+		debitNoteDTO.Consecutive = consecutive
+
+		model := &debitNoteModel{
+			ID:             debitNoteDTO.ID,
+			BillID:         debitNoteDTO.BillID,
+			Prefix:         debitNoteDTO.Prefix,
+			Consecutive:    debitNoteDTO.Consecutive,
+			Reason:         string(debitNoteDTO.Reason),
+			Description:    debitNoteDTO.Description,
+			TotalAmount:    debitNoteDTO.TotalAmount,
+			DiscountAmount: debitNoteDTO.DiscountAmount,
+			VAT:            debitNoteDTO.VAT,
+			ICO:            debitNoteDTO.ICO,
+			CreatedAt:      debitNoteDTO.CreatedAt,
+			UpdatedAt:      debitNoteDTO.UpdatedAt,
+		}
+
+		if err := tx.Create(model).Error; err != nil {
+			return err
+		}
+
+		for _, product := range debitNoteDTO.Products {
+			var vat, ico dto.Money
+			for _, tax := range product.Taxes {
+				switch tax.TaxCode {
+				case dto.TaxCodeVAT:
+					vat = vat.Add(tax.TaxAmount)
+				case dto.TaxCodeICO:
+					ico = ico.Add(tax.TaxAmount)
+				}
+			}
+
+			productModel := &debitNoteProductModel{
+				DebitNoteID: model.ID,
+				ProductID:   lo.EmptyableToPtr(product.ProductID),
+				Description: product.Description,
+				Code:        product.Code,
+				Quantity:    product.Quantity,
+				UnitPrice:   product.UnitPrice,
+				VAT:         vat,
+				ICO:         ico,
+				CreatedAt:   time.Now(),
+			}
+			if err := tx.Create(productModel).Error; err != nil {
+				return err
+			}
+		}
+
+		return enqueueDebitNote(tx, &dto.CreateElectronicDebitNoteRequest{
+			Prefix:      debitNoteDTO.Prefix,
+			Consecutive: debitNoteDTO.Consecutive,
+			Bill:        original,
+			DebitNote:   debitNoteDTO,
+		})
+	})
+}
+
+func (r *DebitNoteRepository) FindByID(ctx context.Context, id string) (*dto.DebitNote, error) {
+	var model debitNoteModel
+	if err := r.db.WithContext(ctx).Where("id = ? AND deleted_at IS NULL", id).First(&model).Error; err != nil {
+		return nil, err
+	}
+
+	var productModels []debitNoteProductModel
+	if err := r.db.WithContext(ctx).Where("debit_note_id = ?", id).Order("created_at").Find(&productModels).Error; err != nil {
+		return nil, err
+	}
+
+	taxAmount := model.VAT.Add(model.ICO)
+
+	return &dto.DebitNote{
+		ID:             model.ID,
+		BillID:         model.BillID,
+		Prefix:         model.Prefix,
+		Consecutive:    model.Consecutive,
+		Reason:         dto.DebitNoteReason(model.Reason),
+		Description:    model.Description,
+		TotalAmount:    model.TotalAmount,
+		DiscountAmount: model.DiscountAmount,
+		TaxAmount:      taxAmount,
+		PayAmount:      model.TotalAmount.Add(taxAmount).Sub(model.DiscountAmount),
+		VAT:            model.VAT,
+		ICO:            model.ICO,
+		CUFE:           model.CUFE,
+		Products: lo.Map(productModels, func(product debitNoteProductModel, _ int) dto.BillProduct {
+			return dto.BillProduct{
+				ProductID:   lo.FromPtr(product.ProductID),
+				Quantity:    product.Quantity,
+				UnitPrice:   product.UnitPrice,
+				Description: product.Description,
+				Code:        product.Code,
+			}
+		}),
+		CreatedAt: model.CreatedAt,
+		UpdatedAt: model.UpdatedAt,
+	}, nil
+}
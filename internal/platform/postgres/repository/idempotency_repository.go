@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"laguna-escondida/backend/internal/domain/dto"
+	"laguna-escondida/backend/internal/domain/ports"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type idempotencyKeyModel struct {
+	Scope          string    `gorm:"type:varchar(50);primaryKey"`
+	IdempotencyKey string    `gorm:"type:varchar(255);primaryKey"`
+	RequestHash    string    `gorm:"type:varchar(64);not null"`
+	Status         string    `gorm:"type:varchar(20);not null"`
+	ResponseStatus *int      `gorm:"type:integer"`
+	ContentType    *string   `gorm:"type:varchar(100)"`
+	ResponseBody   []byte    `gorm:"type:bytea"`
+	CreatedAt      time.Time `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+	ExpiresAt      time.Time `gorm:"type:timestamp;not null"`
+}
+
+func (idempotencyKeyModel) TableName() string {
+	return "idempotency_keys"
+}
+
+type IdempotencyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) ports.IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+func (r *IdempotencyRepository) Reserve(ctx context.Context, scope string, key string, requestHash string, now time.Time, expiresAt time.Time) (*dto.IdempotencyRecord, error) {
+	db := r.db.WithContext(ctx)
+
+	// The primary key lets only one of several concurrent requests insert the key
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&idempotencyKeyModel{
+		Scope:          scope,
+		IdempotencyKey: key,
+		RequestHash:    requestHash,
+		Status:         string(dto.IdempotencyStatusProcessing),
+		CreatedAt:      now,
+		ExpiresAt:      expiresAt,
+	})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return nil, nil
+	}
+
+	// An expired key is taken over as if it was new, the row lock makes only one request win.
+	// A key still processing expires with its lease, when the request holding it died
+	result = db.Model(&idempotencyKeyModel{}).
+		Where("scope = ? AND idempotency_key = ? AND expires_at <= ?", scope, key, now).
+		Updates(map[string]any{
+			"request_hash":    requestHash,
+			"status":          string(dto.IdempotencyStatusProcessing),
+			"response_status": nil,
+			"content_type":    nil,
+			"response_body":   nil,
+			"created_at":      now,
+			"expires_at":      expiresAt,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return nil, nil
+	}
+
+	var model idempotencyKeyModel
+	if err := db.Where("scope = ? AND idempotency_key = ?", scope, key).First(&model).Error; err != nil {
+		return nil, err
+	}
+
+	return idempotencyKeyModelToDTO(&model), nil
+}
+
+// Complete keeps the first response stored, a request that outlived its lease and whose key
+// was taken over does not replace the response of the request that took it
+func (r *IdempotencyRepository) Complete(ctx context.Context, scope string, key string, responseStatus int, contentType string, responseBody []byte, expiresAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&idempotencyKeyModel{}).
+		Where("scope = ? AND idempotency_key = ? AND status = ?", scope, key, string(dto.IdempotencyStatusProcessing)).
+		Updates(map[string]any{
+			"status":          string(dto.IdempotencyStatusCompleted),
+			"response_status": responseStatus,
+			"content_type":    contentType,
+			"response_body":   responseBody,
+			"expires_at":      expiresAt,
+		}).Error
+}
+
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	result := r.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&idempotencyKeyModel{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return int(result.RowsAffected), nil
+}
+
+func idempotencyKeyModelToDTO(model *idempotencyKeyModel) *dto.IdempotencyRecord {
+	record := &dto.IdempotencyRecord{
+		Scope:        model.Scope,
+		Key:          model.IdempotencyKey,
+		RequestHash:  model.RequestHash,
+		Status:       dto.IdempotencyStatus(model.Status),
+		ResponseBody: model.ResponseBody,
+		CreatedAt:    model.CreatedAt,
+		ExpiresAt:    model.ExpiresAt,
+	}
+	if model.ResponseStatus != nil {
+		record.ResponseStatus = *model.ResponseStatus
+	}
+	if model.ContentType != nil {
+		record.ContentType = *model.ContentType
+	}
+
+	return record
+}
@@ -0,0 +1,309 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"laguna-escondida/backend/internal/domain/dto"
+	"laguna-escondida/backend/internal/domain/ports"
+
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// invoiceOutboxLease is how long a claimed entry stays hidden from other dispatchers, well
+// over ELECTRONIC_INVOICE_TIMEOUT, which bounds each request to the provider, so an entry is
+// not sent twice at the same time. An entry claimed again after its lease expired is answered
+// by the provider with the document it already registered
+const invoiceOutboxLease = 2 * time.Minute
+
+type invoiceOutboxModel struct {
+	ID            string     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	DocumentType  string     `gorm:"type:varchar(20);not null;default:invoice"`
+	BillID        string     `gorm:"type:uuid;not null"`
+	NoteID        *string    `gorm:"type:uuid"`
+	Prefix        string     `gorm:"type:varchar(10);not null"`
+	Consecutive   int        `gorm:"type:integer;not null"`
+	Payload       string     `gorm:"type:jsonb;not null"`
+	Status        string     `gorm:"type:varchar(20);not null"`
+	Attempts      int        `gorm:"type:integer;not null;default:0"`
+	LastError     *string    `gorm:"type:text"`
+	NextAttemptAt time.Time  `gorm:"type:timestamp;not null"`
+	SentAt        *time.Time `gorm:"type:timestamp"`
+	CreatedAt     time.Time  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt     time.Time  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (invoiceOutboxModel) TableName() string {
+	return "invoice_outbox"
+}
+
+type InvoiceOutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewInvoiceOutboxRepository(db *gorm.DB) ports.InvoiceOutboxRepository {
+	return &InvoiceOutboxRepository{db: db}
+}
+
+// enqueueInvoice saves the invoice request in the outbox within the transaction of its bill.
+// prefix and consecutive are unique so a bill can never be queued twice
+func enqueueInvoice(tx *gorm.DB, req *dto.CreateElectronicInvoiceRequest) error {
+	return enqueueDocument(tx, dto.ElectronicDocumentTypeInvoice, req.Bill.ID, nil, req.Prefix, req.Consecutive, req)
+}
+
+// enqueueCreditNote saves the credit note request in the outbox within the transaction of the note
+func enqueueCreditNote(tx *gorm.DB, req *dto.CreateElectronicCreditNoteRequest) error {
+	return enqueueDocument(tx, dto.ElectronicDocumentTypeCreditNote, req.Bill.ID, &req.CreditNote.ID, req.Prefix, req.Consecutive, req)
+}
+
+// enqueueDebitNote saves the debit note request in the outbox within the transaction of the note
+func enqueueDebitNote(tx *gorm.DB, req *dto.CreateElectronicDebitNoteRequest) error {
+	return enqueueDocument(tx, dto.ElectronicDocumentTypeDebitNote, req.Bill.ID, &req.DebitNote.ID, req.Prefix, req.Consecutive, req)
+}
+
+func enqueueDocument(tx *gorm.DB, documentType dto.ElectronicDocumentType, billID string, noteID *string, prefix string, consecutive int, req any) error {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	return tx.Create(&invoiceOutboxModel{
+		DocumentType:  string(documentType),
+		BillID:        billID,
+		NoteID:        noteID,
+		Prefix:        prefix,
+		Consecutive:   consecutive,
+		Payload:       string(payload),
+		Status:        string(dto.InvoiceOutboxStatusPending),
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}).Error
+}
+
+// ClaimDue leases the due entries whose payload decodes. An entry whose payload does not
+// decode would fail the same way on every attempt, so its attempt is recorded and it goes
+// straight to the dead letter instead of failing the whole batch
+func (r *InvoiceOutboxRepository) ClaimDue(ctx context.Context, limit int) ([]*dto.InvoiceOutboxEntry, error) {
+	var entries []*dto.InvoiceOutboxEntry
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var models []invoiceOutboxModel
+		now := time.Now()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", string(dto.InvoiceOutboxStatusPending), now).
+			Order("next_attempt_at").
+			Limit(limit).
+			Find(&models).Error; err != nil {
+			return err
+		}
+
+		entries = make([]*dto.InvoiceOutboxEntry, 0, len(models))
+		ids := make([]string, 0, len(models))
+		for i := range models {
+			entry := invoiceOutboxModelToDTO(&models[i])
+			if err := decodeInvoiceOutboxPayload(entry, models[i].Payload); err != nil {
+				if err := tx.Model(&invoiceOutboxModel{}).
+					Where("id = ?", entry.ID).
+					Updates(map[string]any{
+						"status":     string(dto.InvoiceOutboxStatusDead),
+						"attempts":   entry.Attempts + 1,
+						"last_error": fmt.Sprintf("failed to decode payload: %v", err),
+						"updated_at": now,
+					}).Error; err != nil {
+					return err
+				}
+				continue
+			}
+
+			entries = append(entries, entry)
+			ids = append(ids, entry.ID)
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		return tx.Model(&invoiceOutboxModel{}).
+			Where("id IN ?", ids).
+			Updates(map[string]any{
+				"next_attempt_at": now.Add(invoiceOutboxLease),
+				"updated_at":      now,
+			}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *InvoiceOutboxRepository) MarkSent(ctx context.Context, entry *dto.InvoiceOutboxEntry, response *dto.CreateElectronicInvoiceResponse, expectedCUFE string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := markDocumentSent(tx, entry, response, expectedCUFE, now); err != nil {
+			return err
+		}
+
+		return tx.Model(&invoiceOutboxModel{}).
+			Where("id = ?", entry.ID).
+			Updates(map[string]any{
+				"status":     string(dto.InvoiceOutboxStatusSent),
+				"attempts":   entry.Attempts + 1,
+				"last_error": nil,
+				"sent_at":    now,
+				"updated_at": now,
+			}).Error
+	})
+}
+
+// markDocumentSent stores the CUFE and tascode on the bill or the note of the entry
+func markDocumentSent(tx *gorm.DB, entry *dto.InvoiceOutboxEntry, response *dto.CreateElectronicInvoiceResponse, expectedCUFE string, now time.Time) error {
+	switch entry.DocumentType {
+	case dto.ElectronicDocumentTypeCreditNote:
+		return tx.Model(&creditNoteModel{}).
+			Where("id = ?", lo.FromPtr(entry.NoteID)).
+			Updates(map[string]any{
+				"cufe":       response.CUFE,
+				"tascode":    response.Tascode,
+				"updated_at": now,
+			}).Error
+	case dto.ElectronicDocumentTypeDebitNote:
+		return tx.Model(&debitNoteModel{}).
+			Where("id = ?", lo.FromPtr(entry.NoteID)).
+			Updates(map[string]any{
+				"cufe":       response.CUFE,
+				"tascode":    response.Tascode,
+				"updated_at": now,
+			}).Error
+	}
+
+	if err := tx.Model(&billModel{}).
+		Where("id = ?", entry.BillID).
+		Updates(map[string]any{
+			"cufe":          response.CUFE,
+			"expected_cufe": lo.EmptyableToPtr(expectedCUFE),
+			"cufe_mismatch": expectedCUFE != "" && response.CUFE != "" && response.CUFE != expectedCUFE,
+			"tascode":       response.Tascode,
+			"updated_at":    now,
+		}).Error; err != nil {
+		return err
+	}
+
+	if response.PrefixRemaining != nil {
+		return updateProviderRemaining(tx, entry.Prefix, entry.Consecutive, *response.PrefixRemaining)
+	}
+
+	return nil
+}
+
+func (r *InvoiceOutboxRepository) MarkFailed(ctx context.Context, id string, attempts int, lastError string, nextAttemptAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&invoiceOutboxModel{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"attempts":        attempts,
+			"last_error":      lastError,
+			"next_attempt_at": nextAttemptAt,
+			"updated_at":      time.Now(),
+		}).Error
+}
+
+func (r *InvoiceOutboxRepository) MarkDead(ctx context.Context, id string, attempts int, lastError string) error {
+	return r.db.WithContext(ctx).Model(&invoiceOutboxModel{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status":     string(dto.InvoiceOutboxStatusDead),
+			"attempts":   attempts,
+			"last_error": lastError,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+func (r *InvoiceOutboxRepository) FindDeadLetters(ctx context.Context) ([]*dto.InvoiceOutboxEntry, error) {
+	var models []invoiceOutboxModel
+	if err := r.db.WithContext(ctx).
+		Where("status = ?", string(dto.InvoiceOutboxStatusDead)).
+		Order("created_at").
+		Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	// Dead letters are listed without their requests, a payload that does not decode is one
+	// of the reasons an entry ends up here
+	entries := make([]*dto.InvoiceOutboxEntry, len(models))
+	for i := range models {
+		entries[i] = invoiceOutboxModelToDTO(&models[i])
+	}
+
+	return entries, nil
+}
+
+func (r *InvoiceOutboxRepository) Requeue(ctx context.Context, id string) error {
+	now := time.Now()
+	result := r.db.WithContext(ctx).Model(&invoiceOutboxModel{}).
+		Where("id = ? AND status = ?", id, string(dto.InvoiceOutboxStatusDead)).
+		Updates(map[string]any{
+			"status":          string(dto.InvoiceOutboxStatusPending),
+			"attempts":        0,
+			"next_attempt_at": now,
+			"updated_at":      now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// ReleaseRetries makes the entries backing off due again. Entries due within the lease are
+// left alone, they are either claimed by a dispatcher right now or about to be retried anyway
+func (r *InvoiceOutboxRepository) ReleaseRetries(ctx context.Context) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).Model(&invoiceOutboxModel{}).
+		Where("status = ? AND next_attempt_at > ?", string(dto.InvoiceOutboxStatusPending), now.Add(invoiceOutboxLease)).
+		Updates(map[string]any{
+			"next_attempt_at": now,
+			"updated_at":      now,
+		}).Error
+}
+
+func invoiceOutboxModelToDTO(model *invoiceOutboxModel) *dto.InvoiceOutboxEntry {
+	return &dto.InvoiceOutboxEntry{
+		ID:            model.ID,
+		DocumentType:  dto.ElectronicDocumentType(model.DocumentType),
+		BillID:        model.BillID,
+		NoteID:        model.NoteID,
+		Prefix:        model.Prefix,
+		Consecutive:   model.Consecutive,
+		Status:        dto.InvoiceOutboxStatus(model.Status),
+		Attempts:      model.Attempts,
+		LastError:     model.LastError,
+		NextAttemptAt: model.NextAttemptAt,
+		SentAt:        model.SentAt,
+		CreatedAt:     model.CreatedAt,
+		UpdatedAt:     model.UpdatedAt,
+	}
+}
+
+// decodeInvoiceOutboxPayload sets the request the entry was queued with
+func decodeInvoiceOutboxPayload(entry *dto.InvoiceOutboxEntry, payload string) error {
+	switch entry.DocumentType {
+	case dto.ElectronicDocumentTypeCreditNote:
+		entry.CreditNoteRequest = &dto.CreateElectronicCreditNoteRequest{}
+		return json.Unmarshal([]byte(payload), entry.CreditNoteRequest)
+	case dto.ElectronicDocumentTypeDebitNote:
+		entry.DebitNoteRequest = &dto.CreateElectronicDebitNoteRequest{}
+		return json.Unmarshal([]byte(payload), entry.DebitNoteRequest)
+	default:
+		entry.Request = &dto.CreateElectronicInvoiceRequest{}
+		return json.Unmarshal([]byte(payload), entry.Request)
+	}
+}
@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"laguna-escondida/backend/internal/domain/aggregate/resolution"
+	"laguna-escondida/backend/internal/domain/dto"
+	domainError "laguna-escondida/backend/internal/domain/error"
+	"laguna-escondida/backend/internal/domain/ports"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const resolutionDateLayout = "2006-01-02"
+
+type numberingResolutionModel struct {
+	ID                string     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	ResolutionNumber  string     `gorm:"type:varchar(50);not null"`
+	Kind              string     `gorm:"type:varchar(20);not null"`
+	Prefix            string     `gorm:"type:varchar(10);not null"`
+	RangeFrom         int        `gorm:"type:integer;not null"`
+	RangeTo           int        `gorm:"type:integer;not null"`
+	LastConsecutive   int        `gorm:"type:integer;not null"`
+	ValidFrom         time.Time  `gorm:"type:date;not null"`
+	ValidTo           time.Time  `gorm:"type:date;not null"`
+	TechnicalKey      string     `gorm:"type:varchar(255);not null"`
+	ProviderRemaining *int       `gorm:"type:integer"`
+	CreatedAt         time.Time  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt         time.Time  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+	DeletedAt         *time.Time `gorm:"type:timestamp"`
+}
+
+func (numberingResolutionModel) TableName() string {
+	return "numbering_resolutions"
+}
+
+type NumberingResolutionRepository struct {
+	db *gorm.DB
+}
+
+func NewNumberingResolutionRepository(db *gorm.DB) ports.NumberingResolutionRepository {
+	return &NumberingResolutionRepository{db: db}
+}
+
+// activeResolutionQuery narrows the resolutions of a kind to those that can number an invoice
+// on that date, the oldest one is used first
+func activeResolutionQuery(db *gorm.DB, kind dto.NumberingResolutionKind, now time.Time) *gorm.DB {
+	today := now.Format(resolutionDateLayout)
+	return db.Model(&numberingResolutionModel{}).
+		Where("kind = ?", string(kind)).
+		Where("deleted_at IS NULL AND valid_from <= ? AND valid_to >= ? AND last_consecutive < range_to", today, today).
+		Where("provider_remaining IS NULL OR provider_remaining > 0").
+		Order("valid_from, range_from")
+}
+
+// takeInvoiceNumber reserves the next number of the active resolution within the transaction
+// of the bill, so a rolled back bill does not burn a number. The row lock serializes
+// concurrent invoices on the same resolution. Contingency invoices fall back to the
+// electronic numbering when no contingency resolution was registered, so sales never stop
+// The resolution is returned with the number taken as its last consecutive
+func takeInvoiceNumber(tx *gorm.DB, kind dto.NumberingResolutionKind, now time.Time) (*numberingResolutionModel, error) {
+	var model numberingResolutionModel
+	err := activeResolutionQuery(tx, kind, now).Clauses(clause.Locking{Strength: "UPDATE"}).First(&model).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) && kind == dto.NumberingResolutionKindContingency {
+		err = activeResolutionQuery(tx, dto.NumberingResolutionKindElectronic, now).Clauses(clause.Locking{Strength: "UPDATE"}).First(&model).Error
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domainError.ErrNoActiveResolution
+		}
+		return nil, err
+	}
+
+	model.LastConsecutive++
+	if err := tx.Model(&numberingResolutionModel{}).
+		Where("id = ?", model.ID).
+		Updates(map[string]any{
+			"last_consecutive": model.LastConsecutive,
+			"updated_at":       now,
+		}).Error; err != nil {
+		return nil, err
+	}
+
+	return &model, nil
+}
+
+func (r *NumberingResolutionRepository) Create(ctx context.Context, resolution *resolution.Aggregate) error {
+	return r.db.WithContext(ctx).Create(resolutionToModel(resolution.ToDTO())).Error
+}
+
+func (r *NumberingResolutionRepository) Update(ctx context.Context, resolution *resolution.Aggregate, readLastConsecutive int) error {
+	resolutionDTO := resolution.ToDTO()
+	// An invoice numbered since the resolution was read moves last_consecutive, the prefix and
+	// range were validated as unused or against an older last number, so nothing is saved
+	result := r.db.WithContext(ctx).Model(&numberingResolutionModel{}).
+		Where("id = ? AND deleted_at IS NULL AND last_consecutive = ?", resolutionDTO.ID, readLastConsecutive).
+		Updates(map[string]any{
+			"resolution_number": resolutionDTO.ResolutionNumber,
+			"kind":              string(resolutionDTO.Kind),
+			"prefix":            resolutionDTO.Prefix,
+			"range_from":        resolutionDTO.From,
+			"range_to":          resolutionDTO.To,
+			"last_consecutive":  resolutionDTO.LastConsecutive,
+			"valid_from":        resolutionDTO.ValidFrom.Format(resolutionDateLayout),
+			"valid_to":          resolutionDTO.ValidTo.Format(resolutionDateLayout),
+			"technical_key":     resolutionDTO.TechnicalKey,
+			"updated_at":        resolutionDTO.UpdatedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		// Either the resolution is gone or it numbered invoices since it was read
+		var model numberingResolutionModel
+		if err := r.db.WithContext(ctx).Where("id = ? AND deleted_at IS NULL", resolutionDTO.ID).First(&model).Error; err != nil {
+			return err
+		}
+		return domainError.ErrResolutionChanged
+	}
+
+	return nil
+}
+
+// Delete only removes a resolution that never numbered an invoice, one used since it was
+// read is reported as in use
+func (r *NumberingResolutionRepository) Delete(ctx context.Context, id string) error {
+	now := time.Now()
+	result := r.db.WithContext(ctx).Model(&numberingResolutionModel{}).
+		Where("id = ? AND deleted_at IS NULL AND last_consecutive < range_from", id).
+		Updates(map[string]any{
+			"deleted_at": now,
+			"updated_at": now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domainError.ErrResolutionInUse
+	}
+
+	return nil
+}
+
+func (r *NumberingResolutionRepository) FindByID(ctx context.Context, id string) (*dto.NumberingResolution, error) {
+	var model numberingResolutionModel
+	if err := r.db.WithContext(ctx).Where("id = ? AND deleted_at IS NULL", id).First(&model).Error; err != nil {
+		return nil, err
+	}
+
+	return resolutionModelToDTO(&model), nil
+}
+
+func (r *NumberingResolutionRepository) FindAll(ctx context.Context) ([]*dto.NumberingResolution, error) {
+	var models []numberingResolutionModel
+	if err := r.db.WithContext(ctx).Where("deleted_at IS NULL").Order("valid_from, range_from").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	resolutions := make([]*dto.NumberingResolution, len(models))
+	for i := range models {
+		resolutions[i] = resolutionModelToDTO(&models[i])
+	}
+
+	return resolutions, nil
+}
+
+func (r *NumberingResolutionRepository) FindActive(ctx context.Context, now time.Time) (*dto.NumberingResolution, error) {
+	var model numberingResolutionModel
+	if err := activeResolutionQuery(r.db.WithContext(ctx), dto.NumberingResolutionKindElectronic, now).First(&model).Error; err != nil {
+		return nil, err
+	}
+
+	return resolutionModelToDTO(&model), nil
+}
+
+func (r *NumberingResolutionRepository) FindByNumber(ctx context.Context, prefix string, consecutive int) (*dto.NumberingResolution, error) {
+	var model numberingResolutionModel
+	if err := r.db.WithContext(ctx).
+		Where("prefix = ? AND range_from <= ? AND range_to >= ? AND deleted_at IS NULL", prefix, consecutive, consecutive).
+		First(&model).Error; err != nil {
+		return nil, err
+	}
+
+	return resolutionModelToDTO(&model), nil
+}
+
+// updateProviderRemaining keeps the count of numbers left the provider reported for the
+// resolution the consecutive belongs to
+func updateProviderRemaining(tx *gorm.DB, prefix string, consecutive int, remaining int) error {
+	return tx.Model(&numberingResolutionModel{}).
+		Where("prefix = ? AND range_from <= ? AND range_to >= ? AND deleted_at IS NULL", prefix, consecutive, consecutive).
+		Update("provider_remaining", remaining).Error
+}
+
+func resolutionToModel(resolution *dto.NumberingResolution) *numberingResolutionModel {
+	return &numberingResolutionModel{
+		ID:                resolution.ID,
+		ResolutionNumber:  resolution.ResolutionNumber,
+		Kind:              string(resolution.Kind),
+		Prefix:            resolution.Prefix,
+		RangeFrom:         resolution.From,
+		RangeTo:           resolution.To,
+		LastConsecutive:   resolution.LastConsecutive,
+		ValidFrom:         resolution.ValidFrom,
+		ValidTo:           resolution.ValidTo,
+		TechnicalKey:      resolution.TechnicalKey,
+		ProviderRemaining: resolution.ProviderRemaining,
+		CreatedAt:         resolution.CreatedAt,
+		UpdatedAt:         resolution.UpdatedAt,
+	}
+}
+
+func resolutionModelToDTO(model *numberingResolutionModel) *dto.NumberingResolution {
+	return &dto.NumberingResolution{
+		ID:                model.ID,
+		ResolutionNumber:  model.ResolutionNumber,
+		Kind:              dto.NumberingResolutionKind(model.Kind),
+		Prefix:            model.Prefix,
+		From:              model.RangeFrom,
+		To:                model.RangeTo,
+		LastConsecutive:   model.LastConsecutive,
+		ValidFrom:         model.ValidFrom,
+		ValidTo:           model.ValidTo,
+		TechnicalKey:      model.TechnicalKey,
+		ProviderRemaining: model.ProviderRemaining,
+		CreatedAt:         model.CreatedAt,
+		UpdatedAt:         model.UpdatedAt,
+	}
+}
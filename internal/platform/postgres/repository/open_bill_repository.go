@@ -5,9 +5,11 @@ import (
 	"time"
 
 	"laguna-escondida/backend/internal/domain/dto"
+	orderError "laguna-escondida/backend/internal/domain/error"
 	"laguna-escondida/backend/internal/domain/ports"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OpenBillRepository struct {
@@ -21,10 +23,15 @@ func NewOpenBillRepository(db *gorm.DB) ports.OpenBillRepository {
 type openBillModel struct {
 	ID                 string     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
 	TemporalIdentifier string     `gorm:"type:varchar(255);not null"`
-	TotalPrice         float64    `gorm:"type:double precision;not null"`
-	VAT                float64    `gorm:"type:double precision;not null"`
-	ICO                float64    `gorm:"type:double precision;not null"`
-	Tip                float64    `gorm:"type:double precision;not null"`
+	Status             string     `gorm:"type:varchar(20);not null;default:open"`
+	BillID             *string    `gorm:"type:uuid"`
+	PayingSince        *time.Time `gorm:"type:timestamp"`
+	ParentID           *string    `gorm:"type:uuid"`
+	SplitAmount        *dto.Money `gorm:"type:numeric(14,2)"`
+	TotalPrice         dto.Money  `gorm:"type:numeric(14,2);not null"`
+	VAT                dto.Money  `gorm:"type:numeric(14,2);not null"`
+	ICO                dto.Money  `gorm:"type:numeric(14,2);not null"`
+	Tip                dto.Money  `gorm:"type:numeric(14,2);not null"`
 	DocumentURL        *string    `gorm:"type:text"`
 	CreatedAt          time.Time  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
 	UpdatedAt          time.Time  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
@@ -35,92 +42,196 @@ func (openBillModel) TableName() string {
 	return "open_bills"
 }
 
+// openBillProductModel is an order line, it keeps the price it was charged at when saved
 type openBillProductModel struct {
-	ID         string     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	OpenBillID string     `gorm:"type:uuid;not null"`
-	ProductID  string     `gorm:"type:uuid;not null"`
-	Quantity   int        `gorm:"type:integer;not null;default:1"`
-	CreatedAt  time.Time  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
-	UpdatedAt  time.Time  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
-	DeletedAt  *time.Time `gorm:"type:timestamp"`
+	ID                  string     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	OpenBillID          string     `gorm:"type:uuid;not null"`
+	ProductID           string     `gorm:"type:uuid;not null"`
+	Quantity            int        `gorm:"type:integer;not null;default:1"`
+	TotalPriceWithTaxes *dto.Money `gorm:"type:numeric(14,2);column:total_price_with_taxes"`
+	VAT                 *float64   `gorm:"type:double precision"`
+	ICO                 *float64   `gorm:"type:double precision"`
+	CreatedAt           time.Time  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt           time.Time  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+	DeletedAt           *time.Time `gorm:"type:timestamp"`
 }
 
 func (openBillProductModel) TableName() string {
 	return "open_bills_products"
 }
 
+// price returns the price the line was saved with, nil when it was saved without one
+func (m *openBillProductModel) price() *dto.OrderItemPrice {
+	if m.TotalPriceWithTaxes == nil || m.VAT == nil || m.ICO == nil {
+		return nil
+	}
+
+	return &dto.OrderItemPrice{
+		TotalPriceWithTaxes: *m.TotalPriceWithTaxes,
+		VAT:                 *m.VAT,
+		ICO:                 *m.ICO,
+	}
+}
+
+// samePrice tells whether the line is already saved with the price of the item
+func (m *openBillProductModel) samePrice(price *dto.OrderItemPrice) bool {
+	current := m.price()
+	if current == nil || price == nil {
+		return current == price
+	}
+
+	return *current == *price
+}
+
+// priceColumns are the columns of the line price, all of them NULL without a price
+func priceColumns(price *dto.OrderItemPrice) map[string]interface{} {
+	if price == nil {
+		return map[string]interface{}{"total_price_with_taxes": nil, "vat": nil, "ico": nil}
+	}
+
+	return map[string]interface{}{
+		"total_price_with_taxes": price.TotalPriceWithTaxes,
+		"vat":                    price.VAT,
+		"ico":                    price.ICO,
+	}
+}
+
+type openBillTransferModel struct {
+	ID               string    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	SourceOpenBillID string    `gorm:"type:uuid;not null"`
+	TargetOpenBillID string    `gorm:"type:uuid;not null"`
+	ProductID        string    `gorm:"type:uuid;not null"`
+	Quantity         int       `gorm:"type:integer;not null"`
+	MovedBy          string    `gorm:"type:varchar(255);not null"`
+	CreatedAt        time.Time `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (openBillTransferModel) TableName() string {
+	return "open_bill_transfers"
+}
+
 type billModel struct {
-	ID             string     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	TotalAmount    float64    `gorm:"type:double precision;not null;column:total_amount"`
-	DiscountAmount float64    `gorm:"type:double precision;not null;default:0;column:discount_amount"`
-	VAT            float64    `gorm:"type:double precision;not null"`
-	ICO            float64    `gorm:"type:double precision;not null"`
-	Tip            float64    `gorm:"type:double precision;not null"`
-	DocumentURL    *string    `gorm:"type:text"`
-	CUFE           *string    `gorm:"type:varchar(255)"`
-	Tascode        *string    `gorm:"type:varchar(255)"`
-	CreatedAt      time.Time  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
-	UpdatedAt      time.Time  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
-	DeletedAt      *time.Time `gorm:"type:timestamp"`
+	ID                  string     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TotalAmount         dto.Money  `gorm:"type:numeric(14,2);not null;column:total_amount"`
+	DiscountAmount      dto.Money  `gorm:"type:numeric(14,2);not null;default:0;column:discount_amount"`
+	VAT                 dto.Money  `gorm:"type:numeric(14,2);not null"`
+	ICO                 dto.Money  `gorm:"type:numeric(14,2);not null"`
+	Tip                 dto.Money  `gorm:"type:numeric(14,2);not null"`
+	Prefix              *string    `gorm:"type:varchar(10)"`
+	Consecutive         *int       `gorm:"type:integer"`
+	DocumentURL         *string    `gorm:"type:text"`
+	CUFE                *string    `gorm:"type:varchar(255)"`
+	ExpectedCUFE        *string    `gorm:"type:varchar(96);column:expected_cufe"`
+	CUFEMismatch        bool       `gorm:"not null;default:false;column:cufe_mismatch"`
+	Tascode             *string    `gorm:"type:varchar(255)"`
+	DIANStatus          *string    `gorm:"type:varchar(20);column:dian_status"`
+	ContingencyPeriodID *string    `gorm:"type:uuid"`
+	BillOwnerID         *string    `gorm:"type:varchar(255)"`
+	PDF                 *string    `gorm:"type:text;column:pdf"`
+	XML                 *string    `gorm:"type:text;column:xml"`
+	CreatedAt           time.Time  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt           time.Time  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+	DeletedAt           *time.Time `gorm:"type:timestamp"`
 }
 
 func (billModel) TableName() string {
 	return "bills"
 }
 
+// billProductModel is a billed line as it was invoiced, it does not follow catalog changes
 type billProductModel struct {
-	ID        string     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	BillID    string     `gorm:"type:uuid;not null"`
-	ProductID string     `gorm:"type:uuid;not null"`
-	Quantity  int        `gorm:"type:integer;not null;default:1"`
-	CreatedAt time.Time  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
-	UpdatedAt time.Time  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
-	DeletedAt *time.Time `gorm:"type:timestamp"`
+	ID          string     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	BillID      string     `gorm:"type:uuid;not null"`
+	ProductID   string     `gorm:"type:uuid;not null"`
+	LineNumber  int        `gorm:"type:integer;not null"`
+	Quantity    int        `gorm:"type:integer;not null;default:1"`
+	UnitPrice   dto.Money  `gorm:"type:numeric(14,2);not null"`
+	Description *string    `gorm:"type:text"`
+	Brand       *string    `gorm:"type:varchar(255)"`
+	Model       *string    `gorm:"type:varchar(255)"`
+	Code        string     `gorm:"type:varchar(255);not null"`
+	VATRate     float64    `gorm:"type:double precision;not null;column:vat_rate"`
+	ICORate     float64    `gorm:"type:double precision;not null;column:ico_rate"`
+	VATAmount   dto.Money  `gorm:"type:numeric(14,2);not null;column:vat_amount"`
+	ICOAmount   dto.Money  `gorm:"type:numeric(14,2);not null;column:ico_amount"`
+	Allowances  string     `gorm:"type:jsonb;not null;default:'[]'"`
+	CreatedAt   time.Time  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt   time.Time  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+	DeletedAt   *time.Time `gorm:"type:timestamp"`
 }
 
 func (billProductModel) TableName() string {
 	return "bill_products"
 }
 
+type billPaymentModel struct {
+	ID           string    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	BillID       string    `gorm:"type:uuid;not null"`
+	Method       string    `gorm:"type:varchar(50);not null"`
+	Amount       dto.Money `gorm:"type:numeric(14,2);not null"`
+	ChangeAmount dto.Money `gorm:"type:numeric(14,2);not null;default:0;column:change_amount"`
+	Reference    *string   `gorm:"type:varchar(255)"`
+	CreatedAt    time.Time `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (billPaymentModel) TableName() string {
+	return "bill_payments"
+}
+
 func (r *OpenBillRepository) Create(ctx context.Context, openBill *dto.OpenBill, products []dto.OrderProductItem) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// Create the open bill
-		model := &openBillModel{
-			TemporalIdentifier: openBill.TemporalIdentifier,
-			TotalPrice:         openBill.TotalPrice,
-			VAT:                openBill.VAT,
-			ICO:                openBill.ICO,
-			Tip:                openBill.Tip,
-			DocumentURL:        openBill.DocumentURL,
-			CreatedAt:          openBill.CreatedAt,
-			UpdatedAt:          openBill.UpdatedAt,
-		}
+		return r.create(tx, openBill, products)
+	})
+}
 
-		if err := tx.Create(model).Error; err != nil {
+func (r *OpenBillRepository) create(tx *gorm.DB, openBill *dto.OpenBill, products []dto.OrderProductItem) error {
+	// Create the open bill
+	model := &openBillModel{
+		TemporalIdentifier: openBill.TemporalIdentifier,
+		Status:             string(openBill.Status),
+		ParentID:           openBill.ParentID,
+		SplitAmount:        openBill.SplitAmount,
+		TotalPrice:         openBill.TotalPrice,
+		VAT:                openBill.VAT,
+		ICO:                openBill.ICO,
+		Tip:                openBill.Tip,
+		DocumentURL:        openBill.DocumentURL,
+		CreatedAt:          openBill.CreatedAt,
+		UpdatedAt:          openBill.UpdatedAt,
+	}
+
+	if err := tx.Create(model).Error; err != nil {
+		return err
+	}
+
+	// Set the ID back to the DTO
+	openBill.ID = model.ID
+
+	// Create associations with products if any
+	for _, item := range products {
+		if err := tx.Create(newOpenBillProduct(model.ID, item, time.Now())).Error; err != nil {
 			return err
 		}
+	}
 
-		// Set the ID back to the DTO
-		openBill.ID = model.ID
-
-		// Create associations with products if any
-		if len(products) > 0 {
-			for _, item := range products {
-				openBillProduct := &openBillProductModel{
-					OpenBillID: model.ID,
-					ProductID:  item.ProductID,
-					Quantity:   item.Quantity,
-					CreatedAt:  time.Now(),
-					UpdatedAt:  time.Now(),
-				}
-				if err := tx.Create(openBillProduct).Error; err != nil {
-					return err
-				}
-			}
-		}
+	return nil
+}
 
-		return nil
-	})
+func newOpenBillProduct(openBillID string, item dto.OrderProductItem, now time.Time) *openBillProductModel {
+	model := &openBillProductModel{
+		OpenBillID: openBillID,
+		ProductID:  item.ProductID,
+		Quantity:   item.Quantity,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if item.Price != nil {
+		model.TotalPriceWithTaxes = &item.Price.TotalPriceWithTaxes
+		model.VAT = &item.Price.VAT
+		model.ICO = &item.Price.ICO
+	}
+
+	return model
 }
 
 func (r *OpenBillRepository) FindByID(ctx context.Context, id string) (*dto.OpenBill, error) {
@@ -129,101 +240,316 @@ func (r *OpenBillRepository) FindByID(ctx context.Context, id string) (*dto.Open
 		return nil, err
 	}
 
-	// Fetch associated products
+	// Product lines are loaded separately through FindItemsByID
+	return r.toDTO(&model), nil
+}
+
+var openBillSortColumns = map[dto.OpenBillSortField]string{
+	dto.OpenBillSortFieldCreatedAt:  "created_at",
+	dto.OpenBillSortFieldUpdatedAt:  "updated_at",
+	dto.OpenBillSortFieldTotalPrice: "total_price",
+}
+
+func (r *OpenBillRepository) FindAll(ctx context.Context, filter *dto.ListOrdersRequest) ([]*dto.OpenBill, error) {
+	query := r.filteredQuery(ctx, filter)
+
+	column, ok := openBillSortColumns[filter.SortBy]
+	if !ok {
+		column = "created_at"
+	}
+	query = query.Order(clause.OrderByColumn{
+		Column: clause.Column{Name: column},
+		Desc:   filter.SortOrder != dto.SortOrderAsc,
+	})
+
+	if filter.PageSize > 0 {
+		query = query.Limit(filter.PageSize).Offset((filter.Page - 1) * filter.PageSize)
+	}
+
+	var models []openBillModel
+	if err := query.Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	openBills := make([]*dto.OpenBill, len(models))
+	for i, model := range models {
+		openBills[i] = r.toDTO(&model)
+	}
+
+	return openBills, nil
+}
+
+func (r *OpenBillRepository) Count(ctx context.Context, filter *dto.ListOrdersRequest) (int, error) {
+	var total int64
+	if err := r.filteredQuery(ctx, filter).Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	return int(total), nil
+}
+
+func (r *OpenBillRepository) filteredQuery(ctx context.Context, filter *dto.ListOrdersRequest) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&openBillModel{}).Where("deleted_at IS NULL")
+
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("created_at <= ?", *filter.CreatedTo)
+	}
+
+	return query
+}
+
+func (r *OpenBillRepository) FindItemsByID(ctx context.Context, id string) ([]dto.OrderProductItem, error) {
+	return r.findItems(r.db.WithContext(ctx), id)
+}
+
+func (r *OpenBillRepository) findItems(tx *gorm.DB, id string) ([]dto.OrderProductItem, error) {
 	var productModels []openBillProductModel
-	if err := r.db.WithContext(ctx).Where("open_bill_id = ? AND deleted_at IS NULL", id).Find(&productModels).Error; err != nil {
+	if err := tx.Where("open_bill_id = ? AND deleted_at IS NULL", id).Order("created_at").Find(&productModels).Error; err != nil {
 		return nil, err
 	}
 
-	// Fetch product details (would need product repository, but for now just return the open bill)
-	openBill := r.toDTO(&model)
+	items := make([]dto.OrderProductItem, len(productModels))
+	for i, model := range productModels {
+		items[i] = dto.OrderProductItem{
+			ProductID: model.ProductID,
+			Quantity:  model.Quantity,
+			Price:     model.price(),
+		}
+	}
 
-	return openBill, nil
+	return items, nil
 }
 
 func (r *OpenBillRepository) Update(ctx context.Context, openBillID string, openBill *dto.OpenBill, products []dto.OrderProductItem) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// Update the open bill
-		updateData := map[string]interface{}{
-			"total_price": openBill.TotalPrice,
-			"vat":         openBill.VAT,
-			"ico":         openBill.ICO,
-			"tip":         openBill.Tip,
-			"updated_at":  openBill.UpdatedAt,
-		}
-		if err := tx.Model(&openBillModel{}).Where("id = ? AND deleted_at IS NULL", openBillID).Updates(updateData).Error; err != nil {
+		if err := r.updateOpenBill(tx, openBillID, openBill); err != nil {
 			return err
 		}
 
-		// Fetch all existing products (including soft-deleted ones) to check what exists
-		var existingProducts []openBillProductModel
-		if err := tx.Where("open_bill_id = ?", openBillID).Find(&existingProducts).Error; err != nil {
-			return err
-		}
+		return r.syncProducts(tx, openBillID, products)
+	})
+}
 
-		// Create a map of existing products by product_id
-		existingProductMap := make(map[string]*openBillProductModel)
-		for i := range existingProducts {
-			existingProductMap[existingProducts[i].ProductID] = &existingProducts[i]
-		}
+// updateOpenBill saves the totals and status of an open bill as long as it is still open
+func (r *OpenBillRepository) updateOpenBill(tx *gorm.DB, openBillID string, openBill *dto.OpenBill) error {
+	status := openBill.Status
+	if status == "" {
+		status = dto.OpenBillStatusOpen
+	}
 
-		// Create a map of requested products by product_id
-		requestedProductMap := make(map[string]dto.OrderProductItem)
-		for _, item := range products {
-			requestedProductMap[item.ProductID] = item
-		}
+	// Update the open bill
+	updateData := map[string]interface{}{
+		"status":      status,
+		"total_price": openBill.TotalPrice,
+		"vat":         openBill.VAT,
+		"ico":         openBill.ICO,
+		"tip":         openBill.Tip,
+		"updated_at":  openBill.UpdatedAt,
+	}
+	result := tx.Model(&openBillModel{}).
+		Where("id = ? AND status = ? AND deleted_at IS NULL", openBillID, dto.OpenBillStatusOpen).
+		Updates(updateData)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return orderError.ErrInvalidOrderStatus
+	}
 
-		// Process each requested product
-		for _, item := range products {
-			existing, exists := existingProductMap[item.ProductID]
-			now := time.Now()
+	return nil
+}
 
-			if exists {
-				// Product exists - update or restore
-				if existing.DeletedAt != nil {
-					// Restore soft-deleted product and update quantity
-					if err := tx.Model(existing).Updates(map[string]interface{}{
-						"quantity":   item.Quantity,
-						"updated_at": now,
-						"deleted_at": nil,
-					}).Error; err != nil {
-						return err
-					}
-				} else if existing.Quantity != item.Quantity {
-					// Update quantity if different
-					if err := tx.Model(existing).Updates(map[string]interface{}{
-						"quantity":   item.Quantity,
-						"updated_at": now,
-					}).Error; err != nil {
-						return err
-					}
-				}
-			} else {
-				// Product doesn't exist - create new
-				newProduct := &openBillProductModel{
-					OpenBillID: openBillID,
-					ProductID:  item.ProductID,
-					Quantity:   item.Quantity,
-					CreatedAt:  now,
-					UpdatedAt:  now,
+// syncProducts leaves the open bill with exactly the given products and quantities
+func (r *OpenBillRepository) syncProducts(tx *gorm.DB, openBillID string, products []dto.OrderProductItem) error {
+	// Fetch all existing products (including soft-deleted ones) to check what exists
+	var existingProducts []openBillProductModel
+	if err := tx.Where("open_bill_id = ?", openBillID).Find(&existingProducts).Error; err != nil {
+		return err
+	}
+
+	// Create a map of existing products by product_id
+	existingProductMap := make(map[string]*openBillProductModel)
+	for i := range existingProducts {
+		existingProductMap[existingProducts[i].ProductID] = &existingProducts[i]
+	}
+
+	// Create a map of requested products by product_id
+	requestedProductMap := make(map[string]dto.OrderProductItem)
+	for _, item := range products {
+		requestedProductMap[item.ProductID] = item
+	}
+
+	// Process each requested product
+	for _, item := range products {
+		existing, exists := existingProductMap[item.ProductID]
+		now := time.Now()
+
+		if exists {
+			// Product exists - update or restore
+			updateData := priceColumns(item.Price)
+			updateData["quantity"] = item.Quantity
+			updateData["updated_at"] = now
+			if existing.DeletedAt != nil {
+				// Restore soft-deleted product and update quantity and price
+				updateData["deleted_at"] = nil
+				if err := tx.Model(existing).Updates(updateData).Error; err != nil {
+					return err
 				}
-				if err := tx.Create(newProduct).Error; err != nil {
+			} else if existing.Quantity != item.Quantity || !existing.samePrice(item.Price) {
+				// Update quantity and price if different
+				if err := tx.Model(existing).Updates(updateData).Error; err != nil {
 					return err
 				}
 			}
+		} else {
+			// Product doesn't exist - create new
+			if err := tx.Create(newOpenBillProduct(openBillID, item, now)).Error; err != nil {
+				return err
+			}
 		}
+	}
 
-		// Soft delete products that are not in the request
-		for productID, existing := range existingProductMap {
-			if _, inRequest := requestedProductMap[productID]; !inRequest && existing.DeletedAt == nil {
-				// Product exists but not in request - soft delete it
-				now := time.Now()
-				if err := tx.Model(existing).Updates(map[string]interface{}{
-					"deleted_at": &now,
-					"updated_at": now,
-				}).Error; err != nil {
-					return err
-				}
+	// Soft delete products that are not in the request
+	for productID, existing := range existingProductMap {
+		if _, inRequest := requestedProductMap[productID]; !inRequest && existing.DeletedAt == nil {
+			// Product exists but not in request - soft delete it
+			now := time.Now()
+			if err := tx.Model(existing).Updates(map[string]interface{}{
+				"deleted_at": &now,
+				"updated_at": now,
+			}).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *OpenBillRepository) UpdateStatus(ctx context.Context, openBillID string, from dto.OpenBillStatus, to dto.OpenBillStatus, billID *string) error {
+	updateData := map[string]interface{}{
+		"status":     to,
+		"updated_at": time.Now(),
+	}
+	if billID != nil {
+		updateData["bill_id"] = *billID
+	}
+
+	result := r.db.WithContext(ctx).
+		Model(&openBillModel{}).
+		Where("id = ? AND status = ? AND deleted_at IS NULL", openBillID, from).
+		Updates(updateData)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return orderError.ErrInvalidOrderStatus
+	}
+
+	return nil
+}
+
+func (r *OpenBillRepository) StartPayment(ctx context.Context, openBillID string, staleBefore time.Time) error {
+	now := time.Now()
+	result := r.db.WithContext(ctx).
+		Model(&openBillModel{}).
+		Where("id = ? AND deleted_at IS NULL", openBillID).
+		Where("status = ? OR (status = ? AND paying_since < ?)", dto.OpenBillStatusOpen, dto.OpenBillStatusPaying, staleBefore).
+		Updates(map[string]interface{}{
+			"status":       dto.OpenBillStatusPaying,
+			"paying_since": now,
+			"updated_at":   now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return orderError.ErrInvalidOrderStatus
+	}
+
+	return nil
+}
+
+// lockOpenBill locks the open bill until the transaction ends, failing when it is no longer open
+func (r *OpenBillRepository) lockOpenBill(tx *gorm.DB, openBillID string) error {
+	var model openBillModel
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND status = ? AND deleted_at IS NULL", openBillID, dto.OpenBillStatusOpen).
+		Limit(1).
+		Find(&model)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return orderError.ErrInvalidOrderStatus
+	}
+
+	return nil
+}
+
+// sameOrderItems tells whether both lists hold the same products with the same quantities and prices
+func sameOrderItems(a, b []dto.OrderProductItem) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	items := make(map[string]dto.OrderProductItem, len(a))
+	for _, item := range a {
+		items[item.ProductID] = item
+	}
+	for _, item := range b {
+		other, ok := items[item.ProductID]
+		if !ok || other.Quantity != item.Quantity {
+			return false
+		}
+		if other.Price == nil || item.Price == nil {
+			if other.Price != item.Price {
+				return false
+			}
+		} else if *other.Price != *item.Price {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *OpenBillRepository) Split(ctx context.Context, openBillID string, splitItems []dto.OrderProductItem, parts []*dto.OpenBill, items [][]dto.OrderProductItem) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := r.lockOpenBill(tx, openBillID); err != nil {
+			return err
+		}
+
+		current, err := r.findItems(tx, openBillID)
+		if err != nil {
+			return err
+		}
+		if !sameOrderItems(current, splitItems) {
+			return orderError.ErrOrderChanged
+		}
+
+		result := tx.Model(&openBillModel{}).
+			Where("id = ? AND status = ? AND deleted_at IS NULL", openBillID, dto.OpenBillStatusOpen).
+			Updates(map[string]interface{}{
+				"status":     dto.OpenBillStatusSplit,
+				"updated_at": time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return orderError.ErrInvalidOrderStatus
+		}
+
+		for i, part := range parts {
+			if err := r.create(tx, part, items[i]); err != nil {
+				return err
 			}
 		}
 
@@ -231,79 +557,85 @@ func (r *OpenBillRepository) Update(ctx context.Context, openBillID string, open
 	})
 }
 
-func (r *OpenBillRepository) PayOrder(ctx context.Context, openBillID string) (*dto.Bill, error) {
-	var bill *dto.Bill
+func (r *OpenBillRepository) Transfer(ctx context.Context, sourceID string, targetID string, apply ports.OrderTransferFunc) (*dto.OrderItemsTransfer, error) {
+	// Always lock the open bills in the same order so opposite transfers cannot deadlock
+	lockOrder := []string{sourceID, targetID}
+	if targetID < sourceID {
+		lockOrder[0], lockOrder[1] = targetID, sourceID
+	}
+
+	var transfer *dto.OrderItemsTransfer
 	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// Fetch the open bill
-		var openBillModel openBillModel
-		if err := tx.Where("id = ? AND deleted_at IS NULL", openBillID).First(&openBillModel).Error; err != nil {
-			return err
+		for _, openBillID := range lockOrder {
+			if err := r.lockOpenBill(tx, openBillID); err != nil {
+				return err
+			}
 		}
 
-		// Fetch all non-deleted open_bill_products
-		var openBillProducts []openBillProductModel
-		if err := tx.Where("open_bill_id = ? AND deleted_at IS NULL", openBillID).Find(&openBillProducts).Error; err != nil {
+		// The transfer is worked out from the products read under the locks, so quantities
+		// changed since the caller last looked at the orders are validated too
+		sourceItems, err := r.findItems(tx, sourceID)
+		if err != nil {
 			return err
 		}
-
-		// Create the bill from open_bill data
-		now := time.Now()
-		billModel := &billModel{
-			TotalAmount:    openBillModel.TotalPrice,
-			DiscountAmount: 0.0,
-			VAT:            openBillModel.VAT,
-			ICO:            openBillModel.ICO,
-			Tip:            openBillModel.Tip,
-			DocumentURL:    nil,
-			CreatedAt:      now,
-			UpdatedAt:      now,
+		targetItems, err := r.findItems(tx, targetID)
+		if err != nil {
+			return err
 		}
 
-		if err := tx.Create(billModel).Error; err != nil {
+		transfer, err = apply(sourceItems, targetItems)
+		if err != nil {
 			return err
 		}
 
-		// Create bill_products from non-deleted open_bill_products
-		for _, openBillProduct := range openBillProducts {
-			billProduct := &billProductModel{
-				BillID:    billModel.ID,
-				ProductID: openBillProduct.ProductID,
-				Quantity:  openBillProduct.Quantity,
-				CreatedAt: now,
-				UpdatedAt: now,
+		sides := []struct {
+			openBill *dto.OpenBill
+			items    []dto.OrderProductItem
+		}{
+			{openBill: transfer.Source, items: transfer.SourceItems},
+			{openBill: transfer.Target, items: transfer.TargetItems},
+		}
+		for _, side := range sides {
+			if err := r.updateOpenBill(tx, side.openBill.ID, side.openBill); err != nil {
+				return err
 			}
-			if err := tx.Create(billProduct).Error; err != nil {
+			if err := r.syncProducts(tx, side.openBill.ID, side.items); err != nil {
 				return err
 			}
 		}
 
-		// Convert to DTO
-		bill = &dto.Bill{
-			ID:             billModel.ID,
-			TotalAmount:    billModel.TotalAmount,
-			DiscountAmount: billModel.DiscountAmount,
-			VAT:            billModel.VAT,
-			ICO:            billModel.ICO,
-			Tip:            billModel.Tip,
-			DocumentURL:    billModel.DocumentURL,
-			CreatedAt:      billModel.CreatedAt,
-			UpdatedAt:      billModel.UpdatedAt,
+		for i := range transfer.Transfers {
+			model := &openBillTransferModel{
+				SourceOpenBillID: transfer.Transfers[i].SourceOrderID,
+				TargetOpenBillID: transfer.Transfers[i].TargetOrderID,
+				ProductID:        transfer.Transfers[i].ProductID,
+				Quantity:         transfer.Transfers[i].Quantity,
+				MovedBy:          transfer.Transfers[i].MovedBy,
+				CreatedAt:        transfer.Transfers[i].CreatedAt,
+			}
+			if err := tx.Create(model).Error; err != nil {
+				return err
+			}
+			transfer.Transfers[i].ID = model.ID
 		}
 
 		return nil
 	})
-
 	if err != nil {
 		return nil, err
 	}
 
-	return bill, nil
+	return transfer, nil
 }
 
 func (r *OpenBillRepository) toDTO(model *openBillModel) *dto.OpenBill {
 	return &dto.OpenBill{
 		ID:                 model.ID,
 		TemporalIdentifier: model.TemporalIdentifier,
+		Status:             dto.OpenBillStatus(model.Status),
+		BillID:             model.BillID,
+		ParentID:           model.ParentID,
+		SplitAmount:        model.SplitAmount,
 		TotalPrice:         model.TotalPrice,
 		VAT:                model.VAT,
 		ICO:                model.ICO,
@@ -24,14 +24,14 @@ type productModel struct {
 	Name                string     `gorm:"type:varchar(255);not null"`
 	Category            string     `gorm:"type:varchar(100);not null"`
 	Version             int        `gorm:"type:integer;not null"`
-	UnitPrice           float64    `gorm:"type:double precision;not null;column:unit_price"`
+	UnitPrice           dto.Money  `gorm:"type:numeric(14,2);not null;column:unit_price"`
 	VAT                 float64    `gorm:"type:double precision;not null"`
 	ICO                 float64    `gorm:"type:double precision;not null"`
 	Description         *string    `gorm:"type:text"`
 	Brand               *string    `gorm:"type:varchar(255)"`
 	Model               *string    `gorm:"type:varchar(255)"`
 	SKU                 string     `gorm:"type:varchar(255);not null"`
-	TotalPriceWithTaxes float64    `gorm:"type:double precision;not null;column:total_price_with_taxes"`
+	TotalPriceWithTaxes dto.Money  `gorm:"type:numeric(14,2);not null;column:total_price_with_taxes"`
 	CreatedAt           time.Time  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
 	UpdatedAt           time.Time  `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
 	DeletedAt           *time.Time `gorm:"type:timestamp"`
@@ -1,5 +1,7 @@
 package constants
 
+// Notes have their own sequences, invoices are numbered with the active numbering resolution
 const (
-	InvoicePrefix = "LAG"
+	CreditNotePrefix = "NC"
+	DebitNotePrefix  = "ND"
 )
@@ -0,0 +1,33 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"laguna-escondida/backend/internal/domain/service"
+)
+
+// idempotencyCleanupMaxBackoff bounds the wait after failed cleanups, expired keys are
+// harmless until then because they are taken over when reused
+const idempotencyCleanupMaxBackoff = 6 * time.Hour
+
+// IdempotencyKeyCleaner deletes the idempotency keys whose replay window is over
+type IdempotencyKeyCleaner struct {
+	poller
+}
+
+func NewIdempotencyKeyCleaner(idempotencyService *service.IdempotencyService, interval time.Duration) *IdempotencyKeyCleaner {
+	return &IdempotencyKeyCleaner{
+		poller: poller{
+			name:       "Idempotency key cleaner",
+			interval:   interval,
+			maxBackoff: idempotencyCleanupMaxBackoff,
+			task:       idempotencyService.PurgeExpired,
+		},
+	}
+}
+
+// Run blocks until ctx is cancelled
+func (c *IdempotencyKeyCleaner) Run(ctx context.Context) {
+	c.run(ctx)
+}
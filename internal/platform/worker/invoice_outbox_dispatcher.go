@@ -0,0 +1,37 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"laguna-escondida/backend/internal/domain/service"
+)
+
+// InvoiceOutboxDispatcher sends the invoices waiting in the outbox to the provider. Each
+// invoice keeps its own retry schedule, the dispatcher only backs off when the outbox itself fails
+type InvoiceOutboxDispatcher struct {
+	poller
+}
+
+func NewInvoiceOutboxDispatcher(
+	outboxService *service.InvoiceOutboxService,
+	interval time.Duration,
+	maxBackoff time.Duration,
+	batchSize int,
+) *InvoiceOutboxDispatcher {
+	return &InvoiceOutboxDispatcher{
+		poller: poller{
+			name:       "Invoice outbox dispatcher",
+			interval:   interval,
+			maxBackoff: maxBackoff,
+			task: func(ctx context.Context) (int, error) {
+				return outboxService.DispatchPending(ctx, batchSize)
+			},
+		},
+	}
+}
+
+// Run blocks until ctx is cancelled
+func (d *InvoiceOutboxDispatcher) Run(ctx context.Context) {
+	d.run(ctx)
+}
@@ -0,0 +1,37 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"laguna-escondida/backend/internal/domain/service"
+)
+
+// InvoiceReconciliationWorker periodically syncs the DIAN status of the bills the provider
+// is still processing, backing off while the provider fails
+type InvoiceReconciliationWorker struct {
+	poller
+}
+
+func NewInvoiceReconciliationWorker(
+	invoiceService *service.InvoiceService,
+	interval time.Duration,
+	maxBackoff time.Duration,
+	batchSize int,
+) *InvoiceReconciliationWorker {
+	return &InvoiceReconciliationWorker{
+		poller: poller{
+			name:       "Invoice reconciliation worker",
+			interval:   interval,
+			maxBackoff: maxBackoff,
+			task: func(ctx context.Context) (int, error) {
+				return invoiceService.ReconcilePendingBills(ctx, batchSize)
+			},
+		},
+	}
+}
+
+// Run blocks until ctx is cancelled
+func (w *InvoiceReconciliationWorker) Run(ctx context.Context) {
+	w.run(ctx)
+}
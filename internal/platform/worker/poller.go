@@ -0,0 +1,52 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// poller runs a task every interval until its context is cancelled. When the task fails it
+// waits twice as long before the next run, up to maxBackoff, and goes back to the regular
+// interval after a clean run
+type poller struct {
+	name       string
+	interval   time.Duration
+	maxBackoff time.Duration
+	task       func(ctx context.Context) (int, error)
+}
+
+// run blocks until ctx is cancelled, a task in progress is cancelled with it
+func (p *poller) run(ctx context.Context) {
+	log.Printf("%s started, polling every %s", p.name, p.interval)
+
+	maxBackoff := max(p.maxBackoff, p.interval)
+	wait := p.interval
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("%s stopped", p.name)
+			return
+		case <-timer.C:
+		}
+
+		processed, err := p.task(ctx)
+		switch {
+		case ctx.Err() != nil:
+			continue
+		case err != nil:
+			wait = min(wait*2, maxBackoff)
+			log.Printf("%s failed, retrying in %s: %v", p.name, wait, err)
+		default:
+			wait = p.interval
+			if processed > 0 {
+				log.Printf("%s processed %d items", p.name, processed)
+			}
+		}
+
+		timer.Reset(wait)
+	}
+}